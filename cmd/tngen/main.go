@@ -0,0 +1,62 @@
+// Command tngen generates typed Go wrappers for custom actions.
+//
+// Usage:
+//
+//	tngen -spec procedures.json -out procedures_gen.go [-pkg name]
+//
+// or from a go:generate directive:
+//
+//	//go:generate go run github.com/trufnetwork/sdk-go/cmd/tngen -spec procedures.json -out procedures_gen.go
+//
+// See package github.com/trufnetwork/sdk-go/core/tngen for the spec format.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/trufnetwork/sdk-go/core/tngen"
+)
+
+func main() {
+	specPath := flag.String("spec", "", "path to the JSON action spec")
+	outPath := flag.String("out", "", "output file (default: stdout)")
+	pkg := flag.String("pkg", "", "Go package name, overriding the spec")
+	flag.Parse()
+
+	if err := run(*specPath, *outPath, *pkg); err != nil {
+		fmt.Fprintf(os.Stderr, "tngen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(specPath, outPath, pkg string) error {
+	if specPath == "" {
+		return fmt.Errorf("-spec is required")
+	}
+	f, err := os.Open(specPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	spec, err := tngen.ParseSpec(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", specPath, err)
+	}
+	if pkg != "" {
+		spec.Package = pkg
+	}
+
+	src, err := tngen.Generate(spec, filepath.Base(specPath))
+	if err != nil {
+		return fmt.Errorf("%s: %w", specPath, err)
+	}
+	if outPath == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(outPath, src, 0o644)
+}
@@ -0,0 +1,124 @@
+// Package archive keeps signed attestations for audits and hands them to
+// third parties as self-contained proof bundles.
+//
+// A Record is a signed attestation together with its AttestationMetadata.
+// Records are verified before they are stored: the signer is recovered from
+// the signature and the payload is parsed, which also yields the stream,
+// action and block height the store indexes them by. A Bundle is the JSON
+// form of a record, carrying the payload, the signature, the decoded values
+// and the signer, and can be verified offline without the network.
+//
+//	store, err := archive.OpenSQLite("attestations.db")
+//	if err != nil {
+//	    return err
+//	}
+//	defer store.Close()
+//	rec, err := archive.Fetch(ctx, store, attestations, meta)
+//	...
+//	bundle, err := archive.NewBundle(rec)
+//	data, err := json.MarshalIndent(bundle, "", "  ")
+//
+//	// Elsewhere, offline:
+//	var b archive.Bundle
+//	err := json.Unmarshal(data, &b)
+//	parsed, err := b.Verify(trustedValidator)
+package archive
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/trufnetwork/sdk-go/core/contractsapi"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+// Record is an archived signed attestation.
+type Record struct {
+	Metadata types.AttestationMetadata
+	// Signed is the canonical payload followed by the validator signature,
+	// as returned by GetSignedAttestation.
+	Signed []byte
+
+	// Derived from Signed by NewRecord.
+	Signer       string // 0x address of the signing validator
+	DataProvider string
+	StreamID     string
+	ActionID     uint16
+	BlockHeight  uint64
+}
+
+// NewRecord verifies signed and returns it as a record with its metadata.
+func NewRecord(meta types.AttestationMetadata, signed []byte) (*Record, error) {
+	if meta.RequestTxID == "" {
+		return nil, errors.New("request_tx_id is required")
+	}
+	parsed, signer, err := contractsapi.VerifySignedAttestation(signed)
+	if err != nil {
+		return nil, errors.Wrapf(err, "attestation %s", meta.RequestTxID)
+	}
+	meta.RequestTxID = normalizeTxID(meta.RequestTxID)
+	return &Record{
+		Metadata:     meta,
+		Signed:       append([]byte(nil), signed...),
+		Signer:       signer,
+		DataProvider: strings.ToLower(parsed.DataProvider),
+		StreamID:     parsed.StreamID,
+		ActionID:     parsed.ActionID,
+		BlockHeight:  parsed.BlockHeight,
+	}, nil
+}
+
+// Parse parses the record's payload.
+func (r *Record) Parse() (*types.ParsedAttestationPayload, error) {
+	parsed, _, err := contractsapi.VerifySignedAttestation(r.Signed)
+	return parsed, err
+}
+
+// Query selects records. Zero fields match everything.
+type Query struct {
+	DataProvider string
+	StreamID     string
+	Action       string // action name, e.g. "get_record"
+	FromHeight   uint64 // first block height, inclusive
+	ToHeight     uint64 // last block height, inclusive
+	Limit        int
+}
+
+// Store persists records. OpenSQLite returns the default implementation.
+type Store interface {
+	// Save adds rec, replacing any record with the same request tx.
+	Save(ctx context.Context, rec *Record) error
+	// Get returns the record of a request tx, or nil if there is none.
+	Get(ctx context.Context, requestTxID string) (*Record, error)
+	// Find returns the records matching q, ordered by block height.
+	Find(ctx context.Context, q Query) ([]Record, error)
+
+	Close() error
+}
+
+// Fetch downloads the signed attestation described by meta and archives it.
+// The attestation must have been signed.
+func Fetch(ctx context.Context, store Store, attestations types.IAttestationAction, meta types.AttestationMetadata) (*Record, error) {
+	if meta.SignedHeight == nil {
+		return nil, errors.Errorf("attestation %s is not signed yet", meta.RequestTxID)
+	}
+	signed, err := attestations.GetSignedAttestation(ctx, types.GetSignedAttestationInput{RequestTxID: meta.RequestTxID})
+	if err != nil {
+		return nil, errors.Wrapf(err, "get signed attestation %s", meta.RequestTxID)
+	}
+	rec, err := NewRecord(meta, signed.Payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Save(ctx, rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// normalizeTxID keys records by lowercase hex without 0x, the form
+// RequestAttestation returns.
+func normalizeTxID(id string) string {
+	return strings.TrimPrefix(strings.ToLower(id), "0x")
+}
@@ -0,0 +1,220 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trufnetwork/sdk-go/core/contractsapi"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+const (
+	testProvider = "0x1111111111111111111111111111111111111111"
+	testStream   = "stbtcusd000000000000000000000000"
+)
+
+// signedRecordPayload builds a get_record payload at height returning one
+// datapoint and signs it with key.
+func signedRecordPayload(t *testing.T, key *ecdsa.PrivateKey, stream string, height uint64) []byte {
+	t.Helper()
+	lengthPrefixed := func(b []byte) []byte {
+		return append(binary.BigEndian.AppendUint32(nil, uint32(len(b))), b...)
+	}
+	args, err := contractsapi.EncodeActionArgs([]any{testProvider, stream, int64(1700000000), nil, nil, false})
+	require.NoError(t, err)
+	uint256Array, _ := abi.NewType("uint256[]", "", nil)
+	int256Array, _ := abi.NewType("int256[]", "", nil)
+	value, _ := new(big.Int).SetString("42500000000000000000", 10) // 42.5
+	result, err := abi.Arguments{{Type: uint256Array}, {Type: int256Array}}.Pack([]*big.Int{big.NewInt(1700000000)}, []*big.Int{value})
+	require.NoError(t, err)
+
+	p := []byte{1, 0}
+	p = binary.BigEndian.AppendUint64(p, height)
+	p = append(p, lengthPrefixed(bytes.Repeat([]byte{0x11}, 20))...)
+	p = append(p, lengthPrefixed([]byte(stream))...)
+	p = binary.BigEndian.AppendUint16(p, 1)
+	p = append(p, lengthPrefixed(args)...)
+	p = append(p, lengthPrefixed(result)...)
+
+	hash := sha256.Sum256(p)
+	sig, err := crypto.Sign(hash[:], key)
+	require.NoError(t, err)
+	sig[64] += 27
+	return append(p, sig...)
+}
+
+func metadata(txID string, height int64) types.AttestationMetadata {
+	return types.AttestationMetadata{
+		RequestTxID:     txID,
+		AttestationHash: []byte{0xab, 0xcd},
+		Requester:       []byte{0x22, 0x22},
+		CreatedHeight:   height - 2,
+		SignedHeight:    &height,
+	}
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	store, err := OpenSQLite(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	for i, c := range []struct {
+		txID   string
+		stream string
+		height uint64
+	}{
+		{"0xAA01", testStream, 120},
+		{"aa02", testStream, 100},
+		{"aa03", "stethusd000000000000000000000000", 110},
+	} {
+		rec, err := NewRecord(metadata(c.txID, int64(c.height)), signedRecordPayload(t, key, c.stream, c.height))
+		require.NoError(t, err, i)
+		require.NoError(t, store.Save(ctx, rec))
+	}
+
+	rec, err := store.Get(ctx, "0xaa01")
+	require.NoError(t, err)
+	require.NotNil(t, rec)
+	assert.Equal(t, "aa01", rec.Metadata.RequestTxID)
+	assert.Equal(t, int64(120), *rec.Metadata.SignedHeight)
+	assert.Equal(t, []byte{0xab, 0xcd}, rec.Metadata.AttestationHash)
+	assert.Equal(t, strings.ToLower(crypto.PubkeyToAddress(key.PublicKey).Hex()), rec.Signer)
+	assert.Equal(t, testProvider, rec.DataProvider)
+	assert.Equal(t, uint64(120), rec.BlockHeight)
+	parsed, err := rec.Parse()
+	require.NoError(t, err)
+	assert.Equal(t, []any{"1700000000", "42.5"}, parsed.Result[0].Values)
+
+	missing, err := store.Get(ctx, "ffff")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	txIDs := func(recs []Record) []string {
+		var out []string
+		for _, r := range recs {
+			out = append(out, r.Metadata.RequestTxID)
+		}
+		return out
+	}
+	recs, err := store.Find(ctx, Query{StreamID: testStream, Action: "get_record"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aa02", "aa01"}, txIDs(recs))
+
+	recs, err = store.Find(ctx, Query{DataProvider: "0x" + strings.ToUpper(testProvider[2:]), FromHeight: 105, ToHeight: 115})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aa03"}, txIDs(recs))
+
+	recs, err = store.Find(ctx, Query{Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aa02", "aa03"}, txIDs(recs))
+
+	recs, err = store.Find(ctx, Query{Action: "price_above_threshold"})
+	require.NoError(t, err)
+	assert.Empty(t, recs)
+
+	var unknown *types.UnknownActionError
+	_, err = store.Find(ctx, Query{Action: "not_an_action"})
+	assert.ErrorAs(t, err, &unknown)
+
+	// Unverifiable payloads are not archived.
+	_, err = NewRecord(metadata("aa04", 1), []byte{1, 2, 3})
+	assert.Error(t, err)
+}
+
+func TestBundle(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	rec, err := NewRecord(metadata("aa01", 120), signedRecordPayload(t, key, testStream, 120))
+	require.NoError(t, err)
+
+	b, err := NewBundle(rec)
+	require.NoError(t, err)
+	assert.Equal(t, "get_record", b.ActionName)
+	assert.Equal(t, "0x2222", b.Requester)
+	data, err := json.MarshalIndent(b, "", "  ")
+	require.NoError(t, err)
+
+	var imported Bundle
+	require.NoError(t, json.Unmarshal(data, &imported))
+	parsed, err := imported.Verify(strings.ToUpper(rec.Signer))
+	require.NoError(t, err)
+	assert.Equal(t, testStream, parsed.StreamID)
+
+	store, err := OpenSQLite(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+	got, err := Import(ctx, store, &imported)
+	require.NoError(t, err)
+	assert.Equal(t, rec, got)
+	saved, err := store.Get(ctx, "aa01")
+	require.NoError(t, err)
+	assert.Equal(t, rec, saved)
+
+	t.Run("untrusted signer", func(t *testing.T) {
+		_, err := imported.Verify("0x3333333333333333333333333333333333333333")
+		assert.ErrorContains(t, err, "is not trusted")
+	})
+	t.Run("edited values", func(t *testing.T) {
+		edited := imported
+		edited.Decoded = json.RawMessage(strings.Replace(string(imported.Decoded), "42.5", "99.5", 1))
+		_, err := edited.Verify()
+		assert.ErrorContains(t, err, "do not match the payload")
+	})
+	t.Run("edited payload", func(t *testing.T) {
+		edited := imported
+		edited.Payload = strings.Replace(imported.Payload, "78", "79", 1) // block height 120
+		_, err := edited.Verify()
+		assert.ErrorContains(t, err, "signature recovers")
+	})
+	t.Run("wrong action", func(t *testing.T) {
+		edited := imported
+		edited.ActionName = "get_index"
+		_, err := edited.Verify()
+		assert.ErrorContains(t, err, "does not match payload action")
+	})
+}
+
+type fakeAttestations struct {
+	types.IAttestationAction
+	signed []byte
+}
+
+func (f *fakeAttestations) GetSignedAttestation(context.Context, types.GetSignedAttestationInput) (*types.SignedAttestationResult, error) {
+	return &types.SignedAttestationResult{Payload: f.signed}, nil
+}
+
+func TestFetch(t *testing.T) {
+	ctx := context.Background()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	store, err := OpenSQLite(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+	att := &fakeAttestations{signed: signedRecordPayload(t, key, testStream, 120)}
+
+	unsigned := metadata("aa01", 120)
+	unsigned.SignedHeight = nil
+	_, err = Fetch(ctx, store, att, unsigned)
+	assert.ErrorContains(t, err, "not signed yet")
+
+	rec, err := Fetch(ctx, store, att, metadata("aa01", 120))
+	require.NoError(t, err)
+	saved, err := store.Get(ctx, "aa01")
+	require.NoError(t, err)
+	assert.Equal(t, rec, saved)
+}
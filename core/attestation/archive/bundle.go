@@ -0,0 +1,175 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/trufnetwork/sdk-go/core/contractsapi"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+// BundleVersion is the version of the bundle format NewBundle writes.
+const BundleVersion = 1
+
+// Bundle is a self-contained proof of an attestation, for JSON export.
+// Payload and Signature are the proof; Verify checks Signer, ActionName and
+// Decoded against them. The request metadata is copied from the node's
+// records and is not covered by the signature.
+type Bundle struct {
+	Version int `json:"version"`
+
+	RequestTxID     string `json:"request_tx_id"`
+	AttestationHash string `json:"attestation_hash,omitempty"` // hex
+	Requester       string `json:"requester,omitempty"`        // 0x address
+	CreatedHeight   int64  `json:"created_height"`
+	SignedHeight    *int64 `json:"signed_height,omitempty"`
+
+	Payload   string `json:"payload"`   // hex canonical payload
+	Signature string `json:"signature"` // hex, 65 bytes
+	Signer    string `json:"signer"`    // 0x address recovered from Signature
+
+	ActionName string `json:"action_name,omitempty"`
+	// Decoded is the payload as parsed by contractsapi.ParseAttestationPayload.
+	Decoded json.RawMessage `json:"decoded"`
+}
+
+// NewBundle returns the proof bundle of rec.
+func NewBundle(rec *Record) (*Bundle, error) {
+	payload, signature, err := contractsapi.SplitSignedAttestation(rec.Signed)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := contractsapi.ParseAttestationPayload(payload)
+	if err != nil {
+		return nil, errors.Wrapf(err, "attestation %s", rec.Metadata.RequestTxID)
+	}
+	decoded, err := json.Marshal(parsed)
+	if err != nil {
+		return nil, errors.Wrap(err, "encode decoded payload")
+	}
+
+	b := &Bundle{
+		Version:       BundleVersion,
+		RequestTxID:   normalizeTxID(rec.Metadata.RequestTxID),
+		CreatedHeight: rec.Metadata.CreatedHeight,
+		SignedHeight:  rec.Metadata.SignedHeight,
+		Payload:       hex.EncodeToString(payload),
+		Signature:     hex.EncodeToString(signature),
+		Signer:        rec.Signer,
+		ActionName:    types.GetActionName(parsed.ActionID),
+		Decoded:       decoded,
+	}
+	if len(rec.Metadata.AttestationHash) > 0 {
+		b.AttestationHash = hex.EncodeToString(rec.Metadata.AttestationHash)
+	}
+	if len(rec.Metadata.Requester) > 0 {
+		b.Requester = "0x" + hex.EncodeToString(rec.Metadata.Requester)
+	}
+	return b, nil
+}
+
+// Verify checks the bundle offline: the signature must recover Signer, and
+// Decoded must match the payload. With trusted validator addresses, the
+// signer must also be one of them. It returns the parsed payload.
+func (b *Bundle) Verify(trusted ...string) (*types.ParsedAttestationPayload, error) {
+	rec, err := b.Record()
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(rec.Signer, b.Signer) {
+		return nil, errors.Errorf("bundle %s: signature recovers %s, bundle names %s", b.RequestTxID, rec.Signer, b.Signer)
+	}
+	if len(trusted) > 0 && !containsFold(trusted, rec.Signer) {
+		return nil, errors.Errorf("bundle %s: signer %s is not trusted", b.RequestTxID, rec.Signer)
+	}
+
+	parsed, err := rec.Parse()
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := json.Marshal(parsed)
+	if err != nil {
+		return nil, errors.Wrap(err, "encode decoded payload")
+	}
+	var want, got bytes.Buffer
+	if err := json.Compact(&want, decoded); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := json.Compact(&got, b.Decoded); err != nil {
+		return nil, errors.Wrapf(err, "bundle %s: decoded values", b.RequestTxID)
+	}
+	if !bytes.Equal(want.Bytes(), got.Bytes()) {
+		return nil, errors.Errorf("bundle %s: decoded values do not match the payload", b.RequestTxID)
+	}
+	if b.ActionName != "" {
+		if id := types.GetActionID(b.ActionName); id != 0 && id != parsed.ActionID {
+			return nil, errors.Errorf("bundle %s: action %s does not match payload action %d", b.RequestTxID, b.ActionName, parsed.ActionID)
+		}
+	}
+	return parsed, nil
+}
+
+// Record rebuilds the archive record of the bundle, recovering the signer
+// from the signature. It does not check the bundle's other fields; use
+// Verify for that.
+func (b *Bundle) Record() (*Record, error) {
+	if b.Version != BundleVersion {
+		return nil, errors.Errorf("unsupported bundle version %d", b.Version)
+	}
+	payload, err := hex.DecodeString(strings.TrimPrefix(b.Payload, "0x"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "bundle %s: payload", b.RequestTxID)
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(b.Signature, "0x"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "bundle %s: signature", b.RequestTxID)
+	}
+	if len(signature) != contractsapi.AttestationSignatureLength {
+		return nil, errors.Errorf("bundle %s: signature must be %d bytes, got %d", b.RequestTxID, contractsapi.AttestationSignatureLength, len(signature))
+	}
+
+	meta := types.AttestationMetadata{
+		RequestTxID:   b.RequestTxID,
+		CreatedHeight: b.CreatedHeight,
+		SignedHeight:  b.SignedHeight,
+	}
+	if b.AttestationHash != "" {
+		if meta.AttestationHash, err = hex.DecodeString(strings.TrimPrefix(b.AttestationHash, "0x")); err != nil {
+			return nil, errors.Wrapf(err, "bundle %s: attestation hash", b.RequestTxID)
+		}
+	}
+	if b.Requester != "" {
+		if meta.Requester, err = hex.DecodeString(strings.TrimPrefix(b.Requester, "0x")); err != nil {
+			return nil, errors.Wrapf(err, "bundle %s: requester", b.RequestTxID)
+		}
+	}
+	return NewRecord(meta, append(payload, signature...))
+}
+
+// Import verifies b and saves it to store.
+func Import(ctx context.Context, store Store, b *Bundle, trusted ...string) (*Record, error) {
+	if _, err := b.Verify(trusted...); err != nil {
+		return nil, err
+	}
+	rec, err := b.Record()
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Save(ctx, rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package archive
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/trufnetwork/sdk-go/core/types"
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS attestations (
+	request_tx_id    TEXT    PRIMARY KEY,
+	attestation_hash BLOB,
+	requester        BLOB,
+	created_height   INTEGER NOT NULL,
+	signed_height    INTEGER,
+	encrypt_sig      INTEGER NOT NULL,
+	signed           BLOB    NOT NULL,
+	signer           TEXT    NOT NULL,
+	data_provider    TEXT    NOT NULL,
+	stream_id        TEXT    NOT NULL,
+	action_id        INTEGER NOT NULL,
+	block_height     INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS attestations_stream ON attestations (data_provider, stream_id, block_height);
+CREATE INDEX IF NOT EXISTS attestations_action ON attestations (action_id, block_height);
+CREATE INDEX IF NOT EXISTS attestations_height ON attestations (block_height);
+`
+
+const recordColumns = `request_tx_id, attestation_hash, requester, created_height, signed_height, encrypt_sig,
+	signed, signer, data_provider, stream_id, action_id, block_height`
+
+// SQLiteStore is a Store backed by a SQLite database.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+var _ Store = (*SQLiteStore)(nil)
+
+// OpenSQLite opens, creating if needed, the SQLite database at path. Use
+// ":memory:" for a throwaway database.
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "open %s", path)
+	}
+	// A single connection serializes writes and keeps ":memory:" databases
+	// shared.
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "create schema")
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// Close closes the database.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// Save implements Store.
+func (s *SQLiteStore) Save(ctx context.Context, rec *Record) error {
+	m := rec.Metadata
+	var signedHeight sql.NullInt64
+	if m.SignedHeight != nil {
+		signedHeight = sql.NullInt64{Int64: *m.SignedHeight, Valid: true}
+	}
+	_, err := s.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO attestations (`+recordColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		normalizeTxID(m.RequestTxID), m.AttestationHash, m.Requester, m.CreatedHeight, signedHeight, m.EncryptSig,
+		rec.Signed, rec.Signer, strings.ToLower(rec.DataProvider), rec.StreamID, rec.ActionID, int64(rec.BlockHeight))
+	return errors.WithStack(err)
+}
+
+// Get implements Store.
+func (s *SQLiteStore) Get(ctx context.Context, requestTxID string) (*Record, error) {
+	recs, err := s.query(ctx, `SELECT `+recordColumns+` FROM attestations WHERE request_tx_id = ?`, normalizeTxID(requestTxID))
+	if err != nil || len(recs) == 0 {
+		return nil, err
+	}
+	return &recs[0], nil
+}
+
+// Find implements Store.
+func (s *SQLiteStore) Find(ctx context.Context, q Query) ([]Record, error) {
+	var (
+		where []string
+		args  []any
+	)
+	if q.DataProvider != "" {
+		where, args = append(where, "data_provider = ?"), append(args, strings.ToLower(q.DataProvider))
+	}
+	if q.StreamID != "" {
+		where, args = append(where, "stream_id = ?"), append(args, q.StreamID)
+	}
+	if q.Action != "" {
+		id := types.GetActionID(q.Action)
+		if id == 0 {
+			return nil, &types.UnknownActionError{Name: q.Action}
+		}
+		where, args = append(where, "action_id = ?"), append(args, id)
+	}
+	if q.FromHeight > 0 {
+		where, args = append(where, "block_height >= ?"), append(args, int64(q.FromHeight))
+	}
+	if q.ToHeight > 0 {
+		where, args = append(where, "block_height <= ?"), append(args, int64(q.ToHeight))
+	}
+
+	stmt := `SELECT ` + recordColumns + ` FROM attestations`
+	if len(where) > 0 {
+		stmt += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	stmt += ` ORDER BY block_height, request_tx_id`
+	if q.Limit > 0 {
+		stmt += ` LIMIT ?`
+		args = append(args, q.Limit)
+	}
+	return s.query(ctx, stmt, args...)
+}
+
+func (s *SQLiteStore) query(ctx context.Context, stmt string, args ...any) ([]Record, error) {
+	rows, err := s.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+	var out []Record
+	for rows.Next() {
+		var (
+			r            Record
+			signedHeight sql.NullInt64
+			blockHeight  int64
+		)
+		if err := rows.Scan(&r.Metadata.RequestTxID, &r.Metadata.AttestationHash, &r.Metadata.Requester,
+			&r.Metadata.CreatedHeight, &signedHeight, &r.Metadata.EncryptSig,
+			&r.Signed, &r.Signer, &r.DataProvider, &r.StreamID, &r.ActionID, &blockHeight); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if signedHeight.Valid {
+			h := signedHeight.Int64
+			r.Metadata.SignedHeight = &h
+		}
+		r.BlockHeight = uint64(blockHeight)
+		out = append(out, r)
+	}
+	return out, errors.WithStack(rows.Err())
+}
@@ -0,0 +1,395 @@
+package contractsapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+// ActionCodec converts between the query components of an action in
+// types.ActionRegistry and a typed input struct such as
+// types.PriceAboveThresholdInput, and describes the input as a question.
+//
+// The codecs of the four binary actions are registered by default; register
+// more with RegisterActionCodec.
+type ActionCodec interface {
+	// Action returns the ActionRegistry name of the action.
+	Action() string
+	// Decode builds the typed input from the action arguments, as returned
+	// by DecodeActionArgs.
+	Decode(args []any) (types.BinaryActionInput, error)
+	// Encode builds the query components of input.
+	Encode(input types.BinaryActionInput) ([]byte, error)
+	// Question returns the parts a QuestionLocale renders input from.
+	Question(input types.BinaryActionInput) (*QuestionParts, error)
+}
+
+// QuestionParts is the language-independent content of a market question.
+type QuestionParts struct {
+	// Key selects the QuestionLocale template, usually the action name.
+	Key          string
+	DataProvider string
+	StreamID     string
+	Time         time.Time // the timestamp the action reads the stream at
+	// Values fills the template's other placeholders, such as "threshold",
+	// with decimal strings.
+	Values map[string]string
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]ActionCodec{}
+)
+
+func init() {
+	for _, c := range []ActionCodec{
+		priceAboveThresholdCodec{},
+		priceBelowThresholdCodec{},
+		valueInRangeCodec{},
+		valueEqualsCodec{},
+	} {
+		if err := RegisterActionCodec(c); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterActionCodec registers c for its action, replacing any codec
+// registered before. The action must be in types.ActionRegistry.
+func RegisterActionCodec(c ActionCodec) error {
+	if c == nil {
+		return fmt.Errorf("codec is required")
+	}
+	if err := types.ValidateActionName(c.Action()); err != nil {
+		return err
+	}
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	codecs[c.Action()] = c
+	return nil
+}
+
+// GetActionCodec returns the codec registered for action, or nil.
+func GetActionCodec(action string) ActionCodec {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	return codecs[action]
+}
+
+// ActionCodecs returns the names of the actions with a registered codec,
+// sorted.
+func ActionCodecs() []string {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	out := make([]string, 0, len(codecs))
+	for name := range codecs {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// DecodeActionInput decodes query components into the typed input of their
+// action, for example a *types.PriceAboveThresholdInput.
+func DecodeActionInput(queryComponents []byte) (types.BinaryActionInput, error) {
+	dataProvider, streamID, actionID, argsBytes, err := DecodeQueryComponents(queryComponents)
+	if err != nil {
+		return nil, err
+	}
+	c := GetActionCodec(actionID)
+	if c == nil {
+		return nil, fmt.Errorf("no codec registered for action %q", actionID)
+	}
+	args, err := DecodeActionArgs(argsBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode action args: %w", err)
+	}
+	input, err := c.Decode(args)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s args: %w", actionID, err)
+	}
+	// The tuple and the args both carry the stream; the node reads the args.
+	p, err := c.Question(input)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(p.DataProvider, dataProvider) || p.StreamID != streamID {
+		return nil, fmt.Errorf("action args target %s/%s but query components target %s/%s",
+			p.DataProvider, p.StreamID, dataProvider, streamID)
+	}
+	return input, nil
+}
+
+// EncodeActionInput builds the query components of input with the codec of
+// its action. It is the inverse of DecodeActionInput.
+func EncodeActionInput(input types.BinaryActionInput) ([]byte, error) {
+	if input == nil {
+		return nil, fmt.Errorf("input is required")
+	}
+	c := GetActionCodec(input.ActionName())
+	if c == nil {
+		return nil, fmt.Errorf("no codec registered for action %q", input.ActionName())
+	}
+	return c.Encode(input)
+}
+
+// ═══════════════════════════════════════════════════════════════
+// BUILT-IN CODECS
+// Argument order follows 040-binary-attestation-actions.sql:
+// ($data_provider, $stream_id, $timestamp, <values...>, $frozen_at)
+// ═══════════════════════════════════════════════════════════════
+
+type priceAboveThresholdCodec struct{}
+
+func (priceAboveThresholdCodec) Action() string { return "price_above_threshold" }
+
+func (priceAboveThresholdCodec) Decode(args []any) (types.BinaryActionInput, error) {
+	a, err := newArgReader(args, 5)
+	if err != nil {
+		return nil, err
+	}
+	in := &types.PriceAboveThresholdInput{
+		DataProvider: a.string(0),
+		StreamID:     a.string(1),
+		Timestamp:    a.int64(2),
+		Threshold:    a.decimal(3),
+		FrozenAt:     a.optInt64(4),
+	}
+	return in, a.err
+}
+
+func (priceAboveThresholdCodec) Encode(input types.BinaryActionInput) ([]byte, error) {
+	in, ok := input.(*types.PriceAboveThresholdInput)
+	if !ok {
+		return nil, fmt.Errorf("expected *types.PriceAboveThresholdInput, got %T", input)
+	}
+	return BuildPriceAboveThresholdQueryComponents(*in)
+}
+
+func (priceAboveThresholdCodec) Question(input types.BinaryActionInput) (*QuestionParts, error) {
+	in, ok := input.(*types.PriceAboveThresholdInput)
+	if !ok {
+		return nil, fmt.Errorf("expected *types.PriceAboveThresholdInput, got %T", input)
+	}
+	return &QuestionParts{
+		Key:          "price_above_threshold",
+		DataProvider: in.DataProvider,
+		StreamID:     in.StreamID,
+		Time:         time.Unix(in.Timestamp, 0),
+		Values:       map[string]string{"threshold": trimDecimal(in.Threshold)},
+	}, nil
+}
+
+type priceBelowThresholdCodec struct{}
+
+func (priceBelowThresholdCodec) Action() string { return "price_below_threshold" }
+
+func (priceBelowThresholdCodec) Decode(args []any) (types.BinaryActionInput, error) {
+	a, err := newArgReader(args, 5)
+	if err != nil {
+		return nil, err
+	}
+	in := &types.PriceBelowThresholdInput{
+		DataProvider: a.string(0),
+		StreamID:     a.string(1),
+		Timestamp:    a.int64(2),
+		Threshold:    a.decimal(3),
+		FrozenAt:     a.optInt64(4),
+	}
+	return in, a.err
+}
+
+func (priceBelowThresholdCodec) Encode(input types.BinaryActionInput) ([]byte, error) {
+	in, ok := input.(*types.PriceBelowThresholdInput)
+	if !ok {
+		return nil, fmt.Errorf("expected *types.PriceBelowThresholdInput, got %T", input)
+	}
+	return BuildPriceBelowThresholdQueryComponents(*in)
+}
+
+func (priceBelowThresholdCodec) Question(input types.BinaryActionInput) (*QuestionParts, error) {
+	in, ok := input.(*types.PriceBelowThresholdInput)
+	if !ok {
+		return nil, fmt.Errorf("expected *types.PriceBelowThresholdInput, got %T", input)
+	}
+	return &QuestionParts{
+		Key:          "price_below_threshold",
+		DataProvider: in.DataProvider,
+		StreamID:     in.StreamID,
+		Time:         time.Unix(in.Timestamp, 0),
+		Values:       map[string]string{"threshold": trimDecimal(in.Threshold)},
+	}, nil
+}
+
+type valueInRangeCodec struct{}
+
+func (valueInRangeCodec) Action() string { return "value_in_range" }
+
+func (valueInRangeCodec) Decode(args []any) (types.BinaryActionInput, error) {
+	a, err := newArgReader(args, 6)
+	if err != nil {
+		return nil, err
+	}
+	in := &types.ValueInRangeInput{
+		DataProvider: a.string(0),
+		StreamID:     a.string(1),
+		Timestamp:    a.int64(2),
+		MinValue:     a.decimal(3),
+		MaxValue:     a.decimal(4),
+		FrozenAt:     a.optInt64(5),
+	}
+	return in, a.err
+}
+
+func (valueInRangeCodec) Encode(input types.BinaryActionInput) ([]byte, error) {
+	in, ok := input.(*types.ValueInRangeInput)
+	if !ok {
+		return nil, fmt.Errorf("expected *types.ValueInRangeInput, got %T", input)
+	}
+	return BuildValueInRangeQueryComponents(*in)
+}
+
+func (valueInRangeCodec) Question(input types.BinaryActionInput) (*QuestionParts, error) {
+	in, ok := input.(*types.ValueInRangeInput)
+	if !ok {
+		return nil, fmt.Errorf("expected *types.ValueInRangeInput, got %T", input)
+	}
+	return &QuestionParts{
+		Key:          "value_in_range",
+		DataProvider: in.DataProvider,
+		StreamID:     in.StreamID,
+		Time:         time.Unix(in.Timestamp, 0),
+		Values:       map[string]string{"min": trimDecimal(in.MinValue), "max": trimDecimal(in.MaxValue)},
+	}, nil
+}
+
+type valueEqualsCodec struct{}
+
+func (valueEqualsCodec) Action() string { return "value_equals" }
+
+func (valueEqualsCodec) Decode(args []any) (types.BinaryActionInput, error) {
+	a, err := newArgReader(args, 6)
+	if err != nil {
+		return nil, err
+	}
+	in := &types.ValueEqualsInput{
+		DataProvider: a.string(0),
+		StreamID:     a.string(1),
+		Timestamp:    a.int64(2),
+		TargetValue:  a.decimal(3),
+		Tolerance:    a.decimal(4),
+		FrozenAt:     a.optInt64(5),
+	}
+	return in, a.err
+}
+
+func (valueEqualsCodec) Encode(input types.BinaryActionInput) ([]byte, error) {
+	in, ok := input.(*types.ValueEqualsInput)
+	if !ok {
+		return nil, fmt.Errorf("expected *types.ValueEqualsInput, got %T", input)
+	}
+	return BuildValueEqualsQueryComponents(*in)
+}
+
+// Question uses the "value_equals" template for an exact match and
+// "value_equals_tolerance" otherwise.
+func (valueEqualsCodec) Question(input types.BinaryActionInput) (*QuestionParts, error) {
+	in, ok := input.(*types.ValueEqualsInput)
+	if !ok {
+		return nil, fmt.Errorf("expected *types.ValueEqualsInput, got %T", input)
+	}
+	key := "value_equals"
+	if trimDecimal(in.Tolerance) != "0" {
+		key = "value_equals_tolerance"
+	}
+	return &QuestionParts{
+		Key:          key,
+		DataProvider: in.DataProvider,
+		StreamID:     in.StreamID,
+		Time:         time.Unix(in.Timestamp, 0),
+		Values:       map[string]string{"target": trimDecimal(in.TargetValue), "tolerance": trimDecimal(in.Tolerance)},
+	}, nil
+}
+
+// argReader reads decoded action args, keeping the first error.
+type argReader struct {
+	args []any
+	err  error
+}
+
+func newArgReader(args []any, n int) (*argReader, error) {
+	if len(args) != n {
+		return nil, fmt.Errorf("expected %d args, got %d", n, len(args))
+	}
+	return &argReader{args: args}, nil
+}
+
+func (a *argReader) fail(i int, want string) {
+	if a.err == nil {
+		a.err = fmt.Errorf("arg %d: expected %s, got %T", i, want, a.args[i])
+	}
+}
+
+func (a *argReader) string(i int) string {
+	switch v := a.args[i].(type) {
+	case string:
+		return v
+	case *string:
+		if v != nil {
+			return *v
+		}
+	}
+	a.fail(i, "string")
+	return ""
+}
+
+func (a *argReader) int64(i int) int64 {
+	if v := a.optInt64(i); v != nil {
+		return *v
+	}
+	a.fail(i, "int64")
+	return 0
+}
+
+func (a *argReader) optInt64(i int) *int64 {
+	switch v := a.args[i].(type) {
+	case nil:
+		return nil
+	case int64:
+		return &v
+	case *int64:
+		return v
+	}
+	a.fail(i, "int64")
+	return nil
+}
+
+// decimal returns a NUMERIC arg without trailing zeros, so "100000" encodes
+// and decodes to "100000" rather than "100000.000000000000000000".
+func (a *argReader) decimal(i int) string {
+	switch v := a.args[i].(type) {
+	case *kwiltypes.Decimal:
+		if v != nil {
+			return trimDecimal(v.String())
+		}
+	case kwiltypes.Decimal:
+		return trimDecimal(v.String())
+	}
+	a.fail(i, "decimal")
+	return ""
+}
+
+func trimDecimal(s string) string {
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "" || s == "-0" {
+		return "0"
+	}
+	return s
+}
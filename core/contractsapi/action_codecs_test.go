@@ -0,0 +1,127 @@
+package contractsapi_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/contractsapi"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+const (
+	codecProvider = "0x1111111111111111111111111111111111111111"
+	codecStream   = "stbtcusd000000000000000000000000"
+	codecTime     = int64(1798675200) // 2026-12-31 00:00:00 UTC
+)
+
+func TestActionCodecs_RoundTrip(t *testing.T) {
+	frozen := int64(1798600000)
+	for _, input := range []types.BinaryActionInput{
+		&types.PriceAboveThresholdInput{DataProvider: codecProvider, StreamID: codecStream, Timestamp: codecTime, Threshold: "100000"},
+		&types.PriceBelowThresholdInput{DataProvider: codecProvider, StreamID: codecStream, Timestamp: codecTime, Threshold: "4.25", FrozenAt: &frozen},
+		&types.ValueInRangeInput{DataProvider: codecProvider, StreamID: codecStream, Timestamp: codecTime, MinValue: "90000", MaxValue: "110000.5"},
+		&types.ValueEqualsInput{DataProvider: codecProvider, StreamID: codecStream, Timestamp: codecTime, TargetValue: "5.25", Tolerance: "0"},
+	} {
+		t.Run(input.ActionName(), func(t *testing.T) {
+			qc, err := contractsapi.EncodeActionInput(input)
+			require.NoError(t, err)
+
+			decoded, err := contractsapi.DecodeActionInput(qc)
+			require.NoError(t, err)
+			assert.Equal(t, input, decoded)
+
+			again, err := contractsapi.EncodeActionInput(decoded)
+			require.NoError(t, err)
+			assert.Equal(t, qc, again)
+		})
+	}
+	assert.Equal(t, []string{"price_above_threshold", "price_below_threshold", "value_equals", "value_in_range"}, contractsapi.ActionCodecs())
+}
+
+func TestDescribeMarket(t *testing.T) {
+	qc, err := contractsapi.BuildPriceAboveThresholdQueryComponents(types.PriceAboveThresholdInput{
+		DataProvider: codecProvider, StreamID: codecStream, Timestamp: codecTime, Threshold: "100000.00",
+	})
+	require.NoError(t, err)
+
+	q, err := contractsapi.DescribeMarket(qc)
+	require.NoError(t, err)
+	assert.Equal(t, "Will "+codecStream+" exceed 100000 by 2026-12-31?", q)
+
+	named := contractsapi.WithStreamName(func(dp, sid string) string {
+		assert.Equal(t, codecProvider, dp)
+		return "BTC/USD"
+	})
+	q, err = contractsapi.DescribeMarket(qc, named, contractsapi.WithQuestionTimeZone(time.FixedZone("PST", -8*3600)))
+	require.NoError(t, err)
+	assert.Equal(t, "Will BTC/USD exceed 100000 by 2026-12-30?", q)
+
+	// Regional tags fall back to their language.
+	equals, err := contractsapi.DescribeActionInput(&types.ValueEqualsInput{
+		DataProvider: codecProvider, StreamID: codecStream, Timestamp: codecTime, TargetValue: "5.25", Tolerance: "0.01",
+	}, named, contractsapi.WithQuestionLocale("es-MX"))
+	require.NoError(t, err)
+	assert.Equal(t, "¿Será BTC/USD 5,25 (±0,01) el 31/12/2026?", equals)
+
+	inRange, err := contractsapi.DescribeActionInput(&types.ValueInRangeInput{
+		DataProvider: codecProvider, StreamID: codecStream, Timestamp: codecTime, MinValue: "90000", MaxValue: "110000",
+	}, named)
+	require.NoError(t, err)
+	assert.Equal(t, "Will BTC/USD be between 90000 and 110000 on 2026-12-31?", inRange)
+}
+
+func TestRegisterQuestionLocale(t *testing.T) {
+	require.NoError(t, contractsapi.RegisterQuestionLocale("fr", contractsapi.QuestionLocale{
+		Templates: map[string]string{"price_below_threshold": "{stream} passera-t-il sous {threshold} d'ici le {date} ?"},
+	}))
+	input := &types.PriceBelowThresholdInput{DataProvider: codecProvider, StreamID: codecStream, Timestamp: codecTime, Threshold: "4"}
+	q, err := contractsapi.DescribeActionInput(input, contractsapi.WithQuestionLocale("fr"))
+	require.NoError(t, err)
+	assert.Equal(t, codecStream+" passera-t-il sous 4 d'ici le 2026-12-31 ?", q)
+
+	// Templates missing from a locale fall back to English.
+	above := &types.PriceAboveThresholdInput{DataProvider: codecProvider, StreamID: codecStream, Timestamp: codecTime, Threshold: "4"}
+	q, err = contractsapi.DescribeActionInput(above, contractsapi.WithQuestionLocale("fr"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(q, "Will "))
+
+	assert.Error(t, contractsapi.RegisterQuestionLocale("de", contractsapi.QuestionLocale{}))
+}
+
+func TestActionCodecs_Errors(t *testing.T) {
+	// Numeric actions have no codec.
+	args, err := contractsapi.EncodeActionArgs([]any{codecProvider, codecStream, codecTime, nil, nil})
+	require.NoError(t, err)
+	qc, err := contractsapi.EncodeQueryComponents(codecProvider, codecStream, "get_record", args)
+	require.NoError(t, err)
+	_, err = contractsapi.DecodeActionInput(qc)
+	assert.ErrorContains(t, err, `no codec registered for action "get_record"`)
+
+	// Malformed args.
+	qc, err = contractsapi.EncodeQueryComponents(codecProvider, codecStream, "price_above_threshold", args)
+	require.NoError(t, err)
+	_, err = contractsapi.DecodeActionInput(qc)
+	assert.ErrorContains(t, err, "arg 3: expected decimal")
+
+	// Args that name another stream than the tuple.
+	threshold, err := kwiltypes.ParseDecimalExplicit("1", 36, 18)
+	require.NoError(t, err)
+	args, err = contractsapi.EncodeActionArgs([]any{codecProvider, "stother000000000000000000000000000"[:32], codecTime, threshold, nil})
+	require.NoError(t, err)
+	qc, err = contractsapi.EncodeQueryComponents(codecProvider, codecStream, "price_above_threshold", args)
+	require.NoError(t, err)
+	_, err = contractsapi.DecodeActionInput(qc)
+	assert.ErrorContains(t, err, "but query components target")
+
+	assert.Error(t, contractsapi.RegisterActionCodec(nil))
+	var unknown *types.UnknownActionError
+	assert.ErrorAs(t, contractsapi.RegisterActionCodec(fakeCodec{}), &unknown)
+}
+
+type fakeCodec struct{ contractsapi.ActionCodec }
+
+func (fakeCodec) Action() string { return "not_an_action" }
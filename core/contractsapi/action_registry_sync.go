@@ -0,0 +1,107 @@
+package contractsapi
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+// ActionSource lists the attestable actions deployed on a node.
+type ActionSource interface {
+	ListActions(ctx context.Context) ([]types.ActionInfo, error)
+}
+
+// SyncActionRegistry registers the actions listed by src that are missing
+// from types.ActionRegistry, and returns them. Actions the SDK already knows
+// keep their argument schema and result decoder. If a listed action reuses
+// the ID or name of a different known action, nothing is registered.
+func SyncActionRegistry(ctx context.Context, src ActionSource) ([]types.ActionInfo, error) {
+	if src == nil {
+		return nil, errors.New("action source is required")
+	}
+	listed, err := src.ListActions(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "list actions")
+	}
+
+	var added []types.ActionInfo
+	seen := map[uint16]string{}
+	for _, info := range listed {
+		if name, ok := seen[info.ID]; ok && name != info.Name {
+			return nil, errors.Errorf("action ID %d is listed as both %s and %s", info.ID, name, info.Name)
+		}
+		seen[info.ID] = info.Name
+		byID, byName := types.GetActionInfoByID(info.ID), types.GetActionInfo(info.Name)
+		switch {
+		case byID != nil && byID.Name != info.Name:
+			return nil, errors.Errorf("node action %s has ID %d, which the SDK knows as %s", info.Name, info.ID, byID.Name)
+		case byName != nil && byName.ID != info.ID:
+			return nil, errors.Errorf("node action %s has ID %d, which the SDK knows as %d", info.Name, info.ID, byName.ID)
+		case byID == nil:
+			added = append(added, info)
+		}
+	}
+	for _, info := range added {
+		if err := types.RegisterAction(info); err != nil {
+			return nil, err
+		}
+	}
+	return added, nil
+}
+
+// actionCaller is the subset of the gateway client used by
+// CallActionSource.
+type actionCaller interface {
+	Call(ctx context.Context, namespace string, action string, inputs []any) (*kwiltypes.CallResult, error)
+}
+
+// CallActionSource lists actions with a view action that takes no arguments
+// and returns (action_id, action_name, is_binary) rows. Actions listed this
+// way have no argument schema, so their args are left to the node to check.
+type CallActionSource struct {
+	Client actionCaller
+	// Action is the view action to call.
+	Action string
+}
+
+type actionRow struct {
+	ID       int64  `tn:"action_id"`
+	Name     string `tn:"action_name"`
+	IsBinary bool   `tn:"is_binary"`
+}
+
+var actionRowColumns = []string{"action_id", "action_name", "is_binary"}
+
+// ListActions implements ActionSource.
+func (s CallActionSource) ListActions(ctx context.Context) ([]types.ActionInfo, error) {
+	if s.Client == nil || s.Action == "" {
+		return nil, errors.New("client and action are required")
+	}
+	res, err := s.Client.Call(ctx, "", s.Action, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "call %s", s.Action)
+	}
+	if res == nil {
+		return nil, errors.Errorf("%s returned nil response", s.Action)
+	}
+	if res.Error != nil {
+		return nil, errors.Errorf("%s returned error: %s", s.Action, *res.Error)
+	}
+	if res.QueryResult == nil {
+		return nil, errors.Errorf("%s returned nil QueryResult", s.Action)
+	}
+	rows, err := decodePositional[actionRow](res.QueryResult.Values, actionRowColumns)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	out := make([]types.ActionInfo, 0, len(rows))
+	for _, r := range rows {
+		if r.ID <= 0 || r.ID > 0xffff {
+			return nil, errors.Errorf("%s returned invalid action ID %d for %s", s.Action, r.ID, r.Name)
+		}
+		out = append(out, types.ActionInfo{ID: uint16(r.ID), Name: r.Name, IsBinary: r.IsBinary})
+	}
+	return out, nil
+}
@@ -0,0 +1,99 @@
+package contractsapi_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/contractsapi"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+type fakeActionCaller struct {
+	action string
+	rows   [][]any
+}
+
+func (f *fakeActionCaller) Call(_ context.Context, _ string, action string, _ []any) (*kwiltypes.CallResult, error) {
+	f.action = action
+	return &kwiltypes.CallResult{QueryResult: &kwiltypes.QueryResult{Values: f.rows}}, nil
+}
+
+func TestSyncActionRegistry(t *testing.T) {
+	t.Cleanup(func() {
+		types.UnregisterAction(1010)
+		types.UnregisterAction(1011)
+	})
+	caller := &fakeActionCaller{rows: [][]any{
+		{int64(1), "get_record", false},
+		{int64(6), "price_above_threshold", true},
+		{int64(1010), "test_synced_above", true},
+	}}
+	src := contractsapi.CallActionSource{Client: caller, Action: "list_attestation_actions"}
+
+	added, err := contractsapi.SyncActionRegistry(context.Background(), src)
+	require.NoError(t, err)
+	assert.Equal(t, "list_attestation_actions", caller.action)
+	assert.Equal(t, []types.ActionInfo{{ID: 1010, Name: "test_synced_above", IsBinary: true}}, added)
+	assert.True(t, types.IsBinaryActionID(1010))
+	// Built-in actions keep their schema.
+	assert.NotEmpty(t, types.GetActionInfo("get_record").Args)
+
+	// Syncing again adds nothing.
+	added, err = contractsapi.SyncActionRegistry(context.Background(), src)
+	require.NoError(t, err)
+	assert.Empty(t, added)
+
+	// Conflicting IDs abort the sync without registering anything.
+	caller.rows = [][]any{
+		{int64(1011), "test_synced_below", true},
+		{int64(7), "test_renamed", true},
+	}
+	_, err = contractsapi.SyncActionRegistry(context.Background(), src)
+	assert.ErrorContains(t, err, "which the SDK knows as price_below_threshold")
+	assert.Nil(t, types.GetActionInfoByID(1011))
+}
+
+func TestBuildQueryComponents(t *testing.T) {
+	threshold, err := kwiltypes.ParseDecimalExplicit("100", 36, 18)
+	require.NoError(t, err)
+
+	qc, err := contractsapi.BuildQueryComponents(codecProvider, codecStream, "price_above_threshold",
+		[]any{codecProvider, codecStream, codecTime, threshold, (*int64)(nil)})
+	require.NoError(t, err)
+	expected, err := contractsapi.BuildPriceAboveThresholdQueryComponents(types.PriceAboveThresholdInput{
+		DataProvider: codecProvider, StreamID: codecStream, Timestamp: codecTime, Threshold: "100",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, expected, qc)
+
+	_, err = contractsapi.BuildQueryComponents(codecProvider, codecStream, "price_above_threshold",
+		[]any{codecProvider, codecStream, codecTime, "100"})
+	assert.ErrorContains(t, err, "threshold must be numeric(36,18)")
+
+	var unknown *types.UnknownActionError
+	_, err = contractsapi.BuildQueryComponents(codecProvider, codecStream, "test_unregistered", nil)
+	assert.ErrorAs(t, err, &unknown)
+
+	// Registered actions can be built once they have a schema.
+	t.Cleanup(func() { types.UnregisterAction(1020) })
+	require.NoError(t, types.RegisterAction(types.ActionInfo{
+		ID:       1020,
+		Name:     "test_record_above",
+		IsBinary: true,
+		Args: []types.ActionArg{
+			{Name: "data_provider", Type: "text"},
+			{Name: "stream_id", Type: "text"},
+			{Name: "timestamp", Type: "int8"},
+			{Name: "threshold", Type: "numeric(36,18)"},
+		},
+	}))
+	qc, err = contractsapi.BuildQueryComponents(codecProvider, codecStream, "test_record_above",
+		[]any{codecProvider, codecStream, codecTime, threshold})
+	require.NoError(t, err)
+	_, _, action, _, err := contractsapi.DecodeQueryComponents(qc)
+	require.NoError(t, err)
+	assert.Equal(t, "test_record_above", action)
+}
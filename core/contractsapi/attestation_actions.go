@@ -214,63 +214,20 @@ func (a *AttestationAction) ListAttestations(
 		return nil, errors.New("list_attestations returned nil QueryResult")
 	}
 
-	// Parse the result rows
-	// Expected columns: request_tx_id, attestation_hash, requester, created_height, signed_height, encrypt_sig
-	results := make([]types.AttestationMetadata, 0, len(callResult.QueryResult.Values))
-
-	for i, row := range callResult.QueryResult.Values {
-		if len(row) < 6 {
-			return nil, fmt.Errorf("row %d has insufficient columns: expected 6, got %d", i, len(row))
-		}
-
-		metadata := types.AttestationMetadata{}
-
-		// Column 0: request_tx_id (TEXT)
-		// Gateway always returns TEXT as string
-		requestTxID, ok := row[0].(string)
-		if !ok {
-			return nil, fmt.Errorf("row %d: expected request_tx_id to be string, got %T", i, row[0])
-		}
-		metadata.RequestTxID = requestTxID
-
-		// Column 1: attestation_hash (BYTEA)
-		if err := extractBytesColumn(row[1], &metadata.AttestationHash, i, "attestation_hash"); err != nil {
-			return nil, err
-		}
-
-		// Column 2: requester (BYTEA)
-		if err := extractBytesColumn(row[2], &metadata.Requester, i, "requester"); err != nil {
-			return nil, err
-		}
-
-		// Column 3: created_height (INT8)
-		if err := extractInt64Column(row[3], &metadata.CreatedHeight, i, "created_height"); err != nil {
-			return nil, err
-		}
-
-		// Column 4: signed_height (INT8, nullable)
-		if row[4] != nil {
-			var signedHeight int64
-			if err := extractInt64Column(row[4], &signedHeight, i, "signed_height"); err != nil {
-				return nil, err
-			}
-			metadata.SignedHeight = &signedHeight
-		}
-
-		// Column 5: encrypt_sig (BOOLEAN)
-		// Gateway always returns BOOLEAN as bool
-		encryptSig, ok := row[5].(bool)
-		if !ok {
-			return nil, fmt.Errorf("row %d: expected encrypt_sig to be bool, got %T", i, row[5])
-		}
-		metadata.EncryptSig = encryptSig
-
-		results = append(results, metadata)
+	results, err := decodePositional[types.AttestationMetadata](callResult.QueryResult.Values, attestationMetadataColumns)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if results == nil {
+		results = []types.AttestationMetadata{}
 	}
 
 	return results, nil
 }
 
+// attestationMetadataColumns is the column order returned by list_attestations.
+var attestationMetadataColumns = []string{"request_tx_id", "attestation_hash", "requester", "created_height", "signed_height", "encrypt_sig"}
+
 // Helper function to extract bytes from a column
 // The Kwil gateway returns BYTEA columns as base64-encoded strings in JSON responses.
 // However, for certain fields (like wallet addresses), it returns hex strings (prefixed with 0x).
@@ -427,8 +427,8 @@ func ParseAttestationPayload(payload []byte) (*sdktypes.ParsedAttestationPayload
 	}
 	resultBytes := payload[offset : offset+int(resultLen)]
 
-	// Decode result (ABI-encoded as uint256[], int256[])
-	result, err := decodeABIDatapoints(resultBytes)
+	// Decode result with the action's registered decoder
+	result, err := decodeAttestationResult(actionID, resultBytes)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode result: %w", err)
 	}
@@ -445,6 +445,25 @@ func ParseAttestationPayload(payload []byte) (*sdktypes.ParsedAttestationPayload
 	}, nil
 }
 
+// decodeAttestationResult decodes a payload result with the decoder of its
+// action in sdktypes.ActionRegistry: DecodeResult when set, one row holding
+// the bool for binary actions, and timestamp/value rows otherwise.
+func decodeAttestationResult(actionID uint16, data []byte) ([]sdktypes.DecodedRow, error) {
+	info := sdktypes.GetActionInfoByID(actionID)
+	switch {
+	case info != nil && info.DecodeResult != nil:
+		return info.DecodeResult(data)
+	case info != nil && info.IsBinary:
+		b, err := decodeABIBoolean(data)
+		if err != nil {
+			return nil, err
+		}
+		return []sdktypes.DecodedRow{{Values: []any{b}}}, nil
+	default:
+		return decodeABIDatapoints(data)
+	}
+}
+
 // ═══════════════════════════════════════════════════════════════
 // BINARY ACTION RESULT PARSING
 // ═══════════════════════════════════════════════════════════════
@@ -493,7 +512,8 @@ func decodeABIBoolean(data []byte) (bool, error) {
 
 // ParseBooleanResult extracts a boolean result from a binary action attestation payload.
 //
-// This function is specifically for binary attestation actions (IDs 6-9):
+// This function is specifically for binary attestation actions, i.e. those
+// registered with IsBinary, such as the built-in ones (IDs 6-9):
 //   - price_above_threshold (6)
 //   - price_below_threshold (7)
 //   - value_in_range (8)
@@ -506,7 +526,7 @@ func decodeABIBoolean(data []byte) (bool, error) {
 //
 // Returns:
 //   - result: The boolean outcome (TRUE/FALSE)
-//   - actionID: The action ID from the payload (should be 6-9)
+//   - actionID: The action ID from the payload
 //   - err: Error if parsing fails or action is not a binary action
 func ParseBooleanResult(payload []byte) (result bool, actionID uint16, err error) {
 	// First, parse enough to get the action ID and result bytes
@@ -561,7 +581,7 @@ func ParseBooleanResult(payload []byte) (result bool, actionID uint16, err error
 
 	// Validate this is a binary action
 	if !sdktypes.IsBinaryActionID(actionID) {
-		return false, actionID, fmt.Errorf("action ID %d is not a binary action (expected 6-9)", actionID)
+		return false, actionID, fmt.Errorf("action ID %d is not a registered binary action", actionID)
 	}
 
 	// 7. Skip arguments (length-prefixed)
@@ -599,9 +619,8 @@ func ParseBooleanResult(payload []byte) (result bool, actionID uint16, err error
 // This is useful when you've already called ParseAttestationPayload and want to interpret
 // the result as a boolean.
 //
-// Note: This function attempts to re-interpret the Result field. For binary actions,
-// the Result will be empty (decodeABIDatapoints can't parse abi.encode(bool)).
-// Use ParseBooleanResult with raw payload instead for binary actions.
+// ParseAttestationPayload decodes binary action results as a single row holding
+// the bool, unless the action registers its own DecodeResult.
 func ParseBooleanResultFromParsed(parsed *sdktypes.ParsedAttestationPayload) (bool, error) {
 	if parsed == nil {
 		return false, fmt.Errorf("parsed payload is nil")
@@ -609,16 +628,13 @@ func ParseBooleanResultFromParsed(parsed *sdktypes.ParsedAttestationPayload) (bo
 
 	// Validate this is a binary action
 	if !sdktypes.IsBinaryActionID(parsed.ActionID) {
-		return false, fmt.Errorf("action ID %d is not a binary action (expected 6-9)", parsed.ActionID)
+		return false, fmt.Errorf("action ID %d is not a registered binary action", parsed.ActionID)
 	}
 
-	// For binary actions, the Result field may be empty or incorrectly parsed
-	// because decodeABIDatapoints expects uint256[]/int256[] format
 	if len(parsed.Result) == 0 {
 		return false, fmt.Errorf("no result in parsed payload (use ParseBooleanResult with raw payload for binary actions)")
 	}
 
-	// If result was somehow parsed, try to extract boolean
 	if len(parsed.Result[0].Values) == 0 {
 		return false, fmt.Errorf("no values in first result row")
 	}
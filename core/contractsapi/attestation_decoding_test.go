@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/trufnetwork/kwil-db/core/types"
+	sdktypes "github.com/trufnetwork/sdk-go/core/types"
 )
 
 // Test binary reading helpers
@@ -302,6 +303,34 @@ func TestDecodeABIDatapoints(t *testing.T) {
 	})
 }
 
+func TestDecodeAttestationResult(t *testing.T) {
+	boolType, _ := abi.NewType("bool", "", nil)
+	trueResult, err := abi.Arguments{{Type: boolType}}.Pack(true)
+	require.NoError(t, err)
+
+	// Binary actions decode to a single row holding the bool.
+	rows, err := decodeAttestationResult(sdktypes.GetActionID("value_equals"), trueResult)
+	require.NoError(t, err)
+	assert.Equal(t, []sdktypes.DecodedRow{{Values: []any{true}}}, rows)
+
+	// Registered decoders take precedence.
+	t.Cleanup(func() { sdktypes.UnregisterAction(2001) })
+	require.NoError(t, sdktypes.RegisterAction(sdktypes.ActionInfo{
+		ID:   2001,
+		Name: "test_raw_result",
+		DecodeResult: func(result []byte) ([]sdktypes.DecodedRow, error) {
+			return []sdktypes.DecodedRow{{Values: []any{len(result)}}}, nil
+		},
+	}))
+	rows, err = decodeAttestationResult(2001, trueResult)
+	require.NoError(t, err)
+	assert.Equal(t, []sdktypes.DecodedRow{{Values: []any{32}}}, rows)
+
+	// Unknown actions fall back to datapoints.
+	_, err = decodeAttestationResult(2002, trueResult)
+	assert.Error(t, err)
+}
+
 // Test ParseAttestationPayload
 func TestParseAttestationPayload(t *testing.T) {
 	t.Run("minimal valid payload", func(t *testing.T) {
@@ -0,0 +1,62 @@
+package contractsapi
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/trufnetwork/kwil-db/core/crypto"
+	sdktypes "github.com/trufnetwork/sdk-go/core/types"
+)
+
+// AttestationSignatureLength is the length of the secp256k1 signature
+// appended to the canonical payload of a signed attestation.
+const AttestationSignatureLength = 65
+
+// SplitSignedAttestation splits a signed attestation, as returned by
+// GetSignedAttestation, into its canonical payload and signature.
+func SplitSignedAttestation(signed []byte) (payload, signature []byte, err error) {
+	if len(signed) <= AttestationSignatureLength {
+		return nil, nil, fmt.Errorf("signed attestation too short: %d bytes", len(signed))
+	}
+	offset := len(signed) - AttestationSignatureLength
+	return signed[:offset], signed[offset:], nil
+}
+
+// RecoverAttestationSigner returns the 0x-prefixed address of the validator
+// that signed the canonical payload. The signature is over sha256(payload),
+// with V as 27/28 in the Ethereum convention.
+func RecoverAttestationSigner(payload, signature []byte) (string, error) {
+	if len(signature) != AttestationSignatureLength {
+		return "", fmt.Errorf("signature must be %d bytes, got %d", AttestationSignatureLength, len(signature))
+	}
+	hash := sha256.Sum256(payload)
+	sig := make([]byte, AttestationSignatureLength)
+	copy(sig, signature)
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+	pub, err := crypto.RecoverSecp256k1KeyFromSigHash(hash[:], sig)
+	if err != nil {
+		return "", fmt.Errorf("failed to recover attestation signer: %w", err)
+	}
+	return fmt.Sprintf("0x%x", crypto.EthereumAddressFromPubKey(pub)), nil
+}
+
+// VerifySignedAttestation recovers the signer of a signed attestation and
+// parses its payload. A recoverable signature only shows who signed; callers
+// must compare the signer with the validators they trust.
+func VerifySignedAttestation(signed []byte) (*sdktypes.ParsedAttestationPayload, string, error) {
+	payload, signature, err := SplitSignedAttestation(signed)
+	if err != nil {
+		return nil, "", err
+	}
+	signer, err := RecoverAttestationSigner(payload, signature)
+	if err != nil {
+		return nil, "", err
+	}
+	parsed, err := ParseAttestationPayload(payload)
+	if err != nil {
+		return nil, "", err
+	}
+	return parsed, signer, nil
+}
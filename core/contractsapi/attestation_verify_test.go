@@ -0,0 +1,62 @@
+package contractsapi_test
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trufnetwork/sdk-go/core/contractsapi"
+)
+
+// signedBooleanPayload builds a binary action payload returning true and
+// signs it the way validators do.
+func signedBooleanPayload(t *testing.T) (signed []byte, signer string) {
+	t.Helper()
+	lengthPrefixed := func(b []byte) []byte {
+		return append(binary.BigEndian.AppendUint32(nil, uint32(len(b))), b...)
+	}
+	p := []byte{1, 0}
+	p = binary.BigEndian.AppendUint64(p, 100)
+	p = append(p, lengthPrefixed(make([]byte, 20))...)
+	p = append(p, lengthPrefixed([]byte(codecStream))...)
+	p = binary.BigEndian.AppendUint16(p, 6)
+	p = append(p, lengthPrefixed(nil)...)
+	word := make([]byte, 32)
+	word[31] = 1
+	p = append(p, lengthPrefixed(word)...)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	hash := sha256.Sum256(p)
+	sig, err := crypto.Sign(hash[:], key)
+	require.NoError(t, err)
+	sig[64] += 27
+	return append(p, sig...), strings.ToLower(crypto.PubkeyToAddress(key.PublicKey).Hex())
+}
+
+func TestVerifySignedAttestation(t *testing.T) {
+	signed, signer := signedBooleanPayload(t)
+
+	parsed, got, err := contractsapi.VerifySignedAttestation(signed)
+	require.NoError(t, err)
+	assert.Equal(t, signer, got)
+	assert.Equal(t, uint16(6), parsed.ActionID)
+	assert.Equal(t, codecStream, parsed.StreamID)
+	outcome, err := contractsapi.ParseBooleanResultFromParsed(parsed)
+	require.NoError(t, err)
+	assert.True(t, outcome)
+
+	// Tampering with the payload changes the recovered signer.
+	tampered := append([]byte(nil), signed...)
+	tampered[9]++ // block height
+	_, got, err = contractsapi.VerifySignedAttestation(tampered)
+	require.NoError(t, err)
+	assert.NotEqual(t, signer, got)
+
+	_, _, err = contractsapi.VerifySignedAttestation(signed[:65])
+	assert.ErrorContains(t, err, "too short")
+}
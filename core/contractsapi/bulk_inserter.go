@@ -4,8 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strings"
-	"sync"
 	"time"
 
 	pkgerrors "github.com/pkg/errors"
@@ -13,6 +11,7 @@ import (
 	"github.com/trufnetwork/kwil-db/core/crypto/auth"
 	"github.com/trufnetwork/kwil-db/core/log"
 	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/internal/noncepipe"
 	sdktypes "github.com/trufnetwork/sdk-go/core/types"
 )
 
@@ -68,9 +67,7 @@ type BulkInserter struct {
 	waitInterval       time.Duration
 	progressLogEveryN  int
 
-	mu               sync.Mutex
-	pendingNonce     int64
-	nonceInitialized bool
+	pipeline *noncepipe.Pipeline
 }
 
 // BulkInserterOption configures a BulkInserter.
@@ -125,16 +122,15 @@ func WithCatchupMaxAttempts(n int) BulkInserterOption {
 }
 
 // WithInfraMaxAttempts sets the maximum number of attempts per chunk on
-// transient infrastructure errors that are unambiguously pre-broadcast --
-// see isInfraErr for the matched patterns ("no available backend",
-// "connection refused", "no such host"). Pre-broadcast means the request
-// demonstrably never reached kwild, so retrying with the same nonce is
-// safe and cannot produce duplicate transactions. Errors that may fire
-// after the tx was admitted (EOF, connection reset, context deadline
-// exceeded) deliberately stay in the default-fall-through bucket and
-// bubble up to the caller's resume layer, which can recover via partial
-// progress without risking duplicate inserts. Reuses retryBackoff for
-// the inter-attempt sleep. Default: 10.
+// transient infrastructure errors that are unambiguously pre-broadcast
+// ("no available backend", "connection refused", "no such host").
+// Pre-broadcast means the request demonstrably never reached kwild, so
+// retrying with the same nonce is safe and cannot produce duplicate
+// transactions. Errors that may fire after the tx was admitted (EOF,
+// connection reset, context deadline exceeded) deliberately stay in the
+// default-fall-through bucket and bubble up to the caller's resume layer,
+// which can recover via partial progress without risking duplicate
+// inserts. Reuses retryBackoff for the inter-attempt sleep. Default: 10.
 func WithInfraMaxAttempts(n int) BulkInserterOption {
 	return func(b *BulkInserter) {
 		if n > 0 {
@@ -260,6 +256,17 @@ func NewBulkInserter(
 	for _, opt := range opts {
 		opt(b)
 	}
+	b.pipeline = &noncepipe.Pipeline{
+		Accounts:           txClient,
+		AccountID:          accountID,
+		Logger:             b.logger,
+		LogName:            "bulk_inserter",
+		MaxAttempts:        b.maxAttempts,
+		CatchupMaxAttempts: b.catchupMaxAttempts,
+		InfraMaxAttempts:   b.infraMaxAttempts,
+		RetryBackoff:       b.retryBackoff,
+		CatchupBackoff:     b.catchupBackoff,
+	}
 	return b, nil
 }
 
@@ -392,186 +399,13 @@ func (b *BulkInserter) logProgress(done, total, rowsDone int, start time.Time) {
 func (b *BulkInserter) broadcastWithRetry(
 	ctx context.Context,
 	chunk []sdktypes.InsertRecordInput,
-) (hash kwiltypes.Hash, retErr error) {
-	var (
-		nonce             int64
-		nonceLoaded       bool
-		transientAttempts int // counts ErrInvalidNonce + ErrMempoolFull tries
-		catchupAttempts   int // counts "node is catching up" tries
-		infraAttempts     int // counts pre-broadcast infra errors (see isInfraErr)
-	)
-	// On any error exit (attempt exhaustion, context cancellation during
-	// backoff, or an unhandled error), drop the cached nonce. The reserved
-	// nonce was never admitted to the network, so a subsequent InsertAll
-	// would otherwise broadcast the next nonce first and only recover via
-	// ErrInvalidNonce — wasting one round-trip every time. Forcing a fresh
-	// GetAccount on the next call resyncs us with what the ledger actually
-	// admitted. Idempotent with the ErrInvalidNonce path's reset.
-	defer func() {
-		if retErr != nil && nonceLoaded {
-			b.resetNonce()
-		}
-	}()
-	for {
-		// Pull a fresh nonce only on the first attempt OR after an
-		// ErrInvalidNonce reset. On ErrMempoolFull we keep the same nonce
-		// because the tx was rejected at admission — the mempool's
-		// expected nonce for this account hasn't moved.
-		if !nonceLoaded {
-			n, err := b.nextNonce(ctx)
-			if err != nil {
-				return kwiltypes.Hash{}, pkgerrors.Wrap(err, "fetch nonce")
-			}
-			nonce = n
-			nonceLoaded = true
-		}
-
-		hash, err := b.broadcaster.InsertRecords(ctx, chunk,
+) (kwiltypes.Hash, error) {
+	return b.pipeline.Broadcast(ctx, func(nonce int64) (kwiltypes.Hash, error) {
+		return b.broadcaster.InsertRecords(ctx, chunk,
 			kwilclient.WithNonce(nonce),
 			kwilclient.WithSyncBroadcast(false),
 		)
-		if err == nil {
-			return hash, nil
-		}
-
-		switch {
-		case errors.Is(err, kwiltypes.ErrInvalidNonce):
-			if transientAttempts+1 >= b.maxAttempts {
-				return kwiltypes.Hash{}, err
-			}
-			b.logger.Warn("bulk_inserter: invalid nonce, resetting cache",
-				"attempt", transientAttempts+1, "nonce", nonce, "err", err)
-			b.resetNonce()
-			nonceLoaded = false // force re-fetch on next attempt
-			if waitErr := b.backoff(ctx, transientAttempts); waitErr != nil {
-				return kwiltypes.Hash{}, waitErr
-			}
-			transientAttempts++
-		case errors.Is(err, kwiltypes.ErrMempoolFull):
-			if transientAttempts+1 >= b.maxAttempts {
-				return kwiltypes.Hash{}, err
-			}
-			b.logger.Warn("bulk_inserter: mempool full, backing off",
-				"attempt", transientAttempts+1, "nonce", nonce, "err", err)
-			// Keep nonceLoaded=true so we retry with the same nonce.
-			if waitErr := b.backoff(ctx, transientAttempts); waitErr != nil {
-				return kwiltypes.Hash{}, waitErr
-			}
-			transientAttempts++
-		case isCatchingUpErr(err):
-			// Catch-up gets its own larger budget — see WithCatchupMaxAttempts
-			// rationale. Real catch-up events on a public RPC backend (sentry
-			// replaying blocks after a peer flap) routinely run minutes long;
-			// sharing the transient budget here is what previously aborted
-			// 4-hour BulkInserter runs after just 75 seconds of waiting.
-			if catchupAttempts+1 >= b.catchupMaxAttempts {
-				return kwiltypes.Hash{}, err
-			}
-			b.logger.Warn("bulk_inserter: backend catching up, backing off",
-				"attempt", catchupAttempts+1,
-				"max_attempts", b.catchupMaxAttempts,
-				"nonce", nonce, "err", err)
-			// Keep nonceLoaded=true — the backend never admitted the tx, our
-			// cached nonce is still correct.
-			if waitErr := b.backoffCatchup(ctx, catchupAttempts); waitErr != nil {
-				return kwiltypes.Hash{}, waitErr
-			}
-			catchupAttempts++
-		case isInfraErr(err):
-			// Pre-broadcast infra failure: KGW had no backend, TCP refused,
-			// or DNS missed -- the request demonstrably never reached kwild,
-			// so retrying with the same cached nonce is safe and won't
-			// produce duplicate transactions. See WithInfraMaxAttempts and
-			// isInfraErr for the exact patterns.
-			if infraAttempts+1 >= b.infraMaxAttempts {
-				return kwiltypes.Hash{}, err
-			}
-			b.logger.Warn("bulk_inserter: pre-broadcast infra error, backing off",
-				"attempt", infraAttempts+1,
-				"max_attempts", b.infraMaxAttempts,
-				"nonce", nonce, "err", err)
-			// Keep nonceLoaded=true — the backend never saw the tx.
-			if waitErr := b.backoff(ctx, infraAttempts); waitErr != nil {
-				return kwiltypes.Hash{}, waitErr
-			}
-			infraAttempts++
-		default:
-			return kwiltypes.Hash{}, err
-		}
-	}
-}
-
-func (b *BulkInserter) backoff(ctx context.Context, attempt int) error {
-	delay := b.retryBackoff * time.Duration(attempt+1)
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	case <-time.After(delay):
-		return nil
-	}
-}
-
-// backoffCatchup uses a longer base than backoff() because catch-up events
-// typically resolve in tens of seconds, not single seconds. Linear ramp.
-func (b *BulkInserter) backoffCatchup(ctx context.Context, attempt int) error {
-	delay := b.catchupBackoff * time.Duration(attempt+1)
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	case <-time.After(delay):
-		return nil
-	}
-}
-
-// isCatchingUpErr reports whether err is a kwild "node is catching up"
-// rejection. kwild emits this from node/node.go as a raw errors.New and the
-// RPC layer surfaces it as a BroadcastError with TxCode 65535
-// (CodeUnknownError). There is no exported sentinel in kwil-db today, so we
-// match by substring on the literal message kwild produces.
-//
-// TODO: replace with errors.Is(err, kwiltypes.ErrCatchingUp) once kwil-db
-// exports a typed sentinel + dedicated TxCode (P1 follow-up tracked in
-// 0MainnetPredictionMarket/6IncidentTriage-NodeCatchingUp-2026-04-21.md).
-func isCatchingUpErr(err error) bool {
-	if err == nil {
-		return false
-	}
-	return strings.Contains(err.Error(), "node is catching up")
-}
-
-// isInfraErr reports whether err is an unambiguously *pre-broadcast*
-// infrastructure failure -- the request never reached kwild, so retrying
-// with the same cached nonce is safe and cannot produce duplicate
-// transactions.
-//
-// Allowed patterns:
-//   - "no available backend"  -- KGW returns this 500 when its active health
-//     check has zero healthy upstreams. The request is rejected at the
-//     gateway before any backend connection is opened.
-//   - "connection refused"    -- TCP layer refused the connection. Nothing
-//     was sent on the wire.
-//   - "no such host"          -- DNS resolution failed. No connection
-//     attempted.
-//
-// Deliberately excluded (mid-request / post-broadcast ambiguity):
-//   - "EOF"                       -- can fire after kwild accepted the tx
-//     and started replying; retry would re-broadcast the same payload at a
-//     bumped nonce and duplicate the insert.
-//   - "connection reset by peer"  -- can fire mid-response, same risk as EOF.
-//   - "context deadline exceeded" -- the deadline can elapse while kwild
-//     is processing the broadcast; retry has the same duplication risk.
-//
-// These ambiguous errors fall through to the default branch and bubble up
-// to the caller's resume layer, which can recover via partial-progress
-// slicing without risking duplicate inserts.
-func isInfraErr(err error) bool {
-	if err == nil {
-		return false
-	}
-	msg := err.Error()
-	return strings.Contains(msg, "no available backend") ||
-		strings.Contains(msg, "connection refused") ||
-		strings.Contains(msg, "no such host")
+	})
 }
 
 func (b *BulkInserter) drain(ctx context.Context, hashes []kwiltypes.Hash) error {
@@ -583,34 +417,6 @@ func (b *BulkInserter) drain(ctx context.Context, hashes []kwiltypes.Hash) error
 	return nil
 }
 
-func (b *BulkInserter) nextNonce(ctx context.Context) (int64, error) {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-
-	if b.nonceInitialized {
-		nonce := b.pendingNonce
-		b.pendingNonce++
-		return nonce, nil
-	}
-
-	account, err := b.txClient.GetAccount(ctx, b.accountID, kwiltypes.AccountStatusPending)
-	if err != nil {
-		return 0, pkgerrors.Wrap(err, "get account")
-	}
-
-	nonce := account.Nonce + 1
-	b.pendingNonce = nonce + 1
-	b.nonceInitialized = true
-	return nonce, nil
-}
-
-func (b *BulkInserter) resetNonce() {
-	b.mu.Lock()
-	defer b.mu.Unlock()
-	b.nonceInitialized = false
-	b.pendingNonce = 0
-}
-
 func chunkInputs(inputs []sdktypes.InsertRecordInput, size int) [][]sdktypes.InsertRecordInput {
 	if size <= 0 {
 		size = 10
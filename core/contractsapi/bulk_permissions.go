@@ -0,0 +1,501 @@
+package contractsapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	kwilclient "github.com/trufnetwork/kwil-db/core/client/types"
+	"github.com/trufnetwork/kwil-db/core/crypto/auth"
+	"github.com/trufnetwork/kwil-db/core/log"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/internal/noncepipe"
+	"github.com/trufnetwork/sdk-go/core/tnerrors"
+	sdktypes "github.com/trufnetwork/sdk-go/core/types"
+	"github.com/trufnetwork/sdk-go/core/util"
+)
+
+// BulkPermissionClient is the minimal tx interface BulkPermissions needs.
+// *gatewayclient.GatewayClient satisfies this.
+type BulkPermissionClient interface {
+	BulkInsertTxClient
+	Execute(ctx context.Context, namespace string, action string, tuples [][]any, opts ...kwilclient.TxOpt) (kwiltypes.Hash, error)
+}
+
+// BulkPermissionReader is the read side BulkPermissions needs to resolve the
+// row ids of existing grants. sdktypes.IAction satisfies this.
+type BulkPermissionReader interface {
+	GetMetadata(ctx context.Context, locator sdktypes.StreamLocator, key sdktypes.MetadataKey) ([]sdktypes.MetadataEntry, error)
+}
+
+// BulkPermissions applies many permission changes with as few transactions as
+// possible. insert_metadata and disable_metadata accept several argument
+// tuples per call, and every tuple of a call runs inside the same transaction,
+// so up to batchSize grants (or revocations) share one tx. Transactions are
+// broadcast back to back with locally assigned nonces, as BulkInserter does,
+// and only awaited once everything is broadcast.
+//
+// A transaction is atomic: if one tuple fails on the node (for example the
+// signer does not own one of the streams), every item packed into that tx is
+// reported as failed. Items of other transactions are unaffected.
+//
+// Like BulkInserter, use one BulkPermissions per signer and do not call it
+// concurrently.
+type BulkPermissions struct {
+	reader    BulkPermissionReader
+	client    BulkPermissionClient
+	accountID *kwiltypes.AccountID
+	logger    log.Logger
+
+	batchSize    int
+	maxAttempts  int
+	retryBackoff time.Duration
+	waitInterval time.Duration
+
+	pipeline *noncepipe.Pipeline
+}
+
+// BulkPermissionOption configures a BulkPermissions.
+type BulkPermissionOption func(*BulkPermissions)
+
+// WithPermissionBatchSize sets how many metadata tuples go into each
+// transaction. Default: 50.
+func WithPermissionBatchSize(n int) BulkPermissionOption {
+	return func(b *BulkPermissions) {
+		if n > 0 {
+			b.batchSize = n
+		}
+	}
+}
+
+// WithPermissionMaxAttempts sets the maximum number of broadcast attempts per
+// transaction on invalid-nonce and mempool-full errors, and separately on
+// catch-up and on pre-broadcast infra errors. Default: 5.
+func WithPermissionMaxAttempts(n int) BulkPermissionOption {
+	return func(b *BulkPermissions) {
+		if n > 0 {
+			b.maxAttempts = n
+		}
+	}
+}
+
+// WithPermissionRetryBackoff sets the base backoff between broadcast attempts,
+// for every retried error. Actual delay is backoff * (attempt + 1).
+// Default: 2s.
+func WithPermissionRetryBackoff(d time.Duration) BulkPermissionOption {
+	return func(b *BulkPermissions) {
+		if d > 0 {
+			b.retryBackoff = d
+		}
+	}
+}
+
+// WithPermissionWaitInterval sets the polling interval passed to WaitTx.
+// Default: 1s.
+func WithPermissionWaitInterval(d time.Duration) BulkPermissionOption {
+	return func(b *BulkPermissions) {
+		if d > 0 {
+			b.waitInterval = d
+		}
+	}
+}
+
+// WithPermissionLogger attaches a logger. Default: discard.
+func WithPermissionLogger(logger log.Logger) BulkPermissionOption {
+	return func(b *BulkPermissions) {
+		b.logger = logger
+	}
+}
+
+// NewBulkPermissions constructs a BulkPermissions. The signer is used once to
+// derive the account ID for nonce lookups.
+//
+// Most callers should use tnclient.Client.LoadBulkPermissions() instead.
+func NewBulkPermissions(
+	reader BulkPermissionReader,
+	client BulkPermissionClient,
+	signer auth.Signer,
+	opts ...BulkPermissionOption,
+) (*BulkPermissions, error) {
+	if reader == nil {
+		return nil, errors.New("metadata reader is required")
+	}
+	if client == nil {
+		return nil, errors.New("tx client is required")
+	}
+	if signer == nil {
+		return nil, errors.New("signer is required")
+	}
+
+	accountID, err := kwiltypes.GetSignerAccount(signer)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "derive account id from signer")
+	}
+
+	b := &BulkPermissions{
+		reader:       reader,
+		client:       client,
+		accountID:    accountID,
+		logger:       log.DiscardLogger,
+		batchSize:    50,
+		maxAttempts:  5,
+		retryBackoff: 2 * time.Second,
+		waitInterval: 1 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	b.pipeline = &noncepipe.Pipeline{
+		Accounts:           client,
+		AccountID:          accountID,
+		Logger:             b.logger,
+		LogName:            "bulk_permissions",
+		MaxAttempts:        b.maxAttempts,
+		CatchupMaxAttempts: b.maxAttempts,
+		InfraMaxAttempts:   b.maxAttempts,
+		RetryBackoff:       b.retryBackoff,
+		CatchupBackoff:     b.retryBackoff,
+	}
+	return b, nil
+}
+
+// PermissionOutcome is the result of one input item. Outcomes are returned in
+// input order.
+type PermissionOutcome struct {
+	Stream sdktypes.StreamLocator
+	// Target is what the item grants or revokes: a wallet address, a stream id
+	// or a visibility value.
+	Target string
+	// TxHash is nil when the item never reached a transaction (lookup failure,
+	// nothing to revoke, broadcast failure).
+	TxHash *kwiltypes.Hash
+	Err    error
+}
+
+// Succeeded reports whether the item's transaction was included successfully.
+func (o PermissionOutcome) Succeeded() bool {
+	return o.Err == nil
+}
+
+// BulkPermissionError is returned alongside the outcomes when at least one
+// item failed. It unwraps to the first item error.
+type BulkPermissionError struct {
+	Failed int
+	Total  int
+	First  error
+}
+
+func (e *BulkPermissionError) Error() string {
+	return fmt.Sprintf("%d of %d permission changes failed: %v", e.Failed, e.Total, e.First)
+}
+
+func (e *BulkPermissionError) Unwrap() error {
+	return e.First
+}
+
+// ReadWalletRevocation is an allow-list entry to be disabled. A wallet may have
+// several active rows; all of them are disabled.
+type ReadWalletRevocation struct {
+	Wallet string
+	RowIds []*kwiltypes.UUID
+}
+
+// ReadWalletSyncPlan is the difference between a stream's read allow-list and
+// a desired set of wallets.
+type ReadWalletSyncPlan struct {
+	Stream    sdktypes.StreamLocator
+	Grant     []util.EthereumAddress
+	Revoke    []ReadWalletRevocation
+	Unchanged []util.EthereumAddress
+}
+
+// IsEmpty reports whether the allow-list already matches the desired set.
+func (p *ReadWalletSyncPlan) IsEmpty() bool {
+	return len(p.Grant) == 0 && len(p.Revoke) == 0
+}
+
+// ReadWalletSyncResult holds the executed plan and one outcome per grant
+// followed by one outcome per revocation.
+type ReadWalletSyncResult struct {
+	Plan     *ReadWalletSyncPlan
+	Outcomes []PermissionOutcome
+}
+
+// permissionOp is one input item together with the tuples it contributes.
+// All tuples of an op land in the same transaction.
+type permissionOp struct {
+	index  int
+	action string
+	tuples [][]any
+}
+
+type permissionChunk struct {
+	action string
+	ops    []permissionOp
+}
+
+// AllowReadWallets is the batch form of AllowReadWallet.
+func (b *BulkPermissions) AllowReadWallets(ctx context.Context, inputs []sdktypes.ReadWalletInput) ([]PermissionOutcome, error) {
+	outcomes := make([]PermissionOutcome, len(inputs))
+	ops := make([]permissionOp, 0, len(inputs))
+	for i, input := range inputs {
+		outcomes[i] = PermissionOutcome{Stream: input.Stream, Target: input.Wallet.Address()}
+		ops = append(ops, insertMetadataOp(i, input.Stream, sdktypes.AllowReadWalletKey, input.Wallet.Address()))
+	}
+	return b.run(ctx, ops, outcomes)
+}
+
+// DisableReadWallets is the batch form of DisableReadWallet. Each stream's
+// allow-list is read once; every active row of a wallet is disabled, and
+// wallets that are not on the list fail with MetadataValueNotFound without
+// sending a transaction.
+func (b *BulkPermissions) DisableReadWallets(ctx context.Context, inputs []sdktypes.ReadWalletInput) ([]PermissionOutcome, error) {
+	outcomes := make([]PermissionOutcome, len(inputs))
+	rowsByStream := make(map[string]map[string][]*kwiltypes.UUID)
+	lookupErrs := make(map[string]error)
+
+	ops := make([]permissionOp, 0, len(inputs))
+	for i, input := range inputs {
+		wallet := input.Wallet.Address()
+		outcomes[i] = PermissionOutcome{Stream: input.Stream, Target: wallet}
+
+		key := locatorKey(input.Stream)
+		if _, seen := rowsByStream[key]; !seen && lookupErrs[key] == nil {
+			rows, err := b.readWalletRows(ctx, input.Stream)
+			if err != nil {
+				lookupErrs[key] = err
+			} else {
+				rowsByStream[key] = rows
+			}
+		}
+		if err := lookupErrs[key]; err != nil {
+			outcomes[i].Err = err
+			continue
+		}
+
+		rowIds := rowsByStream[key][wallet]
+		if len(rowIds) == 0 {
+			outcomes[i].Err = MetadataValueNotFound
+			continue
+		}
+		ops = append(ops, disableMetadataOp(i, input.Stream, rowIds))
+	}
+	return b.run(ctx, ops, outcomes)
+}
+
+// AllowComposeStreams is the batch form of AllowComposeStream.
+func (b *BulkPermissions) AllowComposeStreams(ctx context.Context, locators []sdktypes.StreamLocator) ([]PermissionOutcome, error) {
+	outcomes := make([]PermissionOutcome, len(locators))
+	ops := make([]permissionOp, 0, len(locators))
+	for i, locator := range locators {
+		outcomes[i] = PermissionOutcome{Stream: locator, Target: locator.StreamId.String()}
+		ops = append(ops, insertMetadataOp(i, locator, sdktypes.AllowComposeStreamKey, locator.StreamId.String()))
+	}
+	return b.run(ctx, ops, outcomes)
+}
+
+// SetReadVisibilities is the batch form of SetReadVisibility.
+func (b *BulkPermissions) SetReadVisibilities(ctx context.Context, inputs []sdktypes.VisibilityInput) ([]PermissionOutcome, error) {
+	outcomes := make([]PermissionOutcome, len(inputs))
+	ops := make([]permissionOp, 0, len(inputs))
+	for i, input := range inputs {
+		value := fmt.Sprintf("%d", int(input.Visibility))
+		outcomes[i] = PermissionOutcome{Stream: input.Stream, Target: value}
+		ops = append(ops, insertMetadataOp(i, input.Stream, sdktypes.ReadVisibilityKey, value))
+	}
+	return b.run(ctx, ops, outcomes)
+}
+
+// PlanReadWalletSync compares the stream's read allow-list with desired
+// without sending anything.
+func (b *BulkPermissions) PlanReadWalletSync(ctx context.Context, stream sdktypes.StreamLocator, desired []util.EthereumAddress) (*ReadWalletSyncPlan, error) {
+	current, err := b.readWalletRows(ctx, stream)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &ReadWalletSyncPlan{Stream: stream}
+	wanted := make(map[string]bool, len(desired))
+	for _, wallet := range desired {
+		addr := wallet.Address()
+		if wanted[addr] {
+			continue
+		}
+		wanted[addr] = true
+		if len(current[addr]) > 0 {
+			plan.Unchanged = append(plan.Unchanged, wallet)
+		} else {
+			plan.Grant = append(plan.Grant, wallet)
+		}
+	}
+	for addr, rowIds := range current {
+		if !wanted[addr] {
+			plan.Revoke = append(plan.Revoke, ReadWalletRevocation{Wallet: addr, RowIds: rowIds})
+		}
+	}
+	sort.Slice(plan.Revoke, func(i, j int) bool { return plan.Revoke[i].Wallet < plan.Revoke[j].Wallet })
+	return plan, nil
+}
+
+// SyncReadWallets makes the stream's read allow-list exactly desired: wallets
+// missing from the list are granted and wallets not in desired are revoked.
+func (b *BulkPermissions) SyncReadWallets(ctx context.Context, stream sdktypes.StreamLocator, desired []util.EthereumAddress) (*ReadWalletSyncResult, error) {
+	plan, err := b.PlanReadWalletSync(ctx, stream, desired)
+	if err != nil {
+		return nil, err
+	}
+
+	outcomes := make([]PermissionOutcome, 0, len(plan.Grant)+len(plan.Revoke))
+	ops := make([]permissionOp, 0, cap(outcomes))
+	for _, wallet := range plan.Grant {
+		ops = append(ops, insertMetadataOp(len(outcomes), stream, sdktypes.AllowReadWalletKey, wallet.Address()))
+		outcomes = append(outcomes, PermissionOutcome{Stream: stream, Target: wallet.Address()})
+	}
+	for _, revocation := range plan.Revoke {
+		ops = append(ops, disableMetadataOp(len(outcomes), stream, revocation.RowIds))
+		outcomes = append(outcomes, PermissionOutcome{Stream: stream, Target: revocation.Wallet})
+	}
+
+	outcomes, err = b.run(ctx, ops, outcomes)
+	return &ReadWalletSyncResult{Plan: plan, Outcomes: outcomes}, err
+}
+
+// readWalletRows returns the active allow_read_wallet row ids keyed by
+// lowercase wallet address.
+func (b *BulkPermissions) readWalletRows(ctx context.Context, stream sdktypes.StreamLocator) (map[string][]*kwiltypes.UUID, error) {
+	entries, err := b.reader.GetMetadata(ctx, stream, sdktypes.AllowReadWalletKey)
+	if err != nil {
+		return nil, pkgerrors.Wrapf(err, "read allow-list of %s", stream.StreamId.String())
+	}
+	rows := make(map[string][]*kwiltypes.UUID)
+	for _, entry := range entries {
+		if entry.ValueRef == nil {
+			continue
+		}
+		rowId, err := kwiltypes.ParseUUID(entry.RowId)
+		if err != nil {
+			return nil, pkgerrors.Wrapf(err, "row id %q", entry.RowId)
+		}
+		addr := strings.ToLower(*entry.ValueRef)
+		rows[addr] = append(rows[addr], rowId)
+	}
+	return rows, nil
+}
+
+func insertMetadataOp(index int, stream sdktypes.StreamLocator, key sdktypes.MetadataKey, value string) permissionOp {
+	return permissionOp{
+		index:  index,
+		action: "insert_metadata",
+		tuples: [][]any{{stream.DataProvider.Address(), stream.StreamId.String(), key.String(), value, string(key.GetType())}},
+	}
+}
+
+func disableMetadataOp(index int, stream sdktypes.StreamLocator, rowIds []*kwiltypes.UUID) permissionOp {
+	tuples := make([][]any, 0, len(rowIds))
+	for _, rowId := range rowIds {
+		tuples = append(tuples, []any{stream.DataProvider.Address(), stream.StreamId.String(), rowId})
+	}
+	return permissionOp{index: index, action: "disable_metadata", tuples: tuples}
+}
+
+// packPermissionOps groups ops by action, keeping input order within each
+// action, and fills each chunk up to batchSize tuples. An op larger than
+// batchSize gets a chunk of its own.
+func packPermissionOps(ops []permissionOp, batchSize int) []permissionChunk {
+	var actions []string
+	byAction := make(map[string][]permissionOp)
+	for _, op := range ops {
+		if _, ok := byAction[op.action]; !ok {
+			actions = append(actions, op.action)
+		}
+		byAction[op.action] = append(byAction[op.action], op)
+	}
+
+	var chunks []permissionChunk
+	for _, action := range actions {
+		current := permissionChunk{action: action}
+		size := 0
+		for _, op := range byAction[action] {
+			if size > 0 && size+len(op.tuples) > batchSize {
+				chunks = append(chunks, current)
+				current = permissionChunk{action: action}
+				size = 0
+			}
+			current.ops = append(current.ops, op)
+			size += len(op.tuples)
+		}
+		if size > 0 {
+			chunks = append(chunks, current)
+		}
+	}
+	return chunks
+}
+
+// run broadcasts every chunk with pipelined nonces, then waits for each tx and
+// records the result on the outcomes of its ops.
+func (b *BulkPermissions) run(ctx context.Context, ops []permissionOp, outcomes []PermissionOutcome) ([]PermissionOutcome, error) {
+	type broadcasted struct {
+		hash  kwiltypes.Hash
+		chunk permissionChunk
+	}
+
+	var pending []broadcasted
+	for _, chunk := range packPermissionOps(ops, b.batchSize) {
+		var tuples [][]any
+		for _, op := range chunk.ops {
+			tuples = append(tuples, op.tuples...)
+		}
+		hash, err := b.broadcastWithRetry(ctx, chunk.action, tuples)
+		if err != nil {
+			setChunkOutcome(outcomes, chunk, nil, pkgerrors.Wrapf(err, "broadcast %s", chunk.action))
+			continue
+		}
+		pending = append(pending, broadcasted{hash: hash, chunk: chunk})
+	}
+
+	for _, p := range pending {
+		hash := p.hash
+		res, err := b.client.WaitTx(ctx, hash, b.waitInterval)
+		if err != nil {
+			err = pkgerrors.Wrapf(err, "wait for tx %s", hash)
+		} else {
+			err = tnerrors.FromTxResult(p.chunk.action, hash, res)
+		}
+		setChunkOutcome(outcomes, p.chunk, &hash, err)
+	}
+
+	var failed int
+	var first error
+	for _, o := range outcomes {
+		if o.Err != nil {
+			failed++
+			if first == nil {
+				first = o.Err
+			}
+		}
+	}
+	if failed > 0 {
+		return outcomes, &BulkPermissionError{Failed: failed, Total: len(outcomes), First: first}
+	}
+	return outcomes, nil
+}
+
+func setChunkOutcome(outcomes []PermissionOutcome, chunk permissionChunk, hash *kwiltypes.Hash, err error) {
+	for _, op := range chunk.ops {
+		outcomes[op.index].TxHash = hash
+		outcomes[op.index].Err = err
+	}
+}
+
+func (b *BulkPermissions) broadcastWithRetry(ctx context.Context, action string, tuples [][]any) (kwiltypes.Hash, error) {
+	return b.pipeline.Broadcast(ctx, func(nonce int64) (kwiltypes.Hash, error) {
+		return b.client.Execute(ctx, "", action, tuples,
+			kwilclient.WithNonce(nonce),
+			kwilclient.WithSyncBroadcast(false),
+		)
+	})
+}
@@ -0,0 +1,223 @@
+package contractsapi_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kwilclient "github.com/trufnetwork/kwil-db/core/client/types"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/contractsapi"
+	sdktypes "github.com/trufnetwork/sdk-go/core/types"
+	"github.com/trufnetwork/sdk-go/core/util"
+)
+
+type executeCall struct {
+	action string
+	tuples [][]any
+	nonce  int64
+}
+
+type fakePermissionClient struct {
+	ledgerNonce     int64
+	getAccountCalls int
+	calls           []executeCall
+	failNext        int
+	failErr         error
+	rejected        map[kwiltypes.Hash]string
+}
+
+func (f *fakePermissionClient) GetAccount(context.Context, *kwiltypes.AccountID, kwiltypes.AccountStatus) (*kwiltypes.Account, error) {
+	f.getAccountCalls++
+	return &kwiltypes.Account{Nonce: f.ledgerNonce}, nil
+}
+
+func (f *fakePermissionClient) WaitTx(_ context.Context, hash kwiltypes.Hash, _ time.Duration) (*kwiltypes.TxQueryResponse, error) {
+	res := &kwiltypes.TxResult{Code: uint32(kwiltypes.CodeOk)}
+	if log, ok := f.rejected[hash]; ok {
+		res = &kwiltypes.TxResult{Code: uint32(kwiltypes.CodeUnknownError), Log: log}
+	}
+	return &kwiltypes.TxQueryResponse{Hash: hash, Result: res}, nil
+}
+
+func (f *fakePermissionClient) Execute(_ context.Context, _ string, action string, tuples [][]any, opts ...kwilclient.TxOpt) (kwiltypes.Hash, error) {
+	f.calls = append(f.calls, executeCall{action: action, tuples: tuples, nonce: kwilclient.GetTxOpts(opts).Nonce})
+	if f.failNext > 0 {
+		f.failNext--
+		return kwiltypes.Hash{}, f.failErr
+	}
+	var h kwiltypes.Hash
+	h[0] = byte(len(f.calls))
+	return h, nil
+}
+
+type fakeMetadataReader struct {
+	entries map[string][]sdktypes.MetadataEntry
+	calls   int
+}
+
+func (f *fakeMetadataReader) GetMetadata(_ context.Context, locator sdktypes.StreamLocator, key sdktypes.MetadataKey) ([]sdktypes.MetadataEntry, error) {
+	f.calls++
+	return f.entries[locator.StreamId.String()+"/"+key.String()], nil
+}
+
+func walletRow(t *testing.T, wallet util.EthereumAddress) sdktypes.MetadataEntry {
+	t.Helper()
+	id := kwiltypes.NewUUIDV5([]byte(wallet.Address() + time.Now().String()))
+	ref := wallet.Address()
+	return sdktypes.MetadataEntry{RowId: id.String(), Key: sdktypes.AllowReadWalletKey, Type: sdktypes.MetadataTypeRef, ValueRef: &ref}
+}
+
+func permissionFixtures(t *testing.T) (sdktypes.StreamLocator, []util.EthereumAddress) {
+	t.Helper()
+	dp, err := util.NewEthereumAddressFromString("0x00000000000000000000000000000000000000d1")
+	require.NoError(t, err)
+	stream := sdktypes.StreamLocator{StreamId: util.GenerateStreamId("bulk-perms"), DataProvider: dp}
+
+	var wallets []util.EthereumAddress
+	for _, s := range []string{
+		"0x0000000000000000000000000000000000000a01",
+		"0x0000000000000000000000000000000000000a02",
+		"0x0000000000000000000000000000000000000a03",
+		"0x0000000000000000000000000000000000000a04",
+		"0x0000000000000000000000000000000000000a05",
+	} {
+		w, err := util.NewEthereumAddressFromString(s)
+		require.NoError(t, err)
+		wallets = append(wallets, w)
+	}
+	return stream, wallets
+}
+
+func newTestBulkPermissions(t *testing.T, reader *fakeMetadataReader, client *fakePermissionClient, opts ...contractsapi.BulkPermissionOption) *contractsapi.BulkPermissions {
+	t.Helper()
+	opts = append([]contractsapi.BulkPermissionOption{
+		contractsapi.WithPermissionRetryBackoff(time.Millisecond),
+		contractsapi.WithPermissionWaitInterval(time.Millisecond),
+	}, opts...)
+	bp, err := contractsapi.NewBulkPermissions(reader, client, newTestSigner(t), opts...)
+	require.NoError(t, err)
+	return bp
+}
+
+func TestBulkPermissions_AllowReadWalletsPacksAndPipelines(t *testing.T) {
+	stream, wallets := permissionFixtures(t)
+	client := &fakePermissionClient{ledgerNonce: 4}
+	bp := newTestBulkPermissions(t, &fakeMetadataReader{}, client, contractsapi.WithPermissionBatchSize(2))
+
+	inputs := make([]sdktypes.ReadWalletInput, len(wallets))
+	for i, w := range wallets {
+		inputs[i] = sdktypes.ReadWalletInput{Stream: stream, Wallet: w}
+	}
+	outcomes, err := bp.AllowReadWallets(context.Background(), inputs)
+	require.NoError(t, err)
+
+	require.Len(t, client.calls, 3)
+	assert.Equal(t, 1, client.getAccountCalls)
+	for i, call := range client.calls {
+		assert.Equal(t, "insert_metadata", call.action)
+		assert.Equal(t, int64(5+i), call.nonce)
+	}
+	assert.Len(t, client.calls[0].tuples, 2)
+	assert.Len(t, client.calls[2].tuples, 1)
+	assert.Equal(t, []any{stream.DataProvider.Address(), stream.StreamId.String(), "allow_read_wallet", wallets[0].Address(), "ref"}, client.calls[0].tuples[0])
+
+	require.Len(t, outcomes, 5)
+	for i, o := range outcomes {
+		assert.True(t, o.Succeeded())
+		assert.Equal(t, wallets[i].Address(), o.Target)
+		require.NotNil(t, o.TxHash)
+	}
+	assert.Equal(t, *outcomes[0].TxHash, *outcomes[1].TxHash)
+	assert.NotEqual(t, *outcomes[1].TxHash, *outcomes[2].TxHash)
+}
+
+func TestBulkPermissions_FailedTxOnlyFailsItsItems(t *testing.T) {
+	stream, _ := permissionFixtures(t)
+	var second kwiltypes.Hash
+	second[0] = 2
+	client := &fakePermissionClient{rejected: map[kwiltypes.Hash]string{second: "not the stream owner"}}
+	bp := newTestBulkPermissions(t, &fakeMetadataReader{}, client, contractsapi.WithPermissionBatchSize(2))
+
+	inputs := make([]sdktypes.VisibilityInput, 4)
+	for i := range inputs {
+		inputs[i] = sdktypes.VisibilityInput{Stream: stream, Visibility: util.PrivateVisibility}
+	}
+	outcomes, err := bp.SetReadVisibilities(context.Background(), inputs)
+
+	var bulkErr *contractsapi.BulkPermissionError
+	require.True(t, errors.As(err, &bulkErr))
+	assert.Equal(t, 2, bulkErr.Failed)
+	assert.True(t, outcomes[0].Succeeded())
+	assert.True(t, outcomes[1].Succeeded())
+	assert.ErrorContains(t, outcomes[2].Err, "not the stream owner")
+	assert.ErrorContains(t, outcomes[3].Err, "not the stream owner")
+	assert.Equal(t, "1", client.calls[0].tuples[0][3])
+}
+
+func TestBulkPermissions_InvalidNonceRefetches(t *testing.T) {
+	stream, wallets := permissionFixtures(t)
+	client := &fakePermissionClient{ledgerNonce: 10, failNext: 1, failErr: kwiltypes.ErrInvalidNonce}
+	bp := newTestBulkPermissions(t, &fakeMetadataReader{}, client)
+
+	outcomes, err := bp.AllowReadWallets(context.Background(), []sdktypes.ReadWalletInput{{Stream: stream, Wallet: wallets[0]}})
+	require.NoError(t, err)
+	assert.True(t, outcomes[0].Succeeded())
+	require.Len(t, client.calls, 2)
+	assert.Equal(t, 2, client.getAccountCalls)
+	assert.Equal(t, int64(11), client.calls[1].nonce)
+}
+
+func TestBulkPermissions_DisableReadWallets(t *testing.T) {
+	stream, wallets := permissionFixtures(t)
+	reader := &fakeMetadataReader{entries: map[string][]sdktypes.MetadataEntry{
+		stream.StreamId.String() + "/allow_read_wallet": {walletRow(t, wallets[0]), walletRow(t, wallets[0]), walletRow(t, wallets[1])},
+	}}
+	client := &fakePermissionClient{}
+	bp := newTestBulkPermissions(t, reader, client)
+
+	outcomes, err := bp.DisableReadWallets(context.Background(), []sdktypes.ReadWalletInput{
+		{Stream: stream, Wallet: wallets[0]},
+		{Stream: stream, Wallet: wallets[2]},
+	})
+	require.Error(t, err)
+	assert.Equal(t, 1, reader.calls, "allow-list read once per stream")
+	assert.True(t, outcomes[0].Succeeded())
+	assert.ErrorIs(t, outcomes[1].Err, contractsapi.MetadataValueNotFound)
+	assert.Nil(t, outcomes[1].TxHash)
+
+	require.Len(t, client.calls, 1)
+	assert.Equal(t, "disable_metadata", client.calls[0].action)
+	assert.Len(t, client.calls[0].tuples, 2, "both rows of the duplicated grant are disabled")
+}
+
+func TestBulkPermissions_SyncReadWallets(t *testing.T) {
+	stream, wallets := permissionFixtures(t)
+	reader := &fakeMetadataReader{entries: map[string][]sdktypes.MetadataEntry{
+		stream.StreamId.String() + "/allow_read_wallet": {walletRow(t, wallets[0]), walletRow(t, wallets[1])},
+	}}
+	client := &fakePermissionClient{}
+	bp := newTestBulkPermissions(t, reader, client)
+
+	result, err := bp.SyncReadWallets(context.Background(), stream, []util.EthereumAddress{wallets[1], wallets[2], wallets[2]})
+	require.NoError(t, err)
+
+	assert.Equal(t, []util.EthereumAddress{wallets[2]}, result.Plan.Grant)
+	assert.Equal(t, []util.EthereumAddress{wallets[1]}, result.Plan.Unchanged)
+	require.Len(t, result.Plan.Revoke, 1)
+	assert.Equal(t, wallets[0].Address(), result.Plan.Revoke[0].Wallet)
+
+	require.Len(t, client.calls, 2)
+	assert.Equal(t, "insert_metadata", client.calls[0].action)
+	assert.Equal(t, "disable_metadata", client.calls[1].action)
+	require.Len(t, result.Outcomes, 2)
+	assert.Equal(t, wallets[2].Address(), result.Outcomes[0].Target)
+	assert.Equal(t, wallets[0].Address(), result.Outcomes[1].Target)
+
+	plan, err := bp.PlanReadWalletSync(context.Background(), stream, []util.EthereumAddress{wallets[0], wallets[1]})
+	require.NoError(t, err)
+	assert.True(t, plan.IsEmpty())
+}
@@ -1,147 +1,236 @@
 package contractsapi
 
 import (
+	"fmt"
+	"math/big"
 	"reflect"
+	"strconv"
 	"strings"
 
+	"github.com/cockroachdb/apd/v3"
 	"github.com/pkg/errors"
 	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/util"
 )
 
+var (
+	decimalType    = reflect.TypeOf(kwiltypes.Decimal{})
+	apdDecimalType = reflect.TypeOf(apd.Decimal{})
+	bigIntType     = reflect.TypeOf(big.Int{})
+	addressType    = reflect.TypeOf(util.EthereumAddress{})
+	bytesType      = reflect.TypeOf([]byte(nil))
+)
+
+// isScalarStruct reports whether t is a struct type decoded from a single
+// column rather than mapped field by field.
+func isScalarStruct(t reflect.Type) bool {
+	switch t {
+	case decimalType, apdDecimalType, bigIntType, addressType:
+		return true
+	}
+	return false
+}
+
 // fieldMappingInfo holds information about how a QueryResult column maps to a struct field.
 // It's unexported as it's an internal detail of DecodeCallResult.
 type fieldMappingInfo struct {
-	StructFieldIndex int
-	// Potentially add OriginalColumnName string if needed for detailed error reporting
+	// Index is the field index path, longer than one for fields of embedded structs.
+	Index []int
+	Name  string
+	// Tagged is set when Name comes from a tn or json tag and must match exactly.
+	Tagged bool
+	// Base64 decodes BYTEA values sent as base64 or 0x-hex text (tn:"col,base64").
+	Base64 bool
 }
 
-// createScannedItemInternal handles the creation of the final typed item (T)
-// from a reflect.Value container that was populated by ScanTo.
-// originalType is the reflect.Type of T itself (e.g., *MyStruct or MyStruct).
-// scannedValueContainer is typically the result of reflect.New(elementType), which is a pointer.
-func createScannedItemInternal[T any](originalType reflect.Type, scannedValueContainer reflect.Value) T {
-	var finalValue T
-	if originalType.Kind() == reflect.Ptr {
-		// If T is a pointer type (*MyStruct), scannedValueContainer is *MyStruct (or **MyStruct if element type was already a pointer, though reflect.New(elementType) usually gives one level of pointer)
-		// We need to ensure scannedValueContainer actually is of type T.
-		if scannedValueContainer.Type() == originalType {
-			finalValue = scannedValueContainer.Interface().(T)
-		} else if scannedValueContainer.Elem().Type() == originalType.Elem() && scannedValueContainer.Type().Kind() == reflect.Ptr {
-			// This case might occur if T is *S and scannedValueContainer is **S, and we want *S
-			// However, typical usage with reflect.New(elementType) where elementType is S means scannedValueContainer is *S.
-			// Let's assume scannedValueContainer is directly assignable or its element is.
-			finalValue = scannedValueContainer.Interface().(T) // This will panic if type T is *S and scannedValueContainer is S. Ok, because reflect.New gives *S.
-		} else {
-			// Fallback, should generally be hit if originalType is *S and scannedValueContainer is *S
-			finalValue = scannedValueContainer.Interface().(T)
+// columnFields lists the decodable fields of structType with their column
+// names, flattening embedded structs. Outer fields shadow embedded ones.
+func columnFields(structType reflect.Type, index []int) []fieldMappingInfo {
+	var direct, embedded []fieldMappingInfo
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		path := append(append([]int{}, index...), i)
+
+		tnName, tnOpts, hasTn := strings.Cut(field.Tag.Get("tn"), ",")
+		jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if tnName == "-" || (tnName == "" && jsonName == "-") {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && !isScalarStruct(field.Type) && tnName == "" && jsonName == "" {
+			embedded = append(embedded, columnFields(field.Type, path)...)
+			continue
 		}
-	} else {
-		// If T is a non-pointer type (MyStruct), scannedValueContainer is *MyStruct.
-		// We need to dereference it to get MyStruct.
-		finalValue = scannedValueContainer.Elem().Interface().(T)
+		if !field.IsExported() {
+			continue
+		}
+
+		info := fieldMappingInfo{Index: path, Name: field.Name}
+		switch {
+		case tnName != "":
+			info.Name, info.Tagged = tnName, true
+		case jsonName != "":
+			info.Name, info.Tagged = jsonName, true
+		}
+		if hasTn {
+			for _, opt := range strings.Split(tnOpts, ",") {
+				if opt == "base64" {
+					info.Base64 = true
+				}
+			}
+		}
+		direct = append(direct, info)
 	}
-	return finalValue
+	return append(direct, embedded...)
 }
 
-// mapColumnsToStructFieldsInternal maps QueryResult column names to struct field indices.
+// mapColumnsToStructFieldsInternal maps QueryResult column names to struct fields.
 // structElemType is the reflect.Type of the struct itself (not a pointer to it).
+// Names given by a tn or json tag must match exactly; untagged fields match
+// case-insensitively. Columns without a field are skipped.
 func mapColumnsToStructFieldsInternal(structElemType reflect.Type, columnNames []string) ([]*fieldMappingInfo, error) {
-	numCols := len(columnNames)
-	colDestinations := make([]*fieldMappingInfo, numCols)
+	fields := columnFields(structElemType, nil)
+	colDestinations := make([]*fieldMappingInfo, len(columnNames))
 
 	for colIdx, colName := range columnNames {
-		foundFieldForColumn := false
-		for fieldIdx := 0; fieldIdx < structElemType.NumField(); fieldIdx++ {
-			field := structElemType.Field(fieldIdx)
-
-			if field.PkgPath != "" { // Field is unexported
-				continue
-			}
-
-			jsonTag := field.Tag.Get("json")
-			if jsonTag == "-" { // Field is explicitly ignored
-				continue
+		for i := range fields {
+			field := &fields[i]
+			if colName == field.Name || (!field.Tagged && strings.EqualFold(colName, field.Name)) {
+				colDestinations[colIdx] = field
+				break
 			}
+		}
+	}
+	return colDestinations, nil
+}
 
-			tagKey := ""
-			if jsonTag != "" {
-				parts := strings.Split(jsonTag, ",")
-				if len(parts) > 0 && parts[0] != "" {
-					tagKey = parts[0]
-				}
-			}
+// decodeValue stores the column value src into dst. NULL leaves dst at its
+// zero value, so pointer fields stay nil.
+func decodeValue(src any, dst reflect.Value, base64 bool) error {
+	if src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	if dst.Kind() == reflect.Ptr {
+		elem := reflect.New(dst.Type().Elem())
+		if err := decodeValue(src, elem.Elem(), base64); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	}
 
-			nameMatch := false
-			if tagKey != "" {
-				if colName == tagKey {
-					nameMatch = true
-				}
-			} else {
-				// Fallback to field name match (case-insensitive for first char, then sensitive, or just equal fold)
-				if colName == field.Name || strings.EqualFold(colName, field.Name) {
-					nameMatch = true
-				}
+	switch dst.Type() {
+	case bytesType:
+		if raw, ok := src.([]byte); ok {
+			dst.SetBytes(raw)
+			return nil
+		}
+		if base64 {
+			var b []byte
+			if err := extractBytesColumn(src, &b, 0, "value"); err != nil {
+				return err
 			}
+			dst.SetBytes(b)
+			return nil
+		}
+	case apdDecimalType:
+		d, _, err := apd.NewFromString(scalarText(src))
+		if err != nil {
+			return errors.Wrapf(err, "parse decimal %q", scalarText(src))
+		}
+		dst.Set(reflect.ValueOf(*d))
+		return nil
+	case bigIntType:
+		text := scalarText(src)
+		// NUMERIC(78,0) may still carry a zero fraction
+		if whole, frac, ok := strings.Cut(text, "."); ok && strings.Trim(frac, "0") == "" {
+			text = whole
+		}
+		n, ok := new(big.Int).SetString(text, 10)
+		if !ok {
+			return errors.Errorf("parse integer %q", text)
+		}
+		dst.Set(reflect.ValueOf(n).Elem())
+		return nil
+	case addressType:
+		addr, err := decodeAddress(src)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(addr))
+		return nil
+	}
 
-			if nameMatch {
-				colDestinations[colIdx] = &fieldMappingInfo{StructFieldIndex: fieldIdx}
-				foundFieldForColumn = true
-				break // Found mapping for this column, move to next column
-			}
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(scalarText(src), 10, dst.Type().Bits())
+		if err != nil {
+			return errors.Wrapf(err, "parse %s", dst.Type())
+		}
+		dst.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(scalarText(src), 10, dst.Type().Bits())
+		if err != nil {
+			return errors.Wrapf(err, "parse %s", dst.Type())
 		}
-		_ = foundFieldForColumn // Can be used for logging if a column doesn't map to any field
+		dst.SetUint(n)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(scalarText(src), dst.Type().Bits())
+		if err != nil {
+			return errors.Wrapf(err, "parse %s", dst.Type())
+		}
+		dst.SetFloat(f)
+		return nil
 	}
-	return colDestinations, nil
-}
 
-// prepareScanTargetsForStructInternal prepares the []any slice of destination pointers for ScanTo.
-// structInstanceVal is the reflect.Value of the struct instance (not a pointer to it).
-// structElemType is the reflect.Type of the struct, for error messages.
-// mappings is the output from mapColumnsToStructFieldsInternal.
-func prepareScanTargetsForStructInternal(structInstanceVal reflect.Value, structElemType reflect.Type, mappings []*fieldMappingInfo, rowSrc []any) ([]any, error) {
-	numCols := len(mappings)
-	dstArgs := make([]any, numCols)
-
-	for colIdx := 0; colIdx < numCols; colIdx++ {
-		if targetInfo := mappings[colIdx]; targetInfo != nil {
-			fieldInStruct := structInstanceVal.Field(targetInfo.StructFieldIndex)
-			if !fieldInStruct.CanAddr() {
-				return nil, errors.Errorf("cannot address field %s (index %d) in struct %s", structElemType.Field(targetInfo.StructFieldIndex).Name, targetInfo.StructFieldIndex, structElemType.Name())
-			}
+	return kwiltypes.ScanTo([]any{src}, dst.Addr().Interface())
+}
 
-			// Handle pointer fields
-			if fieldInStruct.Kind() == reflect.Ptr {
-				// If source value is nil, set field to nil and skip scanning
-				if rowSrc[colIdx] == nil {
-					fieldInStruct.Set(reflect.Zero(fieldInStruct.Type()))
-					dstArgs[colIdx] = new(any) // Dummy target for ScanTo
-					continue
-				}
+// scalarText renders a JSON-decoded column value as text. Numbers arrive as
+// float64 or as strings, depending on the column type.
+func scalarText(src any) string {
+	switch v := src.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []byte:
+		return string(v)
+	}
+	return fmt.Sprint(src)
+}
 
-				// If field is nil, allocate memory for it
-				if fieldInStruct.IsNil() {
-					fieldInStruct.Set(reflect.New(fieldInStruct.Type().Elem()))
-				}
-				// Pass the pointer to the underlying value (e.g., *int64) to ScanTo
-				// fieldInStruct is *int64, fieldInStruct.Interface() returns that *int64
-				dstArgs[colIdx] = fieldInStruct.Interface()
-			} else {
-				// Non-pointer field, pass address of the field
-				dstArgs[colIdx] = fieldInStruct.Addr().Interface()
-			}
-		} else {
-			// For columns in QueryResult that don't map to any field in T, scan into a dummy var.
-			dstArgs[colIdx] = new(any)
-		}
+// decodeAddress accepts 0x-hex text and BYTEA sent as base64.
+func decodeAddress(src any) (util.EthereumAddress, error) {
+	if s, ok := src.(string); ok && (len(s) == 42 && strings.HasPrefix(s, "0x") || len(s) == 40) {
+		return util.NewEthereumAddressFromString(s)
+	}
+	var b []byte
+	if err := extractBytesColumn(src, &b, 0, "address"); err != nil {
+		return util.EthereumAddress{}, err
 	}
-	return dstArgs, nil
+	return util.NewEthereumAddressFromBytes(b)
 }
 
 // DecodeCallResult decodes the result of a view call to a slice of T.
-// T is typically a struct type, where fields are mapped from QueryResult columns
-// using json tags or field names.
-// If T is a scalar type (e.g. int, string) and QueryResult has a single column,
-// it decodes each row's single value into T.
+//
+// T is typically a struct whose exported fields receive the columns. A
+// column maps to the field tagged tn:"<column>", else json:"<column>", else
+// to the field whose name matches case-insensitively ("spread" fills Spread,
+// but a "max_spread" column needs a tn or json tag to fill MaxSpread). Fields
+// of embedded structs are mapped as if declared in T. Pointer fields are nil
+// for NULL.
+//
+// Besides the types kwil-db scans (string, bool, ints, []byte, Decimal,
+// UUID and slices of them), fields may be apd.Decimal, big.Int for
+// NUMERIC(78,0) amounts, or util.EthereumAddress. BYTEA columns the gateway
+// sends as base64 or 0x-hex text decode into []byte with tn:"<column>,base64".
+//
+// If T is a scalar type (e.g. int, string, apd.Decimal) and QueryResult has a
+// single column, it decodes each row's single value into T.
 func DecodeCallResult[T any](result *kwiltypes.QueryResult) ([]T, error) {
 	if result == nil {
 		return nil, errors.New("QueryResult is nil")
@@ -164,28 +253,22 @@ func DecodeCallResult[T any](result *kwiltypes.QueryResult) ([]T, error) {
 	}
 
 	// Handle scalar type T if QueryResult has a single column
-	if elementType.Kind() != reflect.Struct && elementType.Kind() != reflect.Map { // Maps are not directly supported by ScanTo for field mapping
-		if len(result.ColumnNames) == 1 {
-			var scalarResults = make([]T, 0, len(result.Values))
-			for _, rowSrc := range result.Values {
-				if len(rowSrc) != 1 {
-					return nil, errors.Errorf("expected single value in row for scalar decoding, got %d values for type %s", len(rowSrc), elementType.Name())
-				}
-
-				// Create a new instance of the underlying element type of T to scan into (e.g., *string, *int)
-				itemContainer := reflect.New(elementType)
-
-				err := kwiltypes.ScanTo([]any{rowSrc[0]}, itemContainer.Interface())
-				if err != nil {
-					return nil, errors.Wrapf(err, "failed to scan scalar value into %s", elementType.Name())
-				}
-
-				finalValue := createScannedItemInternal[T](originalTypeOfT, itemContainer)
-				scalarResults = append(scalarResults, finalValue)
+	if (elementType.Kind() != reflect.Struct && elementType.Kind() != reflect.Map) || isScalarStruct(elementType) {
+		if len(result.ColumnNames) != 1 {
+			return nil, errors.Errorf("DecodeCallResult: T must be a struct, or a scalar type with a single-column QueryResult. Got type %s (element type %s) with %d columns", originalTypeOfT.Name(), elementType.Name(), len(result.ColumnNames))
+		}
+		var scalarResults = make([]T, 0, len(result.Values))
+		for rowIdx, rowSrc := range result.Values {
+			if len(rowSrc) != 1 {
+				return nil, errors.Errorf("expected single value in row for scalar decoding, got %d values for type %s", len(rowSrc), elementType.Name())
+			}
+			var item T
+			if err := decodeValue(rowSrc[0], reflect.ValueOf(&item).Elem(), false); err != nil {
+				return nil, errors.Wrapf(err, "row %d: failed to scan scalar value into %s", rowIdx, elementType.Name())
 			}
-			return scalarResults, nil
+			scalarResults = append(scalarResults, item)
 		}
-		return nil, errors.Errorf("DecodeCallResult: T must be a struct, or a scalar type with a single-column QueryResult. Got type %s (element type %s) with %d columns", originalTypeOfT.Name(), elementType.Name(), len(result.ColumnNames))
+		return scalarResults, nil
 	}
 
 	// T is a struct type (or pointer to struct)
@@ -201,29 +284,47 @@ func DecodeCallResult[T any](result *kwiltypes.QueryResult) ([]T, error) {
 	}
 
 	var newResults = make([]T, 0, len(result.Values))
-	for _, rowSrc := range result.Values {
+	for rowIdx, rowSrc := range result.Values {
 		if len(rowSrc) != numCols {
 			return nil, errors.Errorf("row length %d does not match column count %d for type %s", len(rowSrc), numCols, elementType.Name())
 		}
 
 		// Create a new instance of the struct (e.g., *MyStruct)
 		itemContainer := reflect.New(elementType)
-		// Get the actual struct value (MyStruct) to access its fields
 		itemStructVal := itemContainer.Elem()
 
-		dstArgs, err := prepareScanTargetsForStructInternal(itemStructVal, elementType, mappings, rowSrc)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to prepare scan targets for struct %s", elementType.Name())
+		for colIdx, mapping := range mappings {
+			if mapping == nil {
+				continue
+			}
+			if err := decodeValue(rowSrc[colIdx], itemStructVal.FieldByIndex(mapping.Index), mapping.Base64); err != nil {
+				return nil, errors.Wrapf(err, "row %d: failed to scan column %s into %s.%s", rowIdx, result.ColumnNames[colIdx], elementType.Name(), mapping.Name)
+			}
 		}
 
-		err = kwiltypes.ScanTo(rowSrc, dstArgs...)
-		if err != nil {
-			return nil, errors.Wrapf(err, "failed to scan row into struct %s", elementType.Name())
+		if originalTypeOfT.Kind() == reflect.Ptr {
+			newResults = append(newResults, itemContainer.Interface().(T))
+		} else {
+			newResults = append(newResults, itemStructVal.Interface().(T))
 		}
-
-		finalValue := createScannedItemInternal[T](originalTypeOfT, itemContainer)
-		newResults = append(newResults, finalValue)
 	}
 
 	return newResults, nil
 }
+
+// decodePositional decodes rows whose column order is fixed by the action
+// signature, naming them with columns. Rows may carry extra trailing columns.
+// It returns nil when there are no rows.
+func decodePositional[T any](values [][]any, columns []string) ([]T, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	rows := make([][]any, len(values))
+	for i, row := range values {
+		if len(row) < len(columns) {
+			return nil, errors.Errorf("invalid row: expected %d columns, got %d", len(columns), len(row))
+		}
+		rows[i] = row[:len(columns)]
+	}
+	return DecodeCallResult[T](&kwiltypes.QueryResult{ColumnNames: columns, Values: rows})
+}
@@ -0,0 +1,145 @@
+package contractsapi
+
+import (
+	"encoding/base64"
+	"math/big"
+	"testing"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/types"
+	"github.com/trufnetwork/sdk-go/core/util"
+)
+
+type decodeAudit struct {
+	CreatedAt int64 `json:"created_at"`
+}
+
+type decodeRow struct {
+	decodeAudit
+	Hash       []byte                `tn:"hash,base64"`
+	SettledAt  *int64                `json:"settled_at"`
+	Price      apd.Decimal           `json:"price"`
+	Collateral *big.Int              `tn:"collateral"`
+	Settled    bool                  `json:"settled"`
+	Creator    util.EthereumAddress  `tn:"creator"`
+	Owner      *util.EthereumAddress `tn:"owner"`
+	Spread     int
+	Ignored    string `tn:"-"`
+}
+
+func TestDecodeCallResult_ExtendedTypes(t *testing.T) {
+	hash := []byte{0xd3, 0x15, 0xe5, 0x1a}
+	creator := make([]byte, 20)
+	creator[19] = 0x42
+
+	rows, err := DecodeCallResult[decodeRow](&kwiltypes.QueryResult{
+		ColumnNames: []string{"hash", "settled_at", "price", "collateral", "settled", "creator", "owner", "spread", "created_at", "ignored"},
+		Values: [][]any{
+			{base64.StdEncoding.EncodeToString(hash), "1700000000", "0.55", "115792089237316195423570985008687907853269984665640564039457584007913129639935", true,
+				base64.StdEncoding.EncodeToString(creator), "0x00000000000000000000000000000000000000aa", float64(5), "12", "x"},
+			{"0x0102", nil, float64(1), "10.000", "false", "0x0000000000000000000000000000000000000042", nil, "7", float64(13), nil},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+
+	first := rows[0]
+	assert.Equal(t, hash, first.Hash)
+	require.NotNil(t, first.SettledAt)
+	assert.Equal(t, int64(1700000000), *first.SettledAt)
+	assert.Equal(t, "0.55", first.Price.String())
+	assert.Equal(t, "115792089237316195423570985008687907853269984665640564039457584007913129639935", first.Collateral.String())
+	assert.True(t, first.Settled)
+	assert.Equal(t, "0x0000000000000000000000000000000000000042", first.Creator.Address())
+	require.NotNil(t, first.Owner)
+	assert.Equal(t, "0x00000000000000000000000000000000000000aa", first.Owner.Address())
+	assert.Equal(t, 5, first.Spread)
+	assert.Equal(t, int64(12), first.CreatedAt, "fields of embedded structs are mapped")
+	assert.Empty(t, first.Ignored)
+
+	second := rows[1]
+	assert.Equal(t, []byte{0x01, 0x02}, second.Hash)
+	assert.Nil(t, second.SettledAt)
+	assert.Equal(t, "1", second.Price.String())
+	assert.Equal(t, "10", second.Collateral.String())
+	assert.False(t, second.Settled)
+	assert.Nil(t, second.Owner)
+	assert.Equal(t, int64(13), second.CreatedAt)
+}
+
+func TestDecodeCallResult_ScalarStructs(t *testing.T) {
+	prices, err := DecodeCallResult[apd.Decimal](&kwiltypes.QueryResult{
+		ColumnNames: []string{"price"},
+		Values:      [][]any{{"1.25"}, {float64(2)}},
+	})
+	require.NoError(t, err)
+	require.Len(t, prices, 2)
+	assert.Equal(t, "1.25", prices[0].String())
+
+	amounts, err := DecodeCallResult[*big.Int](&kwiltypes.QueryResult{
+		ColumnNames: []string{"amount"},
+		Values:      [][]any{{"42"}, {nil}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "42", amounts[0].String())
+	assert.Nil(t, amounts[1])
+}
+
+func TestDecodeCallResult_Errors(t *testing.T) {
+	_, err := DecodeCallResult[decodeRow](&kwiltypes.QueryResult{
+		ColumnNames: []string{"collateral"},
+		Values:      [][]any{{"12abc"}},
+	})
+	assert.ErrorContains(t, err, "row 0: failed to scan column collateral into decodeRow.collateral")
+
+	_, err = decodePositional[decodeRow]([][]any{{"AQ=="}}, []string{"hash", "settled"})
+	assert.ErrorContains(t, err, "invalid row: expected 2 columns, got 1")
+
+	rows, err := decodePositional[decodeRow]([][]any{{"AQ==", true, "extra"}}, []string{"hash", "settled"})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1}, rows[0].Hash)
+	assert.True(t, rows[0].Settled)
+}
+
+func TestDecodePositional_ResultTypes(t *testing.T) {
+	creator := make([]byte, 20)
+	creator[0] = 0xab
+	markets, err := decodePositional[types.MarketInfo]([][]any{{
+		float64(7), "1700000000", true, false, "1700000100", float64(5), "100", "42",
+		base64.StdEncoding.EncodeToString(creator),
+	}}, marketByHashColumns)
+	require.NoError(t, err)
+	require.Len(t, markets, 1)
+	assert.Equal(t, 7, markets[0].ID)
+	require.NotNil(t, markets[0].WinningOutcome)
+	assert.False(t, *markets[0].WinningOutcome)
+	require.NotNil(t, markets[0].SettledAt)
+	assert.Equal(t, int64(1700000100), *markets[0].SettledAt)
+	assert.Equal(t, creator, markets[0].Creator)
+
+	// MAA fields are tagged with their snake_case columns
+	events, err := decodePositional[types.MAAEvent]([][]any{{
+		"3", "0x1111111111111111111111111111111111111111", "JOIN", "unrestricted", "0xabc", nil, nil, nil, "0xdead", float64(12), "1700000000",
+	}}, maaEventColumns)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), events[0].ID)
+	assert.Equal(t, "0x1111111111111111111111111111111111111111", events[0].MAAAddress)
+	assert.Empty(t, events[0].InnerNamespace)
+	assert.Equal(t, int64(12), events[0].BlockHeight)
+
+	fees, err := decodePositional[types.TransactionFeeEntry]([][]any{{
+		"0xaa", "10", "insertRecords", "0xbb", "1000", nil, nil, float64(1), "0xcc", "500",
+	}}, transactionFeeEntryColumns)
+	require.NoError(t, err)
+	assert.Equal(t, 1, fees[0].DistributionSequence)
+	assert.Nil(t, fees[0].FeeRecipient)
+	require.NotNil(t, fees[0].DistributionAmount)
+	assert.Equal(t, "500", *fees[0].DistributionAmount)
+
+	empty, err := decodePositional[types.DepthLevel](nil, depthLevelColumns)
+	require.NoError(t, err)
+	assert.Nil(t, empty)
+}
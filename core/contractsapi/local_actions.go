@@ -14,6 +14,7 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/pkg/errors"
 	adminclient "github.com/trufnetwork/kwil-db/core/rpc/client/admin/jsonrpc"
+	"github.com/trufnetwork/sdk-go/core/tnerrors"
 	"github.com/trufnetwork/sdk-go/core/types"
 )
 
@@ -265,6 +266,15 @@ type localListStreamsResponse struct {
 // METHOD IMPLEMENTATIONS
 // ═══════════════════════════════════════════════════════════════
 
+// callMethod sends an admin RPC and classifies its error. streamID is recorded
+// on the error when the method targets a single stream.
+func (l *LocalActions) callMethod(ctx context.Context, method, streamID string, req, res any) error {
+	if err := l.admin.CallMethod(ctx, method, req, res); err != nil {
+		return tnerrors.Wrap(err, tnerrors.OpAdmin, method, tnerrors.WithStream("", streamID))
+	}
+	return nil
+}
+
 // CreateStream → local.create_stream
 func (l *LocalActions) CreateStream(ctx context.Context, input types.LocalCreateStreamInput) error {
 	req := localCreateStreamRequest{
@@ -275,8 +285,8 @@ func (l *LocalActions) CreateStream(ctx context.Context, input types.LocalCreate
 		return errors.Wrap(err, methodCreateStream)
 	}
 	res := &localCreateStreamResponse{}
-	if err := l.admin.CallMethod(ctx, methodCreateStream, req, res); err != nil {
-		return errors.Wrap(err, methodCreateStream)
+	if err := l.callMethod(ctx, methodCreateStream, input.StreamID, req, res); err != nil {
+		return err
 	}
 	return nil
 }
@@ -296,8 +306,8 @@ func (l *LocalActions) InsertRecords(ctx context.Context, input types.LocalInser
 		return errors.Wrap(err, methodInsertRecords)
 	}
 	res := &localInsertRecordsResponse{}
-	if err := l.admin.CallMethod(ctx, methodInsertRecords, req, res); err != nil {
-		return errors.Wrap(err, methodInsertRecords)
+	if err := l.callMethod(ctx, methodInsertRecords, "", req, res); err != nil {
+		return err
 	}
 	return nil
 }
@@ -318,8 +328,8 @@ func (l *LocalActions) InsertTaxonomy(ctx context.Context, input types.LocalInse
 		return errors.Wrap(err, methodInsertTaxonomy)
 	}
 	res := &localInsertTaxonomyResponse{}
-	if err := l.admin.CallMethod(ctx, methodInsertTaxonomy, req, res); err != nil {
-		return errors.Wrap(err, methodInsertTaxonomy)
+	if err := l.callMethod(ctx, methodInsertTaxonomy, input.StreamID, req, res); err != nil {
+		return err
 	}
 	return nil
 }
@@ -335,8 +345,8 @@ func (l *LocalActions) GetRecord(ctx context.Context, input types.LocalGetRecord
 		return nil, errors.Wrap(err, methodGetRecord)
 	}
 	res := &localGetRecordResponse{}
-	if err := l.admin.CallMethod(ctx, methodGetRecord, req, res); err != nil {
-		return nil, errors.Wrap(err, methodGetRecord)
+	if err := l.callMethod(ctx, methodGetRecord, input.StreamID, req, res); err != nil {
+		return nil, err
 	}
 	records := make([]types.LocalRecordOutput, 0, len(res.Records))
 	for _, r := range res.Records {
@@ -361,8 +371,8 @@ func (l *LocalActions) GetIndex(ctx context.Context, input types.LocalGetIndexIn
 		return nil, errors.Wrap(err, methodGetIndex)
 	}
 	res := &localGetIndexResponse{}
-	if err := l.admin.CallMethod(ctx, methodGetIndex, req, res); err != nil {
-		return nil, errors.Wrap(err, methodGetIndex)
+	if err := l.callMethod(ctx, methodGetIndex, input.StreamID, req, res); err != nil {
+		return nil, err
 	}
 	records := make([]types.LocalIndexOutput, 0, len(res.Records))
 	for _, r := range res.Records {
@@ -383,8 +393,8 @@ func (l *LocalActions) DeleteStream(ctx context.Context, input types.LocalDelete
 		return errors.Wrap(err, methodDeleteStream)
 	}
 	res := &localDeleteStreamResponse{}
-	if err := l.admin.CallMethod(ctx, methodDeleteStream, req, res); err != nil {
-		return errors.Wrap(err, methodDeleteStream)
+	if err := l.callMethod(ctx, methodDeleteStream, input.StreamID, req, res); err != nil {
+		return err
 	}
 	return nil
 }
@@ -399,8 +409,8 @@ func (l *LocalActions) DisableTaxonomy(ctx context.Context, input types.LocalDis
 		return errors.Wrap(err, methodDisableTaxonomy)
 	}
 	res := &localDisableTaxonomyResponse{}
-	if err := l.admin.CallMethod(ctx, methodDisableTaxonomy, req, res); err != nil {
-		return errors.Wrap(err, methodDisableTaxonomy)
+	if err := l.callMethod(ctx, methodDisableTaxonomy, input.StreamID, req, res); err != nil {
+		return err
 	}
 	return nil
 }
@@ -412,8 +422,8 @@ func (l *LocalActions) ListStreams(ctx context.Context) ([]types.LocalStreamInfo
 		return nil, errors.Wrap(err, methodListStreams)
 	}
 	res := &localListStreamsResponse{}
-	if err := l.admin.CallMethod(ctx, methodListStreams, req, res); err != nil {
-		return nil, errors.Wrap(err, methodListStreams)
+	if err := l.callMethod(ctx, methodListStreams, "", req, res); err != nil {
+		return nil, err
 	}
 	streams := make([]types.LocalStreamInfo, 0, len(res.Streams))
 	for _, s := range res.Streams {
@@ -3,7 +3,6 @@ package contractsapi
 import (
 	"context"
 	"fmt"
-	"strconv"
 
 	"github.com/pkg/errors"
 	kwilclient "github.com/trufnetwork/kwil-db/core/client"
@@ -158,19 +157,11 @@ func (s *Action) GetAgentRule(ctx context.Context, ruleID []byte) (*types.MAARul
 	if len(res.Values) == 0 {
 		return nil, nil // no such rule
 	}
-	r := res.Values[0]
-	if len(r) < 7 {
-		return nil, fmt.Errorf("malformed maa_get_rule row: expected >=7 columns, got %d", len(r))
-	}
-	return &types.MAARule{
-		RuleID:         maaStr(r[0]),
-		RestrictedAddr: maaStr(r[1]),
-		RulesHash:      maaStr(r[2]),
-		FeeMode:        maaStr(r[3]),
-		FeeBps:         maaInt64(r[4]),
-		FeeFlat:        maaStr(r[5]),
-		CreatedAt:      maaInt64(r[6]),
-	}, nil
+	rules, err := decodeMAARows[types.MAARule](res, "maa_get_rule", maaRuleColumns)
+	if err != nil {
+		return nil, err
+	}
+	return &rules[0], nil
 }
 
 // GetAgentRuleAllowedActions returns a rule's allow-list (maa_get_allowed_actions), in canonical order.
@@ -179,18 +170,7 @@ func (s *Action) GetAgentRuleAllowedActions(ctx context.Context, ruleID []byte)
 	if err != nil {
 		return nil, err
 	}
-	out := make([]types.MAAAllowedAction, 0, len(res.Values))
-	for _, r := range res.Values {
-		if len(r) < 3 {
-			return nil, fmt.Errorf("malformed maa_get_allowed_actions row: expected >=3 columns, got %d", len(r))
-		}
-		out = append(out, types.MAAAllowedAction{
-			Namespace: maaStr(r[0]),
-			Action:    maaStr(r[1]),
-			BodyHash:  maaStr(r[2]),
-		})
-	}
-	return out, nil
+	return decodeMAARows[types.MAAAllowedAction](res, "maa_get_allowed_actions", maaAllowedActionColumns)
 }
 
 // GetAgentWallet returns an agent wallet and its two component keys (maa_get_instance), or nil if the
@@ -203,17 +183,11 @@ func (s *Action) GetAgentWallet(ctx context.Context, maaAddress []byte) (*types.
 	if len(res.Values) == 0 {
 		return nil, nil // not a known wallet
 	}
-	r := res.Values[0]
-	if len(r) < 5 {
-		return nil, fmt.Errorf("malformed maa_get_instance row: expected >=5 columns, got %d", len(r))
+	instances, err := decodeMAARows[types.MAAInstance](res, "maa_get_instance", maaInstanceColumns)
+	if err != nil {
+		return nil, err
 	}
-	return &types.MAAInstance{
-		MAAAddress:       maaStr(r[0]),
-		RuleID:           maaStr(r[1]),
-		RestrictedAddr:   maaStr(r[2]),
-		UnrestrictedAddr: maaStr(r[3]),
-		CreatedAt:        maaInt64(r[4]),
-	}, nil
+	return &instances[0], nil
 }
 
 // ListAgentRulesByRestricted lists the rules an agent created (maa_list_by_restricted). agent is a
@@ -223,14 +197,7 @@ func (s *Action) ListAgentRulesByRestricted(ctx context.Context, agent string, l
 	if err != nil {
 		return nil, err
 	}
-	out := make([]types.MAARuleRef, 0, len(res.Values))
-	for _, r := range res.Values {
-		if len(r) < 2 {
-			return nil, fmt.Errorf("malformed maa_list_by_restricted row: expected >=2 columns, got %d", len(r))
-		}
-		out = append(out, types.MAARuleRef{RuleID: maaStr(r[0]), CreatedAt: maaInt64(r[1])})
-	}
-	return out, nil
+	return decodeMAARows[types.MAARuleRef](res, "maa_list_by_restricted", maaRuleRefColumns)
 }
 
 // ListAgentWalletsByOwner lists the wallets an owner funded (maa_list_by_unrestricted). owner is a
@@ -240,18 +207,7 @@ func (s *Action) ListAgentWalletsByOwner(ctx context.Context, owner string, limi
 	if err != nil {
 		return nil, err
 	}
-	out := make([]types.MAAOwnedWallet, 0, len(res.Values))
-	for _, r := range res.Values {
-		if len(r) < 3 {
-			return nil, fmt.Errorf("malformed maa_list_by_unrestricted row: expected >=3 columns, got %d", len(r))
-		}
-		out = append(out, types.MAAOwnedWallet{
-			MAAAddress: maaStr(r[0]),
-			RuleID:     maaStr(r[1]),
-			CreatedAt:  maaInt64(r[2]),
-		})
-	}
-	return out, nil
+	return decodeMAARows[types.MAAOwnedWallet](res, "maa_list_by_unrestricted", maaOwnedWalletColumns)
 }
 
 // ListAgentWalletsByRule lists every wallet funded under a rule (maa_list_instances_by_rule).
@@ -260,18 +216,7 @@ func (s *Action) ListAgentWalletsByRule(ctx context.Context, ruleID []byte, limi
 	if err != nil {
 		return nil, err
 	}
-	out := make([]types.MAARuleWallet, 0, len(res.Values))
-	for _, r := range res.Values {
-		if len(r) < 3 {
-			return nil, fmt.Errorf("malformed maa_list_instances_by_rule row: expected >=3 columns, got %d", len(r))
-		}
-		out = append(out, types.MAARuleWallet{
-			MAAAddress:       maaStr(r[0]),
-			UnrestrictedAddr: maaStr(r[1]),
-			CreatedAt:        maaInt64(r[2]),
-		})
-	}
-	return out, nil
+	return decodeMAARows[types.MAARuleWallet](res, "maa_list_instances_by_rule", maaRuleWalletColumns)
 }
 
 // GetAgentRuleEvents returns a rule's append-only audit log (maa_get_events).
@@ -280,26 +225,7 @@ func (s *Action) GetAgentRuleEvents(ctx context.Context, ruleID []byte, limit, o
 	if err != nil {
 		return nil, err
 	}
-	out := make([]types.MAAEvent, 0, len(res.Values))
-	for _, r := range res.Values {
-		if len(r) < 11 {
-			return nil, fmt.Errorf("malformed maa_get_events row: expected >=11 columns, got %d", len(r))
-		}
-		out = append(out, types.MAAEvent{
-			ID:             maaInt64(r[0]),
-			MAAAddress:     maaStr(r[1]),
-			EventType:      maaStr(r[2]),
-			ActorRole:      maaStr(r[3]),
-			ActorAddr:      maaStr(r[4]),
-			InnerNamespace: maaStr(r[5]),
-			InnerAction:    maaStr(r[6]),
-			Amount:         maaStr(r[7]),
-			TxHash:         maaStr(r[8]),
-			BlockHeight:    maaInt64(r[9]),
-			BlockTimestamp: maaInt64(r[10]),
-		})
-	}
-	return out, nil
+	return decodeMAARows[types.MAAEvent](res, "maa_get_events", maaEventColumns)
 }
 
 // IsAgentWallet reports whether an address is a known (joined) agent wallet (maa_is_known).
@@ -330,30 +256,30 @@ func (s *Action) callerAddressBytes() ([]byte, error) {
 	return addr.Bytes(), nil
 }
 
-// maaStr coerces a query-result cell to a string. NUMERIC cells arrive as *types.Decimal (Stringer);
-// TEXT as string; NULL as nil -> "".
-func maaStr(v any) string {
-	if v == nil {
-		return ""
-	}
-	return fmt.Sprint(v)
-}
+// Column order of the maa_* views. The names match the tn tags of the MAA
+// result types.
+var (
+	maaRuleColumns          = []string{"rule_id", "restricted_addr", "rules_hash", "fee_mode", "fee_bps", "fee_flat", "created_at"}
+	maaAllowedActionColumns = []string{"namespace", "action", "body_hash"}
+	maaInstanceColumns      = []string{"maa_address", "rule_id", "restricted_addr", "unrestricted_addr", "created_at"}
+	maaRuleRefColumns       = []string{"rule_id", "created_at"}
+	maaOwnedWalletColumns   = []string{"maa_address", "rule_id", "created_at"}
+	maaRuleWalletColumns    = []string{"maa_address", "unrestricted_addr", "created_at"}
+	maaEventColumns         = []string{"id", "maa_address", "event_type", "actor_role", "actor_addr", "inner_namespace",
+		"inner_action", "amount", "tx_hash", "block_height", "block_timestamp"}
+)
 
-// maaInt64 coerces an INT/INT8 query-result cell to int64.
-func maaInt64(v any) int64 {
-	switch t := v.(type) {
-	case nil:
-		return 0
-	case int64:
-		return t
-	case int:
-		return int64(t)
-	case int32:
-		return int64(t)
-	default:
-		n, _ := strconv.ParseInt(fmt.Sprint(t), 10, 64)
-		return n
+// decodeMAARows decodes the rows of a maa_* view. NULL cells decode to "" or 0, and the
+// result is never nil.
+func decodeMAARows[T any](res *kwilTypes.QueryResult, action string, columns []string) ([]T, error) {
+	rows, err := decodePositional[T](res.Values, columns)
+	if err != nil {
+		return nil, errors.Wrapf(err, "malformed %s row", action)
+	}
+	if rows == nil {
+		rows = []T{}
 	}
+	return rows, nil
 }
 
 // maaBool coerces a BOOL query-result cell to bool.
@@ -0,0 +1,175 @@
+package contractsapi
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+// QuestionLocale renders market questions in one language.
+type QuestionLocale struct {
+	// Templates maps QuestionParts.Key to a question template. {stream} and
+	// {date} are always available; the other placeholders come from
+	// QuestionParts.Values, e.g. {threshold}, {min}, {max}, {target} and
+	// {tolerance} for the built-in codecs.
+	Templates map[string]string
+	// DateLayout formats {date} with time.Time.Format. Default: 2006-01-02.
+	DateLayout string
+	// FormatNumber formats decimal values, e.g. to localize the decimal
+	// separator. Nil leaves them as they are.
+	FormatNumber func(string) string
+}
+
+var (
+	localesMu sync.RWMutex
+	locales   = map[string]QuestionLocale{
+		"en": {
+			Templates: map[string]string{
+				"price_above_threshold":  "Will {stream} exceed {threshold} by {date}?",
+				"price_below_threshold":  "Will {stream} drop below {threshold} by {date}?",
+				"value_in_range":         "Will {stream} be between {min} and {max} on {date}?",
+				"value_equals":           "Will {stream} be exactly {target} on {date}?",
+				"value_equals_tolerance": "Will {stream} be {target} (±{tolerance}) on {date}?",
+			},
+			DateLayout: "2006-01-02",
+		},
+		"es": {
+			Templates: map[string]string{
+				"price_above_threshold":  "¿Superará {stream} {threshold} para el {date}?",
+				"price_below_threshold":  "¿Bajará {stream} de {threshold} para el {date}?",
+				"value_in_range":         "¿Estará {stream} entre {min} y {max} el {date}?",
+				"value_equals":           "¿Será {stream} exactamente {target} el {date}?",
+				"value_equals_tolerance": "¿Será {stream} {target} (±{tolerance}) el {date}?",
+			},
+			DateLayout:   "02/01/2006",
+			FormatNumber: func(s string) string { return strings.Replace(s, ".", ",", 1) },
+		},
+	}
+)
+
+// DefaultQuestionLocale is used when no locale is requested, and when the
+// requested one has no template for a question.
+const DefaultQuestionLocale = "en"
+
+// RegisterQuestionLocale adds or replaces the locale for a language tag such
+// as "fr" or "pt-BR". Templates missing from it fall back to the language
+// without region, then to DefaultQuestionLocale.
+func RegisterQuestionLocale(tag string, l QuestionLocale) error {
+	if tag == "" {
+		return fmt.Errorf("locale tag is required")
+	}
+	if len(l.Templates) == 0 {
+		return fmt.Errorf("locale %s has no templates", tag)
+	}
+	if l.DateLayout == "" {
+		l.DateLayout = "2006-01-02"
+	}
+	localesMu.Lock()
+	defer localesMu.Unlock()
+	locales[strings.ToLower(tag)] = l
+	return nil
+}
+
+// QuestionOption configures DescribeMarket and DescribeActionInput.
+type QuestionOption func(*questionOptions)
+
+type questionOptions struct {
+	locale     string
+	location   *time.Location
+	streamName func(dataProvider, streamID string) string
+}
+
+// WithQuestionLocale renders the question in the locale registered for tag.
+// Default: DefaultQuestionLocale.
+func WithQuestionLocale(tag string) QuestionOption {
+	return func(o *questionOptions) {
+		o.locale = strings.ToLower(tag)
+	}
+}
+
+// WithQuestionTimeZone formats {date} in loc. Default: UTC.
+func WithQuestionTimeZone(loc *time.Location) QuestionOption {
+	return func(o *questionOptions) {
+		if loc != nil {
+			o.location = loc
+		}
+	}
+}
+
+// WithStreamName names the stream in the question, e.g. from its metadata.
+// Default: the stream ID.
+func WithStreamName(name func(dataProvider, streamID string) string) QuestionOption {
+	return func(o *questionOptions) {
+		o.streamName = name
+	}
+}
+
+// DescribeMarket renders a market's query components as a question, such as
+// "Will stbtcusd... exceed 100000 by 2026-12-31?".
+func DescribeMarket(queryComponents []byte, opts ...QuestionOption) (string, error) {
+	input, err := DecodeActionInput(queryComponents)
+	if err != nil {
+		return "", err
+	}
+	return DescribeActionInput(input, opts...)
+}
+
+// DescribeActionInput renders input as a question with the codec of its
+// action.
+func DescribeActionInput(input types.BinaryActionInput, opts ...QuestionOption) (string, error) {
+	if input == nil {
+		return "", fmt.Errorf("input is required")
+	}
+	o := questionOptions{locale: DefaultQuestionLocale, location: time.UTC}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	c := GetActionCodec(input.ActionName())
+	if c == nil {
+		return "", fmt.Errorf("no codec registered for action %q", input.ActionName())
+	}
+	p, err := c.Question(input)
+	if err != nil {
+		return "", err
+	}
+	l, tmpl, ok := questionTemplate(o.locale, p.Key)
+	if !ok {
+		return "", fmt.Errorf("no question template for %q", p.Key)
+	}
+
+	stream := p.StreamID
+	if o.streamName != nil {
+		stream = o.streamName(p.DataProvider, p.StreamID)
+	}
+	pairs := []string{"{stream}", stream, "{date}", p.Time.In(o.location).Format(l.DateLayout)}
+	for k, v := range p.Values {
+		if l.FormatNumber != nil {
+			v = l.FormatNumber(v)
+		}
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+	return strings.NewReplacer(pairs...).Replace(tmpl), nil
+}
+
+// questionTemplate looks key up in tag ("pt-br"), its language ("pt") and
+// DefaultQuestionLocale, in that order.
+func questionTemplate(tag, key string) (QuestionLocale, string, bool) {
+	localesMu.RLock()
+	defer localesMu.RUnlock()
+	candidates := []string{tag}
+	if lang, _, ok := strings.Cut(tag, "-"); ok {
+		candidates = append(candidates, lang)
+	}
+	candidates = append(candidates, DefaultQuestionLocale)
+	for _, t := range candidates {
+		if l, ok := locales[t]; ok {
+			if tmpl, ok := l.Templates[key]; ok {
+				return l, tmpl, true
+			}
+		}
+	}
+	return QuestionLocale{}, "", false
+}
@@ -0,0 +1,512 @@
+package contractsapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/trufnetwork/sdk-go/core/types"
+	"github.com/trufnetwork/sdk-go/core/util"
+)
+
+// MarketProblemCode identifies the kind of issue found while validating a market spec.
+type MarketProblemCode string
+
+const (
+	MarketProblemInvalidQuery           MarketProblemCode = "invalid_query"
+	MarketProblemInvalidMarket          MarketProblemCode = "invalid_market"
+	MarketProblemSettleTimeTooEarly     MarketProblemCode = "settle_time_too_early"
+	MarketProblemStreamNotFound         MarketProblemCode = "stream_not_found"
+	MarketProblemStreamNotReadable      MarketProblemCode = "stream_not_readable"
+	MarketProblemNoRecentData           MarketProblemCode = "no_recent_data"
+	MarketProblemThresholdOutOfRange    MarketProblemCode = "threshold_out_of_range"
+	MarketProblemMarketExists           MarketProblemCode = "market_exists"
+	MarketProblemInsufficientFeeBalance MarketProblemCode = "insufficient_fee_balance"
+)
+
+// MarketProblem describes a single reason a market spec would be rejected or
+// would produce a market that cannot settle sensibly.
+type MarketProblem struct {
+	Code    MarketProblemCode
+	Message string
+}
+
+func (p MarketProblem) String() string {
+	return fmt.Sprintf("%s: %s", p.Code, p.Message)
+}
+
+// MarketValidationError is returned by MarketSpec when one or more problems
+// were found. Like TaxonomyValidationError, all problems are collected so a
+// caller can fix everything before paying the market creation fee.
+type MarketValidationError struct {
+	Problems []MarketProblem
+}
+
+func (e *MarketValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		msgs = append(msgs, p.String())
+	}
+	return fmt.Sprintf("invalid market (%d problem(s)): %s", len(e.Problems), strings.Join(msgs, "; "))
+}
+
+// HasProblem reports whether a problem with the given code was found.
+func (e *MarketValidationError) HasProblem(code MarketProblemCode) bool {
+	for _, p := range e.Problems {
+		if p.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// QueryHash returns the hash the order book indexes a market by: the SHA-256
+// of its ABI-encoded query components.
+func QueryHash(queryComponents []byte) []byte {
+	h := sha256.Sum256(queryComponents)
+	return h[:]
+}
+
+// Defaults used by MarketSpec.Validate.
+const (
+	DefaultMarketLookback     = 7 * 24 * time.Hour
+	DefaultMarketMaxDeviation = 0.5
+)
+
+// MarketSpec describes a binary prediction market over one of the binary
+// attestation actions and checks it before create_market is submitted.
+//
+// Build performs the local checks only (query arguments, market parameters
+// and settle time). Validate additionally queries the network to confirm the
+// stream exists and is readable by the creator, the threshold is within
+// reach of the stream's recent values, no market exists for the same query,
+// and the creator holds enough balance for the creation fee.
+//
+// Example:
+//
+//	input, err := contractsapi.NewPriceAboveThresholdSpec(provider, streamID, timestamp, "100000").
+//	    WithBridge("eth_usdc").
+//	    WithSettleTime(timestamp + 3600).
+//	    WithMaxSpread(5).
+//	    WithMinOrderSize(100).
+//	    Validate(ctx, actions, orderBook, signer)
+//	var verr *contractsapi.MarketValidationError
+//	if errors.As(err, &verr) {
+//	    for _, p := range verr.Problems { ... }
+//	}
+type MarketSpec struct {
+	action       string
+	dataProvider string
+	streamID     string
+	timestamp    int64
+	values       []string // threshold; min, max; or target, tolerance
+	frozenAt     *int64
+
+	bridge       string
+	settleTime   int64
+	maxSpread    int
+	minOrderSize int64
+
+	lookback     time.Duration
+	maxDeviation float64
+	feeBridge    string
+	fee          *big.Int
+}
+
+func newMarketSpec(action, dataProvider, streamID string, timestamp int64, values ...string) *MarketSpec {
+	return &MarketSpec{
+		action:       action,
+		dataProvider: dataProvider,
+		streamID:     streamID,
+		timestamp:    timestamp,
+		values:       values,
+		lookback:     DefaultMarketLookback,
+		maxDeviation: DefaultMarketMaxDeviation,
+	}
+}
+
+// NewPriceAboveThresholdSpec starts a "Will X exceed threshold at timestamp?" market.
+func NewPriceAboveThresholdSpec(dataProvider, streamID string, timestamp int64, threshold string) *MarketSpec {
+	return newMarketSpec("price_above_threshold", dataProvider, streamID, timestamp, threshold)
+}
+
+// NewPriceBelowThresholdSpec starts a "Will X drop below threshold at timestamp?" market.
+func NewPriceBelowThresholdSpec(dataProvider, streamID string, timestamp int64, threshold string) *MarketSpec {
+	return newMarketSpec("price_below_threshold", dataProvider, streamID, timestamp, threshold)
+}
+
+// NewValueInRangeSpec starts a "Will X be between min and max at timestamp?" market.
+func NewValueInRangeSpec(dataProvider, streamID string, timestamp int64, minValue, maxValue string) *MarketSpec {
+	return newMarketSpec("value_in_range", dataProvider, streamID, timestamp, minValue, maxValue)
+}
+
+// NewValueEqualsSpec starts a "Will X equal target (± tolerance) at timestamp?" market.
+func NewValueEqualsSpec(dataProvider, streamID string, timestamp int64, target, tolerance string) *MarketSpec {
+	return newMarketSpec("value_equals", dataProvider, streamID, timestamp, target, tolerance)
+}
+
+// WithFrozenAt freezes the value lookup at the given Unix timestamp.
+func (s *MarketSpec) WithFrozenAt(frozenAt int64) *MarketSpec {
+	s.frozenAt = &frozenAt
+	return s
+}
+
+// WithBridge sets the collateral bridge (see types.ValidBridges).
+func (s *MarketSpec) WithBridge(bridge string) *MarketSpec {
+	s.bridge = bridge
+	return s
+}
+
+// WithSettleTime sets the Unix timestamp from which the market can be settled.
+func (s *MarketSpec) WithSettleTime(settleTime int64) *MarketSpec {
+	s.settleTime = settleTime
+	return s
+}
+
+// WithMaxSpread sets the LP reward spread in cents (1-50).
+func (s *MarketSpec) WithMaxSpread(maxSpread int) *MarketSpec {
+	s.maxSpread = maxSpread
+	return s
+}
+
+// WithMinOrderSize sets the minimum order size for LP rewards.
+func (s *MarketSpec) WithMinOrderSize(minOrderSize int64) *MarketSpec {
+	s.minOrderSize = minOrderSize
+	return s
+}
+
+// WithRecentWindow sets how far back Validate looks for the stream's latest
+// value. Default: DefaultMarketLookback.
+func (s *MarketSpec) WithRecentWindow(lookback time.Duration) *MarketSpec {
+	s.lookback = lookback
+	return s
+}
+
+// WithMaxDeviation sets how far, as a fraction of the latest value, the
+// threshold may lie from it before Validate reports it as out of range.
+// Zero or less disables the check. Default: DefaultMarketMaxDeviation.
+func (s *MarketSpec) WithMaxDeviation(maxDeviation float64) *MarketSpec {
+	s.maxDeviation = maxDeviation
+	return s
+}
+
+// WithCreationFee sets the balance, in the smallest unit of the fee bridge's
+// token, the creator must hold for the creation fee. Without it, Validate
+// only requires a non-zero balance on the market's bridge.
+func (s *MarketSpec) WithCreationFee(feeBridge string, fee *big.Int) *MarketSpec {
+	s.feeBridge = feeBridge
+	s.fee = fee
+	return s
+}
+
+// QueryInput returns the binary action input the spec encodes, one of
+// types.PriceAboveThresholdInput, types.PriceBelowThresholdInput,
+// types.ValueInRangeInput or types.ValueEqualsInput.
+func (s *MarketSpec) QueryInput() any {
+	switch s.action {
+	case "price_above_threshold":
+		return types.PriceAboveThresholdInput{DataProvider: s.dataProvider, StreamID: s.streamID, Timestamp: s.timestamp, Threshold: s.values[0], FrozenAt: s.frozenAt}
+	case "price_below_threshold":
+		return types.PriceBelowThresholdInput{DataProvider: s.dataProvider, StreamID: s.streamID, Timestamp: s.timestamp, Threshold: s.values[0], FrozenAt: s.frozenAt}
+	case "value_in_range":
+		return types.ValueInRangeInput{DataProvider: s.dataProvider, StreamID: s.streamID, Timestamp: s.timestamp, MinValue: s.values[0], MaxValue: s.values[1], FrozenAt: s.frozenAt}
+	default:
+		return types.ValueEqualsInput{DataProvider: s.dataProvider, StreamID: s.streamID, Timestamp: s.timestamp, TargetValue: s.values[0], Tolerance: s.values[1], FrozenAt: s.frozenAt}
+	}
+}
+
+// Build runs the local checks and returns the create_market input. It does
+// not touch the network; use Validate for the full set of checks.
+func (s *MarketSpec) Build() (types.CreateMarketInput, error) {
+	input, problems := s.build()
+	if len(problems) > 0 {
+		return types.CreateMarketInput{}, &MarketValidationError{Problems: problems}
+	}
+	return input, nil
+}
+
+// Validate runs the local checks followed by the network checks, using
+// actions to read the stream and the bridge balance of creator, and ob to
+// look up existing markets. Transport failures are returned as-is; rule
+// violations are reported together as a *MarketValidationError.
+func (s *MarketSpec) Validate(ctx context.Context, actions types.IAction, ob types.IOrderBook, creator util.EthereumAddress) (types.CreateMarketInput, error) {
+	if actions == nil || ob == nil {
+		return types.CreateMarketInput{}, errors.New("actions and order book are required to validate a market")
+	}
+
+	input, problems := s.build()
+
+	locator, err := s.locator()
+	if err == nil {
+		streamProblems, err := s.streamProblems(ctx, actions, locator, creator)
+		if err != nil {
+			return types.CreateMarketInput{}, err
+		}
+		problems = append(problems, streamProblems...)
+	}
+
+	if len(input.QueryComponents) > 0 {
+		exists, err := ob.MarketExists(ctx, types.MarketExistsInput{QueryHash: QueryHash(input.QueryComponents)})
+		if err != nil {
+			return types.CreateMarketInput{}, errors.Wrap(err, "failed to check for an existing market")
+		}
+		if exists {
+			problems = append(problems, MarketProblem{
+				Code:    MarketProblemMarketExists,
+				Message: "a market for this query already exists",
+			})
+		}
+	}
+
+	feeProblem, err := s.feeProblem(ctx, actions, creator)
+	if err != nil {
+		return types.CreateMarketInput{}, err
+	}
+	if feeProblem != nil {
+		problems = append(problems, *feeProblem)
+	}
+
+	if len(problems) > 0 {
+		return types.CreateMarketInput{}, &MarketValidationError{Problems: problems}
+	}
+	return input, nil
+}
+
+// build encodes the query and checks everything that can be decided without
+// the network. The returned input has QueryComponents set whenever the query
+// itself is valid, even if market parameters are not.
+func (s *MarketSpec) build() (types.CreateMarketInput, []MarketProblem) {
+	var problems []MarketProblem
+
+	components, err := s.queryComponents()
+	if err != nil {
+		problems = append(problems, MarketProblem{Code: MarketProblemInvalidQuery, Message: err.Error()})
+	} else if p := s.valuesProblem(); p != nil {
+		problems = append(problems, *p)
+	}
+
+	input := types.CreateMarketInput{
+		Bridge:          s.bridge,
+		QueryComponents: components,
+		SettleTime:      s.settleTime,
+		MaxSpread:       s.maxSpread,
+		MinOrderSize:    s.minOrderSize,
+	}
+	check := input
+	if len(check.QueryComponents) == 0 {
+		// reported above; keep Validate from repeating it
+		check.QueryComponents = make([]byte, 128)
+	}
+	if err := check.Validate(); err != nil {
+		problems = append(problems, MarketProblem{Code: MarketProblemInvalidMarket, Message: err.Error()})
+	}
+
+	lastPoint := s.timestamp
+	if s.frozenAt != nil && *s.frozenAt > lastPoint {
+		lastPoint = *s.frozenAt
+	}
+	if s.settleTime > 0 && s.settleTime <= lastPoint {
+		problems = append(problems, MarketProblem{
+			Code:    MarketProblemSettleTimeTooEarly,
+			Message: fmt.Sprintf("settle_time %d must be after the last data point the query reads (%d)", s.settleTime, lastPoint),
+		})
+	}
+	return input, problems
+}
+
+func (s *MarketSpec) queryComponents() ([]byte, error) {
+	switch in := s.QueryInput().(type) {
+	case types.PriceAboveThresholdInput:
+		return BuildPriceAboveThresholdQueryComponents(in)
+	case types.PriceBelowThresholdInput:
+		return BuildPriceBelowThresholdQueryComponents(in)
+	case types.ValueInRangeInput:
+		return BuildValueInRangeQueryComponents(in)
+	default:
+		return BuildValueEqualsQueryComponents(in.(types.ValueEqualsInput))
+	}
+}
+
+// valuesProblem checks the relation between the action's values.
+func (s *MarketSpec) valuesProblem() *MarketProblem {
+	switch s.action {
+	case "value_in_range":
+		lo, _ := strconv.ParseFloat(s.values[0], 64)
+		hi, _ := strconv.ParseFloat(s.values[1], 64)
+		if lo > hi {
+			return &MarketProblem{Code: MarketProblemInvalidQuery, Message: fmt.Sprintf("min_value %s is greater than max_value %s", s.values[0], s.values[1])}
+		}
+	case "value_equals":
+		if tolerance, _ := strconv.ParseFloat(s.values[1], 64); tolerance < 0 {
+			return &MarketProblem{Code: MarketProblemInvalidQuery, Message: fmt.Sprintf("tolerance must not be negative, got %s", s.values[1])}
+		}
+	}
+	return nil
+}
+
+func (s *MarketSpec) locator() (types.StreamLocator, error) {
+	provider, err := util.NewEthereumAddressFromString(s.dataProvider)
+	if err != nil {
+		return types.StreamLocator{}, err
+	}
+	streamID, err := util.NewStreamId(s.streamID)
+	if err != nil {
+		return types.StreamLocator{}, err
+	}
+	return types.StreamLocator{DataProvider: provider, StreamId: *streamID}, nil
+}
+
+// streamProblems checks that the stream exists, that creator can read it and
+// that the spec's values are within reach of its latest value.
+func (s *MarketSpec) streamProblems(ctx context.Context, actions types.IAction, locator types.StreamLocator, creator util.EthereumAddress) ([]MarketProblem, error) {
+	exists, err := actions.BatchStreamExists(ctx, []types.StreamLocator{locator})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check stream existence")
+	}
+	if len(exists) == 0 || !exists[0].Exists {
+		return []MarketProblem{{Code: MarketProblemStreamNotFound, Message: fmt.Sprintf("stream %s/%s does not exist", s.dataProvider, s.streamID)}}, nil
+	}
+
+	if !sameAddress(locator.DataProvider, creator) {
+		visibility, err := actions.GetReadVisibility(ctx, locator)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get read visibility of %s", s.streamID)
+		}
+		if visibility != nil && *visibility == util.PrivateVisibility {
+			wallets, err := actions.GetAllowedReadWallets(ctx, locator)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to get allowed read wallets of %s", s.streamID)
+			}
+			allowed := false
+			for _, w := range wallets {
+				if sameAddress(w, creator) {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				return []MarketProblem{{
+					Code:    MarketProblemStreamNotReadable,
+					Message: fmt.Sprintf("stream is private and %s is not an allowed reader", creator.Address()),
+				}}, nil
+			}
+		}
+	}
+
+	latest, err := s.latestValue(ctx, actions)
+	if err != nil {
+		return nil, err
+	}
+	if latest == nil {
+		return []MarketProblem{{
+			Code:    MarketProblemNoRecentData,
+			Message: fmt.Sprintf("stream has no records in the last %s", s.lookback),
+		}}, nil
+	}
+	if p := s.deviationProblem(*latest); p != nil {
+		return []MarketProblem{*p}, nil
+	}
+	return nil, nil
+}
+
+// latestValue returns the stream's most recent value within the lookback
+// window, or nil if there is none.
+func (s *MarketSpec) latestValue(ctx context.Context, actions types.IAction) (*float64, error) {
+	from := int(time.Now().Add(-s.lookback).Unix())
+	result, err := actions.GetRecord(ctx, types.GetRecordInput{
+		DataProvider: s.dataProvider,
+		StreamId:     s.streamID,
+		From:         &from,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read recent records")
+	}
+	if len(result.Results) == 0 {
+		return nil, nil
+	}
+	records := result.Results
+	sort.SliceStable(records, func(i, j int) bool { return records[i].EventTime < records[j].EventTime })
+	v, err := records[len(records)-1].Value.Float64()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to convert latest value")
+	}
+	return &v, nil
+}
+
+// deviationProblem reports values farther than maxDeviation × |latest| from
+// latest. A range only needs to come within reach.
+func (s *MarketSpec) deviationProblem(latest float64) *MarketProblem {
+	if s.maxDeviation <= 0 || latest == 0 {
+		return nil
+	}
+	band := math.Abs(latest) * s.maxDeviation
+	lo, hi := latest-band, latest+band
+
+	outside := func(raw string) bool {
+		v, _ := strconv.ParseFloat(raw, 64)
+		return v < lo || v > hi
+	}
+	switch s.action {
+	case "value_in_range":
+		rangeLo, _ := strconv.ParseFloat(s.values[0], 64)
+		rangeHi, _ := strconv.ParseFloat(s.values[1], 64)
+		if rangeHi >= lo && rangeLo <= hi {
+			return nil
+		}
+	default:
+		if !outside(s.values[0]) {
+			return nil
+		}
+	}
+	return &MarketProblem{
+		Code: MarketProblemThresholdOutOfRange,
+		Message: fmt.Sprintf("%s is more than %.0f%% away from the latest value %s",
+			strings.Join(s.values[:s.compared()], "-"), s.maxDeviation*100, strconv.FormatFloat(latest, 'f', -1, 64)),
+	}
+}
+
+// compared returns how many of the spec's values are thresholds; a
+// value_equals tolerance is not.
+func (s *MarketSpec) compared() int {
+	if s.action == "value_in_range" {
+		return 2
+	}
+	return 1
+}
+
+// feeProblem checks the creator's balance on the fee bridge.
+func (s *MarketSpec) feeProblem(ctx context.Context, actions types.IAction, creator util.EthereumAddress) (*MarketProblem, error) {
+	bridge, fee := s.feeBridge, s.fee
+	if bridge == "" {
+		bridge = s.bridge
+	}
+	if bridge == "" {
+		return nil, nil // reported as invalid_market
+	}
+	if fee == nil {
+		fee = big.NewInt(1)
+	}
+
+	raw, err := actions.GetWalletBalance(ctx, bridge, creator.Address())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get balance on %s", bridge)
+	}
+	balance, ok := new(big.Int).SetString(raw, 10)
+	if !ok {
+		return nil, errors.Errorf("invalid balance %q on %s", raw, bridge)
+	}
+	if balance.Cmp(fee) < 0 {
+		return &MarketProblem{
+			Code:    MarketProblemInsufficientFeeBalance,
+			Message: fmt.Sprintf("balance %s on %s is below the creation fee %s", balance, bridge, fee),
+		}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,222 @@
+package contractsapi_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trufnetwork/sdk-go/core/contractsapi"
+	sdktypes "github.com/trufnetwork/sdk-go/core/types"
+	"github.com/trufnetwork/sdk-go/core/util"
+)
+
+// mockMarketActions stubs the reads MarketSpec.Validate relies on. Any other
+// IAction method panics through the nil embedded interface.
+type mockMarketActions struct {
+	sdktypes.IAction
+
+	exists      bool
+	private     bool
+	readWallets []util.EthereumAddress
+	records     []sdktypes.StreamResult
+	balances    map[string]string
+	recordErr   error
+}
+
+func (m *mockMarketActions) BatchStreamExists(_ context.Context, locators []sdktypes.StreamLocator) ([]sdktypes.StreamExistsResult, error) {
+	out := make([]sdktypes.StreamExistsResult, len(locators))
+	for i, l := range locators {
+		out[i] = sdktypes.StreamExistsResult{StreamLocator: l, Exists: m.exists}
+	}
+	return out, nil
+}
+
+func (m *mockMarketActions) GetReadVisibility(context.Context, sdktypes.StreamLocator) (*util.VisibilityEnum, error) {
+	v := util.PublicVisibility
+	if m.private {
+		v = util.PrivateVisibility
+	}
+	return &v, nil
+}
+
+func (m *mockMarketActions) GetAllowedReadWallets(context.Context, sdktypes.StreamLocator) ([]util.EthereumAddress, error) {
+	return m.readWallets, nil
+}
+
+func (m *mockMarketActions) GetRecord(_ context.Context, input sdktypes.GetRecordInput) (sdktypes.ActionResult, error) {
+	if input.From == nil {
+		return sdktypes.ActionResult{}, errors.New("unbounded read")
+	}
+	return sdktypes.ActionResult{Results: m.records}, m.recordErr
+}
+
+func (m *mockMarketActions) GetWalletBalance(_ context.Context, bridge string, _ string) (string, error) {
+	if b, ok := m.balances[bridge]; ok {
+		return b, nil
+	}
+	return "0", nil
+}
+
+type mockMarketBook struct {
+	sdktypes.IOrderBook
+	existing map[string]bool
+}
+
+func (m *mockMarketBook) MarketExists(_ context.Context, input sdktypes.MarketExistsInput) (bool, error) {
+	return m.existing[string(input.QueryHash)], nil
+}
+
+const (
+	specProvider = "0x1111111111111111111111111111111111111111"
+	specCreator  = "0x2222222222222222222222222222222222222222"
+)
+
+func record(t *testing.T, eventTime int, value string) sdktypes.StreamResult {
+	t.Helper()
+	d, _, err := apd.NewFromString(value)
+	require.NoError(t, err)
+	return sdktypes.StreamResult{EventTime: eventTime, Value: *d}
+}
+
+func newSpecFixture(t *testing.T) (*mockMarketActions, *mockMarketBook, util.EthereumAddress, string, int64) {
+	t.Helper()
+	creator, err := util.NewEthereumAddressFromString(specCreator)
+	require.NoError(t, err)
+	actions := &mockMarketActions{
+		exists: true,
+		records: []sdktypes.StreamResult{
+			record(t, 200, "100"),
+			record(t, 100, "10"),
+		},
+		balances: map[string]string{"eth_usdc": "1000000"},
+	}
+	streamID := util.GenerateStreamId("market_spec")
+	return actions, &mockMarketBook{existing: map[string]bool{}}, creator,
+		streamID.String(), time.Now().Add(24 * time.Hour).Unix()
+}
+
+func TestMarketSpec_Build(t *testing.T) {
+	_, _, _, streamID, ts := newSpecFixture(t)
+
+	input, err := contractsapi.NewPriceAboveThresholdSpec(specProvider, streamID, ts, "120").
+		WithBridge("eth_usdc").
+		WithSettleTime(ts + 3600).
+		WithMaxSpread(5).
+		WithMinOrderSize(10).
+		Build()
+	require.NoError(t, err)
+	want, err := contractsapi.BuildPriceAboveThresholdQueryComponents(sdktypes.PriceAboveThresholdInput{
+		DataProvider: specProvider, StreamID: streamID, Timestamp: ts, Threshold: "120",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, want, input.QueryComponents)
+	assert.Equal(t, "eth_usdc", input.Bridge)
+
+	_, err = contractsapi.NewValueInRangeSpec(specProvider, streamID, ts, "200", "100").
+		WithBridge("nope").
+		WithSettleTime(ts).
+		WithMaxSpread(5).
+		WithMinOrderSize(10).
+		Build()
+	var verr *contractsapi.MarketValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.True(t, verr.HasProblem(contractsapi.MarketProblemInvalidQuery))
+	assert.True(t, verr.HasProblem(contractsapi.MarketProblemInvalidMarket))
+	assert.True(t, verr.HasProblem(contractsapi.MarketProblemSettleTimeTooEarly))
+	assert.Len(t, verr.Problems, 3)
+}
+
+func TestMarketSpec_Validate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("valid", func(t *testing.T) {
+		actions, ob, creator, streamID, ts := newSpecFixture(t)
+		input, err := contractsapi.NewValueEqualsSpec(specProvider, streamID, ts, "110", "500").
+			WithBridge("eth_usdc").WithSettleTime(ts+60).WithMaxSpread(5).WithMinOrderSize(10).
+			Validate(ctx, actions, ob, creator)
+		require.NoError(t, err)
+		assert.NotEmpty(t, input.QueryComponents)
+	})
+
+	t.Run("collects every problem", func(t *testing.T) {
+		actions, ob, creator, streamID, ts := newSpecFixture(t)
+		actions.private = true
+		actions.balances = nil
+
+		spec := contractsapi.NewPriceBelowThresholdSpec(specProvider, streamID, ts, "10").
+			WithBridge("eth_usdc").WithSettleTime(ts + 60).WithMaxSpread(5).WithMinOrderSize(10)
+		built, err := spec.Build()
+		require.NoError(t, err)
+		ob.existing[string(contractsapi.QueryHash(built.QueryComponents))] = true
+
+		_, err = spec.Validate(ctx, actions, ob, creator)
+		var verr *contractsapi.MarketValidationError
+		require.ErrorAs(t, err, &verr)
+		assert.True(t, verr.HasProblem(contractsapi.MarketProblemStreamNotReadable))
+		assert.True(t, verr.HasProblem(contractsapi.MarketProblemMarketExists))
+		assert.True(t, verr.HasProblem(contractsapi.MarketProblemInsufficientFeeBalance))
+	})
+
+	t.Run("threshold far from latest value", func(t *testing.T) {
+		actions, ob, creator, streamID, ts := newSpecFixture(t)
+		spec := contractsapi.NewPriceAboveThresholdSpec(specProvider, streamID, ts, "10").
+			WithBridge("eth_usdc").WithSettleTime(ts + 60).WithMaxSpread(5).WithMinOrderSize(10)
+		_, err := spec.Validate(ctx, actions, ob, creator)
+		var verr *contractsapi.MarketValidationError
+		require.ErrorAs(t, err, &verr)
+		assert.Equal(t, contractsapi.MarketProblemThresholdOutOfRange, verr.Problems[0].Code)
+
+		_, err = spec.WithMaxDeviation(0).Validate(ctx, actions, ob, creator)
+		assert.NoError(t, err)
+
+		_, err = contractsapi.NewValueInRangeSpec(specProvider, streamID, ts, "20", "60").
+			WithBridge("eth_usdc").WithSettleTime(ts+60).WithMaxSpread(5).WithMinOrderSize(10).
+			Validate(ctx, actions, ob, creator)
+		assert.NoError(t, err, "a range reaching into the band is fine")
+	})
+
+	t.Run("missing stream and no data", func(t *testing.T) {
+		actions, ob, creator, streamID, ts := newSpecFixture(t)
+		actions.exists = false
+		spec := contractsapi.NewPriceAboveThresholdSpec(specProvider, streamID, ts, "100").
+			WithBridge("eth_usdc").WithSettleTime(ts + 60).WithMaxSpread(5).WithMinOrderSize(10)
+		_, err := spec.Validate(ctx, actions, ob, creator)
+		var verr *contractsapi.MarketValidationError
+		require.ErrorAs(t, err, &verr)
+		assert.True(t, verr.HasProblem(contractsapi.MarketProblemStreamNotFound))
+
+		actions.exists = true
+		actions.records = nil
+		_, err = spec.Validate(ctx, actions, ob, creator)
+		require.ErrorAs(t, err, &verr)
+		assert.True(t, verr.HasProblem(contractsapi.MarketProblemNoRecentData))
+	})
+
+	t.Run("creation fee", func(t *testing.T) {
+		actions, ob, creator, streamID, ts := newSpecFixture(t)
+		actions.balances["eth_truf"] = "5"
+		spec := contractsapi.NewPriceAboveThresholdSpec(specProvider, streamID, ts, "100").
+			WithBridge("eth_usdc").WithSettleTime(ts+60).WithMaxSpread(5).WithMinOrderSize(10).
+			WithCreationFee("eth_truf", big.NewInt(6))
+		_, err := spec.Validate(ctx, actions, ob, creator)
+		var verr *contractsapi.MarketValidationError
+		require.ErrorAs(t, err, &verr)
+		assert.True(t, verr.HasProblem(contractsapi.MarketProblemInsufficientFeeBalance))
+	})
+
+	t.Run("transport errors are returned", func(t *testing.T) {
+		actions, ob, creator, streamID, ts := newSpecFixture(t)
+		actions.recordErr = errors.New("node unavailable")
+		_, err := contractsapi.NewPriceAboveThresholdSpec(specProvider, streamID, ts, "100").
+			WithBridge("eth_usdc").WithSettleTime(ts+60).WithMaxSpread(5).WithMinOrderSize(10).
+			Validate(ctx, actions, ob, creator)
+		require.ErrorContains(t, err, "node unavailable")
+		var verr *contractsapi.MarketValidationError
+		assert.False(t, errors.As(err, &verr))
+	})
+}
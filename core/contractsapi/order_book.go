@@ -9,6 +9,7 @@ import (
 	"github.com/trufnetwork/kwil-db/core/gatewayclient"
 	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
 	kwilClientType "github.com/trufnetwork/kwil-db/core/client/types"
+	"github.com/trufnetwork/sdk-go/core/tnerrors"
 	"github.com/trufnetwork/sdk-go/core/types"
 )
 
@@ -39,20 +40,21 @@ func LoadOrderBook(options NewOrderBookOptions) (types.IOrderBook, error) {
 // HELPER METHODS
 // ═══════════════════════════════════════════════════════════════
 
-// call wraps _client.Call for read operations
+// call wraps _client.Call for read operations. Errors, including the action's
+// own error, are returned as *tnerrors.Error.
 func (o *OrderBook) call(ctx context.Context, action string, args []any) (*kwiltypes.QueryResult, error) {
 	callResult, err := o._client.Call(ctx, "", action, args)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to call %s", action)
+		return nil, tnerrors.Wrap(err, tnerrors.OpCall, action)
 	}
 	if callResult == nil {
-		return nil, fmt.Errorf("action %s returned nil result", action)
+		return nil, tnerrors.New(tnerrors.OpCall, action, "returned nil result")
 	}
 	if callResult.Error != nil {
-		return nil, fmt.Errorf("action %s returned error: %v", action, callResult.Error)
+		return nil, tnerrors.New(tnerrors.OpCall, action, *callResult.Error)
 	}
 	if callResult.QueryResult == nil {
-		return nil, fmt.Errorf("action %s returned nil QueryResult", action)
+		return nil, tnerrors.New(tnerrors.OpCall, action, "returned nil QueryResult")
 	}
 	return callResult.QueryResult, nil
 }
@@ -60,7 +62,11 @@ func (o *OrderBook) call(ctx context.Context, action string, args []any) (*kwilt
 // execute wraps _client.Execute for write operations
 func (o *OrderBook) execute(ctx context.Context, action string, args [][]any,
 	opts ...kwilClientType.TxOpt) (kwiltypes.Hash, error) {
-	return o._client.Execute(ctx, "", action, args, opts...)
+	hash, err := o._client.Execute(ctx, "", action, args, opts...)
+	if err != nil {
+		return hash, tnerrors.Wrap(err, tnerrors.OpExecute, action)
+	}
+	return hash, nil
 }
 
 // extractIntColumn extracts an int value from a query result column
@@ -102,15 +108,5 @@ func extractBoolColumn(val any, target *bool, colIndex int, colName string) erro
 	return nil
 }
 
-// extractStringColumn extracts a string value from a query result column
-func extractStringColumn(val any, target *string, colIndex int, colName string) error {
-	str, ok := val.(string)
-	if !ok {
-		return fmt.Errorf("invalid %s type (column %d): expected string, got %T", colName, colIndex, val)
-	}
-	*target = str
-	return nil
-}
-
 // Note: extractInt64Column and extractBytesColumn are defined in attestation_actions.go
 // and are available to all files in the contractsapi package
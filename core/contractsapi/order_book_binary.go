@@ -118,19 +118,8 @@ func (o *OrderBook) CreatePriceAboveThresholdMarket(
 		input.FrozenAt, // Can be nil
 	}
 
-	// Encode action arguments
-	argsBytes, err := EncodeActionArgs(args)
-	if err != nil {
-		return kwiltypes.Hash{}, fmt.Errorf("failed to encode action args: %w", err)
-	}
-
 	// Encode query components
-	queryComponents, err := EncodeQueryComponents(
-		input.DataProvider,
-		input.StreamID,
-		"price_above_threshold",
-		argsBytes,
-	)
+	queryComponents, err := BuildQueryComponents(input.DataProvider, input.StreamID, "price_above_threshold", args)
 	if err != nil {
 		return kwiltypes.Hash{}, fmt.Errorf("failed to encode query_components: %w", err)
 	}
@@ -174,17 +163,7 @@ func (o *OrderBook) CreatePriceBelowThresholdMarket(
 		input.FrozenAt,
 	}
 
-	argsBytes, err := EncodeActionArgs(args)
-	if err != nil {
-		return kwiltypes.Hash{}, fmt.Errorf("failed to encode action args: %w", err)
-	}
-
-	queryComponents, err := EncodeQueryComponents(
-		input.DataProvider,
-		input.StreamID,
-		"price_below_threshold",
-		argsBytes,
-	)
+	queryComponents, err := BuildQueryComponents(input.DataProvider, input.StreamID, "price_below_threshold", args)
 	if err != nil {
 		return kwiltypes.Hash{}, fmt.Errorf("failed to encode query_components: %w", err)
 	}
@@ -233,17 +212,7 @@ func (o *OrderBook) CreateValueInRangeMarket(
 		input.FrozenAt,
 	}
 
-	argsBytes, err := EncodeActionArgs(args)
-	if err != nil {
-		return kwiltypes.Hash{}, fmt.Errorf("failed to encode action args: %w", err)
-	}
-
-	queryComponents, err := EncodeQueryComponents(
-		input.DataProvider,
-		input.StreamID,
-		"value_in_range",
-		argsBytes,
-	)
+	queryComponents, err := BuildQueryComponents(input.DataProvider, input.StreamID, "value_in_range", args)
 	if err != nil {
 		return kwiltypes.Hash{}, fmt.Errorf("failed to encode query_components: %w", err)
 	}
@@ -292,17 +261,7 @@ func (o *OrderBook) CreateValueEqualsMarket(
 		input.FrozenAt,
 	}
 
-	argsBytes, err := EncodeActionArgs(args)
-	if err != nil {
-		return kwiltypes.Hash{}, fmt.Errorf("failed to encode action args: %w", err)
-	}
-
-	queryComponents, err := EncodeQueryComponents(
-		input.DataProvider,
-		input.StreamID,
-		"value_equals",
-		argsBytes,
-	)
+	queryComponents, err := BuildQueryComponents(input.DataProvider, input.StreamID, "value_equals", args)
 	if err != nil {
 		return kwiltypes.Hash{}, fmt.Errorf("failed to encode query_components: %w", err)
 	}
@@ -340,17 +299,7 @@ func BuildPriceAboveThresholdQueryComponents(input types.PriceAboveThresholdInpu
 		input.FrozenAt,
 	}
 
-	argsBytes, err := EncodeActionArgs(args)
-	if err != nil {
-		return nil, fmt.Errorf("failed to encode action args: %w", err)
-	}
-
-	return EncodeQueryComponents(
-		input.DataProvider,
-		input.StreamID,
-		"price_above_threshold",
-		argsBytes,
-	)
+	return BuildQueryComponents(input.DataProvider, input.StreamID, "price_above_threshold", args)
 }
 
 // BuildPriceBelowThresholdQueryComponents builds query_components for a price_below_threshold query
@@ -373,17 +322,7 @@ func BuildPriceBelowThresholdQueryComponents(input types.PriceBelowThresholdInpu
 		input.FrozenAt,
 	}
 
-	argsBytes, err := EncodeActionArgs(args)
-	if err != nil {
-		return nil, fmt.Errorf("failed to encode action args: %w", err)
-	}
-
-	return EncodeQueryComponents(
-		input.DataProvider,
-		input.StreamID,
-		"price_below_threshold",
-		argsBytes,
-	)
+	return BuildQueryComponents(input.DataProvider, input.StreamID, "price_below_threshold", args)
 }
 
 // BuildValueInRangeQueryComponents builds query_components for a value_in_range query
@@ -411,17 +350,7 @@ func BuildValueInRangeQueryComponents(input types.ValueInRangeInput) ([]byte, er
 		input.FrozenAt,
 	}
 
-	argsBytes, err := EncodeActionArgs(args)
-	if err != nil {
-		return nil, fmt.Errorf("failed to encode action args: %w", err)
-	}
-
-	return EncodeQueryComponents(
-		input.DataProvider,
-		input.StreamID,
-		"value_in_range",
-		argsBytes,
-	)
+	return BuildQueryComponents(input.DataProvider, input.StreamID, "value_in_range", args)
 }
 
 // BuildValueEqualsQueryComponents builds query_components for a value_equals query
@@ -449,15 +378,5 @@ func BuildValueEqualsQueryComponents(input types.ValueEqualsInput) ([]byte, erro
 		input.FrozenAt,
 	}
 
-	argsBytes, err := EncodeActionArgs(args)
-	if err != nil {
-		return nil, fmt.Errorf("failed to encode action args: %w", err)
-	}
-
-	return EncodeQueryComponents(
-		input.DataProvider,
-		input.StreamID,
-		"value_equals",
-		argsBytes,
-	)
+	return BuildQueryComponents(input.DataProvider, input.StreamID, "value_equals", args)
 }
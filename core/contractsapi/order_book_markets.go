@@ -50,12 +50,13 @@ func (o *OrderBook) GetMarketInfo(ctx context.Context, input types.GetMarketInfo
 		return nil, fmt.Errorf("market not found: query_id=%d", input.QueryID)
 	}
 
-	row := result.Values[0]
-	market, err := parseMarketInfoRow(row, input.QueryID)
+	markets, err := decodePositional[types.MarketInfo](result.Values[:1], marketInfoColumns)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
+	market := &markets[0]
+	market.ID = input.QueryID
 	return market, nil
 }
 
@@ -78,15 +79,12 @@ func (o *OrderBook) GetMarketByHash(ctx context.Context, input types.GetMarketBy
 		return nil, fmt.Errorf("market not found for given hash")
 	}
 
-	row := result.Values[0]
-
-	// get_market_by_hash returns 9 columns: id, settle_time, settled, winning_outcome, settled_at, max_spread, min_order_size, created_at, creator
-	market, err := parseMarketByHashRow(row)
+	markets, err := decodePositional[types.MarketInfo](result.Values[:1], marketByHashColumns)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	return market, nil
+	return &markets[0], nil
 }
 
 // ListMarkets returns paginated list of markets with optional filtering
@@ -118,13 +116,9 @@ func (o *OrderBook) ListMarkets(ctx context.Context, input types.ListMarketsInpu
 		return nil, errors.WithStack(err)
 	}
 
-	var markets []types.MarketSummary
-	for _, row := range result.Values {
-		market, err := parseMarketSummaryRow(row)
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
-		markets = append(markets, market)
+	markets, err := decodePositional[types.MarketSummary](result.Values, marketSummaryColumns)
+	if err != nil {
+		return nil, errors.WithStack(err)
 	}
 
 	return markets, nil
@@ -179,261 +173,27 @@ func (o *OrderBook) ValidateMarketCollateral(ctx context.Context, input types.Va
 		return nil, fmt.Errorf("validation data not found for query_id=%d", input.QueryID)
 	}
 
-	row := result.Values[0]
-	validation, err := parseMarketValidationRow(row)
+	validations, err := decodePositional[types.MarketValidation](result.Values[:1], marketValidationColumns)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	return validation, nil
+	return &validations[0], nil
 }
 
 // ═══════════════════════════════════════════════════════════════
 // PARSING HELPERS
 // ═══════════════════════════════════════════════════════════════
 
-// parseMarketInfoRow parses a row from get_market_info
-// Row format: hash, query_components, bridge, settle_time, settled, winning_outcome, settled_at, max_spread, min_order_size, created_at, creator
-func parseMarketInfoRow(row []any, marketID int) (*types.MarketInfo, error) {
-	if len(row) < 11 {
-		return nil, fmt.Errorf("invalid row: expected 11 columns, got %d", len(row))
-	}
-
-	market := &types.MarketInfo{
-		ID: marketID,
-	}
-
-	// Column 0: hash (BYTEA)
-	if err := extractBytesColumn(row[0], &market.Hash, 0, "hash"); err != nil {
-		return nil, err
-	}
-
-	// Column 1: query_components (BYTEA)
-	if err := extractBytesColumn(row[1], &market.QueryComponents, 1, "query_components"); err != nil {
-		return nil, err
-	}
-
-	// Column 2: bridge (TEXT)
-	if err := extractStringColumn(row[2], &market.Bridge, 2, "bridge"); err != nil {
-		return nil, err
-	}
-
-	// Column 3: settle_time (INT8)
-	if err := extractInt64Column(row[3], &market.SettleTime, 3, "settle_time"); err != nil {
-		return nil, err
-	}
-
-	// Column 4: settled (BOOL)
-	if err := extractBoolColumn(row[4], &market.Settled, 4, "settled"); err != nil {
-		return nil, err
-	}
-
-	// Column 5: winning_outcome (BOOL, nullable)
-	if row[5] != nil {
-		var outcome bool
-		if err := extractBoolColumn(row[5], &outcome, 5, "winning_outcome"); err != nil {
-			return nil, err
-		}
-		market.WinningOutcome = &outcome
-	}
-
-	// Column 6: settled_at (INT8, nullable)
-	if row[6] != nil {
-		var settledAt int64
-		if err := extractInt64Column(row[6], &settledAt, 6, "settled_at"); err != nil {
-			return nil, err
-		}
-		market.SettledAt = &settledAt
-	}
-
-	// Column 7: max_spread (INT)
-	if err := extractIntColumn(row[7], &market.MaxSpread, 7, "max_spread"); err != nil {
-		return nil, err
-	}
-
-	// Column 8: min_order_size (INT8)
-	if err := extractInt64Column(row[8], &market.MinOrderSize, 8, "min_order_size"); err != nil {
-		return nil, err
-	}
-
-	// Column 9: created_at (INT8)
-	if err := extractInt64Column(row[9], &market.CreatedAt, 9, "created_at"); err != nil {
-		return nil, err
-	}
-
-	// Column 10: creator (BYTEA)
-	if err := extractBytesColumn(row[10], &market.Creator, 10, "creator"); err != nil {
-		return nil, err
-	}
-
-	return market, nil
-}
-
-// parseMarketByHashRow parses a row from get_market_by_hash
-// Row format: id, settle_time, settled, winning_outcome, settled_at, max_spread, min_order_size, created_at, creator
-// Note: get_market_by_hash does NOT return query_components or bridge
-func parseMarketByHashRow(row []any) (*types.MarketInfo, error) {
-	if len(row) < 9 {
-		return nil, fmt.Errorf("invalid row: expected 9 columns, got %d", len(row))
-	}
-
-	market := &types.MarketInfo{}
-
-	// Column 0: id (INT)
-	if err := extractIntColumn(row[0], &market.ID, 0, "id"); err != nil {
-		return nil, err
-	}
-
-	// Column 1: settle_time (INT8)
-	if err := extractInt64Column(row[1], &market.SettleTime, 1, "settle_time"); err != nil {
-		return nil, err
-	}
-
-	// Column 2: settled (BOOL)
-	if err := extractBoolColumn(row[2], &market.Settled, 2, "settled"); err != nil {
-		return nil, err
-	}
-
-	// Column 3: winning_outcome (BOOL, nullable)
-	if row[3] != nil {
-		var outcome bool
-		if err := extractBoolColumn(row[3], &outcome, 3, "winning_outcome"); err != nil {
-			return nil, err
-		}
-		market.WinningOutcome = &outcome
-	}
-
-	// Column 4: settled_at (INT8, nullable)
-	if row[4] != nil {
-		var settledAt int64
-		if err := extractInt64Column(row[4], &settledAt, 4, "settled_at"); err != nil {
-			return nil, err
-		}
-		market.SettledAt = &settledAt
-	}
-
-	// Column 5: max_spread (INT)
-	if err := extractIntColumn(row[5], &market.MaxSpread, 5, "max_spread"); err != nil {
-		return nil, err
-	}
-
-	// Column 6: min_order_size (INT8)
-	if err := extractInt64Column(row[6], &market.MinOrderSize, 6, "min_order_size"); err != nil {
-		return nil, err
-	}
-
-	// Column 7: created_at (INT8)
-	if err := extractInt64Column(row[7], &market.CreatedAt, 7, "created_at"); err != nil {
-		return nil, err
-	}
-
-	// Column 8: creator (BYTEA)
-	if err := extractBytesColumn(row[8], &market.Creator, 8, "creator"); err != nil {
-		return nil, err
-	}
-
-	return market, nil
-}
-
-// parseMarketSummaryRow parses a row from list_markets
-// Row format: id, hash, settle_time, settled, winning_outcome, max_spread, min_order_size, created_at
-func parseMarketSummaryRow(row []any) (types.MarketSummary, error) {
-	if len(row) < 8 {
-		return types.MarketSummary{}, fmt.Errorf("invalid row: expected 8 columns, got %d", len(row))
-	}
-
-	summary := types.MarketSummary{}
-
-	// Column 0: id (INT)
-	if err := extractIntColumn(row[0], &summary.ID, 0, "id"); err != nil {
-		return summary, err
-	}
-
-	// Column 1: hash (BYTEA)
-	if err := extractBytesColumn(row[1], &summary.Hash, 1, "hash"); err != nil {
-		return summary, err
-	}
-
-	// Column 2: settle_time (INT8)
-	if err := extractInt64Column(row[2], &summary.SettleTime, 2, "settle_time"); err != nil {
-		return summary, err
-	}
-
-	// Column 3: settled (BOOL)
-	if err := extractBoolColumn(row[3], &summary.Settled, 3, "settled"); err != nil {
-		return summary, err
-	}
-
-	// Column 4: winning_outcome (BOOL, nullable)
-	if row[4] != nil {
-		var outcome bool
-		if err := extractBoolColumn(row[4], &outcome, 4, "winning_outcome"); err != nil {
-			return summary, err
-		}
-		summary.WinningOutcome = &outcome
-	}
-
-	// Column 5: max_spread (INT)
-	if err := extractIntColumn(row[5], &summary.MaxSpread, 5, "max_spread"); err != nil {
-		return summary, err
-	}
-
-	// Column 6: min_order_size (INT8)
-	if err := extractInt64Column(row[6], &summary.MinOrderSize, 6, "min_order_size"); err != nil {
-		return summary, err
-	}
-
-	// Column 7: created_at (INT8)
-	if err := extractInt64Column(row[7], &summary.CreatedAt, 7, "created_at"); err != nil {
-		return summary, err
-	}
-
-	return summary, nil
-}
-
-// parseMarketValidationRow parses a row from validate_market_collateral
-// Row format: valid_token_binaries, valid_collateral, total_true, total_false, vault_balance, expected_collateral, open_buys_value
-func parseMarketValidationRow(row []any) (*types.MarketValidation, error) {
-	if len(row) < 7 {
-		return nil, fmt.Errorf("invalid row: expected 7 columns, got %d", len(row))
-	}
-
-	validation := &types.MarketValidation{}
-
-	// Column 0: valid_token_binaries (BOOL)
-	if err := extractBoolColumn(row[0], &validation.ValidTokenBinaries, 0, "valid_token_binaries"); err != nil {
-		return nil, err
-	}
-
-	// Column 1: valid_collateral (BOOL)
-	if err := extractBoolColumn(row[1], &validation.ValidCollateral, 1, "valid_collateral"); err != nil {
-		return nil, err
-	}
-
-	// Column 2: total_true (INT8)
-	if err := extractInt64Column(row[2], &validation.TotalTrue, 2, "total_true"); err != nil {
-		return nil, err
-	}
-
-	// Column 3: total_false (INT8)
-	if err := extractInt64Column(row[3], &validation.TotalFalse, 3, "total_false"); err != nil {
-		return nil, err
-	}
-
-	// Column 4: vault_balance (NUMERIC(78,0) as string)
-	if err := extractStringColumn(row[4], &validation.VaultBalance, 4, "vault_balance"); err != nil {
-		return nil, err
-	}
-
-	// Column 5: expected_collateral (NUMERIC(78,0) as string)
-	if err := extractStringColumn(row[5], &validation.ExpectedCollateral, 5, "expected_collateral"); err != nil {
-		return nil, err
-	}
-
-	// Column 6: open_buys_value (INT8)
-	if err := extractInt64Column(row[6], &validation.OpenBuysValue, 6, "open_buys_value"); err != nil {
-		return nil, err
-	}
-
-	return validation, nil
-}
+// Column order of the market views, as declared in the migrations.
+var (
+	marketInfoColumns = []string{"hash", "query_components", "bridge", "settle_time", "settled",
+		"winning_outcome", "settled_at", "max_spread", "min_order_size", "created_at", "creator"}
+	// get_market_by_hash does NOT return query_components or bridge
+	marketByHashColumns = []string{"id", "settle_time", "settled", "winning_outcome", "settled_at",
+		"max_spread", "min_order_size", "created_at", "creator"}
+	marketSummaryColumns = []string{"id", "hash", "settle_time", "settled", "winning_outcome",
+		"max_spread", "min_order_size", "created_at"}
+	marketValidationColumns = []string{"valid_token_binaries", "valid_collateral", "total_true",
+		"total_false", "vault_balance", "expected_collateral", "open_buys_value"}
+)
@@ -2,7 +2,6 @@ package contractsapi
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/pkg/errors"
 	"github.com/trufnetwork/sdk-go/core/types"
@@ -29,13 +28,9 @@ func (o *OrderBook) GetOrderBook(ctx context.Context, input types.GetOrderBookIn
 		return nil, errors.WithStack(err)
 	}
 
-	var entries []types.OrderBookEntry
-	for _, row := range result.Values {
-		entry, err := parseOrderBookEntryRow(row)
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
-		entries = append(entries, entry)
+	entries, err := decodePositional[types.OrderBookEntry](result.Values, orderBookEntryColumns)
+	if err != nil {
+		return nil, errors.WithStack(err)
 	}
 
 	return entries, nil
@@ -54,13 +49,9 @@ func (o *OrderBook) GetUserPositions(ctx context.Context) ([]types.UserPosition,
 		return nil, errors.WithStack(err)
 	}
 
-	var positions []types.UserPosition
-	for _, row := range result.Values {
-		position, err := parseUserPositionRow(row)
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
-		positions = append(positions, position)
+	positions, err := decodePositional[types.UserPosition](result.Values, userPositionColumns)
+	if err != nil {
+		return nil, errors.WithStack(err)
 	}
 
 	return positions, nil
@@ -82,13 +73,9 @@ func (o *OrderBook) GetMarketDepth(ctx context.Context, input types.GetMarketDep
 		return nil, errors.WithStack(err)
 	}
 
-	var levels []types.DepthLevel
-	for _, row := range result.Values {
-		level, err := parseDepthLevelRow(row)
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
-		levels = append(levels, level)
+	levels, err := decodePositional[types.DepthLevel](result.Values, depthLevelColumns)
+	if err != nil {
+		return nil, errors.WithStack(err)
 	}
 
 	return levels, nil
@@ -118,13 +105,12 @@ func (o *OrderBook) GetBestPrices(ctx context.Context, input types.GetBestPrices
 		return &types.BestPrices{}, nil
 	}
 
-	row := result.Values[0]
-	prices, err := parseBestPricesRow(row)
+	rows, err := decodePositional[types.BestPrices](result.Values[:1], bestPricesColumns)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	return prices, nil
+	return &rows[0], nil
 }
 
 // GetUserCollateral returns caller's total locked collateral value
@@ -153,13 +139,12 @@ func (o *OrderBook) GetUserCollateral(ctx context.Context) (*types.UserCollatera
 		}, nil
 	}
 
-	row := result.Values[0]
-	collateral, err := parseUserCollateralRow(row)
+	collaterals, err := decodePositional[types.UserCollateral](result.Values[:1], userCollateralColumns)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	return collateral, nil
+	return &collaterals[0], nil
 }
 
 // GetPositionsByWallet retrieves a wallet's portfolio by address
@@ -179,13 +164,9 @@ func (o *OrderBook) GetPositionsByWallet(ctx context.Context, input types.GetPos
 		return nil, errors.WithStack(err)
 	}
 
-	var positions []types.UserPosition
-	for _, row := range result.Values {
-		position, err := parseUserPositionRow(row)
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
-		positions = append(positions, position)
+	positions, err := decodePositional[types.UserPosition](result.Values, userPositionColumns)
+	if err != nil {
+		return nil, errors.WithStack(err)
 	}
 
 	return positions, nil
@@ -217,184 +198,23 @@ func (o *OrderBook) GetCollateralByWallet(ctx context.Context, input types.GetCo
 		}, nil
 	}
 
-	row := result.Values[0]
-	collateral, err := parseUserCollateralRow(row)
+	collaterals, err := decodePositional[types.UserCollateral](result.Values[:1], userCollateralColumns)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	return collateral, nil
+	return &collaterals[0], nil
 }
 
 // ═══════════════════════════════════════════════════════════════
 // PARSING HELPERS
 // ═══════════════════════════════════════════════════════════════
 
-// parseOrderBookEntryRow parses a row from get_order_book
-// Row format: participant_id, price, amount, last_updated, wallet_address
-func parseOrderBookEntryRow(row []any) (types.OrderBookEntry, error) {
-	if len(row) < 5 {
-		return types.OrderBookEntry{}, fmt.Errorf("invalid row: expected 5 columns, got %d", len(row))
-	}
-
-	entry := types.OrderBookEntry{}
-
-	// Column 0: participant_id (INT)
-	if err := extractIntColumn(row[0], &entry.ParticipantID, 0, "participant_id"); err != nil {
-		return entry, err
-	}
-
-	// Column 1: price (INT)
-	if err := extractIntColumn(row[1], &entry.Price, 1, "price"); err != nil {
-		return entry, err
-	}
-
-	// Column 2: amount (INT8)
-	if err := extractInt64Column(row[2], &entry.Amount, 2, "amount"); err != nil {
-		return entry, err
-	}
-
-	// Column 3: last_updated (INT8)
-	if err := extractInt64Column(row[3], &entry.LastUpdated, 3, "last_updated"); err != nil {
-		return entry, err
-	}
-
-	// Column 4: wallet_address (TEXT/BYTEA)
-	if err := extractBytesColumn(row[4], &entry.WalletAddress, 4, "wallet_address"); err != nil {
-		return entry, err
-	}
-
-	return entry, nil
-}
-
-// parseUserPositionRow parses a row from get_user_positions
-// Row format: query_id, outcome, price, amount, position_type
-func parseUserPositionRow(row []any) (types.UserPosition, error) {
-	if len(row) < 5 {
-		return types.UserPosition{}, fmt.Errorf("invalid row: expected 5 columns, got %d", len(row))
-	}
-
-	position := types.UserPosition{}
-
-	// Column 0: query_id (INT)
-	if err := extractIntColumn(row[0], &position.QueryID, 0, "query_id"); err != nil {
-		return position, err
-	}
-
-	// Column 1: outcome (BOOL)
-	if err := extractBoolColumn(row[1], &position.Outcome, 1, "outcome"); err != nil {
-		return position, err
-	}
-
-	// Column 2: price (INT)
-	if err := extractIntColumn(row[2], &position.Price, 2, "price"); err != nil {
-		return position, err
-	}
-
-	// Column 3: amount (INT8)
-	if err := extractInt64Column(row[3], &position.Amount, 3, "amount"); err != nil {
-		return position, err
-	}
-
-	// Column 4: position_type (TEXT)
-	if err := extractStringColumn(row[4], &position.PositionType, 4, "position_type"); err != nil {
-		return position, err
-	}
-
-	return position, nil
-}
-
-// parseDepthLevelRow parses a row from get_market_depth
-// Row format: price, buy_volume, sell_volume
-func parseDepthLevelRow(row []any) (types.DepthLevel, error) {
-	if len(row) < 3 {
-		return types.DepthLevel{}, fmt.Errorf("invalid row: expected 3 columns, got %d", len(row))
-	}
-
-	level := types.DepthLevel{}
-
-	// Column 0: price (INT)
-	if err := extractIntColumn(row[0], &level.Price, 0, "price"); err != nil {
-		return level, err
-	}
-
-	// Column 1: buy_volume (INT8)
-	if err := extractInt64Column(row[1], &level.BuyVolume, 1, "buy_volume"); err != nil {
-		return level, err
-	}
-
-	// Column 2: sell_volume (INT8)
-	if err := extractInt64Column(row[2], &level.SellVolume, 2, "sell_volume"); err != nil {
-		return level, err
-	}
-
-	return level, nil
-}
-
-// parseBestPricesRow parses a row from get_best_prices
-// Row format: best_bid, best_ask, spread
-func parseBestPricesRow(row []any) (*types.BestPrices, error) {
-	if len(row) < 3 {
-		return nil, fmt.Errorf("invalid row: expected 3 columns, got %d", len(row))
-	}
-
-	prices := &types.BestPrices{}
-
-	// Column 0: best_bid (INT, nullable)
-	if row[0] != nil {
-		var bid int
-		if err := extractIntColumn(row[0], &bid, 0, "best_bid"); err != nil {
-			return nil, err
-		}
-		prices.BestBid = &bid
-	}
-
-	// Column 1: best_ask (INT, nullable)
-	if row[1] != nil {
-		var ask int
-		if err := extractIntColumn(row[1], &ask, 1, "best_ask"); err != nil {
-			return nil, err
-		}
-		prices.BestAsk = &ask
-	}
-
-	// Column 2: spread (INT, nullable)
-	if row[2] != nil {
-		var spread int
-		if err := extractIntColumn(row[2], &spread, 2, "spread"); err != nil {
-			return nil, err
-		}
-		prices.Spread = &spread
-	}
-
-	return prices, nil
-}
-
-// parseUserCollateralRow parses a row from get_user_collateral
-// Row format: total_locked, buy_orders_locked, shares_value
-func parseUserCollateralRow(row []any) (*types.UserCollateral, error) {
-	if len(row) < 3 {
-		return nil, fmt.Errorf("invalid row: expected 3 columns, got %d", len(row))
-	}
-
-	collateral := &types.UserCollateral{}
-
-	// All three columns are NUMERIC(78,0) returned as string
-
-	// Column 0: total_locked (NUMERIC(78,0) as string)
-	if err := extractStringColumn(row[0], &collateral.TotalLocked, 0, "total_locked"); err != nil {
-		return nil, err
-	}
-
-	// Column 1: buy_orders_locked (NUMERIC(78,0) as string)
-	if err := extractStringColumn(row[1], &collateral.BuyOrdersLocked, 1, "buy_orders_locked"); err != nil {
-		return nil, err
-	}
-
-	// Column 2: shares_value (NUMERIC(78,0) as string)
-	if err := extractStringColumn(row[2], &collateral.SharesValue, 2, "shares_value"); err != nil {
-		return nil, err
-	}
-
-	return collateral, nil
-}
+// Column order of the order book views, as declared in the migrations.
+var (
+	orderBookEntryColumns = []string{"participant_id", "price", "amount", "last_updated", "wallet_address"}
+	userPositionColumns   = []string{"query_id", "outcome", "price", "amount", "position_type"}
+	depthLevelColumns     = []string{"price", "buy_volume", "sell_volume"}
+	bestPricesColumns     = []string{"best_bid", "best_ask", "spread"}
+	userCollateralColumns = []string{"total_locked", "buy_orders_locked", "shares_value"}
+)
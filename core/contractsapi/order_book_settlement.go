@@ -2,7 +2,6 @@ package contractsapi
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/pkg/errors"
 	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
@@ -97,13 +96,12 @@ func (o *OrderBook) GetDistributionSummary(ctx context.Context, input types.GetD
 		return nil, nil
 	}
 
-	row := result.Values[0]
-	summary, err := parseDistributionSummaryRow(row)
+	summaries, err := decodePositional[types.DistributionSummary](result.Values[:1], distributionSummaryColumns)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	return summary, nil
+	return &summaries[0], nil
 }
 
 // GetDistributionDetails retrieves per-LP reward details
@@ -122,13 +120,9 @@ func (o *OrderBook) GetDistributionDetails(ctx context.Context, input types.GetD
 		return nil, errors.WithStack(err)
 	}
 
-	var details []types.LPRewardDetail
-	for _, row := range result.Values {
-		detail, err := parseLPRewardDetailRow(row)
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
-		details = append(details, detail)
+	details, err := decodePositional[types.LPRewardDetail](result.Values, lpRewardDetailColumns)
+	if err != nil {
+		return nil, errors.WithStack(err)
 	}
 
 	return details, nil
@@ -150,13 +144,9 @@ func (o *OrderBook) GetParticipantRewardHistory(ctx context.Context, input types
 		return nil, errors.WithStack(err)
 	}
 
-	var history []types.RewardHistory
-	for _, row := range result.Values {
-		entry, err := parseRewardHistoryRow(row)
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
-		history = append(history, entry)
+	history, err := decodePositional[types.RewardHistory](result.Values, rewardHistoryColumns)
+	if err != nil {
+		return nil, errors.WithStack(err)
 	}
 
 	return history, nil
@@ -166,103 +156,9 @@ func (o *OrderBook) GetParticipantRewardHistory(ctx context.Context, input types
 // PARSING HELPERS
 // ═══════════════════════════════════════════════════════════════
 
-// parseDistributionSummaryRow parses a row from get_distribution_summary
-// Row format: distribution_id, total_fees_distributed, total_lp_count, block_count, distributed_at
-func parseDistributionSummaryRow(row []any) (*types.DistributionSummary, error) {
-	if len(row) < 5 {
-		return nil, fmt.Errorf("invalid row: expected 5 columns, got %d", len(row))
-	}
-
-	summary := &types.DistributionSummary{}
-
-	// Column 0: distribution_id (INT)
-	if err := extractIntColumn(row[0], &summary.DistributionID, 0, "distribution_id"); err != nil {
-		return nil, err
-	}
-
-	// Column 1: total_fees_distributed (NUMERIC(78,0) as string)
-	if err := extractStringColumn(row[1], &summary.TotalFeesDistributed, 1, "total_fees_distributed"); err != nil {
-		return nil, err
-	}
-
-	// Column 2: total_lp_count (INT8)
-	if err := extractInt64Column(row[2], &summary.TotalLPCount, 2, "total_lp_count"); err != nil {
-		return nil, err
-	}
-
-	// Column 3: block_count (INT8)
-	if err := extractInt64Column(row[3], &summary.BlockCount, 3, "block_count"); err != nil {
-		return nil, err
-	}
-
-	// Column 4: distributed_at (INT8)
-	if err := extractInt64Column(row[4], &summary.DistributedAt, 4, "distributed_at"); err != nil {
-		return nil, err
-	}
-
-	return summary, nil
-}
-
-// parseLPRewardDetailRow parses a row from get_distribution_details
-// Row format: wallet_address, reward_amount, total_reward_percent
-func parseLPRewardDetailRow(row []any) (types.LPRewardDetail, error) {
-	if len(row) < 3 {
-		return types.LPRewardDetail{}, fmt.Errorf("invalid row: expected 3 columns, got %d", len(row))
-	}
-
-	detail := types.LPRewardDetail{}
-
-	// Column 0: wallet_address (BYTEA)
-	if err := extractBytesColumn(row[0], &detail.WalletAddress, 0, "wallet_address"); err != nil {
-		return detail, err
-	}
-
-	// Column 1: reward_amount (NUMERIC(78,0) as string)
-	if err := extractStringColumn(row[1], &detail.RewardAmount, 1, "reward_amount"); err != nil {
-		return detail, err
-	}
-
-	// Column 2: total_reward_percent (NUMERIC(10,2) as string)
-	if err := extractStringColumn(row[2], &detail.TotalRewardPercent, 2, "total_reward_percent"); err != nil {
-		return detail, err
-	}
-
-	return detail, nil
-}
-
-// parseRewardHistoryRow parses a row from get_participant_reward_history
-// Row format: distribution_id, query_id, reward_amount, total_reward_percent, distributed_at
-func parseRewardHistoryRow(row []any) (types.RewardHistory, error) {
-	if len(row) < 5 {
-		return types.RewardHistory{}, fmt.Errorf("invalid row: expected 5 columns, got %d", len(row))
-	}
-
-	history := types.RewardHistory{}
-
-	// Column 0: distribution_id (INT)
-	if err := extractIntColumn(row[0], &history.DistributionID, 0, "distribution_id"); err != nil {
-		return history, err
-	}
-
-	// Column 1: query_id (INT)
-	if err := extractIntColumn(row[1], &history.QueryID, 1, "query_id"); err != nil {
-		return history, err
-	}
-
-	// Column 2: reward_amount (NUMERIC(78,0) as string)
-	if err := extractStringColumn(row[2], &history.RewardAmount, 2, "reward_amount"); err != nil {
-		return history, err
-	}
-
-	// Column 3: total_reward_percent (NUMERIC(10,2) as string)
-	if err := extractStringColumn(row[3], &history.TotalRewardPercent, 3, "total_reward_percent"); err != nil {
-		return history, err
-	}
-
-	// Column 4: distributed_at (INT8)
-	if err := extractInt64Column(row[4], &history.DistributedAt, 4, "distributed_at"); err != nil {
-		return history, err
-	}
-
-	return history, nil
-}
+// Column order of the reward views, as declared in the migrations.
+var (
+	distributionSummaryColumns = []string{"distribution_id", "total_fees_distributed", "total_lp_count", "block_count", "distributed_at"}
+	lpRewardDetailColumns      = []string{"wallet_address", "reward_amount", "total_reward_percent"}
+	rewardHistoryColumns       = []string{"distribution_id", "query_id", "reward_amount", "total_reward_percent", "distributed_at"}
+)
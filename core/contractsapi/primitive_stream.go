@@ -57,7 +57,7 @@ func (p *PrimitiveAction) InsertRecord(ctx context.Context, input types.InsertRe
 		return kwiltypes.Hash{}, errors.WithStack(err)
 	}
 
-	return p._client.Execute(ctx, "", "insert_record", [][]any{{
+	return p.execute(ctx, "insert_record", [][]any{{
 		input.DataProvider,
 		input.StreamId,
 		input.EventTime,
@@ -85,7 +85,7 @@ func (p *PrimitiveAction) InsertRecords(ctx context.Context, inputs []types.Inse
 		values = append(values, valueNumeric)
 	}
 
-	return p._client.Execute(ctx, "", "insert_records", [][]any{{
+	return p.execute(ctx, "insert_records", [][]any{{
 		dataProviders,
 		streamIds,
 		eventTimes,
@@ -3,9 +3,11 @@ package contractsapi
 import (
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/trufnetwork/sdk-go/core/types"
 )
 
 // QueryComponentsABI defines the ABI type for encoding query_components tuple
@@ -86,6 +88,21 @@ func EncodeQueryComponents(dataProvider, streamID, actionID string, args []byte)
 	return encoded, nil
 }
 
+// BuildQueryComponents checks args against the argument schema registered
+// for action in types.ActionRegistry, then encodes them together with the
+// query components tuple. Actions must be registered (see
+// types.RegisterAction); use EncodeQueryComponents to skip the check.
+func BuildQueryComponents(dataProvider, streamID, action string, args []any) ([]byte, error) {
+	if err := types.ValidateActionArgs(action, args); err != nil {
+		return nil, err
+	}
+	argsBytes, err := EncodeActionArgs(args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode action args: %w", err)
+	}
+	return EncodeQueryComponents(dataProvider, streamID, action, argsBytes)
+}
+
 // DecodeQueryComponents decodes ABI-encoded query_components back to its parts
 //
 // Returns:
@@ -196,32 +213,29 @@ func DecodeMarketData(encoded []byte) (*MarketData, error) {
 		return fmt.Sprint(arg)
 	}
 
-	// Map action_id to market type and thresholds
+	// Map action_id to market type
 	// Based on 040-binary-attestation-actions.sql
 	switch actionID {
 	case "price_above_threshold":
 		market.Type = "above"
-		if len(args) >= 4 {
-			market.Thresholds = append(market.Thresholds, formatArg(args[3]))
-		}
 	case "price_below_threshold":
 		market.Type = "below"
-		if len(args) >= 4 {
-			market.Thresholds = append(market.Thresholds, formatArg(args[3]))
-		}
 	case "value_in_range":
 		market.Type = "between"
-		if len(args) >= 5 {
-			market.Thresholds = append(market.Thresholds, formatArg(args[3]), formatArg(args[4]))
-		}
 	case "value_equals":
 		market.Type = "equals"
-		if len(args) >= 5 {
-			market.Thresholds = append(market.Thresholds, formatArg(args[3]), formatArg(args[4]))
-		}
 	default:
 		market.Type = "unknown"
 	}
 
+	// Thresholds are the numeric arguments of the action's registered schema
+	if info := types.GetActionInfo(actionID); info != nil {
+		for i, spec := range info.Args {
+			if i < len(args) && strings.HasPrefix(strings.ToLower(spec.Type), "numeric") {
+				market.Thresholds = append(market.Thresholds, formatArg(args[i]))
+			}
+		}
+	}
+
 	return market, nil
 }
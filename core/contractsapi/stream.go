@@ -6,7 +6,9 @@ import (
 	"github.com/trufnetwork/kwil-db/core/gatewayclient"
 
 	"github.com/pkg/errors"
+	client "github.com/trufnetwork/kwil-db/core/client/types"
 	kwilTypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/tnerrors"
 	"github.com/trufnetwork/sdk-go/core/types"
 )
 
@@ -80,14 +82,15 @@ func (s *Action) GetStreamOwner(ctx context.Context, locator types.StreamLocator
 		return nil, errors.New("no owner found (is the stream initialized?)")
 	}
 
-	return hex.DecodeString(values[0].ValueRef)
+	return hex.DecodeString(stringOrEmpty(values[0].ValueRef))
 }
 
 // CheckStreamExists checks if the stream exists
 func (s *Action) CheckStreamExists(ctx context.Context, input types.CheckStreamExistsInput) error {
-	result, err := s._client.Call(ctx, "", "stream_exists", []any{input.DataProvider, input.StreamId})
+	args := []any{input.DataProvider, input.StreamId}
+	result, err := s._client.Call(ctx, "", "stream_exists", args)
 	if err != nil {
-		return errors.WithStack(err)
+		return tnerrors.Wrap(err, tnerrors.OpCall, "stream_exists", tnerrors.WithArgs(args))
 	}
 
 	if len(result.QueryResult.Values) == 0 || result.QueryResult.Values[0][0] == false {
@@ -97,13 +100,12 @@ func (s *Action) CheckStreamExists(ctx context.Context, input types.CheckStreamE
 	return nil
 }
 
+// call performs a read-only call. Errors, including the action's own error,
+// are returned as *tnerrors.Error.
 func (s *Action) call(ctx context.Context, method string, args []any) (*kwilTypes.QueryResult, error) {
-	result, err := s._client.Call(ctx, "", method, args)
-	if err != nil || result.Error != nil {
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
-		return nil, errors.New(*result.Error)
+	result, err := s.callWithLogs(ctx, method, args)
+	if err != nil {
+		return nil, err
 	}
 
 	return result.QueryResult, nil
@@ -113,15 +115,28 @@ func (s *Action) call(ctx context.Context, method string, args []any) (*kwilType
 func (s *Action) callWithLogs(ctx context.Context, method string, args []any) (*kwilTypes.CallResult, error) {
 	result, err := s._client.Call(ctx, "", method, args)
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, tnerrors.Wrap(err, tnerrors.OpCall, method, tnerrors.WithArgs(args))
+	}
+	if result == nil {
+		return nil, tnerrors.New(tnerrors.OpCall, method, "returned nil result", tnerrors.WithArgs(args))
 	}
 	if result.Error != nil {
-		return nil, errors.New(*result.Error)
+		return nil, tnerrors.New(tnerrors.OpCall, method, *result.Error, tnerrors.WithArgs(args))
 	}
 
 	return result, nil
 }
 
-func (s *Action) execute(ctx context.Context, method string, args [][]any) (kwilTypes.Hash, error) {
-	return s._client.Execute(ctx, "", method, args)
+// execute broadcasts a write action. The stream of the first argument tuple
+// is recorded on the returned *tnerrors.Error.
+func (s *Action) execute(ctx context.Context, method string, args [][]any, opts ...client.TxOpt) (kwilTypes.Hash, error) {
+	hash, err := s._client.Execute(ctx, "", method, args, opts...)
+	if err != nil {
+		var opts []tnerrors.Option
+		if len(args) > 0 {
+			opts = append(opts, tnerrors.WithArgs(args[0]))
+		}
+		return hash, tnerrors.Wrap(err, tnerrors.OpExecute, method, opts...)
+	}
+	return hash, nil
 }
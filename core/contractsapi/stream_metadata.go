@@ -0,0 +1,179 @@
+package contractsapi
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/pkg/errors"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+// GetMetadata returns the active rows stored under key, newest first.
+func (s *Action) GetMetadata(ctx context.Context, locator types.StreamLocator, key types.MetadataKey) ([]types.MetadataEntry, error) {
+	rows, err := s.getMetadata(ctx, getMetadataParams{
+		Stream:  locator,
+		Key:     key,
+		OrderBy: "created_at DESC",
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	entries := make([]types.MetadataEntry, 0, len(rows))
+	for _, row := range rows {
+		entry, err := metadataEntryFromRow(key, row)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// ListMetadata returns the active rows of every protocol key and of extraKeys,
+// grouped by key in the order they were queried.
+func (s *Action) ListMetadata(ctx context.Context, locator types.StreamLocator, extraKeys ...types.MetadataKey) ([]types.MetadataEntry, error) {
+	keys := append([]types.MetadataKey{}, types.KnownMetadataKeys...)
+	for _, k := range extraKeys {
+		if !k.IsKnown() {
+			keys = append(keys, k)
+		}
+	}
+
+	var entries []types.MetadataEntry
+	for _, key := range keys {
+		keyEntries, err := s.GetMetadata(ctx, locator, key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "list metadata %s", key)
+		}
+		entries = append(entries, keyEntries...)
+	}
+	return entries, nil
+}
+
+// SetMetadata inserts a metadata row for a user-defined key. Protocol keys
+// are rejected; they have dedicated setters.
+func (s *Action) SetMetadata(ctx context.Context, input types.SetMetadataInput) (kwiltypes.Hash, error) {
+	if err := checkUserMetadataKey(input.Key); err != nil {
+		return kwiltypes.Hash{}, err
+	}
+
+	valType, err := input.ResolveType()
+	if err != nil {
+		return kwiltypes.Hash{}, errors.WithStack(err)
+	}
+	if err := checkMetadataValueType(valType, input.Value); err != nil {
+		return kwiltypes.Hash{}, err
+	}
+
+	return s.insertMetadata(ctx, InsertMetadataInput{
+		Stream: input.Stream,
+		Key:    input.Key,
+		Value:  input.Value,
+		Type:   valType,
+	})
+}
+
+// DisableMetadata disables a row after confirming it is an active row of input.Key,
+// so the reserved-key guard cannot be bypassed with a bare row id.
+func (s *Action) DisableMetadata(ctx context.Context, input types.DisableMetadataInput) (kwiltypes.Hash, error) {
+	if err := checkUserMetadataKey(input.Key); err != nil {
+		return kwiltypes.Hash{}, err
+	}
+	rowId, err := kwiltypes.ParseUUID(input.RowId)
+	if err != nil {
+		return kwiltypes.Hash{}, errors.Wrap(err, "invalid row id")
+	}
+
+	entries, err := s.GetMetadata(ctx, input.Stream, input.Key)
+	if err != nil {
+		return kwiltypes.Hash{}, errors.WithStack(err)
+	}
+	found := false
+	for _, e := range entries {
+		if existing, err := kwiltypes.ParseUUID(e.RowId); err == nil && *existing == *rowId {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return kwiltypes.Hash{}, MetadataValueNotFound
+	}
+
+	return s.disableMetadata(ctx, DisableMetadataInput{
+		Stream: input.Stream,
+		RowId:  rowId,
+	})
+}
+
+// checkUserMetadataKey rejects empty keys and the protocol keys, which the
+// generic SetMetadata and DisableMetadata must not touch.
+func checkUserMetadataKey(key types.MetadataKey) error {
+	if key == "" {
+		return errors.New("metadata key is required")
+	}
+	if key.IsKnown() {
+		return errors.Wrapf(ErrReservedMetadataKey, "%s", key.String())
+	}
+	return nil
+}
+
+func metadataEntryFromRow(key types.MetadataKey, row getMetadataResult) (types.MetadataEntry, error) {
+	entry := types.MetadataEntry{
+		RowId:    row.RowId,
+		Key:      key,
+		ValueF:   row.ValueF,
+		ValueS:   row.ValueS,
+		ValueRef: row.ValueRef,
+	}
+	if row.CreatedAt != "" {
+		createdAt, err := strconv.ParseInt(row.CreatedAt, 10, 64)
+		if err != nil {
+			return types.MetadataEntry{}, errors.Wrap(err, "created_at")
+		}
+		entry.CreatedAt = createdAt
+	}
+	if row.ValueI != nil {
+		v, err := strconv.ParseInt(*row.ValueI, 10, 64)
+		if err != nil {
+			return types.MetadataEntry{}, errors.Wrap(err, "value_i")
+		}
+		entry.ValueI = &v
+	}
+	if row.ValueB != nil {
+		v := *row.ValueB == "true" || *row.ValueB == "t"
+		entry.ValueB = &v
+	}
+
+	switch {
+	case entry.ValueI != nil:
+		entry.Type = types.MetadataTypeInt
+	case entry.ValueF != nil:
+		entry.Type = types.MetadataTypeFloat
+	case entry.ValueB != nil:
+		entry.Type = types.MetadataTypeBool
+	case entry.ValueRef != nil:
+		entry.Type = types.MetadataTypeRef
+	default:
+		entry.Type = types.MetadataTypeString
+	}
+	return entry, nil
+}
+
+// checkMetadataValueType rejects values whose Go type cannot be rendered as
+// valType, which StringFromValue would otherwise panic on.
+func checkMetadataValueType(valType types.MetadataType, value types.MetadataValue) error {
+	actual, err := value.Type()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	expected := valType
+	if expected == types.MetadataTypeRef {
+		expected = types.MetadataTypeString
+	}
+	if actual != expected {
+		return errors.Errorf("metadata value of type %s cannot be stored as %s", actual, valType)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package contractsapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/types"
+	"github.com/trufnetwork/sdk-go/core/util"
+)
+
+func testMetadataLocator(t *testing.T) types.StreamLocator {
+	t.Helper()
+	streamId, err := util.NewStreamId("st00000000000000000000000000aabb")
+	require.NoError(t, err)
+	dp, err := util.NewEthereumAddressFromString("0x000000000000000000000000000000000000a110")
+	require.NoError(t, err)
+	return types.StreamLocator{StreamId: *streamId, DataProvider: dp}
+}
+
+// Protocol keys are only writable through their dedicated setters, such as
+// SetAllowZeros or SetReadVisibility.
+func TestSetMetadata_RejectsKnownKeys(t *testing.T) {
+	action := &Action{}
+	locator := testMetadataLocator(t)
+
+	for _, key := range types.KnownMetadataKeys {
+		_, err := action.SetMetadata(context.Background(), types.SetMetadataInput{
+			Stream: locator,
+			Key:    key,
+			Value:  types.NewMetadataValue(1),
+		})
+		require.True(t, errors.Is(err, ErrReservedMetadataKey), key)
+
+		_, err = action.DisableMetadata(context.Background(), types.DisableMetadataInput{
+			Stream: locator,
+			Key:    key,
+			RowId:  "0b6e4d3c-1f7a-4c59-9a3e-2b8d6f0c1e55",
+		})
+		require.True(t, errors.Is(err, ErrReservedMetadataKey), key)
+	}
+
+	_, err := action.SetMetadata(context.Background(), types.SetMetadataInput{Stream: locator})
+	assert.ErrorContains(t, err, "metadata key is required")
+}
+
+func TestSetMetadata_RejectsMismatchedValueType(t *testing.T) {
+	action := &Action{}
+
+	_, err := action.SetMetadata(context.Background(), types.SetMetadataInput{
+		Stream: testMetadataLocator(t),
+		Key:    "release_count",
+		Value:  types.NewMetadataValue("yesterday"),
+		Type:   types.MetadataTypeInt,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot be stored as int")
+}
+
+func TestMetadataRowDecoding(t *testing.T) {
+	result := &kwiltypes.QueryResult{
+		ColumnNames: []string{"row_id", "value_i", "value_f", "value_b", "value_s", "value_ref", "created_at"},
+		Values: [][]any{
+			{"0b6e4d3c-1f7a-4c59-9a3e-2b8d6f0c1e55", nil, nil, true, nil, nil, int64(12)},
+			{"1b6e4d3c-1f7a-4c59-9a3e-2b8d6f0c1e55", int64(42), nil, nil, nil, nil, int64(10)},
+			{"2b6e4d3c-1f7a-4c59-9a3e-2b8d6f0c1e55", nil, nil, nil, "CPI", nil, int64(9)},
+		},
+	}
+	rows, err := DecodeCallResult[getMetadataResult](result)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+
+	entry, err := metadataEntryFromRow("flag", rows[0])
+	require.NoError(t, err)
+	assert.Equal(t, types.MetadataTypeBool, entry.Type)
+	v, err := types.MetadataEntryValue[bool](entry)
+	require.NoError(t, err)
+	assert.True(t, v)
+	assert.Equal(t, int64(12), entry.CreatedAt)
+
+	entry, err = metadataEntryFromRow("count", rows[1])
+	require.NoError(t, err)
+	assert.Equal(t, types.MetadataTypeInt, entry.Type)
+	n, err := types.MetadataEntryValue[int64](entry)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), n)
+	_, err = types.MetadataEntryValue[string](entry)
+	assert.Error(t, err)
+
+	entry, err = metadataEntryFromRow("unit", rows[2])
+	require.NoError(t, err)
+	assert.Equal(t, types.MetadataTypeString, entry.Type)
+	assert.Equal(t, "CPI", entry.String())
+}
@@ -24,14 +24,17 @@ type getMetadataParams struct {
 	Offset  int
 	OrderBy string
 }
+
+// getMetadataResult is a get_metadata row. Only the value column of the row's
+// type is populated; the others are NULL.
 type getMetadataResult struct {
-	RowId     string `json:"row_id"`
-	ValueI    string `json:"value_i"`
-	ValueF    string `json:"value_f"`
-	ValueB    string `json:"value_b"`
-	ValueS    string `json:"value_s"`
-	ValueRef  string `json:"value_ref"`
-	CreatedAt string `json:"created_at"`
+	RowId     string  `json:"row_id"`
+	ValueI    *string `json:"value_i"`
+	ValueF    *string `json:"value_f"`
+	ValueB    *string `json:"value_b"`
+	ValueS    *string `json:"value_s"`
+	ValueRef  *string `json:"value_ref"`
+	CreatedAt string  `json:"created_at"`
 }
 
 // getValueByKey returns the value of the metadata by its key
@@ -43,18 +46,25 @@ func (g getMetadataResult) getValueByKey(t types.MetadataKey) (string, error) {
 
 	switch metadataType {
 	case types.MetadataTypeInt:
-		return g.ValueI, nil
+		return stringOrEmpty(g.ValueI), nil
 	case types.MetadataTypeBool:
-		return g.ValueB, nil
+		return stringOrEmpty(g.ValueB), nil
 	case types.MetadataTypeString:
-		return g.ValueS, nil
+		return stringOrEmpty(g.ValueS), nil
 	case types.MetadataTypeRef:
-		return g.ValueRef, nil
+		return stringOrEmpty(g.ValueRef), nil
 	default:
 		return "", errors.New("unsupported metadata type")
 	}
 }
 
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
 // addArgOrNull adds a new argument to the list of arguments
 // this helps us making it NULL if it's equal to its zero value
 // The caveat is that we won't be able to pass the zero value of the type. Issues with this?
@@ -93,16 +103,19 @@ type InsertMetadataInput struct {
 	Stream types.StreamLocator
 	Key    types.MetadataKey
 	Value  types.MetadataValue
+	// optional. Defaults to Key.GetType()
+	Type types.MetadataType
 }
 
 // ErrReservedMetadataKey is returned when a caller tries to route a
-// reserved metadata key (currently only AllowZerosKey) through the
-// generic insert/disable helpers. Reserved keys have dedicated mutators
-// (e.g. SetAllowZeros) that own the disable-then-insert sequence; the
-// generic path would let two parallel rows coexist and break the
-// "latest non-disabled wins" semantics that the node-side actions rely
-// on. The node enforces this too — this is a friendlier client-side
-// error for SDK developers.
+// reserved metadata key through the generic insert/disable helpers:
+// AllowZerosKey for the private helpers, and every protocol key
+// (types.MetadataKey.IsKnown) for SetMetadata and DisableMetadata.
+// Reserved keys have dedicated mutators (e.g. SetAllowZeros) that own
+// the disable-then-insert sequence; the generic path would let two
+// parallel rows coexist and break the "latest non-disabled wins"
+// semantics that the node-side actions rely on. The node enforces this
+// too — this is a friendlier client-side error for SDK developers.
 var ErrReservedMetadataKey = errors.New("reserved metadata key: use the dedicated mutator (e.g. SetAllowZeros)")
 
 func (s *Action) insertMetadata(ctx context.Context, input InsertMetadataInput) (kwiltypes.Hash, error) {
@@ -110,7 +123,10 @@ func (s *Action) insertMetadata(ctx context.Context, input InsertMetadataInput)
 		return kwiltypes.Hash{}, errors.Wrapf(ErrReservedMetadataKey, "%s", input.Key.String())
 	}
 
-	valType := input.Key.GetType()
+	valType := input.Type
+	if valType == "" {
+		valType = input.Key.GetType()
+	}
 	valStr, err := valType.StringFromValue(input.Value)
 	if err != nil {
 		return kwiltypes.Hash{}, errors.WithStack(err)
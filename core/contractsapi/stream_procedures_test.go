@@ -1,9 +1,17 @@
 package contractsapi
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	clientTypes "github.com/trufnetwork/kwil-db/core/client/types"
+	"github.com/trufnetwork/kwil-db/core/gatewayclient"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/tnerrors"
+	"github.com/trufnetwork/sdk-go/core/types"
 )
 
 func TestParseLogsForMetadata(t *testing.T) {
@@ -24,3 +32,31 @@ func TestParseLogsForMetadata(t *testing.T) {
 		assert.Empty(t, result)
 	})
 }
+
+func TestGetRecord_ClassifiesActionError(t *testing.T) {
+	srv := newLocalRPCServer()
+	defer srv.close()
+	srv.setResult("user.health", kwiltypes.Health{ChainInfo: kwiltypes.ChainInfo{ChainID: "tn-test"}, Healthy: true})
+	message := "stream does not exist: data_provider=0x1111111111111111111111111111111111111111 stream_id=stmissing00000000000000000000000"
+	srv.setResult("user.call", kwiltypes.CallResult{Error: &message})
+
+	gw, err := gatewayclient.NewClient(context.Background(), srv.baseURL(), &gatewayclient.GatewayOptions{
+		Options: clientTypes.Options{ChainID: "tn-test", Silence: true},
+	})
+	require.NoError(t, err)
+	actions, err := LoadAction(NewActionOptions{Client: gw})
+	require.NoError(t, err)
+
+	_, err = actions.GetRecord(context.Background(), types.GetRecordInput{
+		DataProvider: "0x1111111111111111111111111111111111111111",
+		StreamId:     "stmissing00000000000000000000000",
+	})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, tnerrors.ErrStreamNotFound)
+	var tnErr *tnerrors.Error
+	require.True(t, errors.As(err, &tnErr))
+	assert.Equal(t, tnerrors.OpCall, tnErr.Op)
+	assert.Equal(t, "get_record", tnErr.Action)
+	assert.Equal(t, "stmissing00000000000000000000000", tnErr.StreamId)
+	assert.Equal(t, message, tnErr.Message)
+}
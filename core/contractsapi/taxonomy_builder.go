@@ -0,0 +1,368 @@
+package contractsapi
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/trufnetwork/sdk-go/core/types"
+	"github.com/trufnetwork/sdk-go/core/util"
+)
+
+// TaxonomyProblemCode identifies the kind of issue found while validating a taxonomy.
+type TaxonomyProblemCode string
+
+const (
+	TaxonomyProblemNoChildren         TaxonomyProblemCode = "no_children"
+	TaxonomyProblemDuplicateChild     TaxonomyProblemCode = "duplicate_child"
+	TaxonomyProblemSelfReference      TaxonomyProblemCode = "self_reference"
+	TaxonomyProblemNonPositiveWeight  TaxonomyProblemCode = "non_positive_weight"
+	TaxonomyProblemChildNotFound      TaxonomyProblemCode = "child_not_found"
+	TaxonomyProblemChildNotReadable   TaxonomyProblemCode = "child_not_readable"
+	TaxonomyProblemChildNotComposable TaxonomyProblemCode = "child_not_composable"
+	TaxonomyProblemStartDateTooEarly  TaxonomyProblemCode = "start_date_too_early"
+)
+
+// TaxonomyProblem describes a single reason a taxonomy would be rejected by the node.
+// Child is nil for problems that concern the taxonomy as a whole.
+type TaxonomyProblem struct {
+	Code    TaxonomyProblemCode
+	Child   *types.StreamLocator
+	Message string
+}
+
+func (p TaxonomyProblem) String() string {
+	if p.Child == nil {
+		return fmt.Sprintf("%s: %s", p.Code, p.Message)
+	}
+	return fmt.Sprintf("%s (%s/%s): %s", p.Code, p.Child.DataProvider.Address(), p.Child.StreamId.String(), p.Message)
+}
+
+// TaxonomyValidationError is returned by TaxonomyBuilder when one or more problems
+// were found. All problems are collected rather than stopping at the first one, so a
+// caller can fix everything before paying for a transaction.
+type TaxonomyValidationError struct {
+	Problems []TaxonomyProblem
+}
+
+func (e *TaxonomyValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		msgs = append(msgs, p.String())
+	}
+	return fmt.Sprintf("invalid taxonomy (%d problem(s)): %s", len(e.Problems), strings.Join(msgs, "; "))
+}
+
+// HasProblem reports whether a problem with the given code was found.
+func (e *TaxonomyValidationError) HasProblem(code TaxonomyProblemCode) bool {
+	for _, p := range e.Problems {
+		if p.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// TaxonomyBuilder assembles a types.Taxonomy and checks it before it is submitted with
+// InsertTaxonomy.
+//
+// Build performs the local checks only (children, weights, normalization). Validate
+// additionally queries the network to confirm every child exists, can be read by the
+// parent's data provider, allows the parent to compose it, and that the start date is
+// later than the latest taxonomy version already registered for the parent.
+//
+// Example:
+//
+//	taxonomy, err := contractsapi.NewTaxonomyBuilder(parent).
+//	    AddChild(childA, 3).
+//	    AddChild(childB, 1).
+//	    NormalizeWeights().
+//	    WithStartDate(startDate).
+//	    Validate(ctx, composedActions)
+//	var verr *contractsapi.TaxonomyValidationError
+//	if errors.As(err, &verr) {
+//	    for _, p := range verr.Problems { ... }
+//	}
+type TaxonomyBuilder struct {
+	parent    types.StreamLocator
+	items     []types.TaxonomyItem
+	startDate *int
+	normalize bool
+}
+
+// NewTaxonomyBuilder starts a taxonomy for the given composed parent stream.
+func NewTaxonomyBuilder(parent types.StreamLocator) *TaxonomyBuilder {
+	return &TaxonomyBuilder{parent: parent}
+}
+
+// AddChild appends a child stream with its weight.
+func (b *TaxonomyBuilder) AddChild(child types.StreamLocator, weight float64) *TaxonomyBuilder {
+	b.items = append(b.items, types.TaxonomyItem{ChildStream: child, Weight: weight})
+	return b
+}
+
+// AddChildren appends several children at once.
+func (b *TaxonomyBuilder) AddChildren(items ...types.TaxonomyItem) *TaxonomyBuilder {
+	b.items = append(b.items, items...)
+	return b
+}
+
+// WithStartDate sets the Unix timestamp from which the taxonomy takes effect.
+func (b *TaxonomyBuilder) WithStartDate(startDate int) *TaxonomyBuilder {
+	b.startDate = &startDate
+	return b
+}
+
+// NormalizeWeights rescales the weights so they sum to 1 when the taxonomy is built.
+func (b *TaxonomyBuilder) NormalizeWeights() *TaxonomyBuilder {
+	b.normalize = true
+	return b
+}
+
+// Build runs the local checks and returns the resulting taxonomy. It does not
+// touch the network; use Validate for the full set of checks.
+func (b *TaxonomyBuilder) Build() (types.Taxonomy, error) {
+	problems := b.localProblems()
+	if len(problems) > 0 {
+		return types.Taxonomy{}, &TaxonomyValidationError{Problems: problems}
+	}
+	return b.taxonomy(), nil
+}
+
+// Validate runs the local checks followed by the network checks, using actions to
+// query the children's existence and permissions and the parent's current taxonomy.
+// Transport failures are returned as-is; rule violations are reported together as a
+// *TaxonomyValidationError.
+func (b *TaxonomyBuilder) Validate(ctx context.Context, actions types.IComposedAction) (types.Taxonomy, error) {
+	if actions == nil {
+		return types.Taxonomy{}, errors.New("composed actions are required to validate a taxonomy")
+	}
+
+	problems := b.localProblems()
+
+	// deduplicate before hitting the network; duplicates are already reported
+	children := make([]types.StreamLocator, 0, len(b.items))
+	seen := make(map[string]bool, len(b.items))
+	for _, item := range b.items {
+		key := locatorKey(item.ChildStream)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		children = append(children, item.ChildStream)
+	}
+
+	if len(children) > 0 {
+		missing, err := actions.BatchFilterStreamsByExistence(ctx, children, false)
+		if err != nil {
+			return types.Taxonomy{}, errors.Wrap(err, "failed to check child stream existence")
+		}
+		missingSet := make(map[string]bool, len(missing))
+		for _, m := range missing {
+			missingSet[locatorKey(m)] = true
+		}
+
+		for i := range children {
+			child := children[i]
+			if missingSet[locatorKey(child)] {
+				problems = append(problems, TaxonomyProblem{
+					Code:    TaxonomyProblemChildNotFound,
+					Child:   &child,
+					Message: "child stream does not exist",
+				})
+				continue
+			}
+
+			// streams of the same data provider are always readable and composable by it
+			if sameAddress(child.DataProvider, b.parent.DataProvider) {
+				continue
+			}
+
+			childProblems, err := b.permissionProblems(ctx, actions, child)
+			if err != nil {
+				return types.Taxonomy{}, err
+			}
+			problems = append(problems, childProblems...)
+		}
+	}
+
+	startProblem, err := b.startDateProblem(ctx, actions)
+	if err != nil {
+		return types.Taxonomy{}, err
+	}
+	if startProblem != nil {
+		problems = append(problems, *startProblem)
+	}
+
+	if len(problems) > 0 {
+		return types.Taxonomy{}, &TaxonomyValidationError{Problems: problems}
+	}
+	return b.taxonomy(), nil
+}
+
+// localProblems checks everything that can be decided without the network.
+func (b *TaxonomyBuilder) localProblems() []TaxonomyProblem {
+	var problems []TaxonomyProblem
+	if len(b.items) == 0 {
+		problems = append(problems, TaxonomyProblem{
+			Code:    TaxonomyProblemNoChildren,
+			Message: "taxonomy must have at least one child stream",
+		})
+	}
+
+	seen := make(map[string]bool, len(b.items))
+	for i := range b.items {
+		item := b.items[i]
+		child := item.ChildStream
+		key := locatorKey(child)
+		if seen[key] {
+			problems = append(problems, TaxonomyProblem{
+				Code:    TaxonomyProblemDuplicateChild,
+				Child:   &child,
+				Message: "child stream is listed more than once",
+			})
+		}
+		seen[key] = true
+
+		if key == locatorKey(b.parent) {
+			problems = append(problems, TaxonomyProblem{
+				Code:    TaxonomyProblemSelfReference,
+				Child:   &child,
+				Message: "a stream cannot be its own child",
+			})
+		}
+
+		if !(item.Weight > 0) || math.IsInf(item.Weight, 0) {
+			problems = append(problems, TaxonomyProblem{
+				Code:    TaxonomyProblemNonPositiveWeight,
+				Child:   &child,
+				Message: fmt.Sprintf("weight must be a positive finite number, got %v", item.Weight),
+			})
+		}
+	}
+	return problems
+}
+
+// permissionProblems checks that the parent's data provider may read the child
+// and that the parent stream may compose it.
+func (b *TaxonomyBuilder) permissionProblems(ctx context.Context, actions types.IComposedAction, child types.StreamLocator) ([]TaxonomyProblem, error) {
+	var problems []TaxonomyProblem
+
+	readVisibility, err := actions.GetReadVisibility(ctx, child)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get read visibility of %s", child.StreamId.String())
+	}
+	if readVisibility != nil && *readVisibility == util.PrivateVisibility {
+		wallets, err := actions.GetAllowedReadWallets(ctx, child)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get allowed read wallets of %s", child.StreamId.String())
+		}
+		allowed := false
+		for _, w := range wallets {
+			if sameAddress(w, b.parent.DataProvider) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			problems = append(problems, TaxonomyProblem{
+				Code:    TaxonomyProblemChildNotReadable,
+				Child:   &child,
+				Message: fmt.Sprintf("child stream is private and %s is not an allowed reader", b.parent.DataProvider.Address()),
+			})
+		}
+	}
+
+	composeVisibility, err := actions.GetComposeVisibility(ctx, child)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get compose visibility of %s", child.StreamId.String())
+	}
+	if composeVisibility != nil && *composeVisibility == util.PrivateVisibility {
+		streams, err := actions.GetAllowedComposeStreams(ctx, child)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get allowed compose streams of %s", child.StreamId.String())
+		}
+		allowed := false
+		for _, s := range streams {
+			if locatorKey(s) == locatorKey(b.parent) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			problems = append(problems, TaxonomyProblem{
+				Code:    TaxonomyProblemChildNotComposable,
+				Child:   &child,
+				Message: fmt.Sprintf("child stream is private to composition and does not allow %s", b.parent.StreamId.String()),
+			})
+		}
+	}
+
+	return problems, nil
+}
+
+// startDateProblem compares the start date against the latest taxonomy version of
+// the parent. A parent without any taxonomy accepts any start date.
+func (b *TaxonomyBuilder) startDateProblem(ctx context.Context, actions types.IComposedAction) (*TaxonomyProblem, error) {
+	latest, err := actions.DescribeTaxonomies(ctx, types.DescribeTaxonomiesParams{
+		Stream:        b.parent,
+		LatestVersion: true,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to describe current taxonomy of parent stream")
+	}
+	if len(latest.TaxonomyItems) == 0 || latest.StartDate == nil {
+		return nil, nil
+	}
+
+	// InsertTaxonomy submits 0 when no start date is set
+	startDate := 0
+	if b.startDate != nil {
+		startDate = *b.startDate
+	}
+	if startDate <= *latest.StartDate {
+		return &TaxonomyProblem{
+			Code:    TaxonomyProblemStartDateTooEarly,
+			Message: fmt.Sprintf("start date %d must be later than the latest taxonomy version start date %d", startDate, *latest.StartDate),
+		}, nil
+	}
+	return nil, nil
+}
+
+// taxonomy materializes the builder state, normalizing weights if requested.
+func (b *TaxonomyBuilder) taxonomy() types.Taxonomy {
+	items := make([]types.TaxonomyItem, len(b.items))
+	copy(items, b.items)
+
+	if b.normalize {
+		var total float64
+		for _, item := range items {
+			total += item.Weight
+		}
+		for i := range items {
+			items[i].Weight = items[i].Weight / total
+		}
+	}
+
+	var startDate *int
+	if b.startDate != nil {
+		sd := *b.startDate
+		startDate = &sd
+	}
+
+	return types.Taxonomy{
+		ParentStream:  b.parent,
+		TaxonomyItems: items,
+		StartDate:     startDate,
+	}
+}
+
+func locatorKey(locator types.StreamLocator) string {
+	return strings.ToLower(locator.DataProvider.Address()) + "/" + locator.StreamId.String()
+}
+
+func sameAddress(a, b util.EthereumAddress) bool {
+	return strings.EqualFold(a.Address(), b.Address())
+}
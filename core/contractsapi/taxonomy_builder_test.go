@@ -0,0 +1,190 @@
+package contractsapi_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trufnetwork/sdk-go/core/contractsapi"
+	sdktypes "github.com/trufnetwork/sdk-go/core/types"
+	"github.com/trufnetwork/sdk-go/core/util"
+)
+
+// mockComposedActions stubs the read methods TaxonomyBuilder.Validate relies on.
+// Any other IComposedAction method panics through the nil embedded interface.
+type mockComposedActions struct {
+	sdktypes.IComposedAction
+
+	missing           []sdktypes.StreamLocator
+	readVisibility    map[string]util.VisibilityEnum
+	readWallets       map[string][]util.EthereumAddress
+	composeVisibility map[string]util.VisibilityEnum
+	composeStreams    map[string][]sdktypes.StreamLocator
+	latest            sdktypes.Taxonomy
+}
+
+func (m *mockComposedActions) BatchFilterStreamsByExistence(_ context.Context, _ []sdktypes.StreamLocator, returnExisting bool) ([]sdktypes.StreamLocator, error) {
+	if returnExisting {
+		return nil, errors.New("unexpected returnExisting=true")
+	}
+	return m.missing, nil
+}
+
+func (m *mockComposedActions) GetReadVisibility(_ context.Context, locator sdktypes.StreamLocator) (*util.VisibilityEnum, error) {
+	v := m.readVisibility[locator.StreamId.String()]
+	return &v, nil
+}
+
+func (m *mockComposedActions) GetAllowedReadWallets(_ context.Context, locator sdktypes.StreamLocator) ([]util.EthereumAddress, error) {
+	return m.readWallets[locator.StreamId.String()], nil
+}
+
+func (m *mockComposedActions) GetComposeVisibility(_ context.Context, locator sdktypes.StreamLocator) (*util.VisibilityEnum, error) {
+	v := m.composeVisibility[locator.StreamId.String()]
+	return &v, nil
+}
+
+func (m *mockComposedActions) GetAllowedComposeStreams(_ context.Context, locator sdktypes.StreamLocator) ([]sdktypes.StreamLocator, error) {
+	return m.composeStreams[locator.StreamId.String()], nil
+}
+
+func (m *mockComposedActions) DescribeTaxonomies(_ context.Context, _ sdktypes.DescribeTaxonomiesParams) (sdktypes.Taxonomy, error) {
+	return m.latest, nil
+}
+
+func testLocator(t *testing.T, dp string, name string) sdktypes.StreamLocator {
+	t.Helper()
+	addr, err := util.NewEthereumAddressFromString(dp)
+	require.NoError(t, err)
+	return sdktypes.StreamLocator{StreamId: util.GenerateStreamId(name), DataProvider: addr}
+}
+
+const (
+	ownerDP = "0x000000000000000000000000000000000000a110"
+	otherDP = "0x000000000000000000000000000000000000b220"
+)
+
+func TestTaxonomyBuilder_BuildNormalizesWeights(t *testing.T) {
+	parent := testLocator(t, ownerDP, "parent")
+	a := testLocator(t, ownerDP, "a")
+	b := testLocator(t, ownerDP, "b")
+
+	taxonomy, err := contractsapi.NewTaxonomyBuilder(parent).
+		AddChild(a, 3).
+		AddChild(b, 1).
+		NormalizeWeights().
+		WithStartDate(100).
+		Build()
+	require.NoError(t, err)
+
+	require.Len(t, taxonomy.TaxonomyItems, 2)
+	assert.InDelta(t, 0.75, taxonomy.TaxonomyItems[0].Weight, 1e-12)
+	assert.InDelta(t, 0.25, taxonomy.TaxonomyItems[1].Weight, 1e-12)
+	require.NotNil(t, taxonomy.StartDate)
+	assert.Equal(t, 100, *taxonomy.StartDate)
+	assert.Equal(t, parent, taxonomy.ParentStream)
+}
+
+func TestTaxonomyBuilder_BuildReportsLocalProblems(t *testing.T) {
+	parent := testLocator(t, ownerDP, "parent")
+	a := testLocator(t, ownerDP, "a")
+
+	_, err := contractsapi.NewTaxonomyBuilder(parent).
+		AddChild(a, 1).
+		AddChild(a, -2).
+		AddChild(parent, 0).
+		Build()
+	require.Error(t, err)
+
+	var verr *contractsapi.TaxonomyValidationError
+	require.True(t, errors.As(err, &verr))
+	assert.True(t, verr.HasProblem(contractsapi.TaxonomyProblemDuplicateChild))
+	assert.True(t, verr.HasProblem(contractsapi.TaxonomyProblemSelfReference))
+	assert.True(t, verr.HasProblem(contractsapi.TaxonomyProblemNonPositiveWeight))
+	assert.Len(t, verr.Problems, 4, "two bad weights, one duplicate, one self reference")
+
+	_, err = contractsapi.NewTaxonomyBuilder(parent).Build()
+	require.True(t, errors.As(err, &verr))
+	assert.True(t, verr.HasProblem(contractsapi.TaxonomyProblemNoChildren))
+}
+
+func TestTaxonomyBuilder_ValidateChecksNetworkState(t *testing.T) {
+	parent := testLocator(t, ownerDP, "parent")
+	own := testLocator(t, ownerDP, "own")
+	missing := testLocator(t, otherDP, "missing")
+	privateRead := testLocator(t, otherDP, "private-read")
+	privateCompose := testLocator(t, otherDP, "private-compose")
+	allowed := testLocator(t, otherDP, "allowed")
+
+	owner, err := util.NewEthereumAddressFromString(ownerDP)
+	require.NoError(t, err)
+	latestStart := 500
+
+	mock := &mockComposedActions{
+		missing: []sdktypes.StreamLocator{missing},
+		readVisibility: map[string]util.VisibilityEnum{
+			privateRead.StreamId.String(): util.PrivateVisibility,
+			allowed.StreamId.String():     util.PrivateVisibility,
+		},
+		readWallets: map[string][]util.EthereumAddress{
+			allowed.StreamId.String(): {owner},
+		},
+		composeVisibility: map[string]util.VisibilityEnum{
+			privateCompose.StreamId.String(): util.PrivateVisibility,
+			allowed.StreamId.String():        util.PrivateVisibility,
+		},
+		composeStreams: map[string][]sdktypes.StreamLocator{
+			allowed.StreamId.String(): {parent},
+		},
+		latest: sdktypes.Taxonomy{
+			ParentStream:  parent,
+			TaxonomyItems: []sdktypes.TaxonomyItem{{ChildStream: own, Weight: 1}},
+			StartDate:     &latestStart,
+		},
+	}
+
+	_, err = contractsapi.NewTaxonomyBuilder(parent).
+		AddChild(own, 1).
+		AddChild(missing, 1).
+		AddChild(privateRead, 1).
+		AddChild(privateCompose, 1).
+		AddChild(allowed, 1).
+		WithStartDate(latestStart).
+		Validate(context.Background(), mock)
+	require.Error(t, err)
+
+	var verr *contractsapi.TaxonomyValidationError
+	require.True(t, errors.As(err, &verr))
+	codes := map[contractsapi.TaxonomyProblemCode]string{}
+	for _, p := range verr.Problems {
+		if p.Child != nil {
+			codes[p.Code] = p.Child.StreamId.String()
+		} else {
+			codes[p.Code] = ""
+		}
+	}
+	assert.Equal(t, map[contractsapi.TaxonomyProblemCode]string{
+		contractsapi.TaxonomyProblemChildNotFound:      missing.StreamId.String(),
+		contractsapi.TaxonomyProblemChildNotReadable:   privateRead.StreamId.String(),
+		contractsapi.TaxonomyProblemChildNotComposable: privateCompose.StreamId.String(),
+		contractsapi.TaxonomyProblemStartDateTooEarly:  "",
+	}, codes)
+
+	taxonomy, err := contractsapi.NewTaxonomyBuilder(parent).
+		AddChild(own, 2).
+		AddChild(allowed, 2).
+		NormalizeWeights().
+		WithStartDate(latestStart + 1).
+		Validate(context.Background(), &mockComposedActions{
+			readVisibility:    mock.readVisibility,
+			readWallets:       mock.readWallets,
+			composeVisibility: mock.composeVisibility,
+			composeStreams:    mock.composeStreams,
+			latest:            mock.latest,
+		})
+	require.NoError(t, err)
+	require.Len(t, taxonomy.TaxonomyItems, 2)
+	assert.InDelta(t, 0.5, taxonomy.TaxonomyItems[0].Weight, 1e-12)
+}
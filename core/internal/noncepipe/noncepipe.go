@@ -0,0 +1,242 @@
+// Package noncepipe broadcasts transactions from one signer back to back with
+// locally assigned nonces, retrying rejected broadcasts. It is shared by
+// contractsapi.BulkInserter, contractsapi.BulkPermissions and
+// orderbook/batch.Replacer.
+//
+// The mempool admits transactions strictly in nonce order
+// (kwil-db/node/txapp/mempool.go:180-204): tx N+2 only enters once tx N+1 has
+// been admitted. Admission is fast (~50ms HTTP) while inclusion is slow (~1-2s
+// block time), so broadcasting sequentially with cached nonces and awaiting
+// inclusion later is far faster than waiting between broadcasts. Concurrent
+// broadcast from one signer is NOT safe: HTTP reordering produces
+// out-of-order arrivals which the mempool rejects with ErrInvalidNonce.
+package noncepipe
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/trufnetwork/kwil-db/core/log"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/tnerrors"
+)
+
+// AccountClient is the account lookup a Pipeline needs.
+type AccountClient interface {
+	GetAccount(ctx context.Context, accountID *kwiltypes.AccountID, status kwiltypes.AccountStatus) (*kwiltypes.Account, error)
+}
+
+// Pipeline hands out consecutive nonces for one account and retries
+// broadcasts.
+//
+// Recovery: on ErrInvalidNonce the cache is cleared and re-fetched from the
+// ledger on the next attempt. On ErrMempoolFull, "node is catching up" and
+// pre-broadcast infra errors it backs off but keeps the nonce (the nonce is
+// still valid; the network or the receiving backend is just busy). Invalid
+// nonce and mempool full share MaxAttempts; catch-up and infra errors have
+// budgets of their own. A zero budget means no retries.
+type Pipeline struct {
+	Accounts  AccountClient
+	AccountID *kwiltypes.AccountID
+	Logger    log.Logger
+	// LogName prefixes the retry log lines, e.g. "bulk_inserter".
+	LogName string
+
+	MaxAttempts        int
+	CatchupMaxAttempts int
+	InfraMaxAttempts   int
+	// Backoffs ramp linearly: the delay before retry n (from 0) is
+	// backoff * (n + 1). RetryBackoff also applies to infra errors.
+	RetryBackoff   time.Duration
+	CatchupBackoff time.Duration
+
+	mu               sync.Mutex
+	pendingNonce     int64
+	nonceInitialized bool
+}
+
+// Broadcast calls send with the next nonce, retrying as described on
+// Pipeline. send must broadcast without waiting for inclusion. On failure the
+// cached nonce is dropped.
+func (p *Pipeline) Broadcast(
+	ctx context.Context,
+	send func(nonce int64) (kwiltypes.Hash, error),
+) (hash kwiltypes.Hash, retErr error) {
+	var (
+		nonce             int64
+		nonceLoaded       bool
+		transientAttempts int // counts ErrInvalidNonce + ErrMempoolFull tries
+		catchupAttempts   int // counts "node is catching up" tries
+		infraAttempts     int // counts pre-broadcast infra errors
+	)
+	// On any error exit (attempt exhaustion, context cancellation during
+	// backoff, or an unhandled error), drop the cached nonce. The reserved
+	// nonce was never admitted to the network, so a subsequent broadcast
+	// would otherwise send the next nonce first and only recover via
+	// ErrInvalidNonce — wasting one round-trip every time. Forcing a fresh
+	// GetAccount on the next call resyncs us with what the ledger actually
+	// admitted. Idempotent with the ErrInvalidNonce path's reset.
+	defer func() {
+		if retErr != nil && nonceLoaded {
+			p.Reset()
+		}
+	}()
+	for {
+		// Pull a fresh nonce only on the first attempt OR after an
+		// ErrInvalidNonce reset. On ErrMempoolFull we keep the same nonce
+		// because the tx was rejected at admission — the mempool's
+		// expected nonce for this account hasn't moved.
+		if !nonceLoaded {
+			n, err := p.nextNonce(ctx)
+			if err != nil {
+				return kwiltypes.Hash{}, pkgerrors.Wrap(err, "fetch nonce")
+			}
+			nonce = n
+			nonceLoaded = true
+		}
+
+		hash, err := send(nonce)
+		if err == nil {
+			return hash, nil
+		}
+
+		// Classify also matches the kwil-db sentinels (kwiltypes.ErrInvalidNonce,
+		// kwiltypes.ErrMempoolFull) and errors already wrapped by tnerrors.
+		switch tnerrors.Classify(err) {
+		case tnerrors.ErrInvalidNonce:
+			if transientAttempts+1 >= p.MaxAttempts {
+				return kwiltypes.Hash{}, err
+			}
+			p.logger().Warn(p.LogName+": invalid nonce, resetting cache",
+				"attempt", transientAttempts+1, "nonce", nonce, "err", err)
+			p.Reset()
+			nonceLoaded = false // force re-fetch on next attempt
+			if waitErr := backoff(ctx, p.RetryBackoff, transientAttempts); waitErr != nil {
+				return kwiltypes.Hash{}, waitErr
+			}
+			transientAttempts++
+		case tnerrors.ErrMempoolFull:
+			if transientAttempts+1 >= p.MaxAttempts {
+				return kwiltypes.Hash{}, err
+			}
+			p.logger().Warn(p.LogName+": mempool full, backing off",
+				"attempt", transientAttempts+1, "nonce", nonce, "err", err)
+			// Keep nonceLoaded=true so we retry with the same nonce.
+			if waitErr := backoff(ctx, p.RetryBackoff, transientAttempts); waitErr != nil {
+				return kwiltypes.Hash{}, waitErr
+			}
+			transientAttempts++
+		case tnerrors.ErrNodeCatchingUp:
+			// kwild emits "node is catching up" from node/node.go as a raw
+			// errors.New, surfaced as a BroadcastError with TxCode 65535
+			// (CodeUnknownError); tnerrors matches the literal message.
+			//
+			// Catch-up gets its own larger budget. Real catch-up events on a
+			// public RPC backend (sentry replaying blocks after a peer flap)
+			// routinely run minutes long; sharing the transient budget here is
+			// what previously aborted 4-hour BulkInserter runs after just 75
+			// seconds of waiting.
+			if catchupAttempts+1 >= p.CatchupMaxAttempts {
+				return kwiltypes.Hash{}, err
+			}
+			p.logger().Warn(p.LogName+": backend catching up, backing off",
+				"attempt", catchupAttempts+1,
+				"max_attempts", p.CatchupMaxAttempts,
+				"nonce", nonce, "err", err)
+			// Keep nonceLoaded=true — the backend never admitted the tx, our
+			// cached nonce is still correct.
+			if waitErr := backoff(ctx, p.CatchupBackoff, catchupAttempts); waitErr != nil {
+				return kwiltypes.Hash{}, waitErr
+			}
+			catchupAttempts++
+		case tnerrors.ErrUnavailable:
+			// Pre-broadcast infra failure: KGW had no backend ("no available
+			// backend"), TCP refused ("connection refused"), or DNS missed
+			// ("no such host") -- the request demonstrably never reached
+			// kwild, so retrying with the same cached nonce is safe and won't
+			// produce duplicate transactions. Errors that may fire after kwild
+			// accepted the tx ("EOF", "connection reset by peer", "context
+			// deadline exceeded") are not classified as ErrUnavailable and
+			// fall through to the default branch, so the caller's resume
+			// layer can recover without risking duplicate transactions.
+			if infraAttempts+1 >= p.InfraMaxAttempts {
+				return kwiltypes.Hash{}, err
+			}
+			p.logger().Warn(p.LogName+": pre-broadcast infra error, backing off",
+				"attempt", infraAttempts+1,
+				"max_attempts", p.InfraMaxAttempts,
+				"nonce", nonce, "err", err)
+			// Keep nonceLoaded=true — the backend never saw the tx.
+			if waitErr := backoff(ctx, p.RetryBackoff, infraAttempts); waitErr != nil {
+				return kwiltypes.Hash{}, waitErr
+			}
+			infraAttempts++
+		default:
+			return kwiltypes.Hash{}, err
+		}
+	}
+}
+
+// Sync drops the cached nonce and re-fetches it from the ledger, so callers
+// can surface an unreachable node before broadcasting anything.
+func (p *Pipeline) Sync(ctx context.Context) error {
+	p.Reset()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	account, err := p.Accounts.GetAccount(ctx, p.AccountID, kwiltypes.AccountStatusPending)
+	if err != nil {
+		return pkgerrors.Wrap(err, "get account")
+	}
+	p.pendingNonce = account.Nonce + 1
+	p.nonceInitialized = true
+	return nil
+}
+
+// Reset drops the cached nonce so the next broadcast re-fetches it from the
+// ledger.
+func (p *Pipeline) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.nonceInitialized = false
+	p.pendingNonce = 0
+}
+
+func (p *Pipeline) nextNonce(ctx context.Context) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.nonceInitialized {
+		nonce := p.pendingNonce
+		p.pendingNonce++
+		return nonce, nil
+	}
+
+	account, err := p.Accounts.GetAccount(ctx, p.AccountID, kwiltypes.AccountStatusPending)
+	if err != nil {
+		return 0, pkgerrors.Wrap(err, "get account")
+	}
+
+	nonce := account.Nonce + 1
+	p.pendingNonce = nonce + 1
+	p.nonceInitialized = true
+	return nonce, nil
+}
+
+func (p *Pipeline) logger() log.Logger {
+	if p.Logger == nil {
+		return log.DiscardLogger
+	}
+	return p.Logger
+}
+
+func backoff(ctx context.Context, base time.Duration, attempt int) error {
+	delay := base * time.Duration(attempt+1)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(delay):
+		return nil
+	}
+}
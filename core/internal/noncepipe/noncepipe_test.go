@@ -0,0 +1,72 @@
+package noncepipe
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+)
+
+type fakeAccounts struct {
+	nonce   int64
+	lookups int
+}
+
+func (f *fakeAccounts) GetAccount(context.Context, *kwiltypes.AccountID, kwiltypes.AccountStatus) (*kwiltypes.Account, error) {
+	f.lookups++
+	return &kwiltypes.Account{Nonce: f.nonce}, nil
+}
+
+func TestBroadcast(t *testing.T) {
+	ctx := context.Background()
+	accounts := &fakeAccounts{nonce: 4}
+	p := &Pipeline{Accounts: accounts, MaxAttempts: 3, CatchupMaxAttempts: 2, InfraMaxAttempts: 2}
+
+	var sent []int64
+	send := func(errs ...error) func(int64) (kwiltypes.Hash, error) {
+		return func(nonce int64) (kwiltypes.Hash, error) {
+			sent = append(sent, nonce)
+			if len(errs) > 0 {
+				err := errs[0]
+				errs = errs[1:]
+				return kwiltypes.Hash{}, err
+			}
+			return kwiltypes.Hash{byte(nonce)}, nil
+		}
+	}
+
+	// Nonces are consecutive and fetched once.
+	_, err := p.Broadcast(ctx, send())
+	require.NoError(t, err)
+	hash, err := p.Broadcast(ctx, send())
+	require.NoError(t, err)
+	assert.Equal(t, kwiltypes.Hash{6}, hash)
+	assert.Equal(t, []int64{5, 6}, sent)
+	assert.Equal(t, 1, accounts.lookups)
+
+	// Mempool full, catch-up and infra errors retry with the same nonce.
+	sent = nil
+	_, err = p.Broadcast(ctx, send(kwiltypes.ErrMempoolFull,
+		errors.New("node is catching up, cannot process transactions right now"),
+		errors.New("dial tcp: connection refused")))
+	require.NoError(t, err)
+	assert.Equal(t, []int64{7, 7, 7, 7}, sent)
+
+	// Invalid nonce re-fetches it from the ledger.
+	sent = nil
+	accounts.nonce = 9
+	_, err = p.Broadcast(ctx, send(kwiltypes.ErrInvalidNonce))
+	require.NoError(t, err)
+	assert.Equal(t, []int64{8, 10}, sent)
+	assert.Equal(t, 2, accounts.lookups)
+
+	// Exhausted budgets and other errors fail and drop the cached nonce.
+	_, err = p.Broadcast(ctx, send(kwiltypes.ErrMempoolFull, kwiltypes.ErrMempoolFull, kwiltypes.ErrMempoolFull))
+	assert.ErrorIs(t, err, kwiltypes.ErrMempoolFull)
+	_, err = p.Broadcast(ctx, send(errors.New("EOF")))
+	assert.ErrorContains(t, err, "EOF")
+	assert.Equal(t, 3, accounts.lookups, "the EOF broadcast re-fetched the nonce")
+}
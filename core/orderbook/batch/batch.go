@@ -0,0 +1,332 @@
+// Package batch replaces a wallet's orders in a market with a desired set in
+// one burst of pipelined transactions.
+//
+//	r, err := batch.New(ob, kwilClient, signer)
+//	if err != nil {
+//	    return err
+//	}
+//	report, err := r.ReplaceOrders(ctx, queryID, []batch.OrderSpec{
+//	    {Outcome: true, Side: bot.Buy, Price: 45, Amount: 100},
+//	    {Outcome: true, Side: bot.Sell, Price: 55, Amount: 100},
+//	})
+//	for _, res := range report.Failed() {
+//	    log.Printf("%s: %v", res.Op, res.Err)
+//	}
+//
+// ReplaceOrders reads the wallet's live orders with GetPositionsByWallet and
+// diffs them against the desired orders with bot.Plan, so orders already
+// resting as desired are left alone and moved orders keep their FIFO position
+// through ChangeBid and ChangeAsk. The operations are then broadcast back to
+// back with consecutive nonces, without waiting for inclusion in between,
+// through the same nonce pipeline as contractsapi.BulkInserter, and awaited
+// together at the end. Broadcasts rejected for a stale nonce, a full mempool
+// or a busy node are retried before the operation is reported as failed.
+//
+// Each transaction is atomic on its own, but the replacement as a whole is
+// not: a failed broadcast stops the batch and the operations after it are
+// reported as ErrSkipped, while a transaction that fails on chain does not
+// undo the ones around it. ReplaceOrders can be called again with the same
+// orders to finish a partial replacement.
+//
+// bot.Plan rejects desired orders that cross each other and orders the
+// operations so that the wallet's resting orders never cross one another
+// mid-batch either.
+package batch
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	kwilClientType "github.com/trufnetwork/kwil-db/core/client/types"
+	"github.com/trufnetwork/kwil-db/core/crypto/auth"
+	"github.com/trufnetwork/kwil-db/core/log"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/internal/noncepipe"
+	"github.com/trufnetwork/sdk-go/core/orderbook/bot"
+	"github.com/trufnetwork/sdk-go/core/tnerrors"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+// OrderSpec is an order the wallet should have resting: an outcome, a side,
+// an unsigned price in cents (1-99) and an amount of shares. Specs at the
+// same outcome, side and price are merged.
+type OrderSpec = bot.Quote
+
+// ErrSkipped is the error of operations that were not broadcast because an
+// operation before them failed to broadcast.
+var ErrSkipped = errors.New("skipped after an earlier broadcast failed")
+
+// TxClient is the account and transaction interface Replacer needs.
+// *gatewayclient.GatewayClient satisfies it.
+type TxClient interface {
+	GetAccount(ctx context.Context, accountID *kwiltypes.AccountID, status kwiltypes.AccountStatus) (*kwiltypes.Account, error)
+	WaitTx(ctx context.Context, txHash kwiltypes.Hash, interval time.Duration) (*kwiltypes.TxQueryResponse, error)
+}
+
+// Result is the outcome of one operation.
+type Result struct {
+	Op    bot.Op
+	Nonce int64          // 0 when the operation was not broadcast
+	Hash  kwiltypes.Hash // zero when the operation was not broadcast
+	// Err is the broadcast error, the WaitTx error or the failed transaction
+	// (matching tnerrors.ErrTxFailed), or ErrSkipped. Nil on success, or
+	// once broadcast when the Replacer does not wait.
+	Err error
+}
+
+// Report describes one ReplaceOrders call.
+type Report struct {
+	QueryID int
+	// Live holds the wallet's positions in the market, orders and holdings,
+	// as read before planning.
+	Live    []types.UserPosition
+	Results []Result // in broadcast order; empty when nothing had to change
+}
+
+// Failed returns the results with an error.
+func (r *Report) Failed() []Result {
+	var out []Result
+	for _, res := range r.Results {
+		if res.Err != nil {
+			out = append(out, res)
+		}
+	}
+	return out
+}
+
+// Replacer replaces the orders of the signer's wallet.
+//
+// A Replacer is not safe for concurrent use, and nothing else should sign
+// with the same key while ReplaceOrders runs, or the pipelined nonces
+// collide.
+type Replacer struct {
+	ob           types.IOrderBook
+	tx           TxClient
+	accountID    *kwiltypes.AccountID
+	wallet       string
+	txOpts       []kwilClientType.TxOpt
+	wait         bool
+	waitInterval time.Duration
+	maxAttempts  int
+	retryBackoff time.Duration
+	logger       log.Logger
+
+	pipeline *noncepipe.Pipeline
+}
+
+// Option configures a Replacer.
+type Option func(*Replacer)
+
+// WithTxOptions adds options, such as a fee, to every transaction. Nonce and
+// broadcast options are set by the Replacer.
+func WithTxOptions(opts ...kwilClientType.TxOpt) Option {
+	return func(r *Replacer) {
+		r.txOpts = append(r.txOpts, opts...)
+	}
+}
+
+// WithMaxAttempts sets the maximum number of broadcast attempts per
+// operation on invalid-nonce and mempool-full errors, and separately on
+// catch-up and on pre-broadcast infra errors. Default: 5.
+func WithMaxAttempts(n int) Option {
+	return func(r *Replacer) {
+		if n > 0 {
+			r.maxAttempts = n
+		}
+	}
+}
+
+// WithRetryBackoff sets the base backoff between broadcast attempts, for
+// every retried error. Actual delay is backoff * (attempt + 1). Default: 2s.
+func WithRetryBackoff(d time.Duration) Option {
+	return func(r *Replacer) {
+		if d > 0 {
+			r.retryBackoff = d
+		}
+	}
+}
+
+// WithWaitInterval sets the polling interval passed to WaitTx. Default: 1s.
+func WithWaitInterval(d time.Duration) Option {
+	return func(r *Replacer) {
+		if d > 0 {
+			r.waitInterval = d
+		}
+	}
+}
+
+// WithoutWait makes ReplaceOrders return once every operation is broadcast,
+// leaving Result.Err nil for broadcasts the chain may still reject.
+func WithoutWait() Option {
+	return func(r *Replacer) {
+		r.wait = false
+	}
+}
+
+// WithLogger attaches a logger. Default: discard.
+func WithLogger(logger log.Logger) Option {
+	return func(r *Replacer) {
+		r.logger = logger
+	}
+}
+
+// New returns a Replacer for the wallet of signer, which must be the signer
+// ob sends transactions with.
+func New(ob types.IOrderBook, tx TxClient, signer auth.Signer, opts ...Option) (*Replacer, error) {
+	if ob == nil {
+		return nil, pkgerrors.New("order book is required")
+	}
+	if tx == nil {
+		return nil, pkgerrors.New("tx client is required")
+	}
+	if signer == nil {
+		return nil, pkgerrors.New("signer is required")
+	}
+	accountID, err := kwiltypes.GetSignerAccount(signer)
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "derive account id from signer")
+	}
+	wallet, err := auth.EthSecp256k1Authenticator{}.Identifier(signer.CompactID())
+	if err != nil {
+		return nil, pkgerrors.Wrap(err, "derive wallet address from signer")
+	}
+
+	r := &Replacer{
+		ob:           ob,
+		tx:           tx,
+		accountID:    accountID,
+		wallet:       strings.ToLower(wallet),
+		wait:         true,
+		waitInterval: time.Second,
+		maxAttempts:  5,
+		retryBackoff: 2 * time.Second,
+		logger:       log.DiscardLogger,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.pipeline = &noncepipe.Pipeline{
+		Accounts:           tx,
+		AccountID:          accountID,
+		Logger:             r.logger,
+		LogName:            "batch",
+		MaxAttempts:        r.maxAttempts,
+		CatchupMaxAttempts: r.maxAttempts,
+		InfraMaxAttempts:   r.maxAttempts,
+		RetryBackoff:       r.retryBackoff,
+		CatchupBackoff:     r.retryBackoff,
+	}
+	return r, nil
+}
+
+// Wallet returns the 0x-prefixed, lowercase address whose orders are
+// replaced.
+func (r *Replacer) Wallet() string {
+	return r.wallet
+}
+
+// Plan returns the operations ReplaceOrders would broadcast, in order, and
+// the live orders they were planned against, without sending anything.
+func (r *Replacer) Plan(ctx context.Context, queryID int, desired []OrderSpec) ([]bot.Op, []types.UserPosition, error) {
+	positions, err := r.ob.GetPositionsByWallet(ctx, types.GetPositionsByWalletInput{WalletHex: r.wallet})
+	if err != nil {
+		return nil, nil, pkgerrors.Wrap(err, "get positions")
+	}
+	var live []types.UserPosition
+	for _, p := range positions {
+		if p.QueryID == queryID {
+			live = append(live, p)
+		}
+	}
+	ops, err := bot.Plan(live, desired)
+	if err != nil {
+		return nil, nil, pkgerrors.Wrap(err, "plan")
+	}
+	return ops, live, nil
+}
+
+// ReplaceOrders makes desired the wallet's complete set of orders in market
+// queryID: live orders not in desired are cancelled or moved, and missing
+// ones placed. Holdings are not touched. It returns an error without
+// broadcasting anything when desired is invalid or crosses itself, or when
+// the live orders or the nonce cannot be read; per-operation failures are
+// reported in the Report.
+func (r *Replacer) ReplaceOrders(ctx context.Context, queryID int, desired []OrderSpec) (*Report, error) {
+	ops, live, err := r.Plan(ctx, queryID, desired)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{QueryID: queryID, Live: live, Results: make([]Result, len(ops))}
+	if len(ops) == 0 {
+		return report, nil
+	}
+
+	// Resync up front: another signer may have used the key since the last
+	// call, and an unreachable node is reported before anything is sent.
+	if err := r.pipeline.Sync(ctx); err != nil {
+		return nil, err
+	}
+	failed := false
+	for i, op := range ops {
+		res := &report.Results[i]
+		res.Op = op
+		if failed {
+			res.Err = ErrSkipped
+			continue
+		}
+		var nonce int64
+		hash, err := r.pipeline.Broadcast(ctx, func(n int64) (kwiltypes.Hash, error) {
+			nonce = n
+			return r.send(ctx, queryID, op, n)
+		})
+		if err != nil {
+			res.Err = pkgerrors.Wrapf(err, "broadcast %s", op)
+			failed = true
+			continue
+		}
+		res.Nonce, res.Hash = nonce, hash
+	}
+
+	if r.wait {
+		for i := range report.Results {
+			res := &report.Results[i]
+			if res.Err != nil {
+				continue
+			}
+			txRes, err := r.tx.WaitTx(ctx, res.Hash, r.waitInterval)
+			if err != nil {
+				res.Err = pkgerrors.Wrapf(err, "wait for tx %s", res.Hash)
+				continue
+			}
+			res.Err = tnerrors.FromTxResult(action(res.Op), res.Hash, txRes)
+		}
+	}
+	return report, nil
+}
+
+func (r *Replacer) send(ctx context.Context, queryID int, op bot.Op, nonce int64) (kwiltypes.Hash, error) {
+	opts := append(append([]kwilClientType.TxOpt(nil), r.txOpts...),
+		kwilClientType.WithNonce(nonce),
+		kwilClientType.WithSyncBroadcast(false),
+	)
+	return bot.Send(ctx, r.ob, queryID, op, opts...)
+}
+
+// action returns the order book action op is sent with.
+func action(op bot.Op) string {
+	switch {
+	case op.Kind == bot.OpCancel:
+		return "cancel_order"
+	case op.Kind == bot.OpChange && op.Side == bot.Buy:
+		return "change_bid"
+	case op.Kind == bot.OpChange:
+		return "change_ask"
+	case op.Side == bot.Buy:
+		return "place_buy_order"
+	default:
+		return "place_sell_order"
+	}
+}
@@ -0,0 +1,249 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kwilClientType "github.com/trufnetwork/kwil-db/core/client/types"
+	"github.com/trufnetwork/kwil-db/core/crypto"
+	"github.com/trufnetwork/kwil-db/core/crypto/auth"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/orderbook/bot"
+	"github.com/trufnetwork/sdk-go/core/orderbook/sim"
+	"github.com/trufnetwork/sdk-go/core/tnerrors"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+// recordingBook records the nonce of every write and fails the ones listed
+// in fail, by call number.
+type recordingBook struct {
+	*sim.Trader
+	nonces []int64
+	fail   map[int]error
+}
+
+func (r *recordingBook) record(opts []kwilClientType.TxOpt) error {
+	r.nonces = append(r.nonces, kwilClientType.GetTxOpts(opts).Nonce)
+	if err := r.fail[len(r.nonces)]; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *recordingBook) PlaceBuyOrder(ctx context.Context, in types.PlaceBuyOrderInput, opts ...kwilClientType.TxOpt) (kwiltypes.Hash, error) {
+	if err := r.record(opts); err != nil {
+		return kwiltypes.Hash{}, err
+	}
+	return r.Trader.PlaceBuyOrder(ctx, in, opts...)
+}
+
+func (r *recordingBook) PlaceSellOrder(ctx context.Context, in types.PlaceSellOrderInput, opts ...kwilClientType.TxOpt) (kwiltypes.Hash, error) {
+	if err := r.record(opts); err != nil {
+		return kwiltypes.Hash{}, err
+	}
+	return r.Trader.PlaceSellOrder(ctx, in, opts...)
+}
+
+func (r *recordingBook) CancelOrder(ctx context.Context, in types.CancelOrderInput, opts ...kwilClientType.TxOpt) (kwiltypes.Hash, error) {
+	if err := r.record(opts); err != nil {
+		return kwiltypes.Hash{}, err
+	}
+	return r.Trader.CancelOrder(ctx, in, opts...)
+}
+
+func (r *recordingBook) ChangeBid(ctx context.Context, in types.ChangeBidInput, opts ...kwilClientType.TxOpt) (kwiltypes.Hash, error) {
+	if err := r.record(opts); err != nil {
+		return kwiltypes.Hash{}, err
+	}
+	return r.Trader.ChangeBid(ctx, in, opts...)
+}
+
+func (r *recordingBook) ChangeAsk(ctx context.Context, in types.ChangeAskInput, opts ...kwilClientType.TxOpt) (kwiltypes.Hash, error) {
+	if err := r.record(opts); err != nil {
+		return kwiltypes.Hash{}, err
+	}
+	return r.Trader.ChangeAsk(ctx, in, opts...)
+}
+
+type fakeTxClient struct {
+	nonce    int64
+	accounts int
+	waited   []kwiltypes.Hash
+	failed   map[int]bool // WaitTx calls, from 1, whose transaction failed
+}
+
+func (f *fakeTxClient) GetAccount(_ context.Context, _ *kwiltypes.AccountID, status kwiltypes.AccountStatus) (*kwiltypes.Account, error) {
+	if status != kwiltypes.AccountStatusPending {
+		return nil, errors.New("want pending status")
+	}
+	f.accounts++
+	return &kwiltypes.Account{Nonce: f.nonce}, nil
+}
+
+func (f *fakeTxClient) WaitTx(_ context.Context, hash kwiltypes.Hash, _ time.Duration) (*kwiltypes.TxQueryResponse, error) {
+	f.waited = append(f.waited, hash)
+	res := &kwiltypes.TxQueryResponse{Hash: hash, Result: &kwiltypes.TxResult{Code: uint32(kwiltypes.CodeOk)}}
+	if f.failed[len(f.waited)] {
+		res.Result = &kwiltypes.TxResult{Code: 1, Log: "insufficient balance"}
+	}
+	return res, nil
+}
+
+func newTestSigner(t *testing.T) auth.Signer {
+	t.Helper()
+	priv, _, err := crypto.GenerateSecp256k1Key(rand.Reader)
+	require.NoError(t, err)
+	key, ok := priv.(*crypto.Secp256k1PrivateKey)
+	require.True(t, ok)
+	return &auth.EthPersonalSigner{Key: *key}
+}
+
+// setup lists a market and gives the signer's wallet a YES bid at 40, a YES
+// ask at 60 and a NO ask at 70, 10 shares each.
+func setup(t *testing.T) (*recordingBook, *fakeTxClient, *Replacer) {
+	t.Helper()
+	ctx := context.Background()
+	signer := newTestSigner(t)
+	wallet, err := auth.EthSecp256k1Authenticator{}.Identifier(signer.CompactID())
+	require.NoError(t, err)
+
+	s := sim.New()
+	require.NoError(t, s.Deposit(wallet, 100_000))
+	tr := s.Trader(wallet)
+	must := func(_ kwiltypes.Hash, err error) { require.NoError(t, err) }
+	must(tr.CreateMarket(ctx, types.CreateMarketInput{
+		Bridge:          "hoodi_tt2",
+		QueryComponents: bytes.Repeat([]byte{1}, 128),
+		SettleTime:      s.Now() + 3600,
+		MaxSpread:       5,
+		MinOrderSize:    1,
+	}))
+	must(tr.PlaceBuyOrder(ctx, types.PlaceBuyOrderInput{QueryID: 1, Outcome: true, Price: 40, Amount: 10}))
+	must(tr.PlaceSplitLimitOrder(ctx, types.PlaceSplitLimitOrderInput{QueryID: 1, TruePrice: 30, Amount: 10}))
+	must(tr.PlaceSellOrder(ctx, types.PlaceSellOrderInput{QueryID: 1, Outcome: true, Price: 60, Amount: 10}))
+
+	book := &recordingBook{Trader: tr, fail: map[int]error{}}
+	tx := &fakeTxClient{nonce: 7, failed: map[int]bool{}}
+	r, err := New(book, tx, signer, WithRetryBackoff(time.Millisecond))
+	require.NoError(t, err)
+	require.Equal(t, tr.Wallet(), r.Wallet())
+	return book, tx, r
+}
+
+var desired = []OrderSpec{
+	{Outcome: true, Side: bot.Buy, Price: 45, Amount: 10},
+	{Outcome: true, Side: bot.Sell, Price: 58, Amount: 10},
+	{Outcome: false, Side: bot.Sell, Price: 75, Amount: 10},
+	{Outcome: false, Side: bot.Buy, Price: 20, Amount: 5},
+}
+
+func TestReplaceOrders(t *testing.T) {
+	ctx := context.Background()
+	book, tx, r := setup(t)
+
+	report, err := r.ReplaceOrders(ctx, 1, desired)
+	require.NoError(t, err)
+	assert.Empty(t, report.Failed())
+	var ops []bot.Op
+	for _, res := range report.Results {
+		ops = append(ops, res.Op)
+	}
+	// The NO ask retreats before the YES orders advance.
+	assert.Equal(t, []bot.Op{
+		{Kind: bot.OpChange, Outcome: false, Side: bot.Sell, OldPrice: 70, Price: 75, Amount: 10},
+		{Kind: bot.OpChange, Outcome: true, Side: bot.Buy, OldPrice: 40, Price: 45, Amount: 10},
+		{Kind: bot.OpChange, Outcome: true, Side: bot.Sell, OldPrice: 60, Price: 58, Amount: 10},
+		{Kind: bot.OpPlace, Outcome: false, Side: bot.Buy, Price: 20, Amount: 5},
+	}, ops)
+	assert.Equal(t, []int64{8, 9, 10, 11}, book.nonces)
+	assert.Equal(t, int64(11), report.Results[3].Nonce)
+	assert.Len(t, tx.waited, 4)
+	assert.Equal(t, 1, tx.accounts)
+
+	positions, err := book.GetPositionsByWallet(ctx, types.GetPositionsByWalletInput{WalletHex: r.Wallet()})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []types.UserPosition{
+		{QueryID: 1, Outcome: true, Price: -45, Amount: 10, PositionType: "buy_order"},
+		{QueryID: 1, Outcome: true, Price: 58, Amount: 10, PositionType: "sell_order"},
+		{QueryID: 1, Outcome: false, Price: 75, Amount: 10, PositionType: "sell_order"},
+		{QueryID: 1, Outcome: false, Price: -20, Amount: 5, PositionType: "buy_order"},
+	}, positions)
+
+	// Already in place: nothing to send.
+	report, err = r.ReplaceOrders(ctx, 1, desired)
+	require.NoError(t, err)
+	assert.Empty(t, report.Results)
+	assert.Len(t, book.nonces, 4)
+	assert.Equal(t, 1, tx.accounts)
+
+	// An empty set cancels everything.
+	report, err = r.ReplaceOrders(ctx, 1, nil)
+	require.NoError(t, err)
+	assert.Len(t, report.Results, 4)
+	for _, res := range report.Results {
+		assert.Equal(t, bot.OpCancel, res.Op.Kind)
+	}
+}
+
+func TestReplaceOrders_Failures(t *testing.T) {
+	ctx := context.Background()
+	book, tx, r := setup(t)
+	book.fail[1] = tnerrors.ErrInvalidNonce
+	book.fail[3] = errors.New("connection reset by peer")
+
+	report, err := r.ReplaceOrders(ctx, 1, desired)
+	require.NoError(t, err)
+	require.Len(t, report.Results, 4)
+	// The first broadcast resynced its nonce and went through; the next
+	// failed and the rest were skipped.
+	assert.Equal(t, 2, tx.accounts)
+	assert.Equal(t, []int64{8, 8, 9}, book.nonces)
+	assert.NoError(t, report.Results[0].Err)
+	assert.ErrorContains(t, report.Results[1].Err, "connection reset by peer")
+	assert.ErrorIs(t, report.Results[2].Err, ErrSkipped)
+	assert.ErrorIs(t, report.Results[3].Err, ErrSkipped)
+	assert.Len(t, report.Failed(), 3)
+	assert.Equal(t, []kwiltypes.Hash{report.Results[0].Hash}, tx.waited)
+
+	// A transaction that fails on chain is reported with its result.
+	book.fail = map[int]error{}
+	tx.waited, tx.failed[2] = nil, true
+	report, err = r.ReplaceOrders(ctx, 1, desired)
+	require.NoError(t, err)
+	require.Len(t, report.Results, 3)
+	require.Len(t, report.Failed(), 1)
+	assert.ErrorIs(t, report.Results[1].Err, tnerrors.ErrTxFailed)
+	assert.ErrorContains(t, report.Results[1].Err, "insufficient balance")
+}
+
+func TestReplaceOrders_Crossing(t *testing.T) {
+	ctx := context.Background()
+	book, tx, r := setup(t)
+	for name, specs := range map[string][]OrderSpec{
+		"bid at or above ask": {{Outcome: true, Side: bot.Buy, Price: 50, Amount: 1}, {Outcome: true, Side: bot.Sell, Price: 50, Amount: 1}},
+		"mint":                {{Outcome: true, Side: bot.Buy, Price: 60, Amount: 1}, {Outcome: false, Side: bot.Buy, Price: 40, Amount: 1}},
+		"burn":                {{Outcome: true, Side: bot.Sell, Price: 55, Amount: 1}, {Outcome: false, Side: bot.Sell, Price: 45, Amount: 1}},
+	} {
+		_, err := r.ReplaceOrders(ctx, 1, specs)
+		assert.ErrorContains(t, err, "cross", name)
+	}
+	_, err := r.ReplaceOrders(ctx, 1, []OrderSpec{{Outcome: true, Side: bot.Buy, Price: 100, Amount: 1}})
+	assert.ErrorContains(t, err, "price must be between 1 and 99")
+	assert.Empty(t, book.nonces)
+	assert.Zero(t, tx.accounts)
+}
+
+func TestNew_Validation(t *testing.T) {
+	_, err := New(nil, &fakeTxClient{}, newTestSigner(t))
+	assert.ErrorContains(t, err, "order book is required")
+	_, err = New(&recordingBook{}, nil, newTestSigner(t))
+	assert.ErrorContains(t, err, "tx client is required")
+	_, err = New(&recordingBook{}, &fakeTxClient{}, nil)
+	assert.ErrorContains(t, err, "signer is required")
+}
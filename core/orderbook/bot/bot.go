@@ -0,0 +1,341 @@
+// Package bot runs order book trading strategies against a prediction market.
+//
+// A Strategy looks at a Snapshot of the market's order books and the caller's
+// positions and returns the quotes it wants resting on the book. The Bot trims
+// them to its Limits, diffs them against the live orders with Plan, and sends
+// the cancels, ChangeBid/ChangeAsk calls and placements that close the gap.
+//
+//	ob, _ := client.LoadOrderBook()
+//	b, err := bot.New(ob, queryID, bot.StrategyFunc(func(ctx context.Context, s *bot.Snapshot) ([]bot.Quote, error) {
+//	    return []bot.Quote{
+//	        {Outcome: true, Side: bot.Buy, Price: 45, Amount: 100},
+//	        {Outcome: true, Side: bot.Sell, Price: 55, Amount: 100},
+//	    }, nil
+//	}), bot.WithLimits(bot.Limits{MaxExposure: 1000}), bot.WithPaperTrading())
+//	if err != nil {
+//	    return err
+//	}
+//	return b.Run(ctx)
+package bot
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	kwilClientType "github.com/trufnetwork/kwil-db/core/client/types"
+	"github.com/trufnetwork/kwil-db/core/log"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+// Bot quotes one market with a Strategy.
+//
+// A Bot is not safe for concurrent use: each step reads the live orders and
+// then sends transactions that depend on them.
+type Bot struct {
+	ob       types.IOrderBook
+	queryID  int
+	strategy Strategy
+
+	outcomes []bool
+	limits   Limits
+	interval time.Duration
+	txOpts   []kwilClientType.TxOpt
+	paper    []PaperOption
+	isPaper  bool
+	logger   log.Logger
+	onStep   func(*Report)
+}
+
+// Option configures a Bot.
+type Option func(*Bot)
+
+// WithOutcomes sets the outcomes whose books the bot reads and quotes.
+// Default: both YES (true) and NO (false).
+func WithOutcomes(outcomes ...bool) Option {
+	return func(b *Bot) {
+		if len(outcomes) > 0 {
+			b.outcomes = outcomes
+		}
+	}
+}
+
+// WithLimits sets the risk limits applied to every step's quotes.
+func WithLimits(limits Limits) Option {
+	return func(b *Bot) {
+		b.limits = limits
+	}
+}
+
+// WithInterval sets how often Run steps. Default: 10s.
+func WithInterval(d time.Duration) Option {
+	return func(b *Bot) {
+		if d > 0 {
+			b.interval = d
+		}
+	}
+}
+
+// WithTxOptions sets the options passed to every order transaction.
+func WithTxOptions(opts ...kwilClientType.TxOpt) Option {
+	return func(b *Bot) {
+		b.txOpts = opts
+	}
+}
+
+// WithPaperTrading wraps the order book in a PaperOrderBook, so the bot
+// reads live market data but keeps its orders in memory. Use OrderBook to
+// reach the paper book, e.g. to seed holdings with SetHoldings.
+func WithPaperTrading(opts ...PaperOption) Option {
+	return func(b *Bot) {
+		b.isPaper = true
+		b.paper = opts
+	}
+}
+
+// WithLogger attaches a logger. Default: discard.
+func WithLogger(logger log.Logger) Option {
+	return func(b *Bot) {
+		b.logger = logger
+	}
+}
+
+// WithOnStep registers a callback that receives the report of every step Run
+// completes.
+func WithOnStep(fn func(*Report)) Option {
+	return func(b *Bot) {
+		b.onStep = fn
+	}
+}
+
+// New returns a bot quoting market queryID on ob with strategy.
+func New(ob types.IOrderBook, queryID int, strategy Strategy, opts ...Option) (*Bot, error) {
+	if ob == nil {
+		return nil, errors.New("order book is required")
+	}
+	if strategy == nil {
+		return nil, errors.New("strategy is required")
+	}
+	if queryID < 1 {
+		return nil, errors.Errorf("query_id must be positive, got %d", queryID)
+	}
+
+	b := &Bot{
+		ob:       ob,
+		queryID:  queryID,
+		strategy: strategy,
+		outcomes: []bool{true, false},
+		interval: 10 * time.Second,
+		logger:   log.DiscardLogger,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	if b.isPaper {
+		b.ob = NewPaperOrderBook(ob, b.paper...)
+	}
+	return b, nil
+}
+
+// OrderBook returns the order book the bot trades on: the one passed to New,
+// or the PaperOrderBook wrapping it with WithPaperTrading.
+func (b *Bot) OrderBook() types.IOrderBook {
+	return b.ob
+}
+
+// OpResult is the outcome of one planned operation.
+type OpResult struct {
+	Op   Op
+	Hash kwiltypes.Hash
+	Err  error
+}
+
+// Report describes one step.
+type Report struct {
+	Snapshot *Snapshot
+	Quotes   []Quote // the strategy's quotes after limits
+	Results  []OpResult
+}
+
+// Failed returns the results whose transaction could not be sent.
+func (r *Report) Failed() []OpResult {
+	var out []OpResult
+	for _, res := range r.Results {
+		if res.Err != nil {
+			out = append(out, res)
+		}
+	}
+	return out
+}
+
+// Snapshot reads the market state a strategy sees.
+func (b *Bot) Snapshot(ctx context.Context) (*Snapshot, error) {
+	snap := &Snapshot{QueryID: b.queryID, Books: make(map[bool]*Book, len(b.outcomes))}
+	for _, outcome := range b.outcomes {
+		entries, err := b.ob.GetOrderBook(ctx, types.GetOrderBookInput{QueryID: b.queryID, Outcome: outcome})
+		if err != nil {
+			return nil, errors.Wrapf(err, "get order book (outcome=%t)", outcome)
+		}
+		best, err := b.ob.GetBestPrices(ctx, types.GetBestPricesInput{QueryID: b.queryID, Outcome: outcome})
+		if err != nil {
+			return nil, errors.Wrapf(err, "get best prices (outcome=%t)", outcome)
+		}
+		depth, err := b.ob.GetMarketDepth(ctx, types.GetMarketDepthInput{QueryID: b.queryID, Outcome: outcome})
+		if err != nil {
+			return nil, errors.Wrapf(err, "get market depth (outcome=%t)", outcome)
+		}
+		snap.Books[outcome] = &Book{Entries: entries, Best: best, Depth: depth}
+	}
+
+	positions, err := b.ob.GetUserPositions(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "get user positions")
+	}
+	for _, p := range positions {
+		if p.QueryID == b.queryID && b.trades(p.Outcome) {
+			snap.Positions = append(snap.Positions, p)
+		}
+	}
+
+	snap.Collateral, err = b.ob.GetUserCollateral(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "get user collateral")
+	}
+	return snap, nil
+}
+
+// Step takes a snapshot, asks the strategy for quotes and reconciles the live
+// orders with them. Failed transactions are recorded in the report and do not
+// stop the remaining operations; an error is returned only when the step
+// could not be planned.
+func (b *Bot) Step(ctx context.Context) (*Report, error) {
+	snap, err := b.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	quotes, err := b.strategy.Quotes(ctx, snap)
+	if err != nil {
+		return nil, errors.Wrap(err, "strategy")
+	}
+
+	var kept []Quote
+	for _, q := range quotes {
+		if b.trades(q.Outcome) {
+			kept = append(kept, q)
+		} else {
+			b.logger.Warn("bot: dropping quote for an outcome the bot does not trade", "outcome", q.Outcome)
+		}
+	}
+	quotes, err = b.limits.apply(snap, kept)
+	if err != nil {
+		return nil, errors.Wrap(err, "apply limits")
+	}
+
+	report := &Report{Snapshot: snap, Quotes: quotes}
+	report.Results, err = b.reconcile(ctx, snap.Positions, quotes)
+	return report, err
+}
+
+// CancelAll cancels every order the bot's caller has resting in the market on
+// the outcomes the bot trades.
+func (b *Bot) CancelAll(ctx context.Context) ([]OpResult, error) {
+	positions, err := b.ob.GetUserPositions(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "get user positions")
+	}
+	var mine []types.UserPosition
+	for _, p := range positions {
+		if p.QueryID == b.queryID && b.trades(p.Outcome) {
+			mine = append(mine, p)
+		}
+	}
+	return b.reconcile(ctx, mine, nil)
+}
+
+// Run steps every interval until ctx is done, logging failed steps and
+// transactions, and returns ctx.Err(). It leaves the orders in place on
+// exit; call CancelAll to pull them.
+func (b *Bot) Run(ctx context.Context) error {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for {
+		report, err := b.Step(ctx)
+		if err != nil {
+			b.logger.Warn("bot: step failed", "query_id", b.queryID, "error", err)
+		} else {
+			for _, res := range report.Failed() {
+				b.logger.Warn("bot: order transaction failed", "query_id", b.queryID, "op", res.Op.String(), "error", res.Err)
+			}
+			if b.onStep != nil {
+				b.onStep(report)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (b *Bot) reconcile(ctx context.Context, positions []types.UserPosition, quotes []Quote) ([]OpResult, error) {
+	ops, err := Plan(positions, quotes)
+	if err != nil {
+		return nil, errors.Wrap(err, "plan")
+	}
+	results := make([]OpResult, 0, len(ops))
+	for _, op := range ops {
+		hash, err := b.send(ctx, op)
+		results = append(results, OpResult{Op: op, Hash: hash, Err: err})
+	}
+	return results, nil
+}
+
+func (b *Bot) send(ctx context.Context, op Op) (kwiltypes.Hash, error) {
+	return Send(ctx, b.ob, b.queryID, op, b.txOpts...)
+}
+
+// Send broadcasts op against market queryID with the order book action it
+// maps to: CancelOrder, ChangeBid, ChangeAsk, PlaceBuyOrder or PlaceSellOrder.
+func Send(ctx context.Context, ob types.IOrderBook, queryID int, op Op, opts ...kwilClientType.TxOpt) (kwiltypes.Hash, error) {
+	switch {
+	case op.Kind == OpCancel:
+		price := op.Price
+		if op.Side == Buy {
+			price = -price
+		}
+		return ob.CancelOrder(ctx, types.CancelOrderInput{QueryID: queryID, Outcome: op.Outcome, Price: price}, opts...)
+	case op.Kind == OpChange && op.Side == Buy:
+		return ob.ChangeBid(ctx, types.ChangeBidInput{
+			QueryID:   queryID,
+			Outcome:   op.Outcome,
+			OldPrice:  -op.OldPrice,
+			NewPrice:  -op.Price,
+			NewAmount: op.Amount,
+		}, opts...)
+	case op.Kind == OpChange:
+		return ob.ChangeAsk(ctx, types.ChangeAskInput{
+			QueryID:   queryID,
+			Outcome:   op.Outcome,
+			OldPrice:  op.OldPrice,
+			NewPrice:  op.Price,
+			NewAmount: op.Amount,
+		}, opts...)
+	case op.Side == Buy:
+		return ob.PlaceBuyOrder(ctx, types.PlaceBuyOrderInput{QueryID: queryID, Outcome: op.Outcome, Price: op.Price, Amount: op.Amount}, opts...)
+	default:
+		return ob.PlaceSellOrder(ctx, types.PlaceSellOrderInput{QueryID: queryID, Outcome: op.Outcome, Price: op.Price, Amount: op.Amount}, opts...)
+	}
+}
+
+func (b *Bot) trades(outcome bool) bool {
+	for _, o := range b.outcomes {
+		if o == outcome {
+			return true
+		}
+	}
+	return false
+}
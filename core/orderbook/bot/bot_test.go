@@ -0,0 +1,156 @@
+package bot
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+// marketData serves a fixed order book; any other call panics.
+type marketData struct {
+	types.IOrderBook
+}
+
+func (marketData) GetOrderBook(context.Context, types.GetOrderBookInput) ([]types.OrderBookEntry, error) {
+	return []types.OrderBookEntry{{ParticipantID: 9, Price: -48, Amount: 20}, {ParticipantID: 9, Price: 52, Amount: 20}}, nil
+}
+
+func (marketData) GetBestPrices(context.Context, types.GetBestPricesInput) (*types.BestPrices, error) {
+	bid, ask := 48, 52
+	return &types.BestPrices{BestBid: &bid, BestAsk: &ask}, nil
+}
+
+func (marketData) GetMarketDepth(context.Context, types.GetMarketDepthInput) ([]types.DepthLevel, error) {
+	return []types.DepthLevel{{Price: 48, BuyVolume: 20}, {Price: 52, SellVolume: 20}}, nil
+}
+
+// insideSpread quotes one cent inside the YES best bid and ask.
+var insideSpread = StrategyFunc(func(_ context.Context, s *Snapshot) ([]Quote, error) {
+	best := s.Books[true].Best
+	return []Quote{
+		{Outcome: true, Side: Buy, Price: *best.BestBid + 1, Amount: 100},
+		{Outcome: true, Side: Sell, Price: *best.BestAsk - 1, Amount: 100},
+	}, nil
+})
+
+func TestBot_PaperTrading(t *testing.T) {
+	ctx := context.Background()
+	b, err := New(marketData{}, 1, insideSpread, WithOutcomes(true), WithPaperTrading())
+	require.NoError(t, err)
+	paper := b.OrderBook().(*PaperOrderBook)
+	paper.SetHoldings(1, true, 60)
+
+	report, err := b.Step(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, report.Failed())
+	assert.Equal(t, []Quote{
+		{Outcome: true, Side: Buy, Price: 49, Amount: 100},
+		{Outcome: true, Side: Sell, Price: 51, Amount: 60},
+	}, report.Quotes, "asks are capped at the shares held")
+
+	positions, err := paper.GetUserPositions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []types.UserPosition{
+		{QueryID: 1, Outcome: true, Price: -49, Amount: 100, PositionType: "buy_order"},
+		{QueryID: 1, Outcome: true, Price: 51, Amount: 60, PositionType: "sell_order"},
+	}, positions)
+
+	// Nothing changed, so the second step sends nothing.
+	report, err = b.Step(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, report.Results)
+
+	results, err := b.CancelAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+	positions, err = paper.GetUserPositions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []types.UserPosition{{QueryID: 1, Outcome: true, Amount: 60, PositionType: "holding"}}, positions)
+}
+
+func TestBot_NeverCrossesItself(t *testing.T) {
+	ctx := context.Background()
+	quote := func(bid, ask int) Strategy {
+		return StrategyFunc(func(context.Context, *Snapshot) ([]Quote, error) {
+			return []Quote{
+				{Outcome: true, Side: Buy, Price: bid, Amount: 10},
+				{Outcome: true, Side: Sell, Price: ask, Amount: 10},
+			}, nil
+		})
+	}
+	b, err := New(marketData{}, 1, quote(40, 50), WithOutcomes(true), WithPaperTrading())
+	require.NoError(t, err)
+	b.OrderBook().(*PaperOrderBook).SetHoldings(1, true, 10)
+	_, err = b.Step(ctx)
+	require.NoError(t, err)
+
+	// Moving both quotes up: the ask leaves first, so the bid at 55 never
+	// rests above the ask at 50.
+	b.strategy = quote(55, 60)
+	report, err := b.Step(ctx)
+	require.NoError(t, err)
+	require.Empty(t, report.Failed())
+	var ops []Op
+	for _, res := range report.Results {
+		ops = append(ops, res.Op)
+	}
+	assert.Equal(t, []Op{
+		{Kind: OpChange, Outcome: true, Side: Sell, OldPrice: 50, Price: 60, Amount: 10},
+		{Kind: OpChange, Outcome: true, Side: Buy, OldPrice: 40, Price: 55, Amount: 10},
+	}, ops)
+}
+
+func TestBot_Limits(t *testing.T) {
+	ctx := context.Background()
+	strategy := StrategyFunc(func(context.Context, *Snapshot) ([]Quote, error) {
+		return []Quote{
+			{Outcome: true, Side: Buy, Price: 40, Amount: 300},
+			{Outcome: true, Side: Buy, Price: 45, Amount: 300},
+			{Outcome: false, Side: Buy, Price: 50, Amount: 300},
+		}, nil
+	})
+	b, err := New(marketData{}, 1, strategy, WithPaperTrading(), WithLimits(Limits{
+		MaxOrderAmount:  250,
+		MaxExposure:     400,
+		MaxOpenBuyValue: 30_000,
+	}))
+	require.NoError(t, err)
+	b.OrderBook().(*PaperOrderBook).SetHoldings(1, true, 100)
+
+	report, err := b.Step(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Quote{
+		{Outcome: false, Side: Buy, Price: 50, Amount: 250}, // 250 × 50 = 12500 cents
+		{Outcome: true, Side: Buy, Price: 45, Amount: 250},  // 250 × 45 = 11250 cents
+		{Outcome: true, Side: Buy, Price: 40, Amount: 50},   // exposure: 100 held + 250 + 50; 25750 cents in all
+	}, report.Quotes)
+
+	collateral, err := b.OrderBook().GetUserCollateral(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "257500000000000000000", collateral.BuyOrdersLocked)
+
+	// Once locked collateral reaches the cap, bids can only shrink.
+	b.limits = Limits{MaxLockedCollateral: big.NewInt(1)}
+	b.strategy = StrategyFunc(func(context.Context, *Snapshot) ([]Quote, error) {
+		return []Quote{
+			{Outcome: true, Side: Buy, Price: 45, Amount: 100},
+			{Outcome: true, Side: Buy, Price: 30, Amount: 100},
+		}, nil
+	})
+	report, err = b.Step(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Quote{{Outcome: true, Side: Buy, Price: 45, Amount: 100}}, report.Quotes)
+}
+
+func TestNew_Validation(t *testing.T) {
+	_, err := New(nil, 1, insideSpread)
+	assert.ErrorContains(t, err, "order book is required")
+	_, err = New(marketData{}, 0, insideSpread)
+	assert.ErrorContains(t, err, "query_id must be positive")
+	_, err = New(marketData{}, 1, nil)
+	assert.ErrorContains(t, err, "strategy is required")
+}
@@ -0,0 +1,107 @@
+package bot
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Limits bound what the bot will quote. Zero values disable a limit. When a
+// limit binds, the bot trims quotes rather than failing the step, keeping the
+// best-priced quotes: the highest bids and the lowest asks.
+type Limits struct {
+	// MaxOrderAmount caps the shares of a single quote.
+	MaxOrderAmount int64
+	// MaxExposure caps, per outcome, the shares held plus the shares bid for.
+	MaxExposure int64
+	// MaxOpenBuyValue caps the value of all bids together, in cents
+	// (price × amount summed over both outcomes).
+	MaxOpenBuyValue int64
+	// MaxLockedCollateral stops the bot from locking more collateral once the
+	// TotalLocked reported by GetUserCollateral reaches it, in the bridge
+	// token's smallest unit. Bids can then only shrink or be cancelled.
+	MaxLockedCollateral *big.Int
+}
+
+// apply trims quotes to the limits. Independently of the limits, asks are
+// capped at the shares the caller owns (holdings plus shares already resting
+// in asks), since the node rejects asks it cannot cover.
+func (l Limits) apply(snap *Snapshot, quotes []Quote) ([]Quote, error) {
+	quotes = append([]Quote(nil), quotes...)
+	sort.SliceStable(quotes, func(i, j int) bool {
+		if quotes[i].Side != quotes[j].Side {
+			return quotes[i].Side < quotes[j].Side
+		}
+		if quotes[i].Side == Buy {
+			return quotes[i].Price > quotes[j].Price
+		}
+		return quotes[i].Price < quotes[j].Price
+	})
+
+	capBids := false
+	if l.MaxLockedCollateral != nil && snap.Collateral != nil {
+		locked, ok := new(big.Int).SetString(snap.Collateral.TotalLocked, 10)
+		if !ok {
+			return nil, errors.Errorf("parse locked collateral %q", snap.Collateral.TotalLocked)
+		}
+		capBids = locked.Cmp(l.MaxLockedCollateral) >= 0
+	}
+
+	sellBudget := map[bool]int64{}
+	bidBudget := map[bool]int64{}
+	for _, outcome := range []bool{true, false} {
+		sellBudget[outcome] = snap.Holdings(outcome)
+		for _, o := range snap.Orders(outcome, Sell) {
+			sellBudget[outcome] += o.Amount
+		}
+		if l.MaxExposure > 0 {
+			bidBudget[outcome] = max(l.MaxExposure-snap.Holdings(outcome), 0)
+		}
+	}
+	valueBudget := l.MaxOpenBuyValue
+
+	out := quotes[:0]
+	for _, q := range quotes {
+		if err := q.validate(); err != nil {
+			return nil, errors.Wrapf(err, "quote %+v", q)
+		}
+		if l.MaxOrderAmount > 0 {
+			q.Amount = min(q.Amount, l.MaxOrderAmount)
+		}
+
+		if q.Side == Sell {
+			q.Amount = min(q.Amount, sellBudget[q.Outcome])
+			sellBudget[q.Outcome] -= q.Amount
+		} else {
+			if capBids {
+				q.Amount = min(q.Amount, restingAmount(snap, q))
+			}
+			if l.MaxExposure > 0 {
+				q.Amount = min(q.Amount, bidBudget[q.Outcome])
+				bidBudget[q.Outcome] -= q.Amount
+			}
+			if l.MaxOpenBuyValue > 0 {
+				q.Amount = min(q.Amount, valueBudget/int64(q.Price))
+				valueBudget -= q.Amount * int64(q.Price)
+			}
+		}
+
+		if q.Amount > 0 {
+			out = append(out, q)
+		}
+	}
+	return out, nil
+}
+
+// restingAmount returns the shares the caller already has resting at q's
+// outcome, side and price.
+func restingAmount(snap *Snapshot, q Quote) int64 {
+	var n int64
+	for _, o := range snap.Orders(q.Outcome, q.Side) {
+		if o.Price == q.Price {
+			n += o.Amount
+		}
+	}
+	return n
+}
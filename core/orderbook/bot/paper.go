@@ -0,0 +1,248 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+	kwilClientType "github.com/trufnetwork/kwil-db/core/client/types"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+// PaperOrderBook is a types.IOrderBook for paper trading. Market data
+// (GetOrderBook, GetBestPrices, GetMarketDepth, markets and rewards) comes from
+// the wrapped order book, while the caller's orders are kept in memory and
+// never broadcast: PlaceBuyOrder, PlaceSellOrder, CancelOrder, ChangeBid and
+// ChangeAsk update the paper positions that GetUserPositions and
+// GetUserCollateral report. Paper orders rest without filling and do not
+// appear in the live order book.
+type PaperOrderBook struct {
+	types.IOrderBook
+
+	mu             sync.Mutex
+	positions      map[paperKey]int64
+	txCount        uint64
+	collateralUnit *big.Int
+}
+
+type paperKey struct {
+	queryID int
+	outcome bool
+	price   int // signed, as stored by the node: 0 holding, <0 buy, >0 sell
+}
+
+// PaperOption configures a PaperOrderBook.
+type PaperOption func(*PaperOrderBook)
+
+// WithPaperCollateralUnit sets the collateral locked per share and cent of
+// price, in the bridge token's smallest unit. Default: 10^16, a cent of an
+// 18-decimal token.
+func WithPaperCollateralUnit(unit *big.Int) PaperOption {
+	return func(p *PaperOrderBook) {
+		if unit != nil && unit.Sign() > 0 {
+			p.collateralUnit = new(big.Int).Set(unit)
+		}
+	}
+}
+
+// NewPaperOrderBook returns a paper order book reading market data from live.
+func NewPaperOrderBook(live types.IOrderBook, opts ...PaperOption) *PaperOrderBook {
+	p := &PaperOrderBook{
+		IOrderBook:     live,
+		positions:      make(map[paperKey]int64),
+		collateralUnit: new(big.Int).Exp(big.NewInt(10), big.NewInt(16), nil),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// SetHoldings sets the paper shares of outcome held in a market, so that
+// paper asks can be placed against them.
+func (p *PaperOrderBook) SetHoldings(queryID int, outcome bool, amount int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.set(paperKey{queryID, outcome, 0}, amount)
+}
+
+// PlaceBuyOrder rests a paper bid.
+func (p *PaperOrderBook) PlaceBuyOrder(_ context.Context, input types.PlaceBuyOrderInput,
+	_ ...kwilClientType.TxOpt) (kwiltypes.Hash, error) {
+	if err := input.Validate(); err != nil {
+		return kwiltypes.Hash{}, errors.WithStack(err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.positions[paperKey{input.QueryID, input.Outcome, -input.Price}] += input.Amount
+	return p.nextHash(), nil
+}
+
+// PlaceSellOrder moves paper holdings into an ask.
+func (p *PaperOrderBook) PlaceSellOrder(_ context.Context, input types.PlaceSellOrderInput,
+	_ ...kwilClientType.TxOpt) (kwiltypes.Hash, error) {
+	if err := input.Validate(); err != nil {
+		return kwiltypes.Hash{}, errors.WithStack(err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	holding := paperKey{input.QueryID, input.Outcome, 0}
+	if p.positions[holding] < input.Amount {
+		return kwiltypes.Hash{}, fmt.Errorf("insufficient shares: have %d, need %d", p.positions[holding], input.Amount)
+	}
+	p.set(holding, p.positions[holding]-input.Amount)
+	p.positions[paperKey{input.QueryID, input.Outcome, input.Price}] += input.Amount
+	return p.nextHash(), nil
+}
+
+// PlaceSplitLimitOrder is not supported in paper trading.
+func (p *PaperOrderBook) PlaceSplitLimitOrder(context.Context, types.PlaceSplitLimitOrderInput,
+	...kwilClientType.TxOpt) (kwiltypes.Hash, error) {
+	return kwiltypes.Hash{}, errors.New("paper trading does not support PlaceSplitLimitOrder")
+}
+
+// CancelOrder removes a paper order; a cancelled ask returns its shares to
+// holdings.
+func (p *PaperOrderBook) CancelOrder(_ context.Context, input types.CancelOrderInput,
+	_ ...kwilClientType.TxOpt) (kwiltypes.Hash, error) {
+	if err := input.Validate(); err != nil {
+		return kwiltypes.Hash{}, errors.WithStack(err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := paperKey{input.QueryID, input.Outcome, input.Price}
+	amount, ok := p.positions[key]
+	if !ok {
+		return kwiltypes.Hash{}, fmt.Errorf("order not found: query_id=%d outcome=%t price=%d", input.QueryID, input.Outcome, input.Price)
+	}
+	delete(p.positions, key)
+	if input.Price > 0 {
+		p.positions[paperKey{input.QueryID, input.Outcome, 0}] += amount
+	}
+	return p.nextHash(), nil
+}
+
+// ChangeBid moves a paper bid to a new price and amount.
+func (p *PaperOrderBook) ChangeBid(_ context.Context, input types.ChangeBidInput,
+	_ ...kwilClientType.TxOpt) (kwiltypes.Hash, error) {
+	if err := input.Validate(); err != nil {
+		return kwiltypes.Hash{}, errors.WithStack(err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	old := paperKey{input.QueryID, input.Outcome, input.OldPrice}
+	if _, ok := p.positions[old]; !ok {
+		return kwiltypes.Hash{}, fmt.Errorf("buy order not found at price %d", input.OldPrice)
+	}
+	delete(p.positions, old)
+	p.positions[paperKey{input.QueryID, input.Outcome, input.NewPrice}] += input.NewAmount
+	return p.nextHash(), nil
+}
+
+// ChangeAsk moves a paper ask to a new price and amount, pulling shares from
+// or returning them to holdings.
+func (p *PaperOrderBook) ChangeAsk(_ context.Context, input types.ChangeAskInput,
+	_ ...kwilClientType.TxOpt) (kwiltypes.Hash, error) {
+	if err := input.Validate(); err != nil {
+		return kwiltypes.Hash{}, errors.WithStack(err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	old := paperKey{input.QueryID, input.Outcome, input.OldPrice}
+	amount, ok := p.positions[old]
+	if !ok {
+		return kwiltypes.Hash{}, fmt.Errorf("sell order not found at price %d", input.OldPrice)
+	}
+	holding := paperKey{input.QueryID, input.Outcome, 0}
+	delta := input.NewAmount - amount
+	if delta > p.positions[holding] {
+		return kwiltypes.Hash{}, fmt.Errorf("insufficient shares: have %d, need %d", p.positions[holding], delta)
+	}
+	p.set(holding, p.positions[holding]-delta)
+	delete(p.positions, old)
+	p.positions[paperKey{input.QueryID, input.Outcome, input.NewPrice}] += input.NewAmount
+	return p.nextHash(), nil
+}
+
+// GetUserPositions returns the paper positions across all markets.
+func (p *PaperOrderBook) GetUserPositions(context.Context) ([]types.UserPosition, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	positions := make([]types.UserPosition, 0, len(p.positions))
+	for key, amount := range p.positions {
+		positionType := "holding"
+		switch {
+		case key.price < 0:
+			positionType = "buy_order"
+		case key.price > 0:
+			positionType = "sell_order"
+		}
+		positions = append(positions, types.UserPosition{
+			QueryID:      key.queryID,
+			Outcome:      key.outcome,
+			Price:        key.price,
+			Amount:       amount,
+			PositionType: positionType,
+		})
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		a, b := positions[i], positions[j]
+		if a.QueryID != b.QueryID {
+			return a.QueryID < b.QueryID
+		}
+		if a.Outcome != b.Outcome {
+			return a.Outcome
+		}
+		return a.Price < b.Price
+	})
+	return positions, nil
+}
+
+// GetUserCollateral values the paper positions like the node does: bids lock
+// price × amount, and shares held or resting in asks count at $1.00 each.
+func (p *PaperOrderBook) GetUserCollateral(context.Context) (*types.UserCollateral, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var buyCents, shares int64
+	for key, amount := range p.positions {
+		if key.price < 0 {
+			buyCents += int64(-key.price) * amount
+		} else {
+			shares += amount
+		}
+	}
+	buy := new(big.Int).Mul(big.NewInt(buyCents), p.collateralUnit)
+	value := new(big.Int).Mul(big.NewInt(shares*100), p.collateralUnit)
+	return &types.UserCollateral{
+		TotalLocked:     new(big.Int).Add(buy, value).String(),
+		BuyOrdersLocked: buy.String(),
+		SharesValue:     value.String(),
+	}, nil
+}
+
+func (p *PaperOrderBook) set(key paperKey, amount int64) {
+	if amount == 0 {
+		delete(p.positions, key)
+		return
+	}
+	p.positions[key] = amount
+}
+
+// nextHash returns a fake transaction hash, unique within this paper book.
+func (p *PaperOrderBook) nextHash() kwiltypes.Hash {
+	p.txCount++
+	return kwiltypes.HashBytes([]byte(fmt.Sprintf("paper-tx-%d", p.txCount)))
+}
+
+var _ types.IOrderBook = (*PaperOrderBook)(nil)
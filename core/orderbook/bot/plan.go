@@ -0,0 +1,205 @@
+package bot
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+// OpKind is the order book action an Op maps to.
+type OpKind int
+
+const (
+	// OpCancel maps to CancelOrder.
+	OpCancel OpKind = iota
+	// OpChange maps to ChangeBid or ChangeAsk, which keep the order's FIFO
+	// position and only lock the collateral difference.
+	OpChange
+	// OpPlace maps to PlaceBuyOrder or PlaceSellOrder.
+	OpPlace
+)
+
+func (k OpKind) String() string {
+	switch k {
+	case OpCancel:
+		return "cancel"
+	case OpChange:
+		return "change"
+	default:
+		return "place"
+	}
+}
+
+// Op is one order book transaction planned by Plan.
+type Op struct {
+	Kind     OpKind
+	Outcome  bool
+	Side     Side
+	OldPrice int   // OpChange only: the price of the order being moved
+	Price    int   // unsigned price of the cancelled, placed or changed-to order
+	Amount   int64 // OpPlace and OpChange only
+}
+
+func (o Op) String() string {
+	switch o.Kind {
+	case OpCancel:
+		return fmt.Sprintf("cancel %s outcome=%t @%d", o.Side, o.Outcome, o.Price)
+	case OpChange:
+		return fmt.Sprintf("change %s outcome=%t @%d -> %d @%d", o.Side, o.Outcome, o.OldPrice, o.Amount, o.Price)
+	default:
+		return fmt.Sprintf("place %s outcome=%t %d @%d", o.Side, o.Outcome, o.Amount, o.Price)
+	}
+}
+
+// Plan returns the operations that turn the live orders in positions into
+// quotes, using as few transactions as it can:
+//
+//   - an order already resting at a quoted price and amount is left alone;
+//   - an order at a quoted price with another amount is cancelled and placed
+//     again, because ChangeBid and ChangeAsk must move the price;
+//   - remaining orders are moved to remaining quoted prices on the same side
+//     with one ChangeBid or ChangeAsk each, pairing them from the best price;
+//   - leftover orders are cancelled and leftover quotes placed.
+//
+// Cancels come first so that the collateral and shares they free are
+// available to the changes and placements after them. Changes that move an
+// order away from the other side of the book (bids down, asks up) come before
+// those that move an order towards it, so every order resting between two
+// operations is either where quoted or less aggressive, and none crosses
+// another as long as the quotes do not. positions must belong to a single
+// market; quotes at the same outcome, side and price are merged.
+func Plan(positions []types.UserPosition, quotes []Quote) ([]Op, error) {
+	desired, err := mergeQuotes(quotes)
+	if err != nil {
+		return nil, err
+	}
+
+	var cancels, changes, places []Op
+	for _, outcome := range []bool{true, false} {
+		for _, side := range []Side{Buy, Sell} {
+			live := mergeLive(liveOrders(positions, outcome, side))
+			want := desired[sideKey{outcome, side}]
+
+			var staleLive, newWant []Quote
+			for _, q := range sortedQuotes(want, side) {
+				amount, ok := live[q.Price]
+				switch {
+				case !ok:
+					newWant = append(newWant, q)
+				case amount != q.Amount:
+					cancels = append(cancels, Op{Kind: OpCancel, Outcome: outcome, Side: side, Price: q.Price})
+					places = append(places, Op{Kind: OpPlace, Outcome: outcome, Side: side, Price: q.Price, Amount: q.Amount})
+				}
+			}
+			for _, l := range sortedQuotes(live, side) {
+				if _, ok := want[l.Price]; !ok {
+					staleLive = append(staleLive, l)
+				}
+			}
+
+			n := min(len(staleLive), len(newWant))
+			for i := 0; i < n; i++ {
+				changes = append(changes, Op{
+					Kind:     OpChange,
+					Outcome:  outcome,
+					Side:     side,
+					OldPrice: staleLive[i].Price,
+					Price:    newWant[i].Price,
+					Amount:   newWant[i].Amount,
+				})
+			}
+			for _, l := range staleLive[n:] {
+				cancels = append(cancels, Op{Kind: OpCancel, Outcome: outcome, Side: side, Price: l.Price})
+			}
+			for _, q := range newWant[n:] {
+				places = append(places, Op{Kind: OpPlace, Outcome: outcome, Side: side, Price: q.Price, Amount: q.Amount})
+			}
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool { return retreats(changes[i]) && !retreats(changes[j]) })
+	ops := append(cancels, changes...)
+	return append(ops, places...), nil
+}
+
+// retreats reports whether a change moves its order away from the other side
+// of the book.
+func retreats(op Op) bool {
+	if op.Side == Buy {
+		return op.Price < op.OldPrice
+	}
+	return op.Price > op.OldPrice
+}
+
+type sideKey struct {
+	outcome bool
+	side    Side
+}
+
+// mergeQuotes validates quotes and sums the amounts quoted at the same price.
+// It rejects quotes that would trade against ourselves: a bid at or above an
+// ask of the same outcome, or a match through the mint (YES bid + NO bid >=
+// 100) or burn (YES ask + NO ask <= 100) of the order book.
+func mergeQuotes(quotes []Quote) (map[sideKey]map[int]int64, error) {
+	out := make(map[sideKey]map[int]int64)
+	for i, q := range quotes {
+		if err := q.validate(); err != nil {
+			return nil, fmt.Errorf("quote %d: %w", i, err)
+		}
+		key := sideKey{q.Outcome, q.Side}
+		if out[key] == nil {
+			out[key] = make(map[int]int64)
+		}
+		out[key][q.Price] += q.Amount
+	}
+
+	for _, outcome := range []bool{true, false} {
+		bids, asks := out[sideKey{outcome, Buy}], out[sideKey{outcome, Sell}]
+		for bid := range bids {
+			for ask := range asks {
+				if bid >= ask {
+					return nil, fmt.Errorf("quotes cross: bid %d >= ask %d on outcome=%t", bid, ask, outcome)
+				}
+			}
+		}
+	}
+	for yes := range out[sideKey{true, Buy}] {
+		for no := range out[sideKey{false, Buy}] {
+			if yes+no >= 100 {
+				return nil, fmt.Errorf("quotes cross: YES bid %d and NO bid %d add up to 100 or more", yes, no)
+			}
+		}
+	}
+	for yes := range out[sideKey{true, Sell}] {
+		for no := range out[sideKey{false, Sell}] {
+			if yes+no <= 100 {
+				return nil, fmt.Errorf("quotes cross: YES ask %d and NO ask %d add up to 100 or less", yes, no)
+			}
+		}
+	}
+	return out, nil
+}
+
+func mergeLive(orders []Quote) map[int]int64 {
+	out := make(map[int]int64, len(orders))
+	for _, o := range orders {
+		out[o.Price] += o.Amount
+	}
+	return out
+}
+
+// sortedQuotes lists amounts by price, best first: highest bid, lowest ask.
+func sortedQuotes(byPrice map[int]int64, side Side) []Quote {
+	out := make([]Quote, 0, len(byPrice))
+	for price, amount := range byPrice {
+		out = append(out, Quote{Side: side, Price: price, Amount: amount})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if side == Buy {
+			return out[i].Price > out[j].Price
+		}
+		return out[i].Price < out[j].Price
+	})
+	return out
+}
@@ -0,0 +1,98 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+func TestPlan(t *testing.T) {
+	positions := []types.UserPosition{
+		{QueryID: 1, Outcome: true, Price: 0, Amount: 500},
+		{QueryID: 1, Outcome: true, Price: -45, Amount: 100}, // kept
+		{QueryID: 1, Outcome: true, Price: -44, Amount: 100}, // resized
+		{QueryID: 1, Outcome: true, Price: -40, Amount: 100}, // moved to 43
+		{QueryID: 1, Outcome: true, Price: 60, Amount: 50},   // moved to 55
+		{QueryID: 1, Outcome: false, Price: -30, Amount: 10}, // cancelled
+	}
+	quotes := []Quote{
+		{Outcome: true, Side: Buy, Price: 45, Amount: 100},
+		{Outcome: true, Side: Buy, Price: 44, Amount: 60},
+		{Outcome: true, Side: Buy, Price: 43, Amount: 80},
+		{Outcome: true, Side: Sell, Price: 55, Amount: 30},
+		{Outcome: true, Side: Sell, Price: 56, Amount: 10},
+		{Outcome: true, Side: Sell, Price: 56, Amount: 10},
+	}
+
+	ops, err := Plan(positions, quotes)
+	require.NoError(t, err)
+	assert.Equal(t, []Op{
+		{Kind: OpCancel, Outcome: true, Side: Buy, Price: 44},
+		{Kind: OpCancel, Outcome: false, Side: Buy, Price: 30},
+		{Kind: OpChange, Outcome: true, Side: Buy, OldPrice: 40, Price: 43, Amount: 80},
+		{Kind: OpChange, Outcome: true, Side: Sell, OldPrice: 60, Price: 55, Amount: 30},
+		{Kind: OpPlace, Outcome: true, Side: Buy, Price: 44, Amount: 60},
+		{Kind: OpPlace, Outcome: true, Side: Sell, Price: 56, Amount: 20},
+	}, ops)
+
+	ops, err = Plan(positions, nil)
+	require.NoError(t, err)
+	assert.Len(t, ops, 5, "no quotes cancels every order")
+	for _, op := range ops {
+		assert.Equal(t, OpCancel, op.Kind)
+	}
+}
+
+func TestPlan_RejectsBadQuotes(t *testing.T) {
+	_, err := Plan(nil, []Quote{{Outcome: true, Side: Buy, Price: 100, Amount: 1}})
+	assert.ErrorContains(t, err, "price must be between 1 and 99")
+
+	_, err = Plan(nil, []Quote{
+		{Outcome: true, Side: Buy, Price: 55, Amount: 1},
+		{Outcome: true, Side: Sell, Price: 55, Amount: 1},
+	})
+	assert.ErrorContains(t, err, "quotes cross")
+
+	// Bids and asks of the two outcomes match through mint and burn.
+	_, err = Plan(nil, []Quote{
+		{Outcome: true, Side: Buy, Price: 60, Amount: 1},
+		{Outcome: false, Side: Buy, Price: 40, Amount: 1},
+	})
+	assert.ErrorContains(t, err, "YES bid 60 and NO bid 40 add up to 100 or more")
+	_, err = Plan(nil, []Quote{
+		{Outcome: true, Side: Sell, Price: 55, Amount: 1},
+		{Outcome: false, Side: Sell, Price: 45, Amount: 1},
+	})
+	assert.ErrorContains(t, err, "YES ask 55 and NO ask 45 add up to 100 or less")
+	_, err = Plan(nil, []Quote{
+		{Outcome: true, Side: Buy, Price: 60, Amount: 1},
+		{Outcome: false, Side: Buy, Price: 39, Amount: 1},
+		{Outcome: true, Side: Sell, Price: 62, Amount: 1},
+		{Outcome: false, Side: Sell, Price: 41, Amount: 1},
+	})
+	assert.NoError(t, err)
+}
+
+func TestPlan_ChangesRetreatFirst(t *testing.T) {
+	positions := []types.UserPosition{
+		{QueryID: 1, Outcome: true, Price: -40, Amount: 10},
+		{QueryID: 1, Outcome: true, Price: 50, Amount: 10},
+		{QueryID: 1, Outcome: false, Price: -30, Amount: 10},
+		{QueryID: 1, Outcome: false, Price: 52, Amount: 10},
+	}
+	ops, err := Plan(positions, []Quote{
+		{Outcome: true, Side: Buy, Price: 48, Amount: 10},
+		{Outcome: true, Side: Sell, Price: 55, Amount: 10},
+		{Outcome: false, Side: Buy, Price: 25, Amount: 10},
+		{Outcome: false, Side: Sell, Price: 46, Amount: 10},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []Op{
+		{Kind: OpChange, Outcome: true, Side: Sell, OldPrice: 50, Price: 55, Amount: 10},
+		{Kind: OpChange, Outcome: false, Side: Buy, OldPrice: 30, Price: 25, Amount: 10},
+		{Kind: OpChange, Outcome: true, Side: Buy, OldPrice: 40, Price: 48, Amount: 10},
+		{Kind: OpChange, Outcome: false, Side: Sell, OldPrice: 52, Price: 46, Amount: 10},
+	}, ops)
+}
@@ -0,0 +1,111 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+// Side is the side of a quote.
+type Side int
+
+const (
+	// Buy is a bid: the order book stores it with a negative price.
+	Buy Side = iota
+	// Sell is an ask: the order book stores it with a positive price.
+	Sell
+)
+
+func (s Side) String() string {
+	if s == Buy {
+		return "buy"
+	}
+	return "sell"
+}
+
+// Quote is an order the strategy wants resting on the book.
+type Quote struct {
+	Outcome bool  // TRUE for YES shares, FALSE for NO shares
+	Side    Side  // Buy or Sell
+	Price   int   // Price per share in cents (1-99), unsigned for both sides
+	Amount  int64 // Number of shares
+}
+
+func (q Quote) validate() error {
+	if q.Side != Buy && q.Side != Sell {
+		return fmt.Errorf("invalid side %d", q.Side)
+	}
+	if q.Price < 1 || q.Price > 99 {
+		return fmt.Errorf("price must be between 1 and 99, got %d", q.Price)
+	}
+	if q.Amount <= 0 {
+		return fmt.Errorf("amount must be positive, got %d", q.Amount)
+	}
+	return nil
+}
+
+// Book is the state of one outcome's order book.
+type Book struct {
+	Entries []types.OrderBookEntry // GetOrderBook, best price first
+	Best    *types.BestPrices      // GetBestPrices
+	Depth   []types.DepthLevel     // GetMarketDepth
+}
+
+// Snapshot is what a Strategy sees on each step.
+type Snapshot struct {
+	QueryID    int
+	Books      map[bool]*Book        // keyed by outcome; only the outcomes the bot trades
+	Positions  []types.UserPosition  // the caller's holdings and orders in this market
+	Collateral *types.UserCollateral // the caller's locked collateral across all markets
+}
+
+// Holdings returns the shares of outcome the caller holds outside of orders.
+func (s *Snapshot) Holdings(outcome bool) int64 {
+	var n int64
+	for _, p := range s.Positions {
+		if p.Outcome == outcome && p.Price == 0 {
+			n += p.Amount
+		}
+	}
+	return n
+}
+
+// Orders returns the caller's resting orders on one side of outcome, with
+// unsigned prices.
+func (s *Snapshot) Orders(outcome bool, side Side) []Quote {
+	return liveOrders(s.Positions, outcome, side)
+}
+
+func liveOrders(positions []types.UserPosition, outcome bool, side Side) []Quote {
+	var out []Quote
+	for _, p := range positions {
+		if p.Outcome != outcome || p.Price == 0 {
+			continue
+		}
+		if (side == Buy) != (p.Price < 0) {
+			continue
+		}
+		price := p.Price
+		if price < 0 {
+			price = -price
+		}
+		out = append(out, Quote{Outcome: outcome, Side: side, Price: price, Amount: p.Amount})
+	}
+	return out
+}
+
+// Strategy decides which quotes should rest on the book. The bot diffs the
+// returned quotes against the live orders, so a strategy returns its full
+// desired state every time; returning no quotes cancels every order.
+type Strategy interface {
+	Quotes(ctx context.Context, snap *Snapshot) ([]Quote, error)
+}
+
+// StrategyFunc adapts a function to the Strategy interface.
+type StrategyFunc func(ctx context.Context, snap *Snapshot) ([]Quote, error)
+
+// Quotes calls f(ctx, snap).
+func (f StrategyFunc) Quotes(ctx context.Context, snap *Snapshot) ([]Quote, error) {
+	return f(ctx, snap)
+}
@@ -0,0 +1,295 @@
+// Package indexer is a client for the Prediction Market Indexer, the HTTP
+// service that keeps the history the node does not: past markets,
+// settlements, order book snapshots and LP reward distributions.
+//
+//	c, err := indexer.New(indexer.TestnetURL)
+//	if err != nil {
+//	    return err
+//	}
+//	markets, err := c.ListMarkets(ctx, indexer.MarketFilter{Settled: &settled})
+//	snaps, err := c.Snapshots(ctx, markets[0].QueryID, indexer.ListOptions{From: time.Now().Add(-24 * time.Hour)})
+//	rewards, err := c.Rewards(ctx, wallet, indexer.ListOptions{Limit: 10})
+//
+// Endpoint and field documentation:
+// https://github.com/trufnetwork/node/blob/main/docs/prediction-market-indexer.md
+package indexer
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Indexer deployments.
+const (
+	MainnetURL = "https://indexer.infra.truf.network"
+	TestnetURL = "http://ec2-52-15-66-172.us-east-2.compute.amazonaws.com:8080"
+)
+
+// DefaultPageSize is the page size the All* methods request.
+const DefaultPageSize = 100
+
+const basePath = "/v0/prediction-market"
+
+// Client queries a Prediction Market Indexer. It is safe for concurrent use.
+type Client struct {
+	baseURL *url.URL
+	http    *http.Client
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests. Default: a client
+// with a 30s timeout.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.http = hc
+	}
+}
+
+// New returns a client for the indexer at baseURL, e.g. MainnetURL.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, errors.Wrap(err, "parse indexer URL")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.Errorf("indexer URL must be http or https, got %q", baseURL)
+	}
+	c := &Client{
+		baseURL: u,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// ListOptions pages and filters a listing. Zero values are left to the
+// indexer's defaults.
+type ListOptions struct {
+	Limit  int
+	Offset int
+	// From and To bound the record timestamps, inclusive.
+	From time.Time
+	To   time.Time
+}
+
+func (o ListOptions) encode(q url.Values) error {
+	if o.Limit < 0 || o.Offset < 0 {
+		return errors.Errorf("limit and offset must not be negative, got %d and %d", o.Limit, o.Offset)
+	}
+	if !o.From.IsZero() && !o.To.IsZero() && o.To.Before(o.From) {
+		return errors.Errorf("time range ends (%s) before it starts (%s)", o.To, o.From)
+	}
+	if o.Limit > 0 {
+		q.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset > 0 {
+		q.Set("offset", strconv.Itoa(o.Offset))
+	}
+	if !o.From.IsZero() {
+		q.Set("from", strconv.FormatInt(o.From.Unix(), 10))
+	}
+	if !o.To.IsZero() {
+		q.Set("to", strconv.FormatInt(o.To.Unix(), 10))
+	}
+	return nil
+}
+
+// MarketFilter selects markets. From and To bound the creation time.
+type MarketFilter struct {
+	ListOptions
+	Settled *bool // nil for both
+}
+
+// APIError is returned when the indexer answers with a non-2xx status or
+// "ok": false.
+type APIError struct {
+	StatusCode int
+	Message    string
+	Path       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("indexer %s: HTTP %d: %s", e.Path, e.StatusCode, e.Message)
+}
+
+// envelope is the body of every indexer response.
+type envelope struct {
+	OK    bool            `json:"ok"`
+	Data  json.RawMessage `json:"data"`
+	Error string          `json:"error"`
+}
+
+// ListMarkets returns one page of markets.
+func (c *Client) ListMarkets(ctx context.Context, f MarketFilter) ([]Market, error) {
+	q := url.Values{}
+	if err := f.encode(q); err != nil {
+		return nil, err
+	}
+	if f.Settled != nil {
+		q.Set("settled", strconv.FormatBool(*f.Settled))
+	}
+	var raw []marketJSON
+	if err := c.get(ctx, basePath+"/markets", q, &raw); err != nil {
+		return nil, err
+	}
+	out := make([]Market, 0, len(raw))
+	for _, m := range raw {
+		market, err := m.parse()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, market)
+	}
+	return out, nil
+}
+
+// AllMarkets pages through ListMarkets from f.Offset until the indexer runs
+// out of markets. f.Limit is the page size, DefaultPageSize if unset.
+func (c *Client) AllMarkets(ctx context.Context, f MarketFilter) ([]Market, error) {
+	return all(f.Limit, f.Offset, func(limit, offset int) ([]Market, error) {
+		page := f
+		page.Limit, page.Offset = limit, offset
+		return c.ListMarkets(ctx, page)
+	})
+}
+
+// Snapshots returns order book snapshots of a market. From and To bound the
+// snapshot time.
+func (c *Client) Snapshots(ctx context.Context, queryID int, opts ListOptions) ([]Snapshot, error) {
+	if queryID < 1 {
+		return nil, errors.Errorf("query_id must be positive, got %d", queryID)
+	}
+	q := url.Values{}
+	if err := opts.encode(q); err != nil {
+		return nil, err
+	}
+	var out []Snapshot
+	if err := c.get(ctx, fmt.Sprintf("%s/markets/%d/snapshots", basePath, queryID), q, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AllSnapshots pages through Snapshots like AllMarkets.
+func (c *Client) AllSnapshots(ctx context.Context, queryID int, opts ListOptions) ([]Snapshot, error) {
+	return all(opts.Limit, opts.Offset, func(limit, offset int) ([]Snapshot, error) {
+		page := opts
+		page.Limit, page.Offset = limit, offset
+		return c.Snapshots(ctx, queryID, page)
+	})
+}
+
+// Settlements returns the settlement results of a wallet, with or without a
+// 0x prefix. From and To bound the settlement time.
+func (c *Client) Settlements(ctx context.Context, wallet string, opts ListOptions) (*Settlements, error) {
+	path, err := participantPath(wallet, "settlements")
+	if err != nil {
+		return nil, err
+	}
+	q := url.Values{}
+	if err := opts.encode(q); err != nil {
+		return nil, err
+	}
+	var raw settlementsJSON
+	if err := c.get(ctx, path, q, &raw); err != nil {
+		return nil, err
+	}
+	return raw.parse()
+}
+
+// Rewards returns the LP reward distributions of a wallet, with or without a
+// 0x prefix. From and To bound the distribution time.
+func (c *Client) Rewards(ctx context.Context, wallet string, opts ListOptions) (*Rewards, error) {
+	path, err := participantPath(wallet, "rewards")
+	if err != nil {
+		return nil, err
+	}
+	q := url.Values{}
+	if err := opts.encode(q); err != nil {
+		return nil, err
+	}
+	var raw rewardsJSON
+	if err := c.get(ctx, path, q, &raw); err != nil {
+		return nil, err
+	}
+	return raw.parse()
+}
+
+func participantPath(wallet, resource string) (string, error) {
+	h := strings.TrimPrefix(strings.TrimPrefix(wallet, "0x"), "0X")
+	if len(h) != 40 {
+		return "", errors.Errorf("wallet must be 40 hex characters, got %q", wallet)
+	}
+	if _, err := hex.DecodeString(h); err != nil {
+		return "", errors.Errorf("wallet contains invalid hex characters: %q", wallet)
+	}
+	return fmt.Sprintf("%s/participants/%s/%s", basePath, h, resource), nil
+}
+
+func all[T any](limit, offset int, list func(limit, offset int) ([]T, error)) ([]T, error) {
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	var out []T
+	for {
+		page, err := list(limit, offset)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, page...)
+		if len(page) < limit {
+			return out, nil
+		}
+		offset += limit
+	}
+}
+
+func (c *Client) get(ctx context.Context, path string, q url.Values, data any) error {
+	u := *c.baseURL
+	u.Path += path
+	u.RawQuery = q.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return errors.Wrap(err, "build request")
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "GET %s", path)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "read %s", path)
+	}
+	var env envelope
+	if jsonErr := json.Unmarshal(body, &env); jsonErr != nil || resp.StatusCode/100 != 2 || !env.OK {
+		msg := env.Error
+		if msg == "" {
+			msg = strings.TrimSpace(string(body))
+		}
+		if jsonErr != nil && resp.StatusCode/100 == 2 {
+			msg = "malformed response: " + jsonErr.Error()
+		}
+		return &APIError{StatusCode: resp.StatusCode, Message: msg, Path: path}
+	}
+	if err := json.Unmarshal(env.Data, data); err != nil {
+		return errors.Wrapf(err, "decode %s", path)
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+const wallet = "c11Ff6d3cC60823EcDCAB1089F1A4336053851EF"
+
+// server serves handlers by path and records the query of each request.
+func server(t *testing.T, handlers map[string]string) (*Client, *[]url.Values) {
+	t.Helper()
+	var queries []url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query())
+		body, ok := handlers[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"ok":false,"error":"not found"}`)
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	c, err := New(srv.URL + "/")
+	require.NoError(t, err)
+	return c, &queries
+}
+
+func TestListMarkets(t *testing.T) {
+	c, queries := server(t, map[string]string{
+		"/v0/prediction-market/markets": `{"ok":true,"data":[{
+			"query_id":7,"query_hash":"0xabcd","settle_time":1700003600,"settled":true,
+			"winning_outcome":false,"settled_at":1700003700,"created_at":120,
+			"creator":"1111111111111111111111111111111111111111","max_spread":5,
+			"min_order_size":"20","bridge":"hoodi_tt2"}]}`,
+	})
+	settled := true
+	from := time.Unix(1_700_000_000, 0)
+	markets, err := c.ListMarkets(context.Background(), MarketFilter{
+		ListOptions: ListOptions{Limit: 5, Offset: 10, From: from},
+		Settled:     &settled,
+	})
+	require.NoError(t, err)
+	require.Len(t, markets, 1)
+
+	q := (*queries)[0]
+	assert.Equal(t, "5", q.Get("limit"))
+	assert.Equal(t, "10", q.Get("offset"))
+	assert.Equal(t, "1700000000", q.Get("from"))
+	assert.Empty(t, q.Get("to"))
+	assert.Equal(t, "true", q.Get("settled"))
+
+	no := false
+	at := int64(1700003700)
+	creator := make([]byte, 20)
+	for i := range creator {
+		creator[i] = 0x11
+	}
+	assert.Equal(t, types.MarketInfo{
+		ID:             7,
+		Hash:           []byte{0xab, 0xcd},
+		Bridge:         "hoodi_tt2",
+		SettleTime:     1700003600,
+		Settled:        true,
+		WinningOutcome: &no,
+		SettledAt:      &at,
+		MaxSpread:      5,
+		MinOrderSize:   20,
+		CreatedAt:      120,
+		Creator:        creator,
+	}, markets[0].MarketInfo())
+}
+
+func TestAllMarkets(t *testing.T) {
+	var offsets []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		offsets = append(offsets, r.URL.Query().Get("offset"))
+		// 5 markets in pages of 2.
+		fmt.Fprint(w, `{"ok":true,"data":[`)
+		for id := offset + 1; id <= min(offset+2, 5); id++ {
+			if id > offset+1 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"query_id":%d,"min_order_size":1}`, id)
+		}
+		fmt.Fprint(w, `]}`)
+	}))
+	defer srv.Close()
+	c, err := New(srv.URL)
+	require.NoError(t, err)
+
+	markets, err := c.AllMarkets(context.Background(), MarketFilter{ListOptions: ListOptions{Limit: 2}})
+	require.NoError(t, err)
+	require.Len(t, markets, 5)
+	assert.Equal(t, 5, markets[4].QueryID)
+	assert.Equal(t, []string{"", "2", "4"}, offsets)
+}
+
+func TestSnapshots(t *testing.T) {
+	c, queries := server(t, map[string]string{
+		"/v0/prediction-market/markets/3/snapshots": `{"ok":true,"data":[
+			{"block_height":10,"timestamp":1700000000,"yes_bid_price":45,"yes_ask_price":55,"midpoint_price":50,"spread":10},
+			{"block_height":11,"timestamp":1700000001}]}`,
+	})
+	snaps, err := c.Snapshots(context.Background(), 3, ListOptions{From: time.Unix(100, 0), To: time.Unix(200, 0)})
+	require.NoError(t, err)
+	require.Len(t, snaps, 2)
+	assert.Equal(t, 10, *snaps[0].Spread)
+	assert.Nil(t, snaps[1].MidpointPrice)
+	assert.Equal(t, "100", (*queries)[0].Get("from"))
+	assert.Equal(t, "200", (*queries)[0].Get("to"))
+
+	_, err = c.Snapshots(context.Background(), 0, ListOptions{})
+	assert.ErrorContains(t, err, "query_id must be positive")
+	_, err = c.Snapshots(context.Background(), 3, ListOptions{From: time.Unix(200, 0), To: time.Unix(100, 0)})
+	assert.ErrorContains(t, err, "before it starts")
+}
+
+func TestSettlements(t *testing.T) {
+	c, _ := server(t, map[string]string{
+		"/v0/prediction-market/participants/" + wallet + "/settlements": `{"ok":true,"data":{
+			"wallet_address":"0x` + wallet + `","total_won":1,"total_lost":0,
+			"settlements":[{"query_id":2,"winning_shares":10,"losing_shares":0,
+			"payout":"9800000000000000000000","refunded_collateral":"0","timestamp":1700000000}]}}`,
+	})
+	res, err := c.Settlements(context.Background(), "0x"+wallet, ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, res.Settlements, 1)
+	want, _ := new(big.Int).SetString("9800000000000000000000", 10)
+	assert.Equal(t, 0, want.Cmp(res.Settlements[0].Payout))
+	assert.Zero(t, res.Settlements[0].RefundedCollateral.Sign())
+	assert.Equal(t, int64(1), res.TotalWon)
+
+	_, err = c.Settlements(context.Background(), "0x1234", ListOptions{})
+	assert.ErrorContains(t, err, "40 hex characters")
+}
+
+func TestRewards(t *testing.T) {
+	c, _ := server(t, map[string]string{
+		"/v0/prediction-market/participants/" + wallet + "/rewards": `{"ok":true,"data":{
+			"wallet_address":"0x` + wallet + `","total_rewards":"1500000000000000000",
+			"rewards":[{"query_id":4,"total_reward_percent":62.5,"reward_amount":"1500000000000000000",
+			"blocks_sampled":12,"distributed_at":1700000500}]}}`,
+	})
+	res, err := c.Rewards(context.Background(), wallet, ListOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, "1500000000000000000", res.TotalRewards.String())
+	assert.Equal(t, []types.RewardHistory{{
+		QueryID:            4,
+		RewardAmount:       "1500000000000000000",
+		TotalRewardPercent: "62.50",
+		DistributedAt:      1700000500,
+	}}, res.RewardHistory())
+}
+
+func TestErrors(t *testing.T) {
+	c, _ := server(t, map[string]string{
+		"/v0/prediction-market/markets":             `{"ok":false,"error":"database unavailable"}`,
+		"/v0/prediction-market/markets/1/snapshots": `not json`,
+		"/v0/prediction-market/participants/" + wallet + "/rewards": `{"ok":true,"data":{
+			"total_rewards":"1.5","rewards":[]}}`,
+	})
+	ctx := context.Background()
+
+	_, err := c.ListMarkets(ctx, MarketFilter{})
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "database unavailable", apiErr.Message)
+
+	_, err = c.Snapshots(ctx, 2, ListOptions{})
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+
+	_, err = c.Snapshots(ctx, 1, ListOptions{})
+	assert.ErrorContains(t, err, "malformed response")
+
+	_, err = c.Rewards(ctx, wallet, ListOptions{})
+	assert.ErrorContains(t, err, "invalid amount")
+
+	_, err = New("ftp://example.com")
+	assert.Error(t, err)
+}
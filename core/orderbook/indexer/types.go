@@ -0,0 +1,262 @@
+package indexer
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+// Market is a market as recorded by the indexer.
+type Market struct {
+	QueryID        int
+	Hash           []byte // 32-byte query hash
+	SettleTime     int64  // Unix seconds
+	Settled        bool
+	WinningOutcome *bool  // nil until settled
+	SettledAt      *int64 // Unix seconds, nil until settled
+	CreatedAt      int64
+	Creator        []byte // 20-byte address
+	MaxSpread      int    // cents
+	MinOrderSize   int64  // shares
+	Bridge         string
+}
+
+// MarketInfo returns m in the shape returned by IOrderBook.GetMarketInfo.
+// The indexer does not serve query components, so QueryComponents is nil.
+func (m Market) MarketInfo() types.MarketInfo {
+	return types.MarketInfo{
+		ID:             m.QueryID,
+		Hash:           m.Hash,
+		Bridge:         m.Bridge,
+		SettleTime:     m.SettleTime,
+		Settled:        m.Settled,
+		WinningOutcome: m.WinningOutcome,
+		SettledAt:      m.SettledAt,
+		MaxSpread:      m.MaxSpread,
+		MinOrderSize:   m.MinOrderSize,
+		CreatedAt:      m.CreatedAt,
+		Creator:        m.Creator,
+	}
+}
+
+// Snapshot is the top of a market's book at a block. Prices are in cents;
+// fields are nil when the side was empty.
+type Snapshot struct {
+	BlockHeight   int64  `json:"block_height"`
+	Timestamp     int64  `json:"timestamp"`
+	YesBidPrice   *int   `json:"yes_bid_price"`
+	YesAskPrice   *int   `json:"yes_ask_price"`
+	NoBidPrice    *int   `json:"no_bid_price"`
+	NoAskPrice    *int   `json:"no_ask_price"`
+	YesVolume     *int64 `json:"yes_volume"`
+	NoVolume      *int64 `json:"no_volume"`
+	MidpointPrice *int   `json:"midpoint_price"`
+	Spread        *int   `json:"spread"`
+}
+
+// Settlement is a wallet's result in one settled market. Amounts are in wei.
+type Settlement struct {
+	QueryID            int
+	WinningShares      int64
+	LosingShares       int64
+	Payout             *big.Int
+	RefundedCollateral *big.Int
+	Timestamp          int64
+}
+
+// Settlements are the settlement results of a wallet.
+type Settlements struct {
+	WalletAddress string
+	Settlements   []Settlement
+	TotalWon      int64
+	TotalLost     int64
+}
+
+// Reward is an LP reward distribution to a wallet. RewardAmount is in wei.
+type Reward struct {
+	QueryID            int
+	TotalRewardPercent float64
+	RewardAmount       *big.Int
+	BlocksSampled      int
+	DistributedAt      int64
+}
+
+// RewardHistory returns r in the shape returned by
+// IOrderBook.GetParticipantRewardHistory. The indexer does not report
+// distribution IDs, so DistributionID is 0.
+func (r Reward) RewardHistory() types.RewardHistory {
+	return types.RewardHistory{
+		QueryID:            r.QueryID,
+		RewardAmount:       r.RewardAmount.String(),
+		TotalRewardPercent: strconv.FormatFloat(r.TotalRewardPercent, 'f', 2, 64),
+		DistributedAt:      r.DistributedAt,
+	}
+}
+
+// Rewards are the LP reward distributions of a wallet.
+type Rewards struct {
+	WalletAddress string
+	Rewards       []Reward
+	TotalRewards  *big.Int // wei
+}
+
+// RewardHistory returns every reward of rs as IOrderBook.GetParticipantRewardHistory
+// would.
+func (rs *Rewards) RewardHistory() []types.RewardHistory {
+	out := make([]types.RewardHistory, 0, len(rs.Rewards))
+	for _, r := range rs.Rewards {
+		out = append(out, r.RewardHistory())
+	}
+	return out
+}
+
+// The *JSON types mirror the indexer's wire format. Amounts arrive as
+// decimal strings (or numbers) and byte fields as hex.
+
+type marketJSON struct {
+	QueryID        int         `json:"query_id"`
+	QueryHash      string      `json:"query_hash"`
+	SettleTime     int64       `json:"settle_time"`
+	Settled        bool        `json:"settled"`
+	WinningOutcome *bool       `json:"winning_outcome"`
+	SettledAt      *int64      `json:"settled_at"`
+	CreatedAt      int64       `json:"created_at"`
+	Creator        string      `json:"creator"`
+	MaxSpread      int         `json:"max_spread"`
+	MinOrderSize   json.Number `json:"min_order_size"`
+	Bridge         string      `json:"bridge"`
+}
+
+func (m marketJSON) parse() (Market, error) {
+	hash, err := decodeHex(m.QueryHash)
+	if err != nil {
+		return Market{}, errors.Wrapf(err, "market %d: query_hash", m.QueryID)
+	}
+	creator, err := decodeHex(m.Creator)
+	if err != nil {
+		return Market{}, errors.Wrapf(err, "market %d: creator", m.QueryID)
+	}
+	var minSize int64
+	if m.MinOrderSize != "" {
+		if minSize, err = m.MinOrderSize.Int64(); err != nil {
+			return Market{}, errors.Wrapf(err, "market %d: min_order_size", m.QueryID)
+		}
+	}
+	return Market{
+		QueryID:        m.QueryID,
+		Hash:           hash,
+		SettleTime:     m.SettleTime,
+		Settled:        m.Settled,
+		WinningOutcome: m.WinningOutcome,
+		SettledAt:      m.SettledAt,
+		CreatedAt:      m.CreatedAt,
+		Creator:        creator,
+		MaxSpread:      m.MaxSpread,
+		MinOrderSize:   minSize,
+		Bridge:         m.Bridge,
+	}, nil
+}
+
+type settlementsJSON struct {
+	WalletAddress string `json:"wallet_address"`
+	Settlements   []struct {
+		QueryID            int         `json:"query_id"`
+		WinningShares      int64       `json:"winning_shares"`
+		LosingShares       int64       `json:"losing_shares"`
+		Payout             json.Number `json:"payout"`
+		RefundedCollateral json.Number `json:"refunded_collateral"`
+		Timestamp          int64       `json:"timestamp"`
+	} `json:"settlements"`
+	TotalWon  int64 `json:"total_won"`
+	TotalLost int64 `json:"total_lost"`
+}
+
+func (s settlementsJSON) parse() (*Settlements, error) {
+	out := &Settlements{
+		WalletAddress: s.WalletAddress,
+		Settlements:   make([]Settlement, 0, len(s.Settlements)),
+		TotalWon:      s.TotalWon,
+		TotalLost:     s.TotalLost,
+	}
+	for _, r := range s.Settlements {
+		payout, err := parseAmount(r.Payout)
+		if err != nil {
+			return nil, errors.Wrapf(err, "market %d: payout", r.QueryID)
+		}
+		refund, err := parseAmount(r.RefundedCollateral)
+		if err != nil {
+			return nil, errors.Wrapf(err, "market %d: refunded_collateral", r.QueryID)
+		}
+		out.Settlements = append(out.Settlements, Settlement{
+			QueryID:            r.QueryID,
+			WinningShares:      r.WinningShares,
+			LosingShares:       r.LosingShares,
+			Payout:             payout,
+			RefundedCollateral: refund,
+			Timestamp:          r.Timestamp,
+		})
+	}
+	return out, nil
+}
+
+type rewardsJSON struct {
+	WalletAddress string `json:"wallet_address"`
+	Rewards       []struct {
+		QueryID            int         `json:"query_id"`
+		TotalRewardPercent float64     `json:"total_reward_percent"`
+		RewardAmount       json.Number `json:"reward_amount"`
+		BlocksSampled      int         `json:"blocks_sampled"`
+		DistributedAt      int64       `json:"distributed_at"`
+	} `json:"rewards"`
+	TotalRewards json.Number `json:"total_rewards"`
+}
+
+func (r rewardsJSON) parse() (*Rewards, error) {
+	total, err := parseAmount(r.TotalRewards)
+	if err != nil {
+		return nil, errors.Wrap(err, "total_rewards")
+	}
+	out := &Rewards{
+		WalletAddress: r.WalletAddress,
+		Rewards:       make([]Reward, 0, len(r.Rewards)),
+		TotalRewards:  total,
+	}
+	for _, rw := range r.Rewards {
+		amount, err := parseAmount(rw.RewardAmount)
+		if err != nil {
+			return nil, errors.Wrapf(err, "market %d: reward_amount", rw.QueryID)
+		}
+		out.Rewards = append(out.Rewards, Reward{
+			QueryID:            rw.QueryID,
+			TotalRewardPercent: rw.TotalRewardPercent,
+			RewardAmount:       amount,
+			BlocksSampled:      rw.BlocksSampled,
+			DistributedAt:      rw.DistributedAt,
+		})
+	}
+	return out, nil
+}
+
+// parseAmount parses a wei amount; an absent amount is zero.
+func parseAmount(n json.Number) (*big.Int, error) {
+	if n == "" {
+		return new(big.Int), nil
+	}
+	v, ok := new(big.Int).SetString(n.String(), 10)
+	if !ok {
+		return nil, errors.Errorf("invalid amount %q", n)
+	}
+	return v, nil
+}
+
+func decodeHex(s string) ([]byte, error) {
+	if s == "" {
+		return nil, nil
+	}
+	return hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X"))
+}
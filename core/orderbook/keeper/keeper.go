@@ -0,0 +1,543 @@
+// Package keeper settles prediction markets and samples their LP rewards.
+//
+// Markets are not settled by the network on their own: after a market's
+// settle time someone has to request a signed attestation of its query and
+// call settle_market. LP rewards likewise accrue only for blocks someone
+// samples with sample_lp_rewards. A Keeper does both on a schedule:
+//
+//	k, err := keeper.Dial(ctx, endpoint, signer, keeper.Config{
+//	    PollInterval:      time.Minute,
+//	    MaxAttestationFee: "1000000000000000000",
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	go k.Run(ctx)
+//	for _, m := range k.Markets() {
+//	    fmt.Println(m.QueryID, m.State, m.Err)
+//	}
+package keeper
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	kwilClientType "github.com/trufnetwork/kwil-db/core/client/types"
+	"github.com/trufnetwork/kwil-db/core/crypto/auth"
+	"github.com/trufnetwork/kwil-db/core/log"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/contractsapi"
+	"github.com/trufnetwork/sdk-go/core/tnclient"
+	"github.com/trufnetwork/sdk-go/core/tnerrors"
+	"github.com/trufnetwork/sdk-go/core/types"
+	"github.com/trufnetwork/sdk-go/core/util"
+)
+
+// marketPageSize is the largest page list_markets returns.
+const marketPageSize = 100
+
+// attestationPageSize is the largest page list_attestations returns.
+const attestationPageSize = 5000
+
+// Backend is what a Keeper needs from the network. Its signer pays for and
+// signs every transaction, and Address returns the signer's address.
+// *tnclient.Client implements it.
+type Backend interface {
+	LoadOrderBook() (types.IOrderBook, error)
+	LoadAttestationActions() (types.IAttestationAction, error)
+	WaitForTx(ctx context.Context, txHash kwiltypes.Hash, interval time.Duration) (*kwiltypes.TxQueryResponse, error)
+	BlockHeight(ctx context.Context) (int64, error)
+	Address() util.EthereumAddress
+}
+
+var _ Backend = (*tnclient.Client)(nil)
+
+// Config configures a Keeper. Zero values select the defaults.
+type Config struct {
+	// PollInterval sets how often Run steps. Default: 30s.
+	PollInterval time.Duration
+	// SampleEvery sets how many blocks apart the LP rewards of an open market
+	// are sampled. Default: 50. Negative disables sampling.
+	SampleEvery int64
+	// MaxAttestationFee caps the fee paid for each attestation request, as a
+	// NUMERIC(78,0) string. Default: no cap.
+	MaxAttestationFee string
+	// MaxTxFee, if set, is passed as the fee of every settlement and sampling
+	// transaction.
+	MaxTxFee *big.Int
+	// MaxSettleAttempts sets how many failed settle_market transactions a
+	// market gets before the keeper marks it StateFailed. Default: 5.
+	MaxSettleAttempts int
+	// TxWaitInterval sets the polling interval while waiting for a
+	// transaction. Default: 1s.
+	TxWaitInterval time.Duration
+	// Logger receives failed steps. Default: discard.
+	Logger log.Logger
+	// Now returns the current time, compared with settle times. Default:
+	// time.Now.
+	Now func() time.Time
+}
+
+func (c Config) withDefaults() Config {
+	if c.PollInterval <= 0 {
+		c.PollInterval = 30 * time.Second
+	}
+	if c.SampleEvery == 0 {
+		c.SampleEvery = 50
+	}
+	if c.MaxSettleAttempts <= 0 {
+		c.MaxSettleAttempts = 5
+	}
+	if c.TxWaitInterval <= 0 {
+		c.TxWaitInterval = time.Second
+	}
+	if c.Logger == nil {
+		c.Logger = log.DiscardLogger
+	}
+	if c.Now == nil {
+		c.Now = time.Now
+	}
+	return c
+}
+
+// State is where a market is in the keeper's settlement flow.
+type State string
+
+const (
+	// StateOpen markets have not reached their settle time. The keeper
+	// samples their LP rewards.
+	StateOpen State = "open"
+	// StateAwaitingAttestation markets are past their settle time and wait
+	// for a signed attestation.
+	StateAwaitingAttestation State = "awaiting_attestation"
+	// StateSettling markets have a signed attestation; settle_market has not
+	// succeeded yet.
+	StateSettling State = "settling"
+	// StateSettled markets are settled, by the keeper or anyone else.
+	StateSettled State = "settled"
+	// StateFailed markets ran out of settle attempts. The keeper leaves them
+	// alone until Retry is called.
+	StateFailed State = "failed"
+)
+
+// MarketStatus is the keeper's view of one market.
+type MarketStatus struct {
+	QueryID    int
+	Hash       []byte
+	SettleTime int64
+	State      State
+
+	AttestationTxID string // request_attestation transaction of the market's attestation
+	Outcome         *bool  // outcome reported by the signed attestation
+	SettleAttempts  int    // failed settle_market transactions
+	SettleTx        *kwiltypes.Hash
+	SampledBlock    int64 // last block whose LP rewards the keeper sampled
+
+	Err       error // last error seen for the market; nil after a successful action
+	UpdatedAt time.Time
+}
+
+// Keeper settles markets and samples LP rewards. Step and Run must not be
+// called concurrently; Markets and Market are safe to call at any time.
+type Keeper struct {
+	backend Backend
+	cfg     Config
+	txOpts  []kwilClientType.TxOpt
+
+	mu      sync.Mutex
+	markets map[int]*MarketStatus
+}
+
+// New returns a keeper acting through backend.
+func New(backend Backend, cfg Config) (*Keeper, error) {
+	if backend == nil {
+		return nil, errors.New("backend is required")
+	}
+	k := &Keeper{backend: backend, cfg: cfg.withDefaults(), markets: make(map[int]*MarketStatus)}
+	if k.cfg.MaxTxFee != nil {
+		k.txOpts = append(k.txOpts, kwilClientType.WithFee(k.cfg.MaxTxFee))
+	}
+	return k, nil
+}
+
+// Dial connects to endpoint with signer and returns a keeper acting as it.
+func Dial(ctx context.Context, endpoint string, signer auth.Signer, cfg Config) (*Keeper, error) {
+	if signer == nil {
+		return nil, errors.New("signer is required")
+	}
+	client, err := tnclient.NewClient(ctx, endpoint, tnclient.WithSigner(signer))
+	if err != nil {
+		return nil, errors.Wrap(err, "create client")
+	}
+	return New(client, cfg)
+}
+
+// Markets returns the status of every market the keeper has seen, ordered by
+// query ID.
+func (k *Keeper) Markets() []MarketStatus {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	out := make([]MarketStatus, 0, len(k.markets))
+	for _, m := range k.markets {
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].QueryID < out[j].QueryID })
+	return out
+}
+
+// Market returns the status of one market.
+func (k *Keeper) Market(queryID int) (MarketStatus, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	m, ok := k.markets[queryID]
+	if !ok {
+		return MarketStatus{}, false
+	}
+	return *m, true
+}
+
+// Retry resets the settle attempts of a failed market so the next step tries
+// it again.
+func (k *Keeper) Retry(queryID int) {
+	k.update(queryID, func(m *MarketStatus) {
+		if m.State == StateFailed {
+			m.State = StateSettling
+			m.SettleAttempts = 0
+		}
+	})
+}
+
+// Run steps every PollInterval until ctx is done, logging failed steps, and
+// returns ctx.Err().
+func (k *Keeper) Run(ctx context.Context) error {
+	ticker := time.NewTicker(k.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := k.Step(ctx); err != nil {
+			k.cfg.Logger.Warn("keeper: step failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Step lists the unsettled markets and moves each one forward: open markets
+// have their LP rewards sampled when due, and markets past their settle time
+// get an attestation requested, verified and settled. Failures for a single
+// market are recorded in its status; an error is returned only when the
+// markets could not be listed.
+func (k *Keeper) Step(ctx context.Context) error {
+	ob, err := k.backend.LoadOrderBook()
+	if err != nil {
+		return errors.Wrap(err, "load order book")
+	}
+	active, err := listUnsettled(ctx, ob)
+	if err != nil {
+		return err
+	}
+	now := k.cfg.Now().Unix()
+
+	listed := make(map[int]bool, len(active))
+	var open, due []types.MarketSummary
+	for _, m := range active {
+		listed[m.ID] = true
+		k.track(m)
+		if now < m.SettleTime {
+			open = append(open, m)
+		} else {
+			due = append(due, m)
+		}
+	}
+	k.refreshUnlisted(ctx, ob, listed)
+
+	if len(open) > 0 && k.cfg.SampleEvery > 0 {
+		k.sample(ctx, ob, open)
+	}
+	if len(due) > 0 {
+		k.settleDue(ctx, ob, due)
+	}
+	return nil
+}
+
+func listUnsettled(ctx context.Context, ob types.IOrderBook) ([]types.MarketSummary, error) {
+	var out []types.MarketSummary
+	settled := false
+	for offset := 0; ; offset += marketPageSize {
+		limit, off := marketPageSize, offset
+		page, err := ob.ListMarkets(ctx, types.ListMarketsInput{SettledFilter: &settled, Limit: &limit, Offset: &off})
+		if err != nil {
+			return nil, errors.Wrapf(err, "list markets at offset %d", offset)
+		}
+		out = append(out, page...)
+		if len(page) < marketPageSize {
+			return out, nil
+		}
+	}
+}
+
+// track records a listed market, keeping the state of known ones.
+func (k *Keeper) track(m types.MarketSummary) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.markets[m.ID]; !ok {
+		k.markets[m.ID] = &MarketStatus{QueryID: m.ID, Hash: m.Hash, SettleTime: m.SettleTime, State: StateOpen, UpdatedAt: k.cfg.Now()}
+	}
+}
+
+// refreshUnlisted checks tracked markets that dropped out of the unsettled
+// list, which happens when someone else settles them.
+func (k *Keeper) refreshUnlisted(ctx context.Context, ob types.IOrderBook, listed map[int]bool) {
+	var ids []int
+	for _, m := range k.Markets() {
+		if !listed[m.QueryID] && m.State != StateSettled {
+			ids = append(ids, m.QueryID)
+		}
+	}
+	for _, id := range ids {
+		info, err := ob.GetMarketInfo(ctx, types.GetMarketInfoInput{QueryID: id})
+		if err != nil {
+			k.fail(id, errors.Wrap(err, "get market info"))
+			continue
+		}
+		if info.Settled {
+			k.update(id, func(m *MarketStatus) {
+				m.State = StateSettled
+				m.Outcome = info.WinningOutcome
+				m.Err = nil
+			})
+		}
+	}
+}
+
+// sample samples the LP rewards of open markets whose last sample is at
+// least SampleEvery blocks old.
+func (k *Keeper) sample(ctx context.Context, ob types.IOrderBook, open []types.MarketSummary) {
+	height, err := k.backend.BlockHeight(ctx)
+	if err != nil {
+		for _, m := range open {
+			k.fail(m.ID, errors.Wrap(err, "get block height"))
+		}
+		return
+	}
+	for _, m := range open {
+		status, _ := k.Market(m.ID)
+		if status.SampledBlock > 0 && height-status.SampledBlock < k.cfg.SampleEvery {
+			continue
+		}
+		hash, err := ob.SampleLPRewards(ctx, types.SampleLPRewardsInput{QueryID: m.ID, Block: height}, k.txOpts...)
+		if err == nil {
+			err = k.wait(ctx, hash)
+		}
+		if err != nil {
+			k.fail(m.ID, errors.Wrapf(err, "sample LP rewards at block %d", height))
+			continue
+		}
+		k.update(m.ID, func(s *MarketStatus) {
+			s.SampledBlock = height
+			s.Err = nil
+		})
+	}
+}
+
+// settleDue moves markets past their settle time towards settlement.
+func (k *Keeper) settleDue(ctx context.Context, ob types.IOrderBook, due []types.MarketSummary) {
+	attestations, err := k.backend.LoadAttestationActions()
+	if err != nil {
+		for _, m := range due {
+			k.fail(m.ID, errors.Wrap(err, "load attestation actions"))
+		}
+		return
+	}
+	known, err := listAttestations(ctx, attestations, k.backend.Address())
+	if err != nil {
+		for _, m := range due {
+			k.fail(m.ID, err)
+		}
+		return
+	}
+
+	for _, m := range due {
+		status, _ := k.Market(m.ID)
+		if status.State == StateFailed {
+			continue
+		}
+		att, ok := known[hex.EncodeToString(m.Hash)]
+		switch {
+		case !ok && status.AttestationTxID == "":
+			k.requestAttestation(ctx, ob, attestations, m)
+		case !ok || att.SignedHeight == nil:
+			k.update(m.ID, func(s *MarketStatus) {
+				s.State = StateAwaitingAttestation
+				if ok {
+					s.AttestationTxID = att.RequestTxID
+				}
+			})
+		default:
+			k.settle(ctx, ob, attestations, m, att)
+		}
+	}
+}
+
+// listAttestations pages through the attestations requested by requester
+// and returns the latest ones keyed by hex attestation hash, preferring signed
+// ones.
+func listAttestations(ctx context.Context, attestations types.IAttestationAction, requester util.EthereumAddress) (map[string]types.AttestationMetadata, error) {
+	order := "created_height desc"
+	out := make(map[string]types.AttestationMetadata)
+	for offset := 0; ; offset += attestationPageSize {
+		limit, off := attestationPageSize, offset
+		rows, err := attestations.ListAttestations(ctx, types.ListAttestationsInput{
+			Requester: requester.Bytes(),
+			Limit:     &limit,
+			Offset:    &off,
+			OrderBy:   &order,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "list attestations at offset %d", offset)
+		}
+		for _, row := range rows {
+			key := hex.EncodeToString(row.AttestationHash)
+			if prev, ok := out[key]; ok && (prev.SignedHeight != nil || row.SignedHeight == nil) {
+				continue
+			}
+			out[key] = row
+		}
+		if len(rows) < attestationPageSize {
+			return out, nil
+		}
+	}
+}
+
+// requestAttestation requests an attestation of the market's query and waits
+// for the request transaction. A failed request leaves AttestationTxID empty,
+// so the next step requests again.
+func (k *Keeper) requestAttestation(ctx context.Context, ob types.IOrderBook, attestations types.IAttestationAction, m types.MarketSummary) {
+	info, err := ob.GetMarketInfo(ctx, types.GetMarketInfoInput{QueryID: m.ID})
+	if err != nil {
+		k.fail(m.ID, errors.Wrap(err, "get market info"))
+		return
+	}
+	dataProvider, streamID, actionID, encodedArgs, err := contractsapi.DecodeQueryComponents(info.QueryComponents)
+	if err != nil {
+		k.fail(m.ID, errors.Wrap(err, "decode query components"))
+		return
+	}
+	args, err := contractsapi.DecodeActionArgs(encodedArgs)
+	if err != nil {
+		k.fail(m.ID, errors.Wrap(err, "decode action args"))
+		return
+	}
+	res, err := attestations.RequestAttestation(ctx, types.RequestAttestationInput{
+		DataProvider: dataProvider,
+		StreamID:     streamID,
+		ActionName:   actionID,
+		Args:         args,
+		MaxFee:       k.cfg.MaxAttestationFee,
+	})
+	if err != nil {
+		k.fail(m.ID, errors.Wrap(err, "request attestation"))
+		return
+	}
+	hash, err := kwiltypes.NewHashFromString(res.RequestTxID)
+	if err == nil {
+		err = k.wait(ctx, hash)
+	}
+	if err != nil {
+		k.update(m.ID, func(s *MarketStatus) {
+			s.State = StateAwaitingAttestation
+			s.AttestationTxID = ""
+		})
+		k.fail(m.ID, errors.Wrapf(err, "request attestation tx %s", res.RequestTxID))
+		return
+	}
+	k.update(m.ID, func(s *MarketStatus) {
+		s.State = StateAwaitingAttestation
+		s.AttestationTxID = res.RequestTxID
+		s.Err = nil
+	})
+}
+
+// settle verifies the signed attestation carries a binary outcome and calls
+// settle_market.
+func (k *Keeper) settle(ctx context.Context, ob types.IOrderBook, attestations types.IAttestationAction, m types.MarketSummary, att types.AttestationMetadata) {
+	status, _ := k.Market(m.ID)
+	if status.Outcome == nil || status.AttestationTxID != att.RequestTxID {
+		outcome, err := verify(ctx, attestations, att.RequestTxID)
+		if err != nil {
+			k.fail(m.ID, err)
+			return
+		}
+		k.update(m.ID, func(s *MarketStatus) {
+			s.AttestationTxID = att.RequestTxID
+			s.Outcome = &outcome
+		})
+	}
+	k.update(m.ID, func(s *MarketStatus) { s.State = StateSettling })
+
+	hash, err := ob.SettleMarket(ctx, types.SettleMarketInput{QueryID: m.ID}, k.txOpts...)
+	if err == nil {
+		err = k.wait(ctx, hash)
+	}
+	k.update(m.ID, func(s *MarketStatus) {
+		if err != nil {
+			s.SettleAttempts++
+			s.Err = errors.Wrap(err, "settle market")
+			if s.SettleAttempts >= k.cfg.MaxSettleAttempts {
+				s.State = StateFailed
+			}
+			return
+		}
+		s.State = StateSettled
+		s.SettleTx = &hash
+		s.Err = nil
+	})
+}
+
+// verify fetches the signed attestation and returns the outcome it reports.
+func verify(ctx context.Context, attestations types.IAttestationAction, requestTxID string) (bool, error) {
+	signed, err := attestations.GetSignedAttestation(ctx, types.GetSignedAttestationInput{RequestTxID: requestTxID})
+	if err != nil {
+		return false, errors.Wrapf(err, "get signed attestation %s", requestTxID)
+	}
+	payload, _, err := contractsapi.SplitSignedAttestation(signed.Payload)
+	if err != nil {
+		return false, errors.Wrapf(err, "attestation %s", requestTxID)
+	}
+	outcome, _, err := contractsapi.ParseBooleanResult(payload)
+	if err != nil {
+		return false, errors.Wrapf(err, "attestation %s has no binary outcome", requestTxID)
+	}
+	return outcome, nil
+}
+
+func (k *Keeper) wait(ctx context.Context, hash kwiltypes.Hash) error {
+	res, err := k.backend.WaitForTx(ctx, hash, k.cfg.TxWaitInterval)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return tnerrors.FromTxResult("", hash, res)
+}
+
+func (k *Keeper) update(queryID int, fn func(*MarketStatus)) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if m, ok := k.markets[queryID]; ok {
+		fn(m)
+		m.UpdatedAt = k.cfg.Now()
+	}
+}
+
+func (k *Keeper) fail(queryID int, err error) {
+	k.cfg.Logger.Warn("keeper: market action failed", "query_id", queryID, "error", err)
+	k.update(queryID, func(m *MarketStatus) { m.Err = err })
+}
@@ -0,0 +1,355 @@
+package keeper
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kwilClientType "github.com/trufnetwork/kwil-db/core/client/types"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/contractsapi"
+	"github.com/trufnetwork/sdk-go/core/types"
+	"github.com/trufnetwork/sdk-go/core/util"
+)
+
+const now = int64(1_700_000_000)
+
+var keeperAddress = util.Unsafe_NewEthereumAddressFromString("0x2222222222222222222222222222222222222222")
+
+// fakeNetwork plays the order book and attestation actions. Any other method
+// panics through the nil embedded interfaces.
+type fakeNetwork struct {
+	types.IOrderBook
+	types.IAttestationAction
+
+	markets      []types.MarketInfo
+	attestations []types.AttestationMetadata
+	payloads     map[string][]byte
+	height       int64
+	failTx       map[kwiltypes.Hash]bool
+	failSettle   bool
+	failRequest  bool
+
+	requested []types.RequestAttestationInput
+	sampled   []types.SampleLPRewardsInput
+	settled   []int
+	txs       int
+}
+
+func (f *fakeNetwork) LoadOrderBook() (types.IOrderBook, error) { return f, nil }
+func (f *fakeNetwork) LoadAttestationActions() (types.IAttestationAction, error) {
+	return f, nil
+}
+func (f *fakeNetwork) BlockHeight(context.Context) (int64, error) { return f.height, nil }
+func (f *fakeNetwork) Address() util.EthereumAddress              { return keeperAddress }
+
+func (f *fakeNetwork) WaitForTx(_ context.Context, hash kwiltypes.Hash, _ time.Duration) (*kwiltypes.TxQueryResponse, error) {
+	code := uint32(kwiltypes.CodeOk)
+	if f.failTx[hash] {
+		code = uint32(kwiltypes.CodeUnknownError)
+	}
+	return &kwiltypes.TxQueryResponse{Hash: hash, Result: &kwiltypes.TxResult{Code: code, Log: "attestation not found"}}, nil
+}
+
+func (f *fakeNetwork) tx() kwiltypes.Hash {
+	f.txs++
+	return kwiltypes.Hash{byte(f.txs)}
+}
+
+func (f *fakeNetwork) ListMarkets(_ context.Context, in types.ListMarketsInput) ([]types.MarketSummary, error) {
+	var out []types.MarketSummary
+	for _, m := range f.markets {
+		if in.SettledFilter != nil && m.Settled != *in.SettledFilter {
+			continue
+		}
+		out = append(out, types.MarketSummary{ID: m.ID, Hash: m.Hash, SettleTime: m.SettleTime, Settled: m.Settled})
+	}
+	if *in.Offset >= len(out) {
+		return nil, nil
+	}
+	return out[*in.Offset:min(len(out), *in.Offset+*in.Limit)], nil
+}
+
+func (f *fakeNetwork) GetMarketInfo(_ context.Context, in types.GetMarketInfoInput) (*types.MarketInfo, error) {
+	for i := range f.markets {
+		if f.markets[i].ID == in.QueryID {
+			m := f.markets[i]
+			return &m, nil
+		}
+	}
+	return nil, errors.New("market not found")
+}
+
+func (f *fakeNetwork) SampleLPRewards(_ context.Context, in types.SampleLPRewardsInput, _ ...kwilClientType.TxOpt) (kwiltypes.Hash, error) {
+	f.sampled = append(f.sampled, in)
+	return f.tx(), nil
+}
+
+func (f *fakeNetwork) SettleMarket(_ context.Context, in types.SettleMarketInput, _ ...kwilClientType.TxOpt) (kwiltypes.Hash, error) {
+	hash := f.tx()
+	if f.failSettle {
+		f.failTx[hash] = true
+		return hash, nil
+	}
+	f.settled = append(f.settled, in.QueryID)
+	for i := range f.markets {
+		if f.markets[i].ID == in.QueryID {
+			f.markets[i].Settled = true
+		}
+	}
+	return hash, nil
+}
+
+func (f *fakeNetwork) ListAttestations(_ context.Context, in types.ListAttestationsInput) ([]types.AttestationMetadata, error) {
+	var out []types.AttestationMetadata
+	for _, a := range f.attestations {
+		if bytes.Equal(a.Requester, in.Requester) {
+			out = append(out, a)
+		}
+	}
+	if *in.Offset >= len(out) {
+		return nil, nil
+	}
+	return out[*in.Offset:min(len(out), *in.Offset+*in.Limit)], nil
+}
+
+func (f *fakeNetwork) RequestAttestation(_ context.Context, in types.RequestAttestationInput) (*types.RequestAttestationResult, error) {
+	f.requested = append(f.requested, in)
+	hash := f.tx()
+	if f.failRequest {
+		f.failTx[hash] = true
+	}
+	return &types.RequestAttestationResult{RequestTxID: hash.String()}, nil
+}
+
+// attestation returns a row of the keeper's attestation of market 2.
+func attestation(requestTxID string, signedHeight *int64) types.AttestationMetadata {
+	return types.AttestationMetadata{
+		RequestTxID:     requestTxID,
+		AttestationHash: []byte{0xbb},
+		Requester:       keeperAddress.Bytes(),
+		SignedHeight:    signedHeight,
+	}
+}
+
+func (f *fakeNetwork) GetSignedAttestation(_ context.Context, in types.GetSignedAttestationInput) (*types.SignedAttestationResult, error) {
+	payload, ok := f.payloads[in.RequestTxID]
+	if !ok {
+		return nil, errors.New("not signed")
+	}
+	return &types.SignedAttestationResult{Payload: payload}, nil
+}
+
+// booleanPayload builds a signed payload of a binary action returning result.
+func booleanPayload(result bool) []byte {
+	lengthPrefixed := func(b []byte) []byte {
+		return append(binary.BigEndian.AppendUint32(nil, uint32(len(b))), b...)
+	}
+	p := []byte{1, 0}
+	p = binary.BigEndian.AppendUint64(p, 100)
+	p = append(p, lengthPrefixed(make([]byte, 20))...)
+	p = append(p, lengthPrefixed(make([]byte, 32))...)
+	p = binary.BigEndian.AppendUint16(p, 6)
+	p = append(p, lengthPrefixed(nil)...)
+	word := make([]byte, 32)
+	if result {
+		word[31] = 1
+	}
+	p = append(p, lengthPrefixed(word)...)
+	return append(p, make([]byte, contractsapi.AttestationSignatureLength)...)
+}
+
+func newNetwork(t *testing.T) *fakeNetwork {
+	t.Helper()
+	streamID := util.GenerateStreamId("keeper")
+	components, err := contractsapi.BuildPriceAboveThresholdQueryComponents(types.PriceAboveThresholdInput{
+		DataProvider: "0x1111111111111111111111111111111111111111",
+		StreamID:     streamID.String(),
+		Timestamp:    now,
+		Threshold:    "100",
+	})
+	require.NoError(t, err)
+	return &fakeNetwork{
+		markets: []types.MarketInfo{
+			{ID: 1, Hash: []byte{0xaa}, SettleTime: now + 3600},
+			{ID: 2, Hash: []byte{0xbb}, SettleTime: now - 60, QueryComponents: components},
+		},
+		payloads: map[string][]byte{},
+		failTx:   map[kwiltypes.Hash]bool{},
+		height:   1000,
+	}
+}
+
+func newKeeper(t *testing.T, net *fakeNetwork, cfg Config) *Keeper {
+	t.Helper()
+	cfg.Now = func() time.Time { return time.Unix(now, 0) }
+	cfg.TxWaitInterval = time.Millisecond
+	k, err := New(net, cfg)
+	require.NoError(t, err)
+	return k
+}
+
+func TestKeeper_SettlementFlow(t *testing.T) {
+	ctx := context.Background()
+	net := newNetwork(t)
+	k := newKeeper(t, net, Config{MaxAttestationFee: "500"})
+
+	// Step 1: market 2 is due and has no attestation yet.
+	require.NoError(t, k.Step(ctx))
+	require.Len(t, net.requested, 1)
+	req := net.requested[0]
+	assert.Equal(t, "price_above_threshold", req.ActionName)
+	assert.Equal(t, "500", req.MaxFee)
+	assert.Len(t, req.Args, 5)
+	m2, _ := k.Market(2)
+	assert.Equal(t, StateAwaitingAttestation, m2.State)
+	requestTxID := m2.AttestationTxID
+	assert.NotEmpty(t, requestTxID)
+
+	// Step 2: the attestation exists but is not signed; nothing is re-requested.
+	net.attestations = []types.AttestationMetadata{attestation(requestTxID, nil)}
+	require.NoError(t, k.Step(ctx))
+	assert.Len(t, net.requested, 1)
+	assert.Empty(t, net.settled)
+
+	// Step 3: signed; the keeper verifies the outcome and settles.
+	signed := int64(12)
+	net.attestations[0].SignedHeight = &signed
+	net.payloads[requestTxID] = booleanPayload(true)
+	require.NoError(t, k.Step(ctx))
+	assert.Equal(t, []int{2}, net.settled)
+	m2, _ = k.Market(2)
+	assert.Equal(t, StateSettled, m2.State)
+	require.NotNil(t, m2.Outcome)
+	assert.True(t, *m2.Outcome)
+	assert.NotNil(t, m2.SettleTx)
+	assert.NoError(t, m2.Err)
+
+	// Market 1 is open and had its LP rewards sampled once.
+	m1, _ := k.Market(1)
+	assert.Equal(t, StateOpen, m1.State)
+	assert.Equal(t, []types.SampleLPRewardsInput{{QueryID: 1, Block: 1000}}, net.sampled)
+
+	// Settled markets leave the unsettled list and are not touched again.
+	require.NoError(t, k.Step(ctx))
+	assert.Equal(t, []int{2}, net.settled)
+	assert.Len(t, k.Markets(), 2)
+}
+
+func TestKeeper_SettleRetries(t *testing.T) {
+	ctx := context.Background()
+	net := newNetwork(t)
+	signed := int64(12)
+	net.attestations = []types.AttestationMetadata{attestation("0xr", &signed)}
+	net.payloads["0xr"] = booleanPayload(false)
+	net.failSettle = true
+	k := newKeeper(t, net, Config{MaxSettleAttempts: 2, SampleEvery: -1})
+
+	require.NoError(t, k.Step(ctx))
+	m2, _ := k.Market(2)
+	assert.Equal(t, StateSettling, m2.State)
+	assert.Equal(t, 1, m2.SettleAttempts)
+	assert.ErrorContains(t, m2.Err, "attestation not found")
+
+	require.NoError(t, k.Step(ctx))
+	m2, _ = k.Market(2)
+	assert.Equal(t, StateFailed, m2.State)
+
+	// Failed markets are left alone until retried.
+	require.NoError(t, k.Step(ctx))
+	assert.Equal(t, 2, net.txs)
+
+	net.failSettle = false
+	k.Retry(2)
+	require.NoError(t, k.Step(ctx))
+	m2, _ = k.Market(2)
+	assert.Equal(t, StateSettled, m2.State)
+	assert.False(t, *m2.Outcome)
+	assert.Empty(t, net.sampled, "sampling disabled")
+}
+
+func TestKeeper_FailedAttestationRequest(t *testing.T) {
+	ctx := context.Background()
+	net := newNetwork(t)
+	net.failRequest = true
+	k := newKeeper(t, net, Config{SampleEvery: -1})
+
+	require.NoError(t, k.Step(ctx))
+	m2, _ := k.Market(2)
+	assert.Equal(t, StateAwaitingAttestation, m2.State)
+	assert.Empty(t, m2.AttestationTxID)
+	assert.ErrorContains(t, m2.Err, "request attestation tx")
+
+	// The next step requests again.
+	net.failRequest = false
+	require.NoError(t, k.Step(ctx))
+	assert.Len(t, net.requested, 2)
+	m2, _ = k.Market(2)
+	assert.NotEmpty(t, m2.AttestationTxID)
+	assert.NoError(t, m2.Err)
+}
+
+func TestKeeper_FindsOlderAttestations(t *testing.T) {
+	ctx := context.Background()
+	net := newNetwork(t)
+	// A full page of newer attestations of other queries, and another
+	// requester's attestation of the market, come before the keeper's own.
+	for i := 0; i < attestationPageSize; i++ {
+		net.attestations = append(net.attestations, types.AttestationMetadata{
+			RequestTxID:     "0xother",
+			AttestationHash: binary.BigEndian.AppendUint32(nil, uint32(i)),
+			Requester:       keeperAddress.Bytes(),
+		})
+	}
+	other := attestation("0xforeign", nil)
+	other.Requester = make([]byte, 20)
+	signed := int64(12)
+	net.attestations = append(net.attestations, other, attestation("0xr", &signed))
+	net.payloads["0xr"] = booleanPayload(true)
+	k := newKeeper(t, net, Config{SampleEvery: -1})
+
+	require.NoError(t, k.Step(ctx))
+	assert.Empty(t, net.requested)
+	assert.Equal(t, []int{2}, net.settled)
+	m2, _ := k.Market(2)
+	assert.Equal(t, "0xr", m2.AttestationTxID)
+}
+
+func TestKeeper_SamplingSchedule(t *testing.T) {
+	ctx := context.Background()
+	net := newNetwork(t)
+	net.markets = net.markets[:1]
+	k := newKeeper(t, net, Config{SampleEvery: 10})
+
+	for _, height := range []int64{1000, 1005, 1010, 1011} {
+		net.height = height
+		require.NoError(t, k.Step(ctx))
+	}
+	require.Len(t, net.sampled, 2)
+	assert.Equal(t, int64(1010), net.sampled[1].Block)
+}
+
+func TestKeeper_SettledElsewhere(t *testing.T) {
+	ctx := context.Background()
+	net := newNetwork(t)
+	k := newKeeper(t, net, Config{SampleEvery: -1})
+	require.NoError(t, k.Step(ctx))
+
+	yes := true
+	net.markets[1].Settled = true
+	net.markets[1].WinningOutcome = &yes
+	require.NoError(t, k.Step(ctx))
+	m2, _ := k.Market(2)
+	assert.Equal(t, StateSettled, m2.State)
+	assert.Empty(t, net.settled)
+}
+
+func TestNew_Validation(t *testing.T) {
+	_, err := New(nil, Config{})
+	assert.ErrorContains(t, err, "backend is required")
+}
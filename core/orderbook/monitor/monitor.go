@@ -0,0 +1,396 @@
+// Package monitor watches the collateral integrity of every market.
+//
+// validate_market_collateral checks one market: that its YES and NO share
+// supplies are equal and that its vault holds the collateral its shares and
+// open buy orders require. A Monitor sweeps all markets with it on a
+// schedule, keeps a history of each market's totals to show drift, and
+// raises an Alert through a Notifier when an invariant breaks or recovers:
+//
+//	m, err := monitor.New(ob, monitor.LogNotifier(logger), monitor.Config{
+//	    Interval:    time.Minute,
+//	    Concurrency: 16,
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	go m.Run(ctx)
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/trufnetwork/kwil-db/core/log"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+// marketPageSize is the largest page list_markets returns.
+const marketPageSize = 100
+
+// AlertKind is the invariant an alert is about.
+type AlertKind string
+
+const (
+	// AlertTokenParity means the market's YES and NO share supplies differ.
+	AlertTokenParity AlertKind = "token_parity"
+	// AlertCollateral means the market's vault balance differs from the
+	// collateral its shares and open buy orders require.
+	AlertCollateral AlertKind = "collateral"
+	// AlertCheckFailed means validate_market_collateral itself failed.
+	AlertCheckFailed AlertKind = "check_failed"
+)
+
+// Alert reports an invariant of a market breaking, or holding again.
+type Alert struct {
+	Kind     AlertKind
+	QueryID  int
+	Resolved bool // the invariant holds again
+	Time     time.Time
+	Message  string
+
+	// Sample is the validation that raised the alert; nil for
+	// AlertCheckFailed.
+	Sample *Sample
+	// Err is the failure of an AlertCheckFailed alert.
+	Err error
+}
+
+func (a Alert) String() string {
+	state := "broken"
+	if a.Resolved {
+		state = "resolved"
+	}
+	return fmt.Sprintf("market %d %s %s: %s", a.QueryID, a.Kind, state, a.Message)
+}
+
+// Notifier delivers alerts.
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// NotifierFunc adapts a function to the Notifier interface.
+type NotifierFunc func(ctx context.Context, alert Alert) error
+
+// Notify calls f.
+func (f NotifierFunc) Notify(ctx context.Context, alert Alert) error {
+	return f(ctx, alert)
+}
+
+// LogNotifier returns a notifier logging broken invariants as errors and
+// resolved ones as info.
+func LogNotifier(logger log.Logger) Notifier {
+	return NotifierFunc(func(_ context.Context, a Alert) error {
+		if a.Resolved {
+			logger.Info("monitor: invariant restored", "market", a.QueryID, "kind", string(a.Kind), "message", a.Message)
+		} else {
+			logger.Error("monitor: invariant broken", "market", a.QueryID, "kind", string(a.Kind), "message", a.Message)
+		}
+		return nil
+	})
+}
+
+// Sample is one validation of a market.
+type Sample struct {
+	Time               time.Time
+	TotalTrue          int64
+	TotalFalse         int64
+	VaultBalance       *big.Int
+	ExpectedCollateral *big.Int
+	OpenBuysValue      int64
+	ValidTokenBinaries bool
+	ValidCollateral    bool
+}
+
+// Shortfall returns ExpectedCollateral - VaultBalance: positive when the
+// vault is short, negative when it holds a surplus.
+func (s *Sample) Shortfall() *big.Int {
+	return new(big.Int).Sub(s.ExpectedCollateral, s.VaultBalance)
+}
+
+// MarketHealth is the monitor's view of one market.
+type MarketHealth struct {
+	QueryID int
+	Latest  *Sample     // nil until the first successful validation
+	Broken  []AlertKind // invariants currently broken
+	Err     error       // last validation failure; nil after a success
+
+	// Drift of the totals over the kept history, latest minus oldest.
+	TrueDrift  int64
+	FalseDrift int64
+	VaultDrift *big.Int
+}
+
+// Config configures a Monitor. Zero values select the defaults.
+type Config struct {
+	// Interval sets how often Run sweeps. Default: 1m.
+	Interval time.Duration
+	// Concurrency bounds the markets validated at once. Default: 8.
+	Concurrency int
+	// History sets how many samples are kept per market. Default: 100.
+	History int
+	// Settled restricts the sweep to settled (true) or unsettled (false)
+	// markets. Default: all markets.
+	Settled *bool
+	// Logger receives failed sweeps and notifications. Default: discard.
+	Logger log.Logger
+	// Now timestamps samples and alerts. Default: time.Now.
+	Now func() time.Time
+}
+
+func (c Config) withDefaults() Config {
+	if c.Interval <= 0 {
+		c.Interval = time.Minute
+	}
+	if c.Concurrency <= 0 {
+		c.Concurrency = 8
+	}
+	if c.History <= 0 {
+		c.History = 100
+	}
+	if c.Logger == nil {
+		c.Logger = log.DiscardLogger
+	}
+	if c.Now == nil {
+		c.Now = time.Now
+	}
+	return c
+}
+
+type marketState struct {
+	samples []*Sample
+	broken  map[AlertKind]bool
+	err     error
+}
+
+// Monitor sweeps markets for collateral integrity. Sweep and Run must not be
+// called concurrently; Health and History are safe to call at any time.
+type Monitor struct {
+	ob       types.IOrderBook
+	notifier Notifier
+	cfg      Config
+
+	mu      sync.Mutex
+	markets map[int]*marketState
+}
+
+// New returns a monitor reading ob and alerting through notifier.
+func New(ob types.IOrderBook, notifier Notifier, cfg Config) (*Monitor, error) {
+	if ob == nil {
+		return nil, errors.New("order book is required")
+	}
+	if notifier == nil {
+		return nil, errors.New("notifier is required")
+	}
+	return &Monitor{ob: ob, notifier: notifier, cfg: cfg.withDefaults(), markets: make(map[int]*marketState)}, nil
+}
+
+// Run sweeps until ctx is done, logging failed sweeps, and returns
+// ctx.Err().
+func (m *Monitor) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := m.Sweep(ctx); err != nil && ctx.Err() == nil {
+			m.cfg.Logger.Warn("monitor: sweep failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep validates every listed market once, notifies the alerts it raises
+// and returns them. It fails only if the markets cannot be listed; failed
+// validations become AlertCheckFailed alerts. Notifier errors are logged.
+func (m *Monitor) Sweep(ctx context.Context) ([]Alert, error) {
+	ids, err := m.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*types.MarketValidation, len(ids))
+	errs := make([]error, len(ids))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(m.cfg.Concurrency, len(ids)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i], errs[i] = m.ob.ValidateMarketCollateral(ctx, types.ValidateMarketCollateralInput{QueryID: ids[i]})
+			}
+		}()
+	}
+	for i := range ids {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	now := m.cfg.Now()
+	var alerts []Alert
+	m.mu.Lock()
+	for i, id := range ids {
+		alerts = append(alerts, m.record(id, now, results[i], errs[i])...)
+	}
+	m.mu.Unlock()
+
+	for _, a := range alerts {
+		if err := m.notifier.Notify(ctx, a); err != nil {
+			m.cfg.Logger.Warn("monitor: notify failed", "alert", a.String(), "error", err)
+		}
+	}
+	return alerts, nil
+}
+
+// Health returns the health of every market seen, ordered by query ID.
+func (m *Monitor) Health() []MarketHealth {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]MarketHealth, 0, len(m.markets))
+	for id := range m.markets {
+		out = append(out, m.health(id))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].QueryID < out[j].QueryID })
+	return out
+}
+
+// MarketHealth returns the health of one market, or nil if it was never
+// swept.
+func (m *Monitor) MarketHealth(queryID int) *MarketHealth {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.markets[queryID] == nil {
+		return nil
+	}
+	h := m.health(queryID)
+	return &h
+}
+
+// History returns the kept samples of a market, oldest first.
+func (m *Monitor) History(queryID int) []Sample {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st := m.markets[queryID]
+	if st == nil {
+		return nil
+	}
+	out := make([]Sample, len(st.samples))
+	for i, s := range st.samples {
+		out[i] = *s
+	}
+	return out
+}
+
+func (m *Monitor) health(id int) MarketHealth {
+	st := m.markets[id]
+	h := MarketHealth{QueryID: id, Err: st.err, VaultDrift: new(big.Int)}
+	for _, kind := range []AlertKind{AlertTokenParity, AlertCollateral, AlertCheckFailed} {
+		if st.broken[kind] {
+			h.Broken = append(h.Broken, kind)
+		}
+	}
+	if n := len(st.samples); n > 0 {
+		first, last := st.samples[0], st.samples[n-1]
+		latest := *last
+		h.Latest = &latest
+		h.TrueDrift = last.TotalTrue - first.TotalTrue
+		h.FalseDrift = last.TotalFalse - first.TotalFalse
+		h.VaultDrift.Sub(last.VaultBalance, first.VaultBalance)
+	}
+	return h
+}
+
+// record stores a validation result and returns the alerts of the
+// invariants that changed state. m.mu must be held.
+func (m *Monitor) record(id int, now time.Time, v *types.MarketValidation, verr error) []Alert {
+	st := m.markets[id]
+	if st == nil {
+		st = &marketState{broken: make(map[AlertKind]bool)}
+		m.markets[id] = st
+	}
+	var alerts []Alert
+	transition := func(kind AlertKind, broken bool, alert Alert) {
+		if st.broken[kind] == broken {
+			return
+		}
+		st.broken[kind] = broken
+		alert.Kind, alert.QueryID, alert.Resolved, alert.Time = kind, id, !broken, now
+		alerts = append(alerts, alert)
+	}
+
+	if verr == nil && v == nil {
+		verr = errors.New("no validation result")
+	}
+	var s *Sample
+	if verr == nil {
+		s, verr = sample(now, v)
+	}
+	if verr != nil {
+		st.err = verr
+		transition(AlertCheckFailed, true, Alert{Message: verr.Error(), Err: verr})
+		return alerts
+	}
+	st.err = nil
+	transition(AlertCheckFailed, false, Alert{Message: "validation succeeds again"})
+
+	st.samples = append(st.samples, s)
+	if over := len(st.samples) - m.cfg.History; over > 0 {
+		st.samples = st.samples[over:]
+	}
+
+	parity := s.ValidTokenBinaries && s.TotalTrue == s.TotalFalse
+	msg := fmt.Sprintf("total_true=%d total_false=%d", s.TotalTrue, s.TotalFalse)
+	transition(AlertTokenParity, !parity, Alert{Message: msg, Sample: s})
+
+	msg = fmt.Sprintf("vault_balance=%s expected_collateral=%s shortfall=%s", s.VaultBalance, s.ExpectedCollateral, s.Shortfall())
+	transition(AlertCollateral, !s.ValidCollateral, Alert{Message: msg, Sample: s})
+	return alerts
+}
+
+func sample(now time.Time, v *types.MarketValidation) (*Sample, error) {
+	vault, ok := new(big.Int).SetString(v.VaultBalance, 10)
+	if !ok {
+		return nil, errors.Errorf("invalid vault_balance %q", v.VaultBalance)
+	}
+	expected, ok := new(big.Int).SetString(v.ExpectedCollateral, 10)
+	if !ok {
+		return nil, errors.Errorf("invalid expected_collateral %q", v.ExpectedCollateral)
+	}
+	return &Sample{
+		Time:               now,
+		TotalTrue:          v.TotalTrue,
+		TotalFalse:         v.TotalFalse,
+		VaultBalance:       vault,
+		ExpectedCollateral: expected,
+		OpenBuysValue:      v.OpenBuysValue,
+		ValidTokenBinaries: v.ValidTokenBinaries,
+		ValidCollateral:    v.ValidCollateral,
+	}, nil
+}
+
+func (m *Monitor) list(ctx context.Context) ([]int, error) {
+	var ids []int
+	for offset := 0; ; offset += marketPageSize {
+		limit, off := marketPageSize, offset
+		page, err := m.ob.ListMarkets(ctx, types.ListMarketsInput{SettledFilter: m.cfg.Settled, Limit: &limit, Offset: &off})
+		if err != nil {
+			return nil, errors.Wrapf(err, "list markets at offset %d", offset)
+		}
+		for _, mk := range page {
+			ids = append(ids, mk.ID)
+		}
+		if len(page) < marketPageSize {
+			return ids, nil
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+// fakeBook lists markets and returns scripted validations. Any other method
+// panics through the nil embedded interface.
+type fakeBook struct {
+	types.IOrderBook
+
+	mu          sync.Mutex
+	markets     int
+	validations map[int]*types.MarketValidation
+	errs        map[int]error
+	delay       time.Duration
+
+	inFlight, maxInFlight atomic.Int32
+}
+
+func (f *fakeBook) ListMarkets(_ context.Context, in types.ListMarketsInput) ([]types.MarketSummary, error) {
+	var out []types.MarketSummary
+	for id := *in.Offset + 1; id <= min(*in.Offset+*in.Limit, f.markets); id++ {
+		out = append(out, types.MarketSummary{ID: id})
+	}
+	return out, nil
+}
+
+func (f *fakeBook) ValidateMarketCollateral(_ context.Context, in types.ValidateMarketCollateralInput) (*types.MarketValidation, error) {
+	n := f.inFlight.Add(1)
+	defer f.inFlight.Add(-1)
+	for {
+		peak := f.maxInFlight.Load()
+		if n <= peak || f.maxInFlight.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(f.delay)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.errs[in.QueryID]; err != nil {
+		return nil, err
+	}
+	if v := f.validations[in.QueryID]; v != nil {
+		c := *v
+		return &c, nil
+	}
+	return healthy(10, "1000"), nil
+}
+
+func (f *fakeBook) set(id int, v *types.MarketValidation, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.validations[id], f.errs[id] = v, err
+}
+
+func healthy(shares int64, vault string) *types.MarketValidation {
+	return &types.MarketValidation{
+		ValidTokenBinaries: true,
+		ValidCollateral:    true,
+		TotalTrue:          shares,
+		TotalFalse:         shares,
+		VaultBalance:       vault,
+		ExpectedCollateral: vault,
+	}
+}
+
+func kinds(alerts []Alert) []string {
+	var out []string
+	for _, a := range alerts {
+		s := string(a.Kind)
+		if a.Resolved {
+			s += " resolved"
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func TestSweep(t *testing.T) {
+	ctx := context.Background()
+	book := &fakeBook{markets: 3, validations: map[int]*types.MarketValidation{}, errs: map[int]error{}}
+	var notified []Alert
+	m, err := New(book, NotifierFunc(func(_ context.Context, a Alert) error {
+		notified = append(notified, a)
+		return errors.New("pager down") // logged, not fatal
+	}), Config{})
+	require.NoError(t, err)
+
+	parity := healthy(10, "1000")
+	parity.ValidTokenBinaries, parity.TotalFalse = false, 9
+	book.set(2, parity, nil)
+	book.set(3, nil, errors.New("node unavailable"))
+
+	alerts, err := m.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"token_parity", "check_failed"}, kinds(alerts))
+	assert.Equal(t, 2, alerts[0].QueryID)
+	assert.Equal(t, "total_true=10 total_false=9", alerts[0].Message)
+	assert.Equal(t, 3, alerts[1].QueryID)
+	assert.EqualError(t, alerts[1].Err, "node unavailable")
+	assert.Equal(t, alerts, notified)
+
+	// Nothing changed: no new alerts.
+	alerts, err = m.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, alerts)
+
+	// Market 2 recovers; market 3 answers but its vault is short; market 1
+	// grows.
+	book.set(1, healthy(25, "2500"), nil)
+	book.set(2, nil, nil)
+	short := healthy(5, "400")
+	short.ValidCollateral, short.ExpectedCollateral = false, "500"
+	book.set(3, short, nil)
+	alerts, err = m.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"token_parity resolved", "check_failed resolved", "collateral"}, kinds(alerts))
+	assert.Equal(t, big.NewInt(100), alerts[2].Sample.Shortfall())
+
+	health := m.Health()
+	require.Len(t, health, 3)
+	assert.Equal(t, int64(15), health[0].TrueDrift)
+	assert.Equal(t, int64(15), health[0].FalseDrift)
+	assert.Equal(t, big.NewInt(1500), health[0].VaultDrift)
+	assert.Empty(t, health[1].Broken)
+	assert.Equal(t, []AlertKind{AlertCollateral}, health[2].Broken)
+	assert.Len(t, m.History(1), 3)
+	assert.Len(t, m.History(3), 1)
+	assert.Nil(t, m.MarketHealth(4))
+}
+
+func TestSweep_ConcurrencyAndHistory(t *testing.T) {
+	book := &fakeBook{markets: 150, validations: map[int]*types.MarketValidation{}, errs: map[int]error{}, delay: time.Millisecond}
+	m, err := New(book, NotifierFunc(func(context.Context, Alert) error { return nil }), Config{Concurrency: 4, History: 2})
+	require.NoError(t, err)
+
+	for range 3 {
+		alerts, err := m.Sweep(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, alerts)
+	}
+	assert.Len(t, m.Health(), 150)
+	assert.Len(t, m.History(150), 2)
+	assert.LessOrEqual(t, book.maxInFlight.Load(), int32(4))
+	assert.Greater(t, book.maxInFlight.Load(), int32(1))
+}
+
+func TestNew_Validation(t *testing.T) {
+	_, err := New(nil, LogNotifier(nil), Config{})
+	assert.ErrorContains(t, err, "order book is required")
+	_, err = New(&fakeBook{}, nil, Config{})
+	assert.ErrorContains(t, err, "notifier is required")
+}
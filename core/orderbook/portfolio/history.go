@@ -0,0 +1,134 @@
+package portfolio
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+// feePageSize is the largest page list_transaction_fees returns.
+const feePageSize = 1000
+
+// RewardEntry is an LP reward paid to the wallet.
+type RewardEntry struct {
+	types.RewardHistory
+	Bridge     string   // collateral bridge of the market
+	Amount     *big.Int // RewardAmount, in the bridge token's smallest unit
+	Cumulative *big.Int // rewards paid on Bridge up to and including this one
+}
+
+// FeeEntry is a transaction fee the wallet paid.
+type FeeEntry struct {
+	TxID        string
+	BlockHeight int64
+	Method      string
+	Amount      *big.Int // in the fee token's smallest unit
+	Cumulative  *big.Int // fees paid up to and including this one
+}
+
+// History is the wallet's realized income and costs outside of trading:
+// LP rewards, paid in each market's collateral token, and transaction fees,
+// paid in the network's fee token.
+type History struct {
+	Rewards         []RewardEntry       // oldest first
+	RewardsByBridge map[string]*big.Int // total rewards per bridge
+	Fees            []FeeEntry          // oldest first; empty without WithLedger
+	TotalFees       *big.Int
+}
+
+// History reads the wallet's LP reward history and, with WithLedger, the
+// transaction fees it paid.
+func (p *Portfolio) History(ctx context.Context) (*History, error) {
+	h := &History{RewardsByBridge: make(map[string]*big.Int), TotalFees: new(big.Int)}
+
+	rewards, err := p.ob.GetParticipantRewardHistory(ctx, types.GetParticipantRewardHistoryInput{WalletHex: p.wallet})
+	if err != nil {
+		return nil, errors.Wrap(err, "get reward history")
+	}
+	sort.SliceStable(rewards, func(i, j int) bool { return rewards[i].DistributedAt < rewards[j].DistributedAt })
+
+	bridges := make(map[int]string)
+	for _, r := range rewards {
+		bridge, ok := bridges[r.QueryID]
+		if !ok {
+			info, err := p.ob.GetMarketInfo(ctx, types.GetMarketInfoInput{QueryID: r.QueryID})
+			if err != nil {
+				return nil, errors.Wrapf(err, "get market %d", r.QueryID)
+			}
+			bridge = info.Bridge
+			bridges[r.QueryID] = bridge
+		}
+		amount, err := parseAmount(r.RewardAmount)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reward of distribution %d", r.DistributionID)
+		}
+		total := h.RewardsByBridge[bridge]
+		if total == nil {
+			total = new(big.Int)
+			h.RewardsByBridge[bridge] = total
+		}
+		total.Add(total, amount)
+		h.Rewards = append(h.Rewards, RewardEntry{
+			RewardHistory: r,
+			Bridge:        bridge,
+			Amount:        amount,
+			Cumulative:    new(big.Int).Set(total),
+		})
+	}
+
+	if p.ledger == nil {
+		return h, nil
+	}
+	fees, err := p.fees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range fees {
+		h.TotalFees.Add(h.TotalFees, f.Amount)
+		f.Cumulative = new(big.Int).Set(h.TotalFees)
+		h.Fees = append(h.Fees, f)
+	}
+	return h, nil
+}
+
+// fees pages through the fees the wallet paid. The ledger returns a row per
+// fee distribution, so rows are folded by transaction.
+func (p *Portfolio) fees(ctx context.Context) ([]FeeEntry, error) {
+	var out []FeeEntry
+	seen := make(map[string]bool)
+	for offset := 0; ; offset += feePageSize {
+		limit, off := feePageSize, offset
+		rows, err := p.ledger.ListTransactionFees(ctx, types.ListTransactionFeesInput{
+			Wallet: p.wallet,
+			Mode:   types.TransactionFeeModePaid,
+			Limit:  &limit,
+			Offset: &off,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "list transaction fees")
+		}
+		for _, row := range rows {
+			if seen[row.TxID] || !strings.EqualFold(row.Caller, p.wallet) {
+				continue
+			}
+			seen[row.TxID] = true
+			if p.feeMethods != nil && !p.feeMethods[row.Method] {
+				continue
+			}
+			amount, err := parseAmount(row.TotalFee)
+			if err != nil {
+				return nil, errors.Wrapf(err, "fee of %s", row.TxID)
+			}
+			out = append(out, FeeEntry{TxID: row.TxID, BlockHeight: row.BlockHeight, Method: row.Method, Amount: amount})
+		}
+		if len(rows) < feePageSize {
+			break
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].BlockHeight < out[j].BlockHeight })
+	return out, nil
+}
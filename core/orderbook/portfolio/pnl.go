@@ -0,0 +1,102 @@
+package portfolio
+
+import (
+	"sort"
+
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+// Trade is one of the wallet's fills. The order book keeps no trade history,
+// so callers supply their own (from their transactions, an indexer, or
+// sim.Simulator.Fills) as the cost basis for PnL.
+//
+// A split limit order is two buys: YES at TruePrice and NO at 100 - TruePrice.
+type Trade struct {
+	QueryID int
+	Outcome bool
+	Price   int   // cents per share
+	Amount  int64 // shares; positive when bought, negative when sold
+	Time    int64 // used to order trades; ties keep their order
+}
+
+type lotKey struct {
+	queryID int
+	outcome bool
+}
+
+// lot is the average-cost position of one outcome built from trades.
+type lot struct {
+	shares   int64
+	cost     int64 // cents
+	realized int64 // cents
+}
+
+func costBasis(trades []Trade) map[lotKey]*lot {
+	sorted := append([]Trade(nil), trades...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time < sorted[j].Time })
+
+	lots := make(map[lotKey]*lot)
+	for _, t := range sorted {
+		k := lotKey{t.QueryID, t.Outcome}
+		l := lots[k]
+		if l == nil {
+			l = &lot{}
+			lots[k] = l
+		}
+		l.apply(t)
+	}
+	return lots
+}
+
+func (l *lot) apply(t Trade) {
+	if t.Amount >= 0 {
+		l.shares += t.Amount
+		l.cost += int64(t.Price) * t.Amount
+		return
+	}
+	// Shares sold beyond the recorded ones were bought outside the trade
+	// history; they count as free.
+	sold := -t.Amount
+	removed := l.cost
+	if sold < l.shares {
+		removed = l.cost * sold / l.shares
+	}
+	l.realized += int64(t.Price)*sold - removed
+	l.cost -= removed
+	l.shares = max(l.shares-sold, 0)
+}
+
+// value fills o's PnL. A nil lot means no trades were recorded for the
+// outcome. In a settled market the remaining shares are paid out at $1.00
+// or nothing; otherwise the average cost applies to the shares the order
+// book reports.
+func (l *lot) value(info *types.MarketInfo, o *OutcomePosition) {
+	if l == nil {
+		l = &lot{}
+	}
+	o.Realized = l.realized
+
+	if info.Settled {
+		var payout int64
+		if info.WinningOutcome != nil && *info.WinningOutcome == o.Outcome {
+			payout = 100
+		}
+		o.Realized += payout*l.shares - l.cost
+		return
+	}
+
+	shares := o.Shares()
+	switch {
+	case shares <= l.shares:
+		if l.shares > 0 {
+			o.Cost = l.cost * shares / l.shares
+		}
+	default:
+		o.Cost = l.cost
+		o.Untracked = shares - l.shares
+	}
+	if o.Mark != nil {
+		o.Value = int64(*o.Mark) * shares
+		o.Unrealized = o.Value - o.Cost
+	}
+}
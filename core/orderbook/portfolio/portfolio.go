@@ -0,0 +1,333 @@
+// Package portfolio values a wallet's prediction-market positions.
+//
+// The order book reports positions as raw ob_positions rows (price 0 for
+// holdings, negative for bids, positive for asks) and collateral as wei
+// strings. A Portfolio groups those rows by market and outcome, marks the
+// shares to the current best prices, computes realized and unrealized PnL
+// from the wallet's trades and settlement outcomes, and reports locked and
+// free collateral per bridge token:
+//
+//	ob, _ := tnClient.LoadOrderBook()
+//	p, _ := portfolio.New(ob, wallet, portfolio.WithBalances(tnClient))
+//	snap, err := p.Snapshot(ctx, trades)
+//
+// Share prices and PnL are in cents of the market's collateral token ($1.00
+// per winning share); collateral amounts are in the token's smallest unit.
+package portfolio
+
+import (
+	"context"
+	"math/big"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/trufnetwork/sdk-go/core/types"
+	"github.com/trufnetwork/sdk-go/core/util"
+)
+
+// BalanceReader reads a wallet's free balance on a bridge. *tnclient.Client
+// implements it.
+type BalanceReader interface {
+	GetWalletBalance(ctx context.Context, bridgeIdentifier string, walletAddress string) (string, error)
+}
+
+// MarkMethod chooses the price shares are marked at.
+type MarkMethod int
+
+const (
+	// MarkMid marks at the midpoint of the best bid and ask, or at whichever
+	// of the two exists.
+	MarkMid MarkMethod = iota
+	// MarkBid marks at the best bid: what the shares would sell for now.
+	MarkBid
+)
+
+// Portfolio reads and values one wallet's positions.
+type Portfolio struct {
+	ob         types.IOrderBook
+	wallet     string
+	balances   BalanceReader
+	ledger     types.ITransactionAction
+	mark       MarkMethod
+	feeMethods map[string]bool
+}
+
+// Option configures a Portfolio.
+type Option func(*Portfolio)
+
+// WithBalances reads free collateral from the bridges. Without it,
+// BridgeSummary.Free is nil.
+func WithBalances(b BalanceReader) Option {
+	return func(p *Portfolio) {
+		p.balances = b
+	}
+}
+
+// WithLedger reads the fees the wallet paid from the transaction ledger for
+// History. Without it, History reports rewards only.
+func WithLedger(l types.ITransactionAction) Option {
+	return func(p *Portfolio) {
+		p.ledger = l
+	}
+}
+
+// WithMarkMethod sets how shares are marked. Default: MarkMid.
+func WithMarkMethod(m MarkMethod) Option {
+	return func(p *Portfolio) {
+		p.mark = m
+	}
+}
+
+// WithFeeMethods limits the fees History reports to transactions calling the
+// given actions (e.g. "place_buy_order"). Default: every fee the wallet paid.
+func WithFeeMethods(methods ...string) Option {
+	return func(p *Portfolio) {
+		p.feeMethods = make(map[string]bool, len(methods))
+		for _, m := range methods {
+			p.feeMethods[m] = true
+		}
+	}
+}
+
+// New returns a Portfolio for wallet, a 0x-prefixed Ethereum address.
+func New(ob types.IOrderBook, wallet string, opts ...Option) (*Portfolio, error) {
+	if ob == nil {
+		return nil, errors.New("order book is required")
+	}
+	addr, err := util.NewEthereumAddressFromString(wallet)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid wallet")
+	}
+	p := &Portfolio{ob: ob, wallet: addr.Address()}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p, nil
+}
+
+// Wallet returns the wallet address, lowercase and 0x-prefixed.
+func (p *Portfolio) Wallet() string {
+	return p.wallet
+}
+
+// OutcomePosition is the wallet's stake in one outcome of a market.
+type OutcomePosition struct {
+	Outcome       bool
+	Held          int64                // shares held outside of orders
+	Listed        int64                // shares in sell orders
+	Bid           int64                // shares in buy orders
+	BidCollateral int64                // cents locked in buy orders
+	Orders        []types.UserPosition // buy and sell orders, with signed prices
+
+	// Filled by Snapshot. Value and Unrealized are zero when Mark is nil.
+	Mark       *int  // price the shares are marked at, in cents
+	Value      int64 // Shares() × Mark, in cents
+	Cost       int64 // cost basis of Shares(), in cents
+	Untracked  int64 // shares held with no recorded trade, counted at no cost
+	Realized   int64 // realized PnL in cents, including settlement payouts
+	Unrealized int64 // Value - Cost, in cents
+}
+
+// Shares returns the shares the wallet owns: held plus listed.
+func (o *OutcomePosition) Shares() int64 {
+	return o.Held + o.Listed
+}
+
+// MarketPosition is the wallet's stake in one market.
+type MarketPosition struct {
+	QueryID int
+	Info    *types.MarketInfo // set by Snapshot
+	Yes     OutcomePosition
+	No      OutcomePosition
+}
+
+// Outcome returns the position in outcome.
+func (m *MarketPosition) Outcome(outcome bool) *OutcomePosition {
+	if outcome {
+		return &m.Yes
+	}
+	return &m.No
+}
+
+// Group groups position rows by market and outcome, ordered by query ID.
+func Group(positions []types.UserPosition) []*MarketPosition {
+	byID := make(map[int]*MarketPosition)
+	for _, row := range positions {
+		m := byID[row.QueryID]
+		if m == nil {
+			m = newMarketPosition(row.QueryID)
+			byID[row.QueryID] = m
+		}
+		o := m.Outcome(row.Outcome)
+		switch {
+		case row.Price == 0:
+			o.Held += row.Amount
+		case row.Price < 0:
+			o.Bid += row.Amount
+			o.BidCollateral += int64(-row.Price) * row.Amount
+			o.Orders = append(o.Orders, row)
+		default:
+			o.Listed += row.Amount
+			o.Orders = append(o.Orders, row)
+		}
+	}
+	out := make([]*MarketPosition, 0, len(byID))
+	for _, m := range byID {
+		out = append(out, m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].QueryID < out[j].QueryID })
+	return out
+}
+
+func newMarketPosition(queryID int) *MarketPosition {
+	return &MarketPosition{QueryID: queryID, Yes: OutcomePosition{Outcome: true}, No: OutcomePosition{Outcome: false}}
+}
+
+// Positions returns the wallet's open positions grouped by market.
+func (p *Portfolio) Positions(ctx context.Context) ([]*MarketPosition, error) {
+	rows, err := p.ob.GetPositionsByWallet(ctx, types.GetPositionsByWalletInput{WalletHex: p.wallet})
+	if err != nil {
+		return nil, errors.Wrap(err, "get positions")
+	}
+	return Group(rows), nil
+}
+
+// BridgeSummary totals the wallet's markets collateralized by one bridge
+// token.
+type BridgeSummary struct {
+	Bridge   string
+	Decimals int // decimals of the bridge token
+
+	// In cents, over the markets in the snapshot.
+	Value      int64
+	Cost       int64
+	Realized   int64
+	Unrealized int64
+
+	// In the token's smallest unit, from GetCollateralByWallet and the
+	// bridge balance.
+	Locked          *big.Int // BuyOrdersLocked + SharesValue
+	BuyOrdersLocked *big.Int
+	SharesValue     *big.Int // shares at $1.00 each
+	Free            *big.Int // wallet balance; nil without WithBalances
+}
+
+// Snapshot is a valued portfolio.
+type Snapshot struct {
+	Wallet  string
+	Markets []*MarketPosition         // open positions and markets traded in trades
+	Bridges map[string]*BridgeSummary // keyed by bridge namespace
+}
+
+// Snapshot reads the wallet's positions, values them and computes PnL from
+// trades. Markets that only appear in trades, for instance settled ones
+// whose positions were paid out, are included so their realized PnL counts.
+func (p *Portfolio) Snapshot(ctx context.Context, trades []Trade) (*Snapshot, error) {
+	markets, err := p.Positions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]*MarketPosition, len(markets))
+	for _, m := range markets {
+		byID[m.QueryID] = m
+	}
+	for _, t := range trades {
+		if byID[t.QueryID] == nil {
+			m := newMarketPosition(t.QueryID)
+			byID[t.QueryID] = m
+			markets = append(markets, m)
+		}
+	}
+	sort.Slice(markets, func(i, j int) bool { return markets[i].QueryID < markets[j].QueryID })
+
+	lots := costBasis(trades)
+	snap := &Snapshot{Wallet: p.wallet, Markets: markets, Bridges: make(map[string]*BridgeSummary)}
+	for _, m := range markets {
+		info, err := p.ob.GetMarketInfo(ctx, types.GetMarketInfoInput{QueryID: m.QueryID})
+		if err != nil {
+			return nil, errors.Wrapf(err, "get market %d", m.QueryID)
+		}
+		m.Info = info
+
+		for _, o := range []*OutcomePosition{&m.Yes, &m.No} {
+			if !info.Settled && o.Shares() > 0 {
+				if err := p.markPosition(ctx, m.QueryID, o); err != nil {
+					return nil, err
+				}
+			}
+			lots[lotKey{m.QueryID, o.Outcome}].value(info, o)
+		}
+
+		b := snap.bridge(info.Bridge)
+		for _, o := range []*OutcomePosition{&m.Yes, &m.No} {
+			b.Value += o.Value
+			b.Cost += o.Cost
+			b.Realized += o.Realized
+			b.Unrealized += o.Unrealized
+		}
+	}
+
+	for _, b := range snap.Bridges {
+		if err := p.collateral(ctx, b); err != nil {
+			return nil, err
+		}
+	}
+	return snap, nil
+}
+
+func (s *Snapshot) bridge(name string) *BridgeSummary {
+	b := s.Bridges[name]
+	if b == nil {
+		b = &BridgeSummary{Bridge: name, Decimals: decimals(name)}
+		s.Bridges[name] = b
+	}
+	return b
+}
+
+// markPosition sets o.Mark from the outcome's best prices.
+func (p *Portfolio) markPosition(ctx context.Context, queryID int, o *OutcomePosition) error {
+	best, err := p.ob.GetBestPrices(ctx, types.GetBestPricesInput{QueryID: queryID, Outcome: o.Outcome})
+	if err != nil {
+		return errors.Wrapf(err, "get best prices for market %d", queryID)
+	}
+	switch {
+	case best.BestBid != nil && best.BestAsk != nil && p.mark == MarkMid:
+		mid := (*best.BestBid + *best.BestAsk) / 2
+		o.Mark = &mid
+	case best.BestBid != nil:
+		bid := *best.BestBid
+		o.Mark = &bid
+	case best.BestAsk != nil && p.mark == MarkMid:
+		ask := *best.BestAsk
+		o.Mark = &ask
+	}
+	return nil
+}
+
+func (p *Portfolio) collateral(ctx context.Context, b *BridgeSummary) error {
+	c, err := p.ob.GetCollateralByWallet(ctx, types.GetCollateralByWalletInput{WalletHex: p.wallet, Bridge: b.Bridge})
+	if err != nil {
+		return errors.Wrapf(err, "get collateral on %s", b.Bridge)
+	}
+	if b.Locked, err = parseAmount(c.TotalLocked); err != nil {
+		return errors.Wrap(err, "total_locked")
+	}
+	if b.BuyOrdersLocked, err = parseAmount(c.BuyOrdersLocked); err != nil {
+		return errors.Wrap(err, "buy_orders_locked")
+	}
+	if b.SharesValue, err = parseAmount(c.SharesValue); err != nil {
+		return errors.Wrap(err, "shares_value")
+	}
+
+	if p.balances == nil {
+		return nil
+	}
+	balance, err := p.balances.GetWalletBalance(ctx, b.Bridge, p.wallet)
+	if err != nil {
+		return errors.Wrapf(err, "get balance on %s", b.Bridge)
+	}
+	if b.Free, err = parseAmount(balance); err != nil {
+		return errors.Wrapf(err, "balance on %s", b.Bridge)
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package portfolio
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trufnetwork/sdk-go/core/orderbook/sim"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+const (
+	start = int64(1_700_000_000)
+	alice = "0x1111111111111111111111111111111111111111"
+	bob   = "0x2222222222222222222222222222222222222222"
+	carol = "0x3333333333333333333333333333333333333333"
+)
+
+type simBalances struct{ s *sim.Simulator }
+
+func (b simBalances) GetWalletBalance(_ context.Context, _ string, wallet string) (string, error) {
+	cents, err := b.s.Balance(wallet)
+	return CentsToUnits(cents, 18).String(), err
+}
+
+func TestGroup(t *testing.T) {
+	markets := Group([]types.UserPosition{
+		{QueryID: 2, Outcome: true, Price: 0, Amount: 5, PositionType: "holding"},
+		{QueryID: 1, Outcome: false, Price: -40, Amount: 10, PositionType: "buy_order"},
+		{QueryID: 1, Outcome: false, Price: -35, Amount: 2, PositionType: "buy_order"},
+		{QueryID: 1, Outcome: true, Price: 0, Amount: 3, PositionType: "holding"},
+		{QueryID: 1, Outcome: true, Price: 65, Amount: 7, PositionType: "sell_order"},
+	})
+	require.Len(t, markets, 2)
+	m := markets[0]
+	assert.Equal(t, 1, m.QueryID)
+	assert.Equal(t, int64(3), m.Yes.Held)
+	assert.Equal(t, int64(7), m.Yes.Listed)
+	assert.Equal(t, int64(10), m.Yes.Shares())
+	assert.Equal(t, int64(12), m.No.Bid)
+	assert.Equal(t, int64(470), m.No.BidCollateral)
+	assert.Len(t, m.No.Orders, 2)
+	assert.Equal(t, int64(5), markets[1].Outcome(true).Held)
+	assert.Zero(t, markets[1].No.Shares())
+}
+
+func TestCostBasis(t *testing.T) {
+	lots := costBasis([]Trade{
+		{QueryID: 1, Outcome: true, Price: 60, Amount: 10, Time: 2},
+		{QueryID: 1, Outcome: true, Price: 40, Amount: 10, Time: 1},
+		{QueryID: 1, Outcome: true, Price: 70, Amount: -5, Time: 3},
+	})
+	l := lots[lotKey{1, true}]
+	// avg cost 50: 5 sold at 70 realize 100, 15 shares left cost 750.
+	assert.Equal(t, &lot{shares: 15, cost: 750, realized: 100}, l)
+
+	// Selling more than recorded counts the extra shares as free.
+	l.apply(Trade{Price: 10, Amount: -20})
+	assert.Equal(t, &lot{shares: 0, cost: 0, realized: 100 + 200 - 750}, l)
+}
+
+func TestUnits(t *testing.T) {
+	assert.Equal(t, "10000000000000000", CentsToUnits(1, 18).String())
+	assert.Equal(t, "1500000", CentsToUnits(150, 6).String())
+	assert.Equal(t, "1.5", FormatUnits(big.NewInt(1_500_000), 6))
+	assert.Equal(t, "-0.000001", FormatUnits(big.NewInt(-1), 6))
+	assert.Equal(t, "42", FormatUnits(big.NewInt(42_000_000), 6))
+	_, err := parseAmount("1.5")
+	assert.Error(t, err)
+}
+
+func TestSnapshot(t *testing.T) {
+	ctx := context.Background()
+	s := sim.New(sim.WithTime(start))
+	a, b, c := s.Trader(alice), s.Trader(bob), s.Trader(carol)
+	for _, w := range []string{alice, bob, carol} {
+		require.NoError(t, s.Deposit(w, 100_000))
+	}
+	for i, settle := range []int64{start + 3600, start + 60} {
+		_, err := a.CreateMarket(ctx, types.CreateMarketInput{
+			Bridge:          "hoodi_tt2",
+			QueryComponents: bytes.Repeat([]byte{byte(i + 1)}, 128),
+			SettleTime:      settle,
+			MaxSpread:       5,
+			MinOrderSize:    1,
+		})
+		require.NoError(t, err)
+	}
+
+	// Market 1: Alice gets 10 NO at 45 by minting against Bob, then buys 4
+	// of Bob's YES at 70. Bob relists at 80 and Carol bids NO at 30.
+	must := func(_ any, err error) { require.NoError(t, err) }
+	must(a.PlaceBuyOrder(ctx, types.PlaceBuyOrderInput{QueryID: 1, Outcome: false, Price: 45, Amount: 10}))
+	must(b.PlaceBuyOrder(ctx, types.PlaceBuyOrderInput{QueryID: 1, Outcome: true, Price: 55, Amount: 10}))
+	must(b.PlaceSellOrder(ctx, types.PlaceSellOrderInput{QueryID: 1, Outcome: true, Price: 70, Amount: 10}))
+	must(a.PlaceBuyOrder(ctx, types.PlaceBuyOrderInput{QueryID: 1, Outcome: true, Price: 70, Amount: 4}))
+	must(b.ChangeAsk(ctx, types.ChangeAskInput{QueryID: 1, Outcome: true, OldPrice: 70, NewPrice: 80, NewAmount: 6}))
+	must(c.PlaceBuyOrder(ctx, types.PlaceBuyOrderInput{QueryID: 1, Outcome: false, Price: 30, Amount: 1}))
+
+	// Market 2: Alice splits at 60, Bob buys her NO at 40, YES wins.
+	must(a.PlaceSplitLimitOrder(ctx, types.PlaceSplitLimitOrderInput{QueryID: 2, TruePrice: 60, Amount: 10}))
+	must(b.PlaceBuyOrder(ctx, types.PlaceBuyOrderInput{QueryID: 2, Outcome: false, Price: 40, Amount: 10}))
+	require.NoError(t, s.SetTime(start+60))
+	require.NoError(t, s.Resolve(2, true))
+	must(a.SettleMarket(ctx, types.SettleMarketInput{QueryID: 2}))
+
+	trades := []Trade{
+		{QueryID: 1, Outcome: false, Price: 45, Amount: 10},
+		{QueryID: 1, Outcome: true, Price: 70, Amount: 4},
+		{QueryID: 2, Outcome: true, Price: 60, Amount: 10},
+		{QueryID: 2, Outcome: false, Price: 40, Amount: 10},
+		{QueryID: 2, Outcome: false, Price: 40, Amount: -10},
+	}
+
+	p, err := New(b, alice, WithBalances(simBalances{s}))
+	require.NoError(t, err)
+	snap, err := p.Snapshot(ctx, trades)
+	require.NoError(t, err)
+
+	require.Len(t, snap.Markets, 2)
+	m1, m2 := snap.Markets[0], snap.Markets[1]
+	assert.Equal(t, 80, *m1.Yes.Mark, "only an ask: marked at the ask")
+	assert.Equal(t, int64(320), m1.Yes.Value)
+	assert.Equal(t, int64(280), m1.Yes.Cost)
+	assert.Equal(t, int64(40), m1.Yes.Unrealized)
+	assert.Equal(t, 30, *m1.No.Mark)
+	assert.Equal(t, int64(-150), m1.No.Unrealized)
+
+	assert.True(t, m2.Info.Settled)
+	assert.Equal(t, int64(400), m2.Yes.Realized)
+	assert.Equal(t, int64(0), m2.No.Realized)
+	assert.Zero(t, m2.Yes.Value)
+
+	bridge := snap.Bridges["hoodi_tt2"]
+	require.NotNil(t, bridge)
+	assert.Equal(t, 18, bridge.Decimals)
+	assert.Equal(t, int64(620), bridge.Value)
+	assert.Equal(t, int64(730), bridge.Cost)
+	assert.Equal(t, int64(400), bridge.Realized)
+	assert.Equal(t, int64(-110), bridge.Unrealized)
+	assert.Equal(t, "14000000000000000000", bridge.SharesValue.String())
+	assert.Equal(t, "0", bridge.BuyOrdersLocked.String())
+	assert.Equal(t, CentsToUnits(100_000-450-280-1000+400+1000, 18), bridge.Free)
+
+	// Marking at the bid leaves YES unpriced.
+	p, err = New(b, alice, WithMarkMethod(MarkBid))
+	require.NoError(t, err)
+	snap, err = p.Snapshot(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, snap.Markets, 1)
+	assert.Nil(t, snap.Markets[0].Yes.Mark)
+	assert.Equal(t, int64(4), snap.Markets[0].Yes.Untracked)
+	assert.Nil(t, snap.Bridges["hoodi_tt2"].Free)
+}
+
+// rewardBook serves a reward history; any other call panics.
+type rewardBook struct {
+	types.IOrderBook
+}
+
+func (rewardBook) GetParticipantRewardHistory(context.Context, types.GetParticipantRewardHistoryInput) ([]types.RewardHistory, error) {
+	return []types.RewardHistory{
+		{DistributionID: 2, QueryID: 7, RewardAmount: "2500000", DistributedAt: 200},
+		{DistributionID: 1, QueryID: 7, RewardAmount: "1000000", DistributedAt: 100},
+		{DistributionID: 3, QueryID: 8, RewardAmount: "5", DistributedAt: 300},
+	}, nil
+}
+
+func (rewardBook) GetMarketInfo(_ context.Context, in types.GetMarketInfoInput) (*types.MarketInfo, error) {
+	if in.QueryID == 7 {
+		return &types.MarketInfo{ID: 7, Bridge: "eth_usdc"}, nil
+	}
+	return &types.MarketInfo{ID: in.QueryID, Bridge: "hoodi_tt2"}, nil
+}
+
+type feeLedger struct {
+	types.ITransactionAction
+	rows []types.TransactionFeeEntry
+}
+
+func (l *feeLedger) ListTransactionFees(_ context.Context, in types.ListTransactionFeesInput) ([]types.TransactionFeeEntry, error) {
+	if *in.Offset >= len(l.rows) {
+		return nil, nil
+	}
+	return l.rows[*in.Offset:min(len(l.rows), *in.Offset+*in.Limit)], nil
+}
+
+func TestHistory(t *testing.T) {
+	var rows []types.TransactionFeeEntry
+	for i := 0; i < feePageSize+1; i++ {
+		rows = append(rows, types.TransactionFeeEntry{TxID: "0xaa", BlockHeight: 10, Method: "place_buy_order", Caller: alice, TotalFee: "3"})
+	}
+	rows = append(rows,
+		types.TransactionFeeEntry{TxID: "0xbb", BlockHeight: 5, Method: "create_market", Caller: alice, TotalFee: "100"},
+		types.TransactionFeeEntry{TxID: "0xcc", BlockHeight: 12, Method: "cancel_order", Caller: alice, TotalFee: "1"},
+	)
+
+	p, err := New(rewardBook{}, alice, WithLedger(&feeLedger{rows: rows}), WithFeeMethods("place_buy_order", "cancel_order"))
+	require.NoError(t, err)
+	h, err := p.History(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, h.Rewards, 3)
+	assert.Equal(t, 1, h.Rewards[0].DistributionID)
+	assert.Equal(t, "3500000", h.Rewards[1].Cumulative.String())
+	assert.Equal(t, "eth_usdc", h.Rewards[1].Bridge)
+	assert.Equal(t, "3500000", h.RewardsByBridge["eth_usdc"].String())
+	assert.Equal(t, "5", h.RewardsByBridge["hoodi_tt2"].String())
+
+	require.Len(t, h.Fees, 2, "fee rows are folded by transaction and filtered by method")
+	assert.Equal(t, "0xaa", h.Fees[0].TxID)
+	assert.Equal(t, "4", h.Fees[1].Cumulative.String())
+	assert.Equal(t, "4", h.TotalFees.String())
+}
+
+func TestNew_Validation(t *testing.T) {
+	_, err := New(nil, alice)
+	assert.ErrorContains(t, err, "order book is required")
+	_, err = New(rewardBook{}, "0x12")
+	assert.ErrorContains(t, err, "invalid wallet")
+}
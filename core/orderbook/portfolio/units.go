@@ -0,0 +1,59 @@
+package portfolio
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+// decimals returns the token decimals of a bridge, defaulting to 18.
+func decimals(bridge string) int {
+	if d, ok := types.BridgeDecimals[bridge]; ok {
+		return d
+	}
+	return 18
+}
+
+// CentsToUnits converts cents of a token with the given decimals to its
+// smallest unit.
+func CentsToUnits(cents int64, decimals int) *big.Int {
+	v := big.NewInt(cents)
+	if decimals >= 2 {
+		return v.Mul(v, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals-2)), nil))
+	}
+	return v.Quo(v, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(2-decimals)), nil))
+}
+
+// FormatUnits formats an amount in a token's smallest unit as a decimal
+// string, e.g. 1500000 with 6 decimals is "1.5".
+func FormatUnits(v *big.Int, decimals int) string {
+	if v == nil {
+		return "0"
+	}
+	abs := new(big.Int).Abs(v)
+	divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	whole, frac := new(big.Int).QuoRem(abs, divisor, new(big.Int))
+
+	out := whole.String()
+	if fracStr := strings.TrimRight(frac.String(), "0"); frac.Sign() != 0 {
+		out += "." + strings.Repeat("0", decimals-len(frac.String())) + fracStr
+	}
+	if v.Sign() < 0 {
+		out = "-" + out
+	}
+	return out
+}
+
+// parseAmount parses a NUMERIC(78,0) string; empty means zero.
+func parseAmount(s string) (*big.Int, error) {
+	if s == "" {
+		return new(big.Int), nil
+	}
+	v, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, errors.Errorf("invalid amount %q", s)
+	}
+	return v, nil
+}
@@ -0,0 +1,306 @@
+package recorder
+
+import (
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+// FillKind is how a fill was matched.
+type FillKind string
+
+const (
+	// FillDirect is a buy order of an outcome matched with a sell order of
+	// the same outcome.
+	FillDirect FillKind = "direct"
+	// FillMint is a YES buy at p matched with a NO buy at 100-p, minting a
+	// share pair.
+	FillMint FillKind = "mint"
+	// FillBurn is a YES sell at p matched with a NO sell at 100-p, burning a
+	// share pair.
+	FillBurn FillKind = "burn"
+	// FillUnpaired is a partial fill of a resting order whose counterparty
+	// order never rested on the book.
+	FillUnpaired FillKind = "unpaired"
+)
+
+// Fill is a trade inferred by diffing two successive books of a market.
+//
+// The order book does not report trades, so fills are reconstructed from the
+// orders that shrank or left the book between two blocks:
+//
+//   - Two shrunk orders that could have matched each other (same-outcome bid
+//     and ask at one price, YES and NO bids summing to 100, or YES and NO asks
+//     summing to 100) are paired into a direct, mint or burn fill.
+//   - An order that left the book, or shrank, while a new order crossing it
+//     appeared is paired with that order, which is the taker's remainder.
+//   - Any other shrinkage of an order still on the book is a FillUnpaired
+//     fill with no known taker.
+//   - Any other order that left the book is taken to be cancelled. A taker
+//     that fully consumed a resting order without resting itself is
+//     therefore missed.
+type Fill struct {
+	QueryID int
+	Block   int64
+	Time    int64
+	Kind    FillKind
+	Outcome bool   // outcome of Price; YES for mint and burn fills
+	Price   int    // in cents, 1-99
+	Amount  int64  // shares
+	Maker   string // wallet of the order that rested first
+	Taker   string // wallet of the other order; empty when unknown
+}
+
+type orderKey struct {
+	outcome bool
+	price   int
+	wallet  string
+}
+
+type orderChange struct {
+	orderKey
+	amount      int64 // shares removed, or added for new orders
+	remaining   int64 // shares left unpaired
+	partial     bool  // the order is still on the book
+	lastUpdated int64
+}
+
+func indexBook(b *Book) map[orderKey]types.OrderBookEntry {
+	out := make(map[orderKey]types.OrderBookEntry, len(b.Entries))
+	for _, e := range b.Entries {
+		out[orderKey{b.Outcome, e.Price, walletHex(e.WalletAddress)}] = e
+	}
+	return out
+}
+
+// diffBooks returns the deltas that turn prev into cur.
+func diffBooks(prev, cur *Book) []Delta {
+	before, after := indexBook(prev), indexBook(cur)
+	var out []Delta
+	for key, e := range after {
+		if old, ok := before[key]; !ok || old.Amount != e.Amount || old.LastUpdated != e.LastUpdated {
+			out = append(out, delta(cur, key, e))
+		}
+	}
+	for key, e := range before {
+		if _, ok := after[key]; !ok {
+			e.Amount = 0
+			out = append(out, delta(cur, key, e))
+		}
+	}
+	sortDeltas(out)
+	return out
+}
+
+func delta(b *Book, key orderKey, e types.OrderBookEntry) Delta {
+	return Delta{
+		QueryID:       b.QueryID,
+		Outcome:       b.Outcome,
+		Block:         b.Block,
+		Wallet:        key.wallet,
+		ParticipantID: e.ParticipantID,
+		Price:         e.Price,
+		Amount:        e.Amount,
+		LastUpdated:   e.LastUpdated,
+	}
+}
+
+func sortDeltas(ds []Delta) {
+	sort.Slice(ds, func(i, j int) bool {
+		a, b := ds[i], ds[j]
+		if a.Block != b.Block {
+			return a.Block < b.Block
+		}
+		if a.Outcome != b.Outcome {
+			return a.Outcome
+		}
+		if a.Price != b.Price {
+			return a.Price < b.Price
+		}
+		return a.Wallet < b.Wallet
+	})
+}
+
+// applyDeltas applies deltas, in block order, to a copy of book.
+func applyDeltas(book *Book, deltas []Delta) *Book {
+	entries := make(map[orderKey]types.OrderBookEntry, len(book.Entries))
+	for key, e := range indexBook(book) {
+		entries[key] = e
+	}
+	out := &Book{QueryID: book.QueryID, Outcome: book.Outcome, Block: book.Block, Time: book.Time}
+	for _, d := range deltas {
+		key := orderKey{d.Outcome, d.Price, strings.ToLower(d.Wallet)}
+		if d.Amount == 0 {
+			delete(entries, key)
+		} else {
+			wallet, _ := hex.DecodeString(strings.TrimPrefix(key.wallet, "0x"))
+			entries[key] = types.OrderBookEntry{
+				ParticipantID: d.ParticipantID,
+				Price:         d.Price,
+				Amount:        d.Amount,
+				LastUpdated:   d.LastUpdated,
+				WalletAddress: wallet,
+			}
+		}
+		out.Block = d.Block
+	}
+	for _, e := range entries {
+		out.Entries = append(out.Entries, e)
+	}
+	sortEntries(out.Entries)
+	return out
+}
+
+// sortEntries orders entries like get_order_book: by price, then FIFO.
+func sortEntries(entries []types.OrderBookEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Price != entries[j].Price {
+			return entries[i].Price < entries[j].Price
+		}
+		return entries[i].LastUpdated < entries[j].LastUpdated
+	})
+}
+
+// inferFills pairs the orders that shrank between prev and cur, both keyed by
+// outcome, into fills. See Fill for the rules.
+func inferFills(prev, cur map[bool]*Book) []Fill {
+	var removed, added []*orderChange
+	for _, outcome := range []bool{true, false} {
+		before, after := indexBook(prev[outcome]), indexBook(cur[outcome])
+		for key, old := range before {
+			e, still := after[key]
+			if still && e.Amount >= old.Amount {
+				continue
+			}
+			n := old.Amount - e.Amount
+			removed = append(removed, &orderChange{orderKey: key, amount: n, remaining: n, partial: still, lastUpdated: old.LastUpdated})
+		}
+		for key, e := range after {
+			old, existed := before[key]
+			if existed && e.Amount <= old.Amount {
+				continue
+			}
+			n := e.Amount - old.Amount
+			added = append(added, &orderChange{orderKey: key, amount: n, remaining: n, lastUpdated: e.LastUpdated})
+		}
+	}
+	sortChanges(removed)
+	sortChanges(added)
+
+	queryID, block, now := cur[true].QueryID, cur[true].Block, cur[true].Time
+	var fills []Fill
+	emit := func(kind FillKind, maker, taker *orderChange, amount int64) {
+		f := Fill{QueryID: queryID, Block: block, Time: now, Kind: kind, Amount: amount, Maker: maker.wallet}
+		if taker != nil {
+			f.Taker = taker.wallet
+		}
+		switch kind {
+		case FillMint, FillBurn:
+			yes := maker
+			if !yes.outcome {
+				yes = taker
+			}
+			f.Outcome, f.Price = true, abs(yes.price)
+		default:
+			f.Outcome, f.Price = maker.outcome, abs(maker.price)
+		}
+		fills = append(fills, f)
+	}
+
+	// Two shrunk orders that match each other.
+	for i, a := range removed {
+		for _, b := range removed[i+1:] {
+			if a.remaining == 0 {
+				break
+			}
+			kind, ok := matches(a.orderKey, b.orderKey)
+			if !ok || b.remaining == 0 {
+				continue
+			}
+			n := min(a.remaining, b.remaining)
+			maker, taker := a, b
+			if b.lastUpdated < a.lastUpdated {
+				maker, taker = b, a
+			}
+			emit(kind, maker, taker, n)
+			a.remaining -= n
+			b.remaining -= n
+		}
+	}
+
+	// A resting order consumed by a taker whose remainder now rests.
+	for _, r := range removed {
+		for _, a := range added {
+			if r.remaining == 0 {
+				break
+			}
+			kind, ok := crosses(r.orderKey, a.orderKey)
+			if !ok || a.remaining == 0 {
+				continue
+			}
+			n := r.remaining
+			emit(kind, r, a, n)
+			r.remaining = 0
+		}
+		if r.remaining > 0 && r.partial {
+			emit(FillUnpaired, r, nil, r.remaining)
+			r.remaining = 0
+		}
+	}
+	return fills
+}
+
+// matches reports whether two resting orders could have been matched with
+// each other, and how.
+func matches(a, b orderKey) (FillKind, bool) {
+	switch {
+	case a.outcome == b.outcome && a.price == -b.price:
+		return FillDirect, true
+	case a.outcome != b.outcome && a.price < 0 && b.price < 0 && a.price+b.price == -100:
+		return FillMint, true
+	case a.outcome != b.outcome && a.price > 0 && b.price > 0 && a.price+b.price == 100:
+		return FillBurn, true
+	}
+	return "", false
+}
+
+// crosses reports whether a new order would have matched a resting one,
+// and how.
+func crosses(resting, taker orderKey) (FillKind, bool) {
+	switch {
+	case resting.outcome == taker.outcome && resting.price > 0 && taker.price < 0:
+		return FillDirect, -taker.price >= resting.price
+	case resting.outcome == taker.outcome && resting.price < 0 && taker.price > 0:
+		return FillDirect, taker.price <= -resting.price
+	case resting.outcome != taker.outcome && resting.price < 0 && taker.price < 0:
+		return FillMint, -resting.price-taker.price >= 100
+	case resting.outcome != taker.outcome && resting.price > 0 && taker.price > 0:
+		return FillBurn, resting.price+taker.price <= 100
+	}
+	return "", false
+}
+
+func sortChanges(cs []*orderChange) {
+	sort.Slice(cs, func(i, j int) bool {
+		a, b := cs[i], cs[j]
+		if a.lastUpdated != b.lastUpdated {
+			return a.lastUpdated < b.lastUpdated
+		}
+		if a.outcome != b.outcome {
+			return a.outcome
+		}
+		if a.price != b.price {
+			return a.price < b.price
+		}
+		return a.wallet < b.wallet
+	})
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,387 @@
+// Package recorder keeps a market history of order books without an external
+// indexer.
+//
+// A Recorder polls GetOrderBook, GetMarketDepth and GetBestPrices for every
+// outcome of the markets it follows, once per new block. It writes a full
+// snapshot of each book every few blocks and per-order deltas in between, a
+// top-of-book Tick per block, and the fills it infers by diffing successive
+// books. The query functions read the history back: the book at any recorded
+// block, and spread, mid-price and depth series.
+//
+//	store, err := recorder.OpenSQLite("market_history.db")
+//	if err != nil {
+//	    return err
+//	}
+//	defer store.Close()
+//	ob, _ := tnClient.LoadOrderBook()
+//	r, err := recorder.New(ob, tnClient, recorder.WithStore(store), recorder.WithMarkets(queryID))
+//	if err != nil {
+//	    return err
+//	}
+//	go r.Run(ctx)
+//
+//	spread, err := recorder.SpreadSeries(ctx, store, queryID, true, fromBlock, toBlock)
+package recorder
+
+import (
+	"context"
+	"encoding/hex"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/trufnetwork/kwil-db/core/log"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+// DefaultSQLitePath is the database New opens when no store is configured.
+const DefaultSQLitePath = "market_history.db"
+
+// marketPageSize is the largest page list_markets returns.
+const marketPageSize = 100
+
+// BlockSource reports the latest block height. *tnclient.Client implements it.
+type BlockSource interface {
+	BlockHeight(ctx context.Context) (int64, error)
+}
+
+// Book is the order book of one market outcome at a block.
+type Book struct {
+	QueryID int
+	Outcome bool
+	Block   int64
+	Time    int64 // Unix seconds when the book was read
+	Entries []types.OrderBookEntry
+}
+
+// Delta is a change to one order between two recorded blocks. Orders are
+// identified by wallet and signed price; Amount is the new amount, 0 when the
+// order left the book.
+type Delta struct {
+	QueryID       int
+	Outcome       bool
+	Block         int64
+	Wallet        string // 0x-prefixed hex
+	ParticipantID int
+	Price         int // negative for bids, positive for asks
+	Amount        int64
+	LastUpdated   int64
+}
+
+// Tick is the top of an outcome's book at a block.
+type Tick struct {
+	QueryID    int
+	Outcome    bool
+	Block      int64
+	Time       int64
+	BestBid    *int
+	BestAsk    *int
+	BuyVolume  int64 // shares bid across all levels
+	SellVolume int64 // shares offered across all levels
+	Levels     int   // price levels with volume
+}
+
+// Spread returns BestAsk - BestBid, or nil when a side is empty.
+func (t Tick) Spread() *int {
+	if t.BestBid == nil || t.BestAsk == nil {
+		return nil
+	}
+	s := *t.BestAsk - *t.BestBid
+	return &s
+}
+
+// Mid returns the midpoint of the best bid and ask, or nil when a side is
+// empty.
+func (t Tick) Mid() *float64 {
+	if t.BestBid == nil || t.BestAsk == nil {
+		return nil
+	}
+	m := float64(*t.BestBid+*t.BestAsk) / 2
+	return &m
+}
+
+// Recorder records the order books of a set of markets.
+//
+// A Recorder is not safe for concurrent use.
+type Recorder struct {
+	ob      types.IOrderBook
+	heights BlockSource
+	store   Store
+	ownsDB  bool
+
+	markets       []int
+	interval      time.Duration
+	snapshotEvery int64
+	logger        log.Logger
+	now           func() time.Time
+
+	lastBlock    int64
+	books        map[bookKey]*Book
+	lastSnapshot map[bookKey]int64
+}
+
+type bookKey struct {
+	queryID int
+	outcome bool
+}
+
+// Option configures a Recorder.
+type Option func(*Recorder)
+
+// WithStore sets where the history is written. Default: a SQLite database at
+// DefaultSQLitePath, closed by Close.
+func WithStore(s Store) Option {
+	return func(r *Recorder) {
+		r.store = s
+	}
+}
+
+// WithMarkets sets the markets to record. Default: every unsettled market,
+// re-listed at each snapshot.
+func WithMarkets(queryIDs ...int) Option {
+	return func(r *Recorder) {
+		r.markets = queryIDs
+	}
+}
+
+// WithInterval sets how often Run checks for a new block. Default: 2s.
+func WithInterval(d time.Duration) Option {
+	return func(r *Recorder) {
+		if d > 0 {
+			r.interval = d
+		}
+	}
+}
+
+// WithSnapshotEvery sets how many blocks apart full snapshots are written;
+// deltas are written in between. Default: 100.
+func WithSnapshotEvery(blocks int64) Option {
+	return func(r *Recorder) {
+		if blocks > 0 {
+			r.snapshotEvery = blocks
+		}
+	}
+}
+
+// WithLogger attaches a logger. Default: discard.
+func WithLogger(logger log.Logger) Option {
+	return func(r *Recorder) {
+		r.logger = logger
+	}
+}
+
+// WithClock sets the clock used to timestamp records. Default: time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(r *Recorder) {
+		r.now = now
+	}
+}
+
+// New returns a recorder reading ob at the heights reported by heights.
+func New(ob types.IOrderBook, heights BlockSource, opts ...Option) (*Recorder, error) {
+	if ob == nil {
+		return nil, errors.New("order book is required")
+	}
+	if heights == nil {
+		return nil, errors.New("block source is required")
+	}
+	r := &Recorder{
+		ob:            ob,
+		heights:       heights,
+		interval:      2 * time.Second,
+		snapshotEvery: 100,
+		logger:        log.DiscardLogger,
+		now:           time.Now,
+		books:         make(map[bookKey]*Book),
+		lastSnapshot:  make(map[bookKey]int64),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	for _, id := range r.markets {
+		if id < 1 {
+			return nil, errors.Errorf("query_id must be positive, got %d", id)
+		}
+	}
+	if r.store == nil {
+		store, err := OpenSQLite(DefaultSQLitePath)
+		if err != nil {
+			return nil, err
+		}
+		r.store, r.ownsDB = store, true
+	}
+	return r, nil
+}
+
+// Store returns the store the recorder writes to.
+func (r *Recorder) Store() Store {
+	return r.store
+}
+
+// Close closes the store if New opened it.
+func (r *Recorder) Close() error {
+	if r.ownsDB {
+		return r.store.Close()
+	}
+	return nil
+}
+
+// Run records every new block until ctx is done, logging failed steps, and
+// returns ctx.Err().
+func (r *Recorder) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Step(ctx); err != nil {
+			r.logger.Warn("recorder: step failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Step records the books at the current block. It returns false without
+// reading the books when no block was produced since the last step.
+func (r *Recorder) Step(ctx context.Context) (bool, error) {
+	height, err := r.heights.BlockHeight(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "get block height")
+	}
+	if height <= r.lastBlock {
+		return false, nil
+	}
+	markets, err := r.marketIDs(ctx, height)
+	if err != nil {
+		return false, err
+	}
+
+	now := r.now().Unix()
+	var (
+		ticks  []Tick
+		deltas []Delta
+		fills  []Fill
+	)
+	for _, id := range markets {
+		prev := make(map[bool]*Book, 2)
+		cur := make(map[bool]*Book, 2)
+		for _, outcome := range []bool{true, false} {
+			key := bookKey{id, outcome}
+			book, tick, err := r.read(ctx, id, outcome, height, now)
+			if err != nil {
+				return false, err
+			}
+			ticks = append(ticks, tick)
+			prev[outcome], cur[outcome] = r.books[key], book
+
+			if r.books[key] == nil || height-r.lastSnapshot[key] >= r.snapshotEvery {
+				if err := r.store.SaveSnapshot(ctx, book); err != nil {
+					return false, errors.Wrapf(err, "save snapshot of market %d", id)
+				}
+				r.lastSnapshot[key] = height
+			}
+			if r.books[key] != nil {
+				deltas = append(deltas, diffBooks(r.books[key], book)...)
+			}
+			r.books[key] = book
+		}
+		if prev[true] != nil && prev[false] != nil {
+			fills = append(fills, inferFills(prev, cur)...)
+		}
+	}
+
+	if err := r.store.SaveTicks(ctx, ticks); err != nil {
+		return false, errors.Wrap(err, "save ticks")
+	}
+	if err := r.store.SaveDeltas(ctx, deltas); err != nil {
+		return false, errors.Wrap(err, "save deltas")
+	}
+	if err := r.store.SaveFills(ctx, fills); err != nil {
+		return false, errors.Wrap(err, "save fills")
+	}
+	r.lastBlock = height
+	return true, nil
+}
+
+// marketIDs returns the markets to read at height. Without WithMarkets, the
+// unsettled markets are listed on the first step and at every snapshot.
+func (r *Recorder) marketIDs(ctx context.Context, height int64) ([]int, error) {
+	if len(r.markets) > 0 {
+		return r.markets, nil
+	}
+	if r.lastBlock > 0 && height/r.snapshotEvery == r.lastBlock/r.snapshotEvery {
+		ids := make([]int, 0, len(r.books)/2)
+		for key := range r.books {
+			if key.outcome {
+				ids = append(ids, key.queryID)
+			}
+		}
+		sort.Ints(ids)
+		return ids, nil
+	}
+
+	var ids []int
+	settled := false
+	for offset := 0; ; offset += marketPageSize {
+		limit, off := marketPageSize, offset
+		page, err := r.ob.ListMarkets(ctx, types.ListMarketsInput{SettledFilter: &settled, Limit: &limit, Offset: &off})
+		if err != nil {
+			return nil, errors.Wrapf(err, "list markets at offset %d", offset)
+		}
+		for _, m := range page {
+			ids = append(ids, m.ID)
+		}
+		if len(page) < marketPageSize {
+			break
+		}
+	}
+	// Forget markets that settled so their books are snapshotted afresh if
+	// they are ever recorded again.
+	listed := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		listed[id] = true
+	}
+	for key := range r.books {
+		if !listed[key.queryID] {
+			delete(r.books, key)
+			delete(r.lastSnapshot, key)
+		}
+	}
+	return ids, nil
+}
+
+func (r *Recorder) read(ctx context.Context, queryID int, outcome bool, height, now int64) (*Book, Tick, error) {
+	entries, err := r.ob.GetOrderBook(ctx, types.GetOrderBookInput{QueryID: queryID, Outcome: outcome})
+	if err != nil {
+		return nil, Tick{}, errors.Wrapf(err, "get order book of market %d (outcome=%t)", queryID, outcome)
+	}
+	depth, err := r.ob.GetMarketDepth(ctx, types.GetMarketDepthInput{QueryID: queryID, Outcome: outcome})
+	if err != nil {
+		return nil, Tick{}, errors.Wrapf(err, "get market depth of market %d (outcome=%t)", queryID, outcome)
+	}
+	best, err := r.ob.GetBestPrices(ctx, types.GetBestPricesInput{QueryID: queryID, Outcome: outcome})
+	if err != nil {
+		return nil, Tick{}, errors.Wrapf(err, "get best prices of market %d (outcome=%t)", queryID, outcome)
+	}
+
+	tick := Tick{QueryID: queryID, Outcome: outcome, Block: height, Time: now}
+	if best != nil {
+		tick.BestBid, tick.BestAsk = best.BestBid, best.BestAsk
+	}
+	for _, l := range depth {
+		tick.BuyVolume += l.BuyVolume
+		tick.SellVolume += l.SellVolume
+		if l.BuyVolume > 0 || l.SellVolume > 0 {
+			tick.Levels++
+		}
+	}
+	return &Book{QueryID: queryID, Outcome: outcome, Block: height, Time: now, Entries: entries}, tick, nil
+}
+
+func walletHex(b []byte) string {
+	return "0x" + hex.EncodeToString(b)
+}
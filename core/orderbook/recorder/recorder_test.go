@@ -0,0 +1,167 @@
+package recorder
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trufnetwork/sdk-go/core/orderbook/sim"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+const (
+	start = int64(1_700_000_000)
+	alice = "0x1111111111111111111111111111111111111111"
+	bob   = "0x2222222222222222222222222222222222222222"
+	carol = "0x3333333333333333333333333333333333333333"
+	dave  = "0x4444444444444444444444444444444444444444"
+)
+
+type blocks struct{ height int64 }
+
+func (b *blocks) BlockHeight(context.Context) (int64, error) { return b.height, nil }
+
+func newMarket(t *testing.T, ctx context.Context) *sim.Simulator {
+	t.Helper()
+	s := sim.New(sim.WithTime(start))
+	for _, w := range []string{alice, bob, carol, dave} {
+		require.NoError(t, s.Deposit(w, 100_000))
+	}
+	_, err := s.Trader(alice).CreateMarket(ctx, types.CreateMarketInput{
+		Bridge:          "hoodi_tt2",
+		QueryComponents: bytes.Repeat([]byte{1}, 128),
+		SettleTime:      start + 3600,
+		MaxSpread:       5,
+		MinOrderSize:    1,
+	})
+	require.NoError(t, err)
+	return s
+}
+
+func TestRecorder(t *testing.T) {
+	ctx := context.Background()
+	s := newMarket(t, ctx)
+	a, b, c, d := s.Trader(alice), s.Trader(bob), s.Trader(carol), s.Trader(dave)
+	must := func(_ any, err error) { require.NoError(t, err) }
+
+	store, err := OpenSQLite(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+	chain := &blocks{}
+	r, err := New(a, chain, WithStore(store), WithMarkets(1), WithSnapshotEvery(2),
+		WithClock(func() time.Time { return time.Unix(start+chain.height, 0) }))
+	require.NoError(t, err)
+
+	step := func(height int64) {
+		t.Helper()
+		chain.height = height
+		recorded, err := r.Step(ctx)
+		require.NoError(t, err)
+		require.True(t, recorded)
+	}
+
+	// Block 1: a bid at 40 and an ask at 60.
+	must(b.PlaceSplitLimitOrder(ctx, types.PlaceSplitLimitOrderInput{QueryID: 1, TruePrice: 60, Amount: 5}))
+	must(b.CancelOrder(ctx, types.CancelOrderInput{QueryID: 1, Outcome: false, Price: 40}))
+	must(b.PlaceSellOrder(ctx, types.PlaceSellOrderInput{QueryID: 1, Outcome: true, Price: 60, Amount: 5}))
+	must(a.PlaceBuyOrder(ctx, types.PlaceBuyOrderInput{QueryID: 1, Outcome: true, Price: 40, Amount: 10}))
+	step(1)
+
+	recorded, err := r.Step(ctx)
+	require.NoError(t, err)
+	assert.False(t, recorded, "no new block")
+
+	// Block 2: Carol lifts 3 of Bob's ask without resting.
+	must(c.PlaceBuyOrder(ctx, types.PlaceBuyOrderInput{QueryID: 1, Outcome: true, Price: 60, Amount: 3}))
+	step(2)
+
+	// Block 3: Dave's NO bid at 65 mints against all of Alice's bid and rests
+	// the remainder; Bob cancels his ask.
+	must(d.PlaceBuyOrder(ctx, types.PlaceBuyOrderInput{QueryID: 1, Outcome: false, Price: 65, Amount: 12}))
+	must(b.CancelOrder(ctx, types.CancelOrderInput{QueryID: 1, Outcome: true, Price: 60}))
+	step(3)
+
+	fills, err := store.Fills(ctx, 1, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, fills, 2)
+	assert.Equal(t, Fill{QueryID: 1, Block: 2, Time: start + 2, Kind: FillUnpaired, Outcome: true, Price: 60, Amount: 3, Maker: bob}, fills[0])
+	assert.Equal(t, Fill{QueryID: 1, Block: 3, Time: start + 3, Kind: FillMint, Outcome: true, Price: 40, Amount: 10, Maker: alice, Taker: dave}, fills[1])
+
+	// The YES book at block 2 comes from the block 1 snapshot and deltas.
+	book, err := BookAt(ctx, store, 1, true, 2)
+	require.NoError(t, err)
+	require.Len(t, book.Entries, 2)
+	assert.Equal(t, int64(2), book.Block)
+	assert.Equal(t, -40, book.Entries[0].Price)
+	assert.Equal(t, int64(2), book.Entries[1].Amount)
+	book, err = BookAt(ctx, store, 1, false, 3)
+	require.NoError(t, err)
+	require.Len(t, book.Entries, 1)
+	assert.Equal(t, -65, book.Entries[0].Price)
+	assert.Equal(t, int64(2), book.Entries[0].Amount)
+	book, err = BookAt(ctx, store, 1, true, 0)
+	require.NoError(t, err)
+	assert.Nil(t, book)
+
+	spread, err := SpreadSeries(ctx, store, 1, true, 1, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []Point{{Block: 1, Time: start + 1, Value: 20}, {Block: 2, Time: start + 2, Value: 20}}, spread)
+	mid, err := MidSeries(ctx, store, 1, true, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []Point{{Block: 1, Time: start + 1, Value: 50}}, mid)
+	depth, err := DepthSeries(ctx, store, 1, true, 1, 3)
+	require.NoError(t, err)
+	require.Len(t, depth, 3)
+	assert.Equal(t, DepthPoint{Block: 2, Time: start + 2, BuyVolume: 10, SellVolume: 2, Levels: 2}, depth[1])
+	assert.Zero(t, depth[2].Levels)
+}
+
+func TestRecorder_ListsMarkets(t *testing.T) {
+	ctx := context.Background()
+	s := newMarket(t, ctx)
+	store, err := OpenSQLite(":memory:")
+	require.NoError(t, err)
+	defer store.Close()
+
+	r, err := New(s.Trader(alice), &blocks{height: 7}, WithStore(store))
+	require.NoError(t, err)
+	_, err = r.Step(ctx)
+	require.NoError(t, err)
+	ticks, err := store.Ticks(ctx, 1, false, 7, 7)
+	require.NoError(t, err)
+	assert.Len(t, ticks, 1)
+}
+
+func TestInferFills_DirectPair(t *testing.T) {
+	// A bid and an ask at the same price that both shrank matched each other.
+	prev := map[bool]*Book{
+		true: {QueryID: 1, Outcome: true, Entries: []types.OrderBookEntry{
+			{Price: -55, Amount: 4, LastUpdated: 2, WalletAddress: []byte{0xbb}},
+			{Price: 55, Amount: 6, LastUpdated: 1, WalletAddress: []byte{0xaa}},
+		}},
+		false: {QueryID: 1, Outcome: false},
+	}
+	cur := map[bool]*Book{
+		true: {QueryID: 1, Outcome: true, Block: 9, Entries: []types.OrderBookEntry{
+			{Price: -55, Amount: 1, LastUpdated: 2, WalletAddress: []byte{0xbb}},
+			{Price: 55, Amount: 3, LastUpdated: 1, WalletAddress: []byte{0xaa}},
+		}},
+		false: {QueryID: 1, Outcome: false, Block: 9},
+	}
+	fills := inferFills(prev, cur)
+	require.Len(t, fills, 1)
+	assert.Equal(t, Fill{QueryID: 1, Block: 9, Kind: FillDirect, Outcome: true, Price: 55, Amount: 3, Maker: "0xaa", Taker: "0xbb"}, fills[0])
+}
+
+func TestNew_Validation(t *testing.T) {
+	_, err := New(nil, &blocks{})
+	assert.ErrorContains(t, err, "order book is required")
+	s := sim.New()
+	_, err = New(s.Trader(alice), nil)
+	assert.ErrorContains(t, err, "block source is required")
+	_, err = New(s.Trader(alice), &blocks{}, WithMarkets(0))
+	assert.ErrorContains(t, err, "query_id must be positive")
+}
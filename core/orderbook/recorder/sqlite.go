@@ -0,0 +1,309 @@
+package recorder
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/trufnetwork/sdk-go/core/types"
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS snapshots (
+	query_id INTEGER NOT NULL,
+	outcome  INTEGER NOT NULL,
+	block    INTEGER NOT NULL,
+	time     INTEGER NOT NULL,
+	PRIMARY KEY (query_id, outcome, block)
+);
+CREATE TABLE IF NOT EXISTS snapshot_entries (
+	query_id       INTEGER NOT NULL,
+	outcome        INTEGER NOT NULL,
+	block          INTEGER NOT NULL,
+	wallet         TEXT    NOT NULL,
+	participant_id INTEGER NOT NULL,
+	price          INTEGER NOT NULL,
+	amount         INTEGER NOT NULL,
+	last_updated   INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS snapshot_entries_book ON snapshot_entries (query_id, outcome, block);
+CREATE TABLE IF NOT EXISTS deltas (
+	query_id       INTEGER NOT NULL,
+	outcome        INTEGER NOT NULL,
+	block          INTEGER NOT NULL,
+	wallet         TEXT    NOT NULL,
+	participant_id INTEGER NOT NULL,
+	price          INTEGER NOT NULL,
+	amount         INTEGER NOT NULL,
+	last_updated   INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS deltas_book ON deltas (query_id, outcome, block);
+CREATE TABLE IF NOT EXISTS ticks (
+	query_id    INTEGER NOT NULL,
+	outcome     INTEGER NOT NULL,
+	block       INTEGER NOT NULL,
+	time        INTEGER NOT NULL,
+	best_bid    INTEGER,
+	best_ask    INTEGER,
+	buy_volume  INTEGER NOT NULL,
+	sell_volume INTEGER NOT NULL,
+	levels      INTEGER NOT NULL,
+	PRIMARY KEY (query_id, outcome, block)
+);
+CREATE TABLE IF NOT EXISTS fills (
+	query_id INTEGER NOT NULL,
+	block    INTEGER NOT NULL,
+	time     INTEGER NOT NULL,
+	kind     TEXT    NOT NULL,
+	outcome  INTEGER NOT NULL,
+	price    INTEGER NOT NULL,
+	amount   INTEGER NOT NULL,
+	maker    TEXT    NOT NULL,
+	taker    TEXT    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS fills_market ON fills (query_id, block);
+`
+
+// SQLiteStore is a Store backed by a SQLite database.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+var _ Store = (*SQLiteStore)(nil)
+
+// OpenSQLite opens, creating if needed, the SQLite database at path. Use
+// ":memory:" for a throwaway database.
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "open %s", path)
+	}
+	// A single connection serializes writes and keeps ":memory:" databases
+	// shared.
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "create schema")
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// Close closes the database.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return errors.WithStack(tx.Commit())
+}
+
+// SaveSnapshot implements Store.
+func (s *SQLiteStore) SaveSnapshot(ctx context.Context, book *Book) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR REPLACE INTO snapshots (query_id, outcome, block, time) VALUES (?, ?, ?, ?)`,
+			book.QueryID, book.Outcome, book.Block, book.Time); err != nil {
+			return errors.WithStack(err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM snapshot_entries WHERE query_id = ? AND outcome = ? AND block = ?`,
+			book.QueryID, book.Outcome, book.Block); err != nil {
+			return errors.WithStack(err)
+		}
+		for _, e := range book.Entries {
+			if _, err := tx.ExecContext(ctx,
+				`INSERT INTO snapshot_entries (query_id, outcome, block, wallet, participant_id, price, amount, last_updated)
+				 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+				book.QueryID, book.Outcome, book.Block, walletHex(e.WalletAddress), e.ParticipantID, e.Price, e.Amount, e.LastUpdated); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	})
+}
+
+// SaveDeltas implements Store.
+func (s *SQLiteStore) SaveDeltas(ctx context.Context, deltas []Delta) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, d := range deltas {
+			if _, err := tx.ExecContext(ctx,
+				`INSERT INTO deltas (query_id, outcome, block, wallet, participant_id, price, amount, last_updated)
+				 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+				d.QueryID, d.Outcome, d.Block, strings.ToLower(d.Wallet), d.ParticipantID, d.Price, d.Amount, d.LastUpdated); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	})
+}
+
+// SaveTicks implements Store.
+func (s *SQLiteStore) SaveTicks(ctx context.Context, ticks []Tick) error {
+	if len(ticks) == 0 {
+		return nil
+	}
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, t := range ticks {
+			if _, err := tx.ExecContext(ctx,
+				`INSERT OR REPLACE INTO ticks (query_id, outcome, block, time, best_bid, best_ask, buy_volume, sell_volume, levels)
+				 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				t.QueryID, t.Outcome, t.Block, t.Time, nullInt(t.BestBid), nullInt(t.BestAsk), t.BuyVolume, t.SellVolume, t.Levels); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	})
+}
+
+// SaveFills implements Store.
+func (s *SQLiteStore) SaveFills(ctx context.Context, fills []Fill) error {
+	if len(fills) == 0 {
+		return nil
+	}
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, f := range fills {
+			if _, err := tx.ExecContext(ctx,
+				`INSERT INTO fills (query_id, block, time, kind, outcome, price, amount, maker, taker)
+				 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				f.QueryID, f.Block, f.Time, string(f.Kind), f.Outcome, f.Price, f.Amount, f.Maker, f.Taker); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	})
+}
+
+// LatestSnapshot implements Store.
+func (s *SQLiteStore) LatestSnapshot(ctx context.Context, queryID int, outcome bool, block int64) (*Book, error) {
+	book := &Book{QueryID: queryID, Outcome: outcome}
+	err := s.db.QueryRowContext(ctx,
+		`SELECT block, time FROM snapshots WHERE query_id = ? AND outcome = ? AND block <= ? ORDER BY block DESC LIMIT 1`,
+		queryID, outcome, block).Scan(&book.Block, &book.Time)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT wallet, participant_id, price, amount, last_updated FROM snapshot_entries
+		 WHERE query_id = ? AND outcome = ? AND block = ? ORDER BY price, last_updated`,
+		queryID, outcome, book.Block)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			e      types.OrderBookEntry
+			wallet string
+		)
+		if err := rows.Scan(&wallet, &e.ParticipantID, &e.Price, &e.Amount, &e.LastUpdated); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if e.WalletAddress, err = hex.DecodeString(strings.TrimPrefix(wallet, "0x")); err != nil {
+			return nil, errors.Wrapf(err, "wallet %q", wallet)
+		}
+		book.Entries = append(book.Entries, e)
+	}
+	return book, errors.WithStack(rows.Err())
+}
+
+// Deltas implements Store.
+func (s *SQLiteStore) Deltas(ctx context.Context, queryID int, outcome bool, afterBlock, toBlock int64) ([]Delta, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT block, wallet, participant_id, price, amount, last_updated FROM deltas
+		 WHERE query_id = ? AND outcome = ? AND block > ? AND block <= ? ORDER BY block, rowid`,
+		queryID, outcome, afterBlock, toBlock)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+	var out []Delta
+	for rows.Next() {
+		d := Delta{QueryID: queryID, Outcome: outcome}
+		if err := rows.Scan(&d.Block, &d.Wallet, &d.ParticipantID, &d.Price, &d.Amount, &d.LastUpdated); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		out = append(out, d)
+	}
+	return out, errors.WithStack(rows.Err())
+}
+
+// Ticks implements Store.
+func (s *SQLiteStore) Ticks(ctx context.Context, queryID int, outcome bool, fromBlock, toBlock int64) ([]Tick, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT block, time, best_bid, best_ask, buy_volume, sell_volume, levels FROM ticks
+		 WHERE query_id = ? AND outcome = ? AND block >= ? AND block <= ? ORDER BY block`,
+		queryID, outcome, fromBlock, toBlock)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+	var out []Tick
+	for rows.Next() {
+		var (
+			t        = Tick{QueryID: queryID, Outcome: outcome}
+			bid, ask sql.NullInt64
+		)
+		if err := rows.Scan(&t.Block, &t.Time, &bid, &ask, &t.BuyVolume, &t.SellVolume, &t.Levels); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		t.BestBid, t.BestAsk = intPtr(bid), intPtr(ask)
+		out = append(out, t)
+	}
+	return out, errors.WithStack(rows.Err())
+}
+
+// Fills implements Store.
+func (s *SQLiteStore) Fills(ctx context.Context, queryID int, fromBlock, toBlock int64) ([]Fill, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT block, time, kind, outcome, price, amount, maker, taker FROM fills
+		 WHERE query_id = ? AND block >= ? AND block <= ? ORDER BY block, rowid`,
+		queryID, fromBlock, toBlock)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+	var out []Fill
+	for rows.Next() {
+		f := Fill{QueryID: queryID}
+		var kind string
+		if err := rows.Scan(&f.Block, &f.Time, &kind, &f.Outcome, &f.Price, &f.Amount, &f.Maker, &f.Taker); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		f.Kind = FillKind(kind)
+		out = append(out, f)
+	}
+	return out, errors.WithStack(rows.Err())
+}
+
+func nullInt(p *int) sql.NullInt64 {
+	if p == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(*p), Valid: true}
+}
+
+func intPtr(n sql.NullInt64) *int {
+	if !n.Valid {
+		return nil
+	}
+	v := int(n.Int64)
+	return &v
+}
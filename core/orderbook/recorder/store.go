@@ -0,0 +1,112 @@
+package recorder
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// Store persists recorded history. Implementations only store and return
+// records; reconstruction and series are computed by the package functions.
+// OpenSQLite returns the default implementation.
+type Store interface {
+	SaveSnapshot(ctx context.Context, book *Book) error
+	SaveDeltas(ctx context.Context, deltas []Delta) error
+	SaveTicks(ctx context.Context, ticks []Tick) error
+	SaveFills(ctx context.Context, fills []Fill) error
+
+	// LatestSnapshot returns the last snapshot of the book taken at or before
+	// block, or nil if there is none.
+	LatestSnapshot(ctx context.Context, queryID int, outcome bool, block int64) (*Book, error)
+	// Deltas returns the deltas of the book in (afterBlock, toBlock], in block
+	// order.
+	Deltas(ctx context.Context, queryID int, outcome bool, afterBlock, toBlock int64) ([]Delta, error)
+	// Ticks returns the ticks of the book in [fromBlock, toBlock], in block
+	// order.
+	Ticks(ctx context.Context, queryID int, outcome bool, fromBlock, toBlock int64) ([]Tick, error)
+	// Fills returns the fills of the market in [fromBlock, toBlock], in block
+	// order.
+	Fills(ctx context.Context, queryID int, fromBlock, toBlock int64) ([]Fill, error)
+
+	Close() error
+}
+
+// BookAt reconstructs the book of a market outcome at block from the latest
+// snapshot before it and the deltas since. It returns nil if nothing was
+// recorded at or before block.
+func BookAt(ctx context.Context, store Store, queryID int, outcome bool, block int64) (*Book, error) {
+	snap, err := store.LatestSnapshot(ctx, queryID, outcome, block)
+	if err != nil {
+		return nil, errors.Wrap(err, "load snapshot")
+	}
+	if snap == nil {
+		return nil, nil
+	}
+	deltas, err := store.Deltas(ctx, queryID, outcome, snap.Block, block)
+	if err != nil {
+		return nil, errors.Wrap(err, "load deltas")
+	}
+	return applyDeltas(snap, deltas), nil
+}
+
+// Point is a value of a series at a block.
+type Point struct {
+	Block int64
+	Time  int64
+	Value float64
+}
+
+// DepthPoint is the depth of a book at a block.
+type DepthPoint struct {
+	Block      int64
+	Time       int64
+	BuyVolume  int64
+	SellVolume int64
+	Levels     int
+}
+
+// SpreadSeries returns the bid-ask spread of a market outcome in cents over
+// [fromBlock, toBlock]. Blocks with an empty side are skipped.
+func SpreadSeries(ctx context.Context, store Store, queryID int, outcome bool, fromBlock, toBlock int64) ([]Point, error) {
+	return series(ctx, store, queryID, outcome, fromBlock, toBlock, func(t Tick) *float64 {
+		s := t.Spread()
+		if s == nil {
+			return nil
+		}
+		v := float64(*s)
+		return &v
+	})
+}
+
+// MidSeries returns the mid-price of a market outcome in cents over
+// [fromBlock, toBlock]. Blocks with an empty side are skipped.
+func MidSeries(ctx context.Context, store Store, queryID int, outcome bool, fromBlock, toBlock int64) ([]Point, error) {
+	return series(ctx, store, queryID, outcome, fromBlock, toBlock, Tick.Mid)
+}
+
+// DepthSeries returns the depth of a market outcome over [fromBlock, toBlock].
+func DepthSeries(ctx context.Context, store Store, queryID int, outcome bool, fromBlock, toBlock int64) ([]DepthPoint, error) {
+	ticks, err := store.Ticks(ctx, queryID, outcome, fromBlock, toBlock)
+	if err != nil {
+		return nil, errors.Wrap(err, "load ticks")
+	}
+	out := make([]DepthPoint, 0, len(ticks))
+	for _, t := range ticks {
+		out = append(out, DepthPoint{Block: t.Block, Time: t.Time, BuyVolume: t.BuyVolume, SellVolume: t.SellVolume, Levels: t.Levels})
+	}
+	return out, nil
+}
+
+func series(ctx context.Context, store Store, queryID int, outcome bool, fromBlock, toBlock int64, value func(Tick) *float64) ([]Point, error) {
+	ticks, err := store.Ticks(ctx, queryID, outcome, fromBlock, toBlock)
+	if err != nil {
+		return nil, errors.Wrap(err, "load ticks")
+	}
+	var out []Point
+	for _, t := range ticks {
+		if v := value(t); v != nil {
+			out = append(out, Point{Block: t.Block, Time: t.Time, Value: *v})
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,332 @@
+// Package rewards estimates the LP rewards a set of quotes would earn.
+//
+// sample_lp_rewards scores the resting orders of a market at a block and
+// splits the block's rewards in proportion to the scores. Estimate applies
+// the same rules to the current book with hypothetical quotes added, so a
+// market maker can compare quotes before placing them:
+//
+//	est := rewards.New(ob)
+//	e, err := est.Estimate(ctx, queryID, rewards.Input{
+//	    Wallet: wallet,
+//	    Quotes: []bot.Quote{
+//	        {Outcome: true, Side: bot.Buy, Price: 48, Amount: 100},
+//	        {Outcome: false, Side: bot.Buy, Price: 48, Amount: 100},
+//	    },
+//	})
+//	fmt.Printf("%.2f%% of each sampled block\n", e.Percent)
+//
+// The rules follow the node's sample_lp_rewards
+// (034-order-book-rewards.sql):
+//
+//   - The midpoint is halfway between the best YES bid and the best YES ask,
+//     counting NO orders at their YES-equivalent price (100-p). A market
+//     without both is not sampled.
+//   - The midpoint sets the reward spread (see DefaultSpreadTiers), capped
+//     at the market's MaxSpread. A midpoint outside every tier is not
+//     sampled.
+//   - Only buy orders of at least MinOrderSize shares within the spread of
+//     the midpoint are eligible. A YES bid at p is p below it; a NO bid at q
+//     offers YES at 100-q.
+//   - An order scores Amount × ((spread - distance) / spread)².
+//   - Liquidity must be two-sided: a wallet scores the lesser of its YES-bid
+//     and NO-bid totals.
+//   - Each wallet receives its score's share of the total, in percent.
+//
+// What was actually paid is reported by GetDistributionDetails; compare the
+// two when the node's rules change.
+package rewards
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/trufnetwork/sdk-go/core/orderbook/bot"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+// SpreadTier gives the reward spread of markets whose midpoint is below
+// BelowMidpoint and not covered by an earlier tier.
+type SpreadTier struct {
+	BelowMidpoint float64 // cents
+	Spread        int     // cents
+}
+
+// DefaultSpreadTiers is the node's dynamic spread: 5¢ for midpoints of
+// 0-29¢, 4¢ for 30-59¢ and 3¢ for 60-79¢. Midpoints of 80¢ and above earn
+// nothing.
+var DefaultSpreadTiers = []SpreadTier{
+	{BelowMidpoint: 30, Spread: 5},
+	{BelowMidpoint: 60, Spread: 4},
+	{BelowMidpoint: 80, Spread: 3},
+}
+
+// Market holds the reward parameters of a market.
+type Market struct {
+	MaxSpread    int          // cents, from CreateMarket
+	MinOrderSize int64        // shares, from CreateMarket
+	Tiers        []SpreadTier // nil for DefaultSpreadTiers
+}
+
+// Input is the wallet and quotes to estimate.
+type Input struct {
+	Wallet string      // 0x-prefixed address the quotes would be placed from
+	Quotes []bot.Quote // hypothetical orders, added to the book
+	// ReplaceOrders drops the wallet's resting orders from the book first,
+	// for estimating quotes that replace them, as a bot does.
+	ReplaceOrders bool
+}
+
+// QuoteScore is how one of the wallet's orders scores.
+type QuoteScore struct {
+	Quote    bot.Quote
+	Resting  bool    // an order already on the book rather than an Input quote
+	Distance float64 // cents from the midpoint, in YES terms
+	Score    float64
+	Reason   string // why the order scores nothing; empty when it scores
+}
+
+// Estimate is the expected LP reward of a wallet at the next sampled block.
+type Estimate struct {
+	Midpoint float64 // cents, YES terms; 0 when the book is one-sided
+	Spread   int     // reward spread in cents; 0 when the market is not sampled
+	Reason   string  // why the market is not sampled; empty when it is
+
+	Orders     []QuoteScore // the wallet's orders, resting and hypothetical
+	Score      float64      // the wallet's two-sided score
+	TotalScore float64      // all wallets' scores, the wallet's included
+	Percent    float64      // the wallet's share of a block's rewards, 0-100
+}
+
+// Estimator estimates rewards against a live order book.
+type Estimator struct {
+	ob types.IOrderBook
+}
+
+// New returns an estimator reading ob.
+func New(ob types.IOrderBook) *Estimator {
+	return &Estimator{ob: ob}
+}
+
+// Estimate reads the market's parameters and both books and estimates the
+// reward of in.Quotes on top of them.
+func (e *Estimator) Estimate(ctx context.Context, queryID int, in Input) (*Estimate, error) {
+	info, err := e.ob.GetMarketInfo(ctx, types.GetMarketInfoInput{QueryID: queryID})
+	if err != nil {
+		return nil, errors.Wrapf(err, "get market %d", queryID)
+	}
+	if info.Settled {
+		return nil, errors.Errorf("market %d is settled", queryID)
+	}
+	books := make(map[bool][]types.OrderBookEntry, 2)
+	for _, outcome := range []bool{true, false} {
+		entries, err := e.ob.GetOrderBook(ctx, types.GetOrderBookInput{QueryID: queryID, Outcome: outcome})
+		if err != nil {
+			return nil, errors.Wrapf(err, "get order book of market %d (outcome=%t)", queryID, outcome)
+		}
+		books[outcome] = entries
+	}
+	return Compute(Market{MaxSpread: info.MaxSpread, MinOrderSize: info.MinOrderSize}, books[true], books[false], in)
+}
+
+// FromSnapshot estimates in.Quotes against the books of a bot snapshot,
+// which must hold both outcomes.
+func FromSnapshot(m Market, snap *bot.Snapshot, in Input) (*Estimate, error) {
+	yes, no := snap.Books[true], snap.Books[false]
+	if yes == nil || no == nil {
+		return nil, errors.New("snapshot must hold the books of both outcomes")
+	}
+	return Compute(m, yes.Entries, no.Entries, in)
+}
+
+type order struct {
+	wallet  string
+	outcome bool
+	price   int // signed
+	amount  int64
+	quote   int // index into Input.Quotes, -1 for resting orders
+}
+
+// Compute estimates in.Quotes against the given YES and NO books.
+func Compute(m Market, yes, no []types.OrderBookEntry, in Input) (*Estimate, error) {
+	if m.MaxSpread < 1 {
+		return nil, errors.Errorf("max_spread must be positive, got %d", m.MaxSpread)
+	}
+	wallet, err := normalizeWallet(in.Wallet)
+	if err != nil {
+		return nil, err
+	}
+	tiers := m.Tiers
+	if tiers == nil {
+		tiers = DefaultSpreadTiers
+	}
+
+	var orders []order
+	for _, book := range []struct {
+		outcome bool
+		entries []types.OrderBookEntry
+	}{{true, yes}, {false, no}} {
+		outcome := book.outcome
+		for _, en := range book.entries {
+			w := "0x" + hex.EncodeToString(en.WalletAddress)
+			if en.Price == 0 || (in.ReplaceOrders && w == wallet) {
+				continue
+			}
+			orders = append(orders, order{wallet: w, outcome: outcome, price: en.Price, amount: en.Amount, quote: -1})
+		}
+	}
+	for i, q := range in.Quotes {
+		if err := validateQuote(q); err != nil {
+			return nil, errors.Wrapf(err, "quote %d", i)
+		}
+		price := q.Price
+		if q.Side == bot.Buy {
+			price = -price
+		}
+		orders = append(orders, order{wallet: wallet, outcome: q.Outcome, price: price, amount: q.Amount, quote: i})
+	}
+	sort.SliceStable(orders, func(i, j int) bool {
+		if orders[i].wallet != orders[j].wallet {
+			return orders[i].wallet < orders[j].wallet
+		}
+		return orders[i].quote < orders[j].quote
+	})
+
+	bid, ask, err := topOfBook(orders)
+	if err != nil {
+		return nil, err
+	}
+	est := &Estimate{}
+	switch {
+	case bid == nil || ask == nil:
+		est.Reason = "the book has no YES bid or no YES ask"
+	default:
+		est.Midpoint = float64(*bid+*ask) / 2
+		for _, t := range tiers {
+			if est.Midpoint < t.BelowMidpoint {
+				est.Spread = min(t.Spread, m.MaxSpread)
+				break
+			}
+		}
+		if est.Spread == 0 {
+			est.Reason = fmt.Sprintf("midpoint %.1f¢ is outside every spread tier", est.Midpoint)
+		}
+	}
+
+	sides := make(map[string]*[2]float64) // wallet -> YES-bid, NO-bid scores
+	for _, o := range orders {
+		qs := est.score(o, m.MinOrderSize)
+		if sides[o.wallet] == nil {
+			sides[o.wallet] = &[2]float64{}
+		}
+		if o.outcome {
+			sides[o.wallet][0] += qs.Score
+		} else {
+			sides[o.wallet][1] += qs.Score
+		}
+		if o.wallet == wallet {
+			qs.Resting = o.quote < 0
+			est.Orders = append(est.Orders, qs)
+		}
+	}
+	for w, s := range sides {
+		score := math.Min(s[0], s[1])
+		est.TotalScore += score
+		if w == wallet {
+			est.Score = score
+		}
+	}
+	if est.TotalScore > 0 {
+		est.Percent = 100 * est.Score / est.TotalScore
+	}
+	return est, nil
+}
+
+// score scores one order against the estimate's midpoint and spread.
+func (e *Estimate) score(o order, minSize int64) QuoteScore {
+	q := bot.Quote{Outcome: o.outcome, Side: bot.Buy, Price: -o.price, Amount: o.amount}
+	if o.price > 0 {
+		q.Side, q.Price = bot.Sell, o.price
+	}
+	qs := QuoteScore{Quote: q}
+	// YES-equivalent bid: a NO bid at q offers YES at 100-q.
+	if q.Side == bot.Buy {
+		if o.outcome {
+			qs.Distance = e.Midpoint - float64(q.Price)
+		} else {
+			qs.Distance = float64(100-q.Price) - e.Midpoint
+		}
+	}
+	switch {
+	case e.Spread == 0:
+		qs.Reason = "market is not sampled"
+	case q.Side == bot.Sell:
+		qs.Reason = "only buy orders earn LP rewards"
+	case o.amount < minSize:
+		qs.Reason = fmt.Sprintf("amount %d is below the minimum order size %d", o.amount, minSize)
+	case qs.Distance >= float64(e.Spread):
+		qs.Reason = fmt.Sprintf("%.1f¢ from the midpoint, outside the %d¢ spread", qs.Distance, e.Spread)
+	default:
+		r := (float64(e.Spread) - qs.Distance) / float64(e.Spread)
+		qs.Score = float64(o.amount) * r * r
+	}
+	return qs
+}
+
+// topOfBook returns the best YES bid and ask in cents, counting NO orders at
+// 100-p. It fails if the hypothetical quotes cross the book, since they
+// would trade rather than rest.
+func topOfBook(orders []order) (bid, ask *int, err error) {
+	for _, o := range orders {
+		p := o.price
+		if !o.outcome {
+			// A NO bid at q is a YES ask at 100-q, and the other way round.
+			if p < 0 {
+				p = 100 + p
+			} else {
+				p = -(100 - p)
+			}
+		}
+		if p < 0 && (bid == nil || -p > *bid) {
+			v := -p
+			bid = &v
+		}
+		if p > 0 && (ask == nil || p < *ask) {
+			v := p
+			ask = &v
+		}
+	}
+	if bid != nil && ask != nil && *bid >= *ask {
+		return nil, nil, errors.Errorf("quotes cross the book: best YES bid %d¢ >= best YES ask %d¢", *bid, *ask)
+	}
+	return bid, ask, nil
+}
+
+func validateQuote(q bot.Quote) error {
+	if q.Side != bot.Buy && q.Side != bot.Sell {
+		return errors.Errorf("invalid side %d", q.Side)
+	}
+	if q.Price < 1 || q.Price > 99 {
+		return errors.Errorf("price must be between 1 and 99, got %d", q.Price)
+	}
+	if q.Amount <= 0 {
+		return errors.Errorf("amount must be positive, got %d", q.Amount)
+	}
+	return nil
+}
+
+func normalizeWallet(wallet string) (string, error) {
+	w := strings.ToLower(wallet)
+	if !strings.HasPrefix(w, "0x") {
+		w = "0x" + w
+	}
+	check := types.GetParticipantRewardHistoryInput{WalletHex: w}
+	if err := check.Validate(); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return w, nil
+}
@@ -0,0 +1,148 @@
+package rewards
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trufnetwork/sdk-go/core/orderbook/bot"
+	"github.com/trufnetwork/sdk-go/core/orderbook/sim"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+const (
+	alice = "0x1111111111111111111111111111111111111111"
+	bob   = "0x2222222222222222222222222222222222222222"
+)
+
+var market = Market{MaxSpread: 5, MinOrderSize: 10}
+
+func bid(wallet string, price int, amount int64) types.OrderBookEntry {
+	return types.OrderBookEntry{Price: -price, Amount: amount, WalletAddress: addr(wallet)}
+}
+
+func addr(wallet string) []byte {
+	b := make([]byte, 20)
+	for i := range b {
+		b[i] = wallet[2] - '0'
+		b[i] |= b[i] << 4
+	}
+	return b
+}
+
+func quotes(yes, no int, amount int64) []bot.Quote {
+	return []bot.Quote{
+		{Outcome: true, Side: bot.Buy, Price: yes, Amount: amount},
+		{Outcome: false, Side: bot.Buy, Price: no, Amount: amount},
+	}
+}
+
+func TestCompute(t *testing.T) {
+	// Alice quotes 48/52: a YES bid at 48 and a NO bid at 48.
+	yes := []types.OrderBookEntry{bid(alice, 48, 100)}
+	no := []types.OrderBookEntry{bid(alice, 48, 100)}
+
+	alone, err := Compute(market, yes, no, Input{Wallet: alice})
+	require.NoError(t, err)
+	assert.Equal(t, 50.0, alone.Midpoint)
+	assert.Equal(t, 4, alone.Spread)
+	assert.Equal(t, 25.0, alone.Score) // 100 × (2/4)²
+	assert.Equal(t, 100.0, alone.Percent)
+	require.Len(t, alone.Orders, 2)
+	assert.True(t, alone.Orders[0].Resting)
+
+	// Bob tightens to 49/51 and takes most of the block.
+	e, err := Compute(market, yes, no, Input{Wallet: bob, Quotes: quotes(49, 49, 100)})
+	require.NoError(t, err)
+	assert.Equal(t, 50.0, e.Midpoint)
+	assert.Equal(t, 56.25, e.Score) // 100 × (3/4)²
+	assert.Equal(t, 81.25, e.TotalScore)
+	assert.InDelta(t, 69.23, e.Percent, 0.01)
+	assert.Len(t, e.Orders, 2)
+
+	// One-sided quotes earn nothing.
+	e, err = Compute(market, yes, no, Input{Wallet: bob, Quotes: quotes(49, 49, 100)[:1]})
+	require.NoError(t, err)
+	assert.Equal(t, 50.5, e.Midpoint)
+	assert.Positive(t, e.Orders[0].Score)
+	assert.Zero(t, e.Percent)
+
+	// Replacing Alice's own quotes with wider ones.
+	e, err = Compute(market, yes, no, Input{Wallet: alice, Quotes: quotes(47, 47, 100), ReplaceOrders: true})
+	require.NoError(t, err)
+	assert.Len(t, e.Orders, 2)
+	assert.Equal(t, 100*(1.0/4)*(1.0/4), e.Score)
+}
+
+func TestCompute_Ineligible(t *testing.T) {
+	yes := []types.OrderBookEntry{bid(alice, 48, 100)}
+	no := []types.OrderBookEntry{bid(alice, 48, 100)}
+
+	e, err := Compute(market, yes, no, Input{Wallet: bob, Quotes: []bot.Quote{
+		{Outcome: true, Side: bot.Buy, Price: 49, Amount: 5},
+		{Outcome: false, Side: bot.Sell, Price: 50, Amount: 100},
+		{Outcome: false, Side: bot.Buy, Price: 44, Amount: 100},
+	}})
+	require.NoError(t, err)
+	require.Len(t, e.Orders, 3)
+	assert.Contains(t, e.Orders[0].Reason, "below the minimum order size")
+	assert.Contains(t, e.Orders[1].Reason, "only buy orders")
+	assert.Contains(t, e.Orders[2].Reason, "outside the 4¢ spread")
+	assert.Zero(t, e.Percent)
+
+	// A lopsided market is not sampled.
+	e, err = Compute(market, []types.OrderBookEntry{bid(alice, 84, 100)}, []types.OrderBookEntry{bid(alice, 14, 100)}, Input{Wallet: alice})
+	require.NoError(t, err)
+	assert.Equal(t, 85.0, e.Midpoint)
+	assert.Zero(t, e.Spread)
+	assert.Contains(t, e.Reason, "outside every spread tier")
+	assert.Zero(t, e.Score)
+
+	// MaxSpread caps the tier.
+	e, err = Compute(Market{MaxSpread: 1}, yes, no, Input{Wallet: alice})
+	require.NoError(t, err)
+	assert.Equal(t, 1, e.Spread)
+	assert.Zero(t, e.Score)
+
+	e, err = Compute(market, yes, nil, Input{Wallet: alice})
+	require.NoError(t, err)
+	assert.Contains(t, e.Reason, "no YES bid or no YES ask")
+
+	_, err = Compute(market, yes, no, Input{Wallet: bob, Quotes: quotes(53, 40, 100)})
+	assert.ErrorContains(t, err, "cross the book")
+	_, err = Compute(market, yes, no, Input{Wallet: "bob"})
+	assert.Error(t, err)
+}
+
+func TestEstimator(t *testing.T) {
+	ctx := context.Background()
+	s := sim.New()
+	require.NoError(t, s.Deposit(alice, 100_000))
+	a := s.Trader(alice)
+	_, err := a.CreateMarket(ctx, types.CreateMarketInput{
+		Bridge:          "hoodi_tt2",
+		QueryComponents: bytes.Repeat([]byte{1}, 128),
+		SettleTime:      s.Now() + 3600,
+		MaxSpread:       5,
+		MinOrderSize:    10,
+	})
+	require.NoError(t, err)
+	for _, outcome := range []bool{true, false} {
+		_, err = a.PlaceBuyOrder(ctx, types.PlaceBuyOrderInput{QueryID: 1, Outcome: outcome, Price: 48, Amount: 100})
+		require.NoError(t, err)
+	}
+
+	e, err := New(a).Estimate(ctx, 1, Input{Wallet: bob, Quotes: quotes(49, 49, 100)})
+	require.NoError(t, err)
+	assert.InDelta(t, 69.23, e.Percent, 0.01)
+
+	b, err := bot.New(a, 1, bot.StrategyFunc(func(context.Context, *bot.Snapshot) ([]bot.Quote, error) { return nil, nil }))
+	require.NoError(t, err)
+	snap, err := b.Snapshot(ctx)
+	require.NoError(t, err)
+	e, err = FromSnapshot(market, snap, Input{Wallet: alice})
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, e.Percent)
+}
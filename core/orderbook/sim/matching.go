@@ -0,0 +1,267 @@
+package sim
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+// FillKind says how a fill moved shares and collateral.
+type FillKind int
+
+const (
+	// FillDirect: a buy matched a sell of the same outcome; shares change
+	// hands and the seller receives the price.
+	FillDirect FillKind = iota
+	// FillMint: a YES buy matched a NO buy whose prices sum to at least
+	// 100; a new share pair is minted and $1.00 per pair enters the vault.
+	FillMint
+	// FillBurn: a YES sell matched a NO sell whose prices sum to at most
+	// 100; the pair is burned and $1.00 per pair leaves the vault.
+	FillBurn
+)
+
+func (k FillKind) String() string {
+	switch k {
+	case FillDirect:
+		return "direct"
+	case FillMint:
+		return "mint"
+	default:
+		return "burn"
+	}
+}
+
+// Fill is one match between an incoming order and a resting one.
+type Fill struct {
+	QueryID int
+	Time    int64
+	Kind    FillKind
+	Outcome bool  // outcome of the incoming order
+	Price   int   // price per share the incoming order traded at, in cents
+	Amount  int64 // shares (pairs for mint and burn)
+	Taker   string
+	Maker   string
+}
+
+// order is a row of ob_positions: a holding (price 0), a bid (price < 0) or
+// an ask (price > 0).
+type order struct {
+	owner       *account
+	outcome     bool
+	price       int
+	amount      int64
+	lastUpdated int64
+	seq         uint64
+}
+
+type market struct {
+	info      types.MarketInfo
+	positions []*order
+	vault     int64 // collateral held for the market, in cents
+	resolved  *bool
+}
+
+func (m *market) find(owner *account, outcome bool, price int) *order {
+	for _, o := range m.positions {
+		if o.owner == owner && o.outcome == outcome && o.price == price {
+			return o
+		}
+	}
+	return nil
+}
+
+// add merges amount into the owner's position at price. An order that
+// already rests there keeps its place in the queue.
+func (s *Simulator) add(m *market, owner *account, outcome bool, price int, amount int64, lastUpdated int64) {
+	if amount <= 0 {
+		return
+	}
+	if o := m.find(owner, outcome, price); o != nil {
+		o.amount += amount
+		return
+	}
+	s.seq++
+	m.positions = append(m.positions, &order{
+		owner: owner, outcome: outcome, price: price, amount: amount,
+		lastUpdated: lastUpdated, seq: s.seq,
+	})
+}
+
+func (m *market) remove(o *order) {
+	for i, p := range m.positions {
+		if p == o {
+			m.positions = append(m.positions[:i], m.positions[i+1:]...)
+			return
+		}
+	}
+}
+
+func (m *market) holding(owner *account, outcome bool) int64 {
+	if o := m.find(owner, outcome, 0); o != nil {
+		return o.amount
+	}
+	return 0
+}
+
+// takeHolding moves amount shares out of the owner's holdings.
+func (m *market) takeHolding(owner *account, outcome bool, amount int64) error {
+	o := m.find(owner, outcome, 0)
+	if o == nil || o.amount < amount {
+		return fmt.Errorf("insufficient shares: have %d, need %d", m.holding(owner, outcome), amount)
+	}
+	o.amount -= amount
+	if o.amount == 0 {
+		m.remove(o)
+	}
+	return nil
+}
+
+// candidate is a resting order an incoming order can trade with, at the
+// price the incoming order would pay or receive.
+type candidate struct {
+	o     *order
+	kind  FillKind
+	price int
+}
+
+// buy matches an incoming bid of amount shares at price, whose collateral
+// (price × amount) is already locked in the vault, and rests the remainder.
+func (s *Simulator) buy(m *market, taker *account, outcome bool, price int, amount int64, lastUpdated int64) {
+	var cands []candidate
+	for _, o := range m.positions {
+		if o.owner == taker {
+			continue
+		}
+		switch {
+		case o.outcome == outcome && o.price > 0 && o.price <= price:
+			cands = append(cands, candidate{o, FillDirect, o.price})
+		case o.outcome != outcome && o.price < 0 && -o.price >= 100-price:
+			cands = append(cands, candidate{o, FillMint, 100 + o.price})
+		}
+	}
+	sortCandidates(cands, true)
+
+	for _, c := range cands {
+		if amount == 0 {
+			break
+		}
+		n := min(amount, c.o.amount)
+		amount -= n
+		c.o.amount -= n
+
+		// refund what the taker locked above the trade price
+		taker.balance += int64(price-c.price) * n
+		m.vault -= int64(price-c.price) * n
+
+		switch c.kind {
+		case FillDirect:
+			c.o.owner.balance += int64(c.price) * n
+			m.vault -= int64(c.price) * n
+		case FillMint:
+			// the maker's locked bid and the taker's payment make up $1.00 per pair
+			s.add(m, c.o.owner, c.o.outcome, 0, n, s.now)
+		}
+		s.add(m, taker, outcome, 0, n, s.now)
+		s.record(m, c.kind, outcome, c.price, n, taker, c.o.owner)
+		if c.o.amount == 0 {
+			m.remove(c.o)
+		}
+	}
+	s.add(m, taker, outcome, -price, amount, lastUpdated)
+}
+
+// sell matches an incoming ask of amount shares at price, already taken
+// from the seller's holdings, and rests the remainder.
+func (s *Simulator) sell(m *market, taker *account, outcome bool, price int, amount int64, lastUpdated int64) {
+	var cands []candidate
+	for _, o := range m.positions {
+		if o.owner == taker {
+			continue
+		}
+		switch {
+		case o.outcome == outcome && o.price < 0 && -o.price >= price:
+			cands = append(cands, candidate{o, FillDirect, -o.price})
+		case o.outcome != outcome && o.price > 0 && o.price <= 100-price:
+			cands = append(cands, candidate{o, FillBurn, 100 - o.price})
+		}
+	}
+	sortCandidates(cands, false)
+
+	for _, c := range cands {
+		if amount == 0 {
+			break
+		}
+		n := min(amount, c.o.amount)
+		amount -= n
+		c.o.amount -= n
+
+		taker.balance += int64(c.price) * n
+		m.vault -= int64(c.price) * n
+		switch c.kind {
+		case FillDirect:
+			// the maker's locked bid pays the seller
+			s.add(m, c.o.owner, outcome, 0, n, s.now)
+		case FillBurn:
+			c.o.owner.balance += int64(c.o.price) * n
+			m.vault -= int64(c.o.price) * n
+		}
+		s.record(m, c.kind, outcome, c.price, n, taker, c.o.owner)
+		if c.o.amount == 0 {
+			m.remove(c.o)
+		}
+	}
+	s.add(m, taker, outcome, price, amount, lastUpdated)
+}
+
+// sortCandidates orders candidates best price first for the incoming order
+// (lowest for a buy, highest for a sell), then FIFO.
+func sortCandidates(cands []candidate, buying bool) {
+	sort.SliceStable(cands, func(i, j int) bool {
+		a, b := cands[i], cands[j]
+		if a.price != b.price {
+			if buying {
+				return a.price < b.price
+			}
+			return a.price > b.price
+		}
+		if a.o.lastUpdated != b.o.lastUpdated {
+			return a.o.lastUpdated < b.o.lastUpdated
+		}
+		return a.o.seq < b.o.seq
+	})
+}
+
+func (s *Simulator) record(m *market, kind FillKind, outcome bool, price int, amount int64, taker, maker *account) {
+	s.fills = append(s.fills, Fill{
+		QueryID: m.info.ID,
+		Time:    s.now,
+		Kind:    kind,
+		Outcome: outcome,
+		Price:   price,
+		Amount:  amount,
+		Taker:   taker.wallet.Address(),
+		Maker:   maker.wallet.Address(),
+	})
+}
+
+// settle pays out a market: winning shares, held or listed, are worth $1.00
+// each, losing shares nothing, and open bids are refunded.
+func (s *Simulator) settle(m *market, winner bool) {
+	for _, o := range m.positions {
+		switch {
+		case o.price < 0:
+			o.owner.balance += int64(-o.price) * o.amount
+			m.vault -= int64(-o.price) * o.amount
+		case o.outcome == winner:
+			o.owner.balance += 100 * o.amount
+			m.vault -= 100 * o.amount
+		}
+	}
+	m.positions = nil
+	m.info.Settled = true
+	m.info.WinningOutcome = &winner
+	settledAt := s.now
+	m.info.SettledAt = &settledAt
+}
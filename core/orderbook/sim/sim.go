@@ -0,0 +1,323 @@
+// Package sim is an in-process order book for testing and backtesting
+// prediction-market strategies without a node.
+//
+// A Simulator holds markets, wallets and a clock. Trader returns a
+// types.IOrderBook acting as one wallet, so code written against the SDK's
+// order book (including the bot package) runs unchanged:
+//
+//	s := sim.New(sim.WithTime(start))
+//	alice := s.Trader("0x1111111111111111111111111111111111111111")
+//	s.Deposit(alice.Wallet(), 10_000) // $100.00
+//	_, _ = alice.CreateMarket(ctx, types.CreateMarketInput{...})
+//	_, _ = alice.PlaceBuyOrder(ctx, types.PlaceBuyOrderInput{QueryID: 1, Outcome: true, Price: 55, Amount: 10})
+//
+// It follows the node's order book semantics:
+//
+//   - prices are signed: holdings are stored at 0, bids at -price and asks at
+//     +price, one row per wallet, outcome and price;
+//   - an incoming bid trades with asks of its outcome and, through minting,
+//     with bids on the other outcome whose price is at least 100 minus its
+//     own; an incoming ask trades with bids of its outcome and, through
+//     burning, with asks on the other outcome priced at most 100 minus its
+//     own. Trades happen at the resting order's price, best price first,
+//     then FIFO by LastUpdated;
+//   - bids lock price × amount of collateral, PlaceSplitLimitOrder mints pairs
+//     for $1.00 each, and settlement pays $1.00 per winning share, held or
+//     listed, and refunds open bids.
+//
+// Amounts of collateral are counted in cents and reported through
+// GetUserCollateral and ValidateMarketCollateral in the bridge token's
+// smallest unit (see WithCollateralUnit). Trading and settlement fees and LP
+// reward sampling are not simulated.
+package sim
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/trufnetwork/sdk-go/core/types"
+	"github.com/trufnetwork/sdk-go/core/util"
+)
+
+// ErrNotSimulated is returned by the order book actions the simulator does not
+// model.
+var ErrNotSimulated = errors.New("not simulated")
+
+// Simulator is an in-memory order book exchange. It is safe for concurrent
+// use; every action runs atomically.
+type Simulator struct {
+	mu             sync.Mutex
+	now            int64
+	seq            uint64
+	txCount        uint64
+	markets        []*market
+	accounts       map[string]*account
+	fills          []Fill
+	collateralUnit *big.Int
+}
+
+type account struct {
+	wallet  util.EthereumAddress
+	id      int
+	balance int64 // free collateral in cents; replay accounts may go negative
+	replay  bool
+}
+
+// Option configures a Simulator.
+type Option func(*Simulator)
+
+// WithTime sets the starting clock, in Unix seconds. Default: 0.
+func WithTime(unix int64) Option {
+	return func(s *Simulator) {
+		s.now = unix
+	}
+}
+
+// WithCollateralUnit sets the size of one cent of collateral in the bridge
+// token's smallest unit. Default: 10^16, a cent of an 18-decimal token.
+func WithCollateralUnit(unit *big.Int) Option {
+	return func(s *Simulator) {
+		if unit != nil && unit.Sign() > 0 {
+			s.collateralUnit = new(big.Int).Set(unit)
+		}
+	}
+}
+
+// New returns an empty simulator.
+func New(opts ...Option) *Simulator {
+	s := &Simulator{
+		accounts:       make(map[string]*account),
+		collateralUnit: new(big.Int).Exp(big.NewInt(10), big.NewInt(16), nil),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Now returns the simulator clock, in Unix seconds.
+func (s *Simulator) Now() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now
+}
+
+// SetTime moves the clock. It cannot go backwards.
+func (s *Simulator) SetTime(unix int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if unix < s.now {
+		return errors.Errorf("time cannot go backwards: %d < %d", unix, s.now)
+	}
+	s.now = unix
+	return nil
+}
+
+// Deposit credits free collateral, in cents, to a wallet.
+func (s *Simulator) Deposit(wallet string, cents int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	acct, err := s.account(wallet)
+	if err != nil {
+		return err
+	}
+	acct.balance += cents
+	return nil
+}
+
+// Balance returns a wallet's free collateral, in cents.
+func (s *Simulator) Balance(wallet string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	acct, err := s.account(wallet)
+	if err != nil {
+		return 0, err
+	}
+	return acct.balance, nil
+}
+
+// Resolve sets the outcome SettleMarket will settle a market to, standing in
+// for the attestation the node reads.
+func (s *Simulator) Resolve(queryID int, outcome bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.market(queryID)
+	if err != nil {
+		return err
+	}
+	m.resolved = &outcome
+	return nil
+}
+
+// Fills returns every fill so far, in order.
+func (s *Simulator) Fills() []Fill {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Fill(nil), s.fills...)
+}
+
+// Trader returns an order book acting as wallet, a 0x-prefixed address. It
+// panics if wallet is not a valid address.
+func (s *Simulator) Trader(wallet string) *Trader {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	acct, err := s.account(wallet)
+	if err != nil {
+		panic(err)
+	}
+	return &Trader{sim: s, acct: acct}
+}
+
+// account returns the account of wallet, creating it on first use.
+func (s *Simulator) account(wallet string) (*account, error) {
+	addr, err := util.NewEthereumAddressFromString(wallet)
+	if err != nil {
+		return nil, errors.Wrapf(err, "wallet %q", wallet)
+	}
+	key := addr.Address()
+	if acct, ok := s.accounts[key]; ok {
+		return acct, nil
+	}
+	acct := &account{wallet: addr, id: len(s.accounts) + 1}
+	s.accounts[key] = acct
+	return acct, nil
+}
+
+func (s *Simulator) market(queryID int) (*market, error) {
+	if queryID < 1 || queryID > len(s.markets) {
+		return nil, fmt.Errorf("market not found: query_id=%d", queryID)
+	}
+	return s.markets[queryID-1], nil
+}
+
+func (s *Simulator) openMarket(queryID int) (*market, error) {
+	m, err := s.market(queryID)
+	if err != nil {
+		return nil, err
+	}
+	if m.info.Settled {
+		return nil, fmt.Errorf("market %d is already settled", queryID)
+	}
+	return m, nil
+}
+
+func (s *Simulator) cents(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), s.collateralUnit)
+}
+
+func marketHash(queryComponents []byte) []byte {
+	h := sha256.Sum256(queryComponents)
+	return h[:]
+}
+
+// Frame is a historical state of a market's order book, as recorded from
+// GetOrderBook.
+type Frame struct {
+	Time    int64
+	QueryID int
+	Books   map[bool][]types.OrderBookEntry // keyed by outcome
+}
+
+// Replay steps through frames in order. For each frame it moves the clock to
+// Frame.Time, replaces the resting orders of the recorded participants with
+// the frame's book, and calls step. Recorded orders trade with the orders
+// simulated wallets have resting, exactly as if they had just been placed,
+// so a strategy run from step is backtested against the recorded market.
+//
+// Recorded participants trade from ReplayWallet addresses with unlimited
+// collateral, minting the shares their asks need. Their orders keep the
+// recorded LastUpdated, so queue priority follows the recording.
+func (s *Simulator) Replay(ctx context.Context, frames []Frame, step func(ctx context.Context, frame Frame) error) error {
+	for i, frame := range frames {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.applyFrame(frame); err != nil {
+			return errors.Wrapf(err, "frame %d", i)
+		}
+		if step != nil {
+			if err := step(ctx, frame); err != nil {
+				return errors.Wrapf(err, "frame %d", i)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Simulator) applyFrame(frame Frame) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if frame.Time < s.now {
+		return errors.Errorf("time cannot go backwards: %d < %d", frame.Time, s.now)
+	}
+	s.now = frame.Time
+	m, err := s.openMarket(frame.QueryID)
+	if err != nil {
+		return err
+	}
+
+	// pull the previous frame's orders
+	for _, o := range append([]*order(nil), m.positions...) {
+		if o.owner.replay && o.price != 0 {
+			s.cancel(m, o)
+		}
+	}
+
+	for _, outcome := range []bool{true, false} {
+		entries := append([]types.OrderBookEntry(nil), frame.Books[outcome]...)
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].LastUpdated < entries[j].LastUpdated })
+		for _, e := range entries {
+			if e.Price == 0 || e.Price < -99 || e.Price > 99 || e.Amount <= 0 {
+				return errors.Errorf("invalid recorded order: price=%d amount=%d", e.Price, e.Amount)
+			}
+			acct := s.replayAccount(e.ParticipantID)
+			if e.Price < 0 {
+				acct.balance -= int64(-e.Price) * e.Amount
+				m.vault += int64(-e.Price) * e.Amount
+				s.buy(m, acct, outcome, -e.Price, e.Amount, e.LastUpdated)
+				continue
+			}
+			if short := e.Amount - m.holding(acct, outcome); short > 0 {
+				acct.balance -= 100 * short
+				m.vault += 100 * short
+				s.add(m, acct, true, 0, short, s.now)
+				s.add(m, acct, false, 0, short, s.now)
+			}
+			if err := m.takeHolding(acct, outcome, e.Amount); err != nil {
+				return err
+			}
+			s.sell(m, acct, outcome, e.Price, e.Amount, e.LastUpdated)
+		}
+	}
+	return nil
+}
+
+// ReplayWallet returns the address Replay trades a recorded participant's
+// orders from.
+func ReplayWallet(participantID int) string {
+	return fmt.Sprintf("0x%040x", 0xfeed0000_0000+participantID)
+}
+
+func (s *Simulator) replayAccount(participantID int) *account {
+	acct, _ := s.account(ReplayWallet(participantID))
+	acct.replay = true
+	return acct
+}
+
+// cancel removes a resting order, refunding a bid's collateral or returning
+// an ask's shares to holdings.
+func (s *Simulator) cancel(m *market, o *order) {
+	m.remove(o)
+	if o.price < 0 {
+		o.owner.balance += int64(-o.price) * o.amount
+		m.vault -= int64(-o.price) * o.amount
+		return
+	}
+	s.add(m, o.owner, o.outcome, 0, o.amount, s.now)
+}
@@ -0,0 +1,304 @@
+package sim
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trufnetwork/sdk-go/core/orderbook/bot"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+const (
+	start = int64(1_700_000_000)
+	alice = "0x1111111111111111111111111111111111111111"
+	bob   = "0x2222222222222222222222222222222222222222"
+)
+
+// newMarket returns a simulator with one market (ID 1) settling an hour
+// after start and $1,000 deposited for alice and bob.
+func newMarket(t *testing.T) (*Simulator, *Trader, *Trader) {
+	t.Helper()
+	s := New(WithTime(start))
+	a, b := s.Trader(alice), s.Trader(bob)
+	require.NoError(t, s.Deposit(alice, 100_000))
+	require.NoError(t, s.Deposit(bob, 100_000))
+	_, err := a.CreateMarket(context.Background(), types.CreateMarketInput{
+		Bridge:          "hoodi_tt2",
+		QueryComponents: bytes.Repeat([]byte{1}, 128),
+		SettleTime:      start + 3600,
+		MaxSpread:       5,
+		MinOrderSize:    1,
+	})
+	require.NoError(t, err)
+	return s, a, b
+}
+
+func assertBalance(t *testing.T, s *Simulator, wallet string, want int64) {
+	t.Helper()
+	got, err := s.Balance(wallet)
+	require.NoError(t, err)
+	assert.Equal(t, want, got, "balance of %s", wallet)
+}
+
+func assertCollateralValid(t *testing.T, ob types.IOrderBook) {
+	t.Helper()
+	v, err := ob.ValidateMarketCollateral(context.Background(), types.ValidateMarketCollateralInput{QueryID: 1})
+	require.NoError(t, err)
+	assert.True(t, v.ValidTokenBinaries, "token binaries: %+v", v)
+	assert.True(t, v.ValidCollateral, "collateral: %+v", v)
+}
+
+func positions(t *testing.T, ob types.IOrderBook) []types.UserPosition {
+	t.Helper()
+	p, err := ob.GetUserPositions(context.Background())
+	require.NoError(t, err)
+	return p
+}
+
+func TestSimulator_MintThenDirectMatch(t *testing.T) {
+	ctx := context.Background()
+	s, a, b := newMarket(t)
+
+	// A YES bid at 60 and a NO bid at 45 cross: a pair is minted, YES at
+	// 55 and NO at the resting 45.
+	_, err := a.PlaceBuyOrder(ctx, types.PlaceBuyOrderInput{QueryID: 1, Outcome: false, Price: 45, Amount: 10})
+	require.NoError(t, err)
+	_, err = b.PlaceBuyOrder(ctx, types.PlaceBuyOrderInput{QueryID: 1, Outcome: true, Price: 60, Amount: 10})
+	require.NoError(t, err)
+
+	assert.Equal(t, []Fill{{QueryID: 1, Time: start, Kind: FillMint, Outcome: true, Price: 55, Amount: 10, Taker: bob, Maker: alice}}, s.Fills())
+	assertBalance(t, s, alice, 100_000-450)
+	assertBalance(t, s, bob, 100_000-550)
+	assert.Equal(t, []types.UserPosition{{QueryID: 1, Outcome: false, Amount: 10, PositionType: "holding"}}, positions(t, a))
+	assert.Equal(t, []types.UserPosition{{QueryID: 1, Outcome: true, Amount: 10, PositionType: "holding"}}, positions(t, b))
+	assertCollateralValid(t, a)
+
+	// Bob lists YES at 70; Alice's bid at 75 trades at the resting 70 and the
+	// extra 5 cents per share are refunded.
+	_, err = b.PlaceSellOrder(ctx, types.PlaceSellOrderInput{QueryID: 1, Outcome: true, Price: 70, Amount: 10})
+	require.NoError(t, err)
+	_, err = a.PlaceBuyOrder(ctx, types.PlaceBuyOrderInput{QueryID: 1, Outcome: true, Price: 75, Amount: 4})
+	require.NoError(t, err)
+
+	assert.Equal(t, Fill{QueryID: 1, Time: start, Kind: FillDirect, Outcome: true, Price: 70, Amount: 4, Taker: alice, Maker: bob}, s.Fills()[1])
+	assertBalance(t, s, alice, 100_000-450-280)
+	assertBalance(t, s, bob, 100_000-550+280)
+	assert.Equal(t, []types.UserPosition{
+		{QueryID: 1, Outcome: true, Amount: 4, PositionType: "holding"},
+		{QueryID: 1, Outcome: false, Amount: 10, PositionType: "holding"},
+	}, positions(t, a))
+
+	book, err := a.GetOrderBook(ctx, types.GetOrderBookInput{QueryID: 1, Outcome: true})
+	require.NoError(t, err)
+	require.Len(t, book, 1)
+	assert.Equal(t, 70, book[0].Price)
+	assert.Equal(t, int64(6), book[0].Amount)
+	assertCollateralValid(t, a)
+}
+
+func TestSimulator_Burn(t *testing.T) {
+	ctx := context.Background()
+	s, a, b := newMarket(t)
+
+	// Alice splits 10 pairs, sells the NO shares to Bob at 40 and lists the
+	// YES shares at 10.
+	_, err := a.PlaceSplitLimitOrder(ctx, types.PlaceSplitLimitOrderInput{QueryID: 1, TruePrice: 60, Amount: 10})
+	require.NoError(t, err)
+	_, err = b.PlaceBuyOrder(ctx, types.PlaceBuyOrderInput{QueryID: 1, Outcome: false, Price: 40, Amount: 10})
+	require.NoError(t, err)
+	_, err = a.PlaceSellOrder(ctx, types.PlaceSellOrderInput{QueryID: 1, Outcome: true, Price: 10, Amount: 10})
+	require.NoError(t, err)
+
+	// Bob's NO ask at 85 meets the YES ask at 10: the pairs are burned, Bob
+	// receives 90 and Alice 10 per pair.
+	_, err = b.PlaceSellOrder(ctx, types.PlaceSellOrderInput{QueryID: 1, Outcome: false, Price: 85, Amount: 10})
+	require.NoError(t, err)
+
+	fills := s.Fills()
+	assert.Equal(t, Fill{QueryID: 1, Time: start, Kind: FillBurn, Outcome: false, Price: 90, Amount: 10, Taker: bob, Maker: alice}, fills[len(fills)-1])
+	// Alice: -1000 split, +400 NO sale, +100 burn. Bob: -400 bid, +900 burn.
+	assertBalance(t, s, alice, 100_000-1000+400+100)
+	assertBalance(t, s, bob, 100_000-400+900)
+	assert.Empty(t, positions(t, a))
+	assert.Empty(t, positions(t, b))
+
+	v, err := a.ValidateMarketCollateral(ctx, types.ValidateMarketCollateralInput{QueryID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "0", v.VaultBalance)
+	assert.True(t, v.ValidCollateral)
+}
+
+func TestSimulator_FIFO(t *testing.T) {
+	ctx := context.Background()
+	s, a, b := newMarket(t)
+	carol := s.Trader("0x3333333333333333333333333333333333333333")
+	require.NoError(t, s.Deposit(carol.Wallet(), 100_000))
+
+	_, err := a.PlaceBuyOrder(ctx, types.PlaceBuyOrderInput{QueryID: 1, Outcome: true, Price: 50, Amount: 5})
+	require.NoError(t, err)
+	require.NoError(t, s.SetTime(start+10))
+	_, err = b.PlaceBuyOrder(ctx, types.PlaceBuyOrderInput{QueryID: 1, Outcome: true, Price: 50, Amount: 5})
+	require.NoError(t, err)
+
+	// Changing the amount keeps Alice at the front of the queue.
+	require.NoError(t, s.SetTime(start+20))
+	_, err = a.ChangeBid(ctx, types.ChangeBidInput{QueryID: 1, Outcome: true, OldPrice: -50, NewPrice: -49, NewAmount: 5})
+	require.NoError(t, err)
+	_, err = a.ChangeBid(ctx, types.ChangeBidInput{QueryID: 1, Outcome: true, OldPrice: -49, NewPrice: -50, NewAmount: 8})
+	require.NoError(t, err)
+	assertBalance(t, s, alice, 100_000-400)
+
+	_, err = carol.PlaceSplitLimitOrder(ctx, types.PlaceSplitLimitOrderInput{QueryID: 1, TruePrice: 50, Amount: 10})
+	require.NoError(t, err)
+	_, err = carol.PlaceSellOrder(ctx, types.PlaceSellOrderInput{QueryID: 1, Outcome: true, Price: 50, Amount: 10})
+	require.NoError(t, err)
+
+	fills := s.Fills()
+	require.Len(t, fills, 2)
+	assert.Equal(t, alice, fills[0].Maker)
+	assert.Equal(t, int64(8), fills[0].Amount)
+	assert.Equal(t, bob, fills[1].Maker)
+	assert.Equal(t, int64(2), fills[1].Amount)
+
+	best, err := a.GetBestPrices(ctx, types.GetBestPricesInput{QueryID: 1, Outcome: true})
+	require.NoError(t, err)
+	require.NotNil(t, best.BestBid)
+	assert.Equal(t, 50, *best.BestBid)
+	assert.Nil(t, best.BestAsk)
+	assertCollateralValid(t, a)
+}
+
+func TestSimulator_ChangeAsk(t *testing.T) {
+	ctx := context.Background()
+	s, a, _ := newMarket(t)
+
+	_, err := a.PlaceSplitLimitOrder(ctx, types.PlaceSplitLimitOrderInput{QueryID: 1, TruePrice: 55, Amount: 10})
+	require.NoError(t, err)
+	_, err = a.ChangeAsk(ctx, types.ChangeAskInput{QueryID: 1, Outcome: false, OldPrice: 45, NewPrice: 48, NewAmount: 4})
+	require.NoError(t, err)
+	_, err = a.ChangeAsk(ctx, types.ChangeAskInput{QueryID: 1, Outcome: false, OldPrice: 48, NewPrice: 47, NewAmount: 11})
+	assert.ErrorContains(t, err, "insufficient shares")
+
+	assert.Equal(t, []types.UserPosition{
+		{QueryID: 1, Outcome: true, Amount: 10, PositionType: "holding"},
+		{QueryID: 1, Outcome: false, Amount: 6, PositionType: "holding"},
+		{QueryID: 1, Outcome: false, Price: 48, Amount: 4, PositionType: "sell_order"},
+	}, positions(t, a))
+
+	collateral, err := a.GetUserCollateral(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "0", collateral.BuyOrdersLocked)
+	assert.Equal(t, "20000000000000000000", collateral.SharesValue)
+	assertBalance(t, s, alice, 100_000-1000)
+	assertCollateralValid(t, a)
+}
+
+func TestSimulator_Errors(t *testing.T) {
+	ctx := context.Background()
+	s, a, _ := newMarket(t)
+
+	_, err := a.PlaceBuyOrder(ctx, types.PlaceBuyOrderInput{QueryID: 1, Outcome: true, Price: 50, Amount: 2001})
+	assert.ErrorContains(t, err, "insufficient balance")
+	_, err = a.PlaceSellOrder(ctx, types.PlaceSellOrderInput{QueryID: 1, Outcome: true, Price: 50, Amount: 1})
+	assert.ErrorContains(t, err, "insufficient shares")
+	_, err = a.CancelOrder(ctx, types.CancelOrderInput{QueryID: 1, Outcome: true, Price: -50})
+	assert.ErrorContains(t, err, "order not found")
+	_, err = a.PlaceBuyOrder(ctx, types.PlaceBuyOrderInput{QueryID: 2, Outcome: true, Price: 50, Amount: 1})
+	assert.ErrorContains(t, err, "market not found")
+	_, err = a.SampleLPRewards(ctx, types.SampleLPRewardsInput{QueryID: 1, Block: 1})
+	assert.ErrorIs(t, err, ErrNotSimulated)
+
+	_, err = a.SettleMarket(ctx, types.SettleMarketInput{QueryID: 1})
+	assert.ErrorContains(t, err, "settle_time not reached")
+	require.NoError(t, s.SetTime(start+3600))
+	_, err = a.SettleMarket(ctx, types.SettleMarketInput{QueryID: 1})
+	assert.ErrorContains(t, err, "no signed attestation")
+	assert.Error(t, s.SetTime(start))
+}
+
+func TestSimulator_Settlement(t *testing.T) {
+	ctx := context.Background()
+	s, a, b := newMarket(t)
+
+	_, err := a.PlaceSplitLimitOrder(ctx, types.PlaceSplitLimitOrderInput{QueryID: 1, TruePrice: 60, Amount: 10})
+	require.NoError(t, err)
+	_, err = b.PlaceBuyOrder(ctx, types.PlaceBuyOrderInput{QueryID: 1, Outcome: false, Price: 40, Amount: 6})
+	require.NoError(t, err)
+	_, err = b.PlaceBuyOrder(ctx, types.PlaceBuyOrderInput{QueryID: 1, Outcome: true, Price: 30, Amount: 5})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Resolve(1, false))
+	require.NoError(t, s.SetTime(start+3600))
+	_, err = b.SettleMarket(ctx, types.SettleMarketInput{QueryID: 1})
+	require.NoError(t, err)
+
+	// Alice: -1000 split, +240 sale, +400 for her 4 listed NO shares.
+	// Bob: -240 for 6 NO shares, +600 payout; his open bid is refunded.
+	assertBalance(t, s, alice, 100_000-1000+240+400)
+	assertBalance(t, s, bob, 100_000-240+600)
+	assert.Empty(t, positions(t, a))
+
+	info, err := a.GetMarketInfo(ctx, types.GetMarketInfoInput{QueryID: 1})
+	require.NoError(t, err)
+	assert.True(t, info.Settled)
+	require.NotNil(t, info.WinningOutcome)
+	assert.False(t, *info.WinningOutcome)
+	v, err := a.ValidateMarketCollateral(ctx, types.ValidateMarketCollateralInput{QueryID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "0", v.VaultBalance)
+
+	settled := true
+	markets, err := a.ListMarkets(ctx, types.ListMarketsInput{SettledFilter: &settled})
+	require.NoError(t, err)
+	assert.Len(t, markets, 1)
+	_, err = a.PlaceBuyOrder(ctx, types.PlaceBuyOrderInput{QueryID: 1, Outcome: true, Price: 50, Amount: 1})
+	assert.ErrorContains(t, err, "already settled")
+}
+
+func TestSimulator_ReplayBot(t *testing.T) {
+	ctx := context.Background()
+	s, a, _ := newMarket(t)
+
+	// Quote one cent inside the recorded YES spread.
+	strategy := bot.StrategyFunc(func(_ context.Context, snap *bot.Snapshot) ([]bot.Quote, error) {
+		best := snap.Books[true].Best
+		if best.BestBid == nil {
+			return nil, nil
+		}
+		return []bot.Quote{{Outcome: true, Side: bot.Buy, Price: *best.BestBid + 1, Amount: 10}}, nil
+	})
+	b, err := bot.New(a, 1, strategy, bot.WithOutcomes(true))
+	require.NoError(t, err)
+
+	frames := []Frame{
+		{Time: start + 60, QueryID: 1, Books: map[bool][]types.OrderBookEntry{
+			true: {{ParticipantID: 7, Price: -40, Amount: 50, LastUpdated: start + 30}, {ParticipantID: 8, Price: 45, Amount: 20, LastUpdated: start + 30}},
+		}},
+		// The recorded ask drops to 41 and trades with the bot's bid at 41.
+		{Time: start + 120, QueryID: 1, Books: map[bool][]types.OrderBookEntry{
+			true: {{ParticipantID: 7, Price: -40, Amount: 50, LastUpdated: start + 30}, {ParticipantID: 8, Price: 41, Amount: 10, LastUpdated: start + 90}},
+		}},
+	}
+	var steps int
+	err = s.Replay(ctx, frames, func(ctx context.Context, _ Frame) error {
+		steps++
+		_, err := b.Step(ctx)
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, steps)
+
+	fills := s.Fills()
+	require.Len(t, fills, 1)
+	assert.Equal(t, Fill{QueryID: 1, Time: start + 120, Kind: FillDirect, Outcome: true, Price: 41, Amount: 10,
+		Taker: ReplayWallet(8), Maker: alice}, fills[0])
+	assertBalance(t, s, alice, 100_000-820)
+	assert.Equal(t, []types.UserPosition{
+		{QueryID: 1, Outcome: true, Price: -41, Amount: 10, PositionType: "buy_order"},
+		{QueryID: 1, Outcome: true, Amount: 10, PositionType: "holding"},
+	}, positions(t, a), "the bot re-quotes after its bid is filled")
+	assertCollateralValid(t, a)
+}
@@ -0,0 +1,674 @@
+package sim
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+
+	"github.com/pkg/errors"
+	kwilClientType "github.com/trufnetwork/kwil-db/core/client/types"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+// Trader is the order book as seen by one wallet of a Simulator. Inputs are
+// validated like the SDK does before broadcasting; transaction options are
+// ignored and every write returns a synthetic transaction hash.
+type Trader struct {
+	sim  *Simulator
+	acct *account
+}
+
+var _ types.IOrderBook = (*Trader)(nil)
+
+// Wallet returns the trader's 0x-prefixed address.
+func (t *Trader) Wallet() string {
+	return t.acct.wallet.Address()
+}
+
+// ═══════════════════════════════════════════════════════════════
+// MARKET OPERATIONS
+// ═══════════════════════════════════════════════════════════════
+
+// CreateMarket lists a market. Its hash is the SHA-256 of QueryComponents
+// and SettleTime must be after the simulator clock.
+func (t *Trader) CreateMarket(_ context.Context, input types.CreateMarketInput,
+	_ ...kwilClientType.TxOpt) (kwiltypes.Hash, error) {
+	// Validate compares SettleTime with the wall clock; the simulator checks
+	// it against its own clock so historical markets can be backtested.
+	check := input
+	check.SettleTime = math.MaxInt64
+	if err := check.Validate(); err != nil {
+		return kwiltypes.Hash{}, errors.WithStack(err)
+	}
+
+	s := t.sim
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if input.SettleTime <= s.now {
+		return kwiltypes.Hash{}, fmt.Errorf("settle_time must be after the simulator time, got %d (now: %d)", input.SettleTime, s.now)
+	}
+	hash := marketHash(input.QueryComponents)
+	for _, m := range s.markets {
+		if bytes.Equal(m.info.Hash, hash) {
+			return kwiltypes.Hash{}, fmt.Errorf("market already exists: query_id=%d", m.info.ID)
+		}
+	}
+	s.markets = append(s.markets, &market{info: types.MarketInfo{
+		ID:              len(s.markets) + 1,
+		Hash:            hash,
+		QueryComponents: append([]byte(nil), input.QueryComponents...),
+		Bridge:          input.Bridge,
+		SettleTime:      input.SettleTime,
+		MaxSpread:       input.MaxSpread,
+		MinOrderSize:    input.MinOrderSize,
+		CreatedAt:       s.now,
+		Creator:         t.acct.wallet.Bytes(),
+	}})
+	return t.txHash(), nil
+}
+
+// GetMarketInfo returns a market by ID.
+func (t *Trader) GetMarketInfo(_ context.Context, input types.GetMarketInfoInput) (*types.MarketInfo, error) {
+	if err := input.Validate(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	s := t.sim
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.market(input.QueryID)
+	if err != nil {
+		return nil, err
+	}
+	return copyInfo(m.info), nil
+}
+
+// GetMarketByHash returns a market by query hash, without QueryComponents
+// and Bridge, like get_market_by_hash.
+func (t *Trader) GetMarketByHash(_ context.Context, input types.GetMarketByHashInput) (*types.MarketInfo, error) {
+	if err := input.Validate(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	s := t.sim
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, m := range s.markets {
+		if bytes.Equal(m.info.Hash, input.QueryHash) {
+			info := copyInfo(m.info)
+			info.Hash, info.QueryComponents, info.Bridge = nil, nil, ""
+			return info, nil
+		}
+	}
+	return nil, fmt.Errorf("market not found for given hash")
+}
+
+// ListMarkets lists markets by ID.
+func (t *Trader) ListMarkets(_ context.Context, input types.ListMarketsInput) ([]types.MarketSummary, error) {
+	if err := input.Validate(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	limit, offset := 100, 0
+	if input.Limit != nil {
+		limit = *input.Limit
+	}
+	if input.Offset != nil {
+		offset = *input.Offset
+	}
+
+	s := t.sim
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []types.MarketSummary
+	for _, m := range s.markets {
+		if input.SettledFilter != nil && m.info.Settled != *input.SettledFilter {
+			continue
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+		if len(out) == limit {
+			break
+		}
+		info := copyInfo(m.info)
+		out = append(out, types.MarketSummary{
+			ID:             info.ID,
+			Hash:           info.Hash,
+			SettleTime:     info.SettleTime,
+			Settled:        info.Settled,
+			WinningOutcome: info.WinningOutcome,
+			MaxSpread:      info.MaxSpread,
+			MinOrderSize:   info.MinOrderSize,
+			CreatedAt:      info.CreatedAt,
+		})
+	}
+	return out, nil
+}
+
+// MarketExists reports whether a market has the query hash.
+func (t *Trader) MarketExists(_ context.Context, input types.MarketExistsInput) (bool, error) {
+	if err := input.Validate(); err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	s := t.sim
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, m := range s.markets {
+		if bytes.Equal(m.info.Hash, input.QueryHash) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ValidateMarketCollateral checks that YES and NO shares are issued in pairs
+// and that the vault holds $1.00 per pair plus the collateral of open bids.
+func (t *Trader) ValidateMarketCollateral(_ context.Context, input types.ValidateMarketCollateralInput) (*types.MarketValidation, error) {
+	if err := input.Validate(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	s := t.sim
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.market(input.QueryID)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &types.MarketValidation{}
+	for _, o := range m.positions {
+		switch {
+		case o.price < 0:
+			v.OpenBuysValue += int64(-o.price) * o.amount
+		case o.outcome:
+			v.TotalTrue += o.amount
+		default:
+			v.TotalFalse += o.amount
+		}
+	}
+	expected := 100*v.TotalTrue + v.OpenBuysValue
+	v.ValidTokenBinaries = v.TotalTrue == v.TotalFalse
+	v.ValidCollateral = m.vault == expected
+	v.VaultBalance = s.cents(m.vault).String()
+	v.ExpectedCollateral = s.cents(expected).String()
+	return v, nil
+}
+
+// ═══════════════════════════════════════════════════════════════
+// ORDER OPERATIONS
+// ═══════════════════════════════════════════════════════════════
+
+// PlaceBuyOrder locks price × amount and matches the bid.
+func (t *Trader) PlaceBuyOrder(_ context.Context, input types.PlaceBuyOrderInput,
+	_ ...kwilClientType.TxOpt) (kwiltypes.Hash, error) {
+	if err := input.Validate(); err != nil {
+		return kwiltypes.Hash{}, errors.WithStack(err)
+	}
+
+	s := t.sim
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.openMarket(input.QueryID)
+	if err != nil {
+		return kwiltypes.Hash{}, err
+	}
+	if err := t.lock(m, int64(input.Price)*input.Amount); err != nil {
+		return kwiltypes.Hash{}, err
+	}
+	s.buy(m, t.acct, input.Outcome, input.Price, input.Amount, s.now)
+	return t.txHash(), nil
+}
+
+// PlaceSellOrder lists held shares and matches the ask.
+func (t *Trader) PlaceSellOrder(_ context.Context, input types.PlaceSellOrderInput,
+	_ ...kwilClientType.TxOpt) (kwiltypes.Hash, error) {
+	if err := input.Validate(); err != nil {
+		return kwiltypes.Hash{}, errors.WithStack(err)
+	}
+
+	s := t.sim
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.openMarket(input.QueryID)
+	if err != nil {
+		return kwiltypes.Hash{}, err
+	}
+	if err := m.takeHolding(t.acct, input.Outcome, input.Amount); err != nil {
+		return kwiltypes.Hash{}, err
+	}
+	s.sell(m, t.acct, input.Outcome, input.Price, input.Amount, s.now)
+	return t.txHash(), nil
+}
+
+// PlaceSplitLimitOrder mints Amount pairs for $1.00 each, holds the YES
+// shares and lists the NO shares at 100 - TruePrice.
+func (t *Trader) PlaceSplitLimitOrder(_ context.Context, input types.PlaceSplitLimitOrderInput,
+	_ ...kwilClientType.TxOpt) (kwiltypes.Hash, error) {
+	if err := input.Validate(); err != nil {
+		return kwiltypes.Hash{}, errors.WithStack(err)
+	}
+
+	s := t.sim
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.openMarket(input.QueryID)
+	if err != nil {
+		return kwiltypes.Hash{}, err
+	}
+	if err := t.lock(m, 100*input.Amount); err != nil {
+		return kwiltypes.Hash{}, err
+	}
+	s.add(m, t.acct, true, 0, input.Amount, s.now)
+	s.sell(m, t.acct, false, 100-input.TruePrice, input.Amount, s.now)
+	return t.txHash(), nil
+}
+
+// CancelOrder refunds a bid or returns an ask's shares to holdings.
+func (t *Trader) CancelOrder(_ context.Context, input types.CancelOrderInput,
+	_ ...kwilClientType.TxOpt) (kwiltypes.Hash, error) {
+	if err := input.Validate(); err != nil {
+		return kwiltypes.Hash{}, errors.WithStack(err)
+	}
+
+	s := t.sim
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.openMarket(input.QueryID)
+	if err != nil {
+		return kwiltypes.Hash{}, err
+	}
+	o := m.find(t.acct, input.Outcome, input.Price)
+	if o == nil {
+		return kwiltypes.Hash{}, fmt.Errorf("order not found: query_id=%d outcome=%t price=%d", input.QueryID, input.Outcome, input.Price)
+	}
+	s.cancel(m, o)
+	return t.txHash(), nil
+}
+
+// ChangeBid moves a bid to a new price and amount, locking or refunding only
+// the difference. The new bid keeps the old one's LastUpdated and is matched
+// like a new order.
+func (t *Trader) ChangeBid(_ context.Context, input types.ChangeBidInput,
+	_ ...kwilClientType.TxOpt) (kwiltypes.Hash, error) {
+	if err := input.Validate(); err != nil {
+		return kwiltypes.Hash{}, errors.WithStack(err)
+	}
+
+	s := t.sim
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.openMarket(input.QueryID)
+	if err != nil {
+		return kwiltypes.Hash{}, err
+	}
+	o := m.find(t.acct, input.Outcome, input.OldPrice)
+	if o == nil {
+		return kwiltypes.Hash{}, fmt.Errorf("buy order not found: query_id=%d outcome=%t price=%d", input.QueryID, input.Outcome, input.OldPrice)
+	}
+	oldLock := int64(-o.price) * o.amount
+	newLock := int64(-input.NewPrice) * input.NewAmount
+	if err := t.lock(m, newLock-oldLock); err != nil {
+		return kwiltypes.Hash{}, err
+	}
+	m.remove(o)
+	s.buy(m, t.acct, input.Outcome, -input.NewPrice, input.NewAmount, o.lastUpdated)
+	return t.txHash(), nil
+}
+
+// ChangeAsk moves an ask to a new price and amount, pulling shares from or
+// returning them to holdings. The new ask keeps the old one's LastUpdated and
+// is matched like a new order.
+func (t *Trader) ChangeAsk(_ context.Context, input types.ChangeAskInput,
+	_ ...kwilClientType.TxOpt) (kwiltypes.Hash, error) {
+	if err := input.Validate(); err != nil {
+		return kwiltypes.Hash{}, errors.WithStack(err)
+	}
+
+	s := t.sim
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.openMarket(input.QueryID)
+	if err != nil {
+		return kwiltypes.Hash{}, err
+	}
+	o := m.find(t.acct, input.Outcome, input.OldPrice)
+	if o == nil {
+		return kwiltypes.Hash{}, fmt.Errorf("sell order not found: query_id=%d outcome=%t price=%d", input.QueryID, input.Outcome, input.OldPrice)
+	}
+	switch delta := input.NewAmount - o.amount; {
+	case delta > 0:
+		if err := m.takeHolding(t.acct, input.Outcome, delta); err != nil {
+			return kwiltypes.Hash{}, err
+		}
+	case delta < 0:
+		s.add(m, t.acct, input.Outcome, 0, -delta, s.now)
+	}
+	m.remove(o)
+	s.sell(m, t.acct, input.Outcome, input.NewPrice, input.NewAmount, o.lastUpdated)
+	return t.txHash(), nil
+}
+
+// ═══════════════════════════════════════════════════════════════
+// QUERY OPERATIONS
+// ═══════════════════════════════════════════════════════════════
+
+// GetOrderBook returns the bids and asks of an outcome ordered by signed
+// price (best bid first, then best ask first) and LastUpdated.
+func (t *Trader) GetOrderBook(_ context.Context, input types.GetOrderBookInput) ([]types.OrderBookEntry, error) {
+	if err := input.Validate(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	s := t.sim
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.market(input.QueryID)
+	if err != nil {
+		return nil, err
+	}
+	var out []types.OrderBookEntry
+	for _, o := range sortedOrders(m.positions) {
+		if o.outcome != input.Outcome || o.price == 0 {
+			continue
+		}
+		out = append(out, types.OrderBookEntry{
+			ParticipantID: o.owner.id,
+			Price:         o.price,
+			Amount:        o.amount,
+			LastUpdated:   o.lastUpdated,
+			WalletAddress: o.owner.wallet.Bytes(),
+		})
+	}
+	return out, nil
+}
+
+// GetUserPositions returns the trader's holdings and orders in every market.
+func (t *Trader) GetUserPositions(context.Context) ([]types.UserPosition, error) {
+	t.sim.mu.Lock()
+	defer t.sim.mu.Unlock()
+	return t.sim.positions(t.acct), nil
+}
+
+// GetMarketDepth aggregates an outcome's orders by unsigned price.
+func (t *Trader) GetMarketDepth(_ context.Context, input types.GetMarketDepthInput) ([]types.DepthLevel, error) {
+	if err := input.Validate(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	s := t.sim
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.market(input.QueryID)
+	if err != nil {
+		return nil, err
+	}
+	levels := make(map[int]*types.DepthLevel)
+	for _, o := range m.positions {
+		if o.outcome != input.Outcome || o.price == 0 {
+			continue
+		}
+		price := o.price
+		if price < 0 {
+			price = -price
+		}
+		l := levels[price]
+		if l == nil {
+			l = &types.DepthLevel{Price: price}
+			levels[price] = l
+		}
+		if o.price < 0 {
+			l.BuyVolume += o.amount
+		} else {
+			l.SellVolume += o.amount
+		}
+	}
+	out := make([]types.DepthLevel, 0, len(levels))
+	for _, l := range levels {
+		out = append(out, *l)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Price < out[j].Price })
+	return out, nil
+}
+
+// GetBestPrices returns an outcome's highest bid, lowest ask and spread.
+func (t *Trader) GetBestPrices(_ context.Context, input types.GetBestPricesInput) (*types.BestPrices, error) {
+	if err := input.Validate(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	s := t.sim
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.market(input.QueryID)
+	if err != nil {
+		return nil, err
+	}
+	prices := &types.BestPrices{}
+	for _, o := range m.positions {
+		if o.outcome != input.Outcome {
+			continue
+		}
+		switch {
+		case o.price < 0 && (prices.BestBid == nil || -o.price > *prices.BestBid):
+			bid := -o.price
+			prices.BestBid = &bid
+		case o.price > 0 && (prices.BestAsk == nil || o.price < *prices.BestAsk):
+			ask := o.price
+			prices.BestAsk = &ask
+		}
+	}
+	if prices.BestBid != nil && prices.BestAsk != nil {
+		spread := *prices.BestAsk - *prices.BestBid
+		prices.Spread = &spread
+	}
+	return prices, nil
+}
+
+// GetUserCollateral returns the collateral the trader has locked in bids and
+// the $1.00 value of its shares, across unsettled markets.
+func (t *Trader) GetUserCollateral(context.Context) (*types.UserCollateral, error) {
+	t.sim.mu.Lock()
+	defer t.sim.mu.Unlock()
+	return t.sim.collateral(t.acct), nil
+}
+
+// GetPositionsByWallet returns another wallet's holdings and orders.
+func (t *Trader) GetPositionsByWallet(_ context.Context, input types.GetPositionsByWalletInput) ([]types.UserPosition, error) {
+	if err := input.Validate(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	s := t.sim
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	acct, err := s.account(prefixed(input.WalletHex))
+	if err != nil {
+		return nil, err
+	}
+	return s.positions(acct), nil
+}
+
+// GetCollateralByWallet returns another wallet's locked collateral. The
+// simulator has a single collateral token, so Bridge is not used.
+func (t *Trader) GetCollateralByWallet(_ context.Context, input types.GetCollateralByWalletInput) (*types.UserCollateral, error) {
+	if err := input.Validate(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	s := t.sim
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	acct, err := s.account(prefixed(input.WalletHex))
+	if err != nil {
+		return nil, err
+	}
+	return s.collateral(acct), nil
+}
+
+// ═══════════════════════════════════════════════════════════════
+// SETTLEMENT & REWARDS
+// ═══════════════════════════════════════════════════════════════
+
+// SettleMarket settles a market past its settle time to the outcome set with
+// Simulator.Resolve.
+func (t *Trader) SettleMarket(_ context.Context, input types.SettleMarketInput,
+	_ ...kwilClientType.TxOpt) (kwiltypes.Hash, error) {
+	if err := input.Validate(); err != nil {
+		return kwiltypes.Hash{}, errors.WithStack(err)
+	}
+
+	s := t.sim
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, err := s.openMarket(input.QueryID)
+	if err != nil {
+		return kwiltypes.Hash{}, err
+	}
+	if s.now < m.info.SettleTime {
+		return kwiltypes.Hash{}, fmt.Errorf("settle_time not reached: %d < %d", s.now, m.info.SettleTime)
+	}
+	if m.resolved == nil {
+		return kwiltypes.Hash{}, fmt.Errorf("no signed attestation for market %d", input.QueryID)
+	}
+	s.settle(m, *m.resolved)
+	return t.txHash(), nil
+}
+
+// SampleLPRewards returns ErrNotSimulated.
+func (t *Trader) SampleLPRewards(context.Context, types.SampleLPRewardsInput,
+	...kwilClientType.TxOpt) (kwiltypes.Hash, error) {
+	return kwiltypes.Hash{}, errors.Wrap(ErrNotSimulated, "sample_lp_rewards")
+}
+
+// GetDistributionSummary returns nil: fee distributions are not simulated.
+func (t *Trader) GetDistributionSummary(context.Context, types.GetDistributionSummaryInput) (*types.DistributionSummary, error) {
+	return nil, nil
+}
+
+// GetDistributionDetails returns no rows: fee distributions are not simulated.
+func (t *Trader) GetDistributionDetails(context.Context, types.GetDistributionDetailsInput) ([]types.LPRewardDetail, error) {
+	return nil, nil
+}
+
+// GetParticipantRewardHistory returns no rows: LP rewards are not simulated.
+func (t *Trader) GetParticipantRewardHistory(context.Context, types.GetParticipantRewardHistoryInput) ([]types.RewardHistory, error) {
+	return nil, nil
+}
+
+// ═══════════════════════════════════════════════════════════════
+// HELPERS
+// ═══════════════════════════════════════════════════════════════
+
+// lock moves cents of the trader's free collateral into the market's vault;
+// a negative amount refunds.
+func (t *Trader) lock(m *market, cents int64) error {
+	if cents > t.acct.balance && !t.acct.replay {
+		return fmt.Errorf("insufficient balance: have %d, need %d", t.acct.balance, cents)
+	}
+	t.acct.balance -= cents
+	m.vault += cents
+	return nil
+}
+
+func (t *Trader) txHash() kwiltypes.Hash {
+	t.sim.txCount++
+	return kwiltypes.HashBytes([]byte(fmt.Sprintf("sim-tx-%d", t.sim.txCount)))
+}
+
+func (s *Simulator) positions(acct *account) []types.UserPosition {
+	out := []types.UserPosition{}
+	for _, m := range s.markets {
+		for _, o := range sortedOrders(m.positions) {
+			if o.owner != acct {
+				continue
+			}
+			positionType := "holding"
+			switch {
+			case o.price < 0:
+				positionType = "buy_order"
+			case o.price > 0:
+				positionType = "sell_order"
+			}
+			out = append(out, types.UserPosition{
+				QueryID:      m.info.ID,
+				Outcome:      o.outcome,
+				Price:        o.price,
+				Amount:       o.amount,
+				PositionType: positionType,
+			})
+		}
+	}
+	return out
+}
+
+func (s *Simulator) collateral(acct *account) *types.UserCollateral {
+	var buyCents, shares int64
+	for _, m := range s.markets {
+		for _, o := range m.positions {
+			if o.owner != acct {
+				continue
+			}
+			if o.price < 0 {
+				buyCents += int64(-o.price) * o.amount
+			} else {
+				shares += o.amount
+			}
+		}
+	}
+	buy, value := s.cents(buyCents), s.cents(100*shares)
+	return &types.UserCollateral{
+		TotalLocked:     new(big.Int).Add(buy, value).String(),
+		BuyOrdersLocked: buy.String(),
+		SharesValue:     value.String(),
+	}
+}
+
+// sortedOrders orders positions YES first, then by signed price and FIFO.
+func sortedOrders(positions []*order) []*order {
+	out := append([]*order(nil), positions...)
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.outcome != b.outcome {
+			return a.outcome
+		}
+		if a.price != b.price {
+			return a.price < b.price
+		}
+		if a.lastUpdated != b.lastUpdated {
+			return a.lastUpdated < b.lastUpdated
+		}
+		return a.seq < b.seq
+	})
+	return out
+}
+
+func copyInfo(info types.MarketInfo) *types.MarketInfo {
+	out := info
+	out.Hash = append([]byte(nil), info.Hash...)
+	out.QueryComponents = append([]byte(nil), info.QueryComponents...)
+	out.Creator = append([]byte(nil), info.Creator...)
+	if info.WinningOutcome != nil {
+		w := *info.WinningOutcome
+		out.WinningOutcome = &w
+	}
+	if info.SettledAt != nil {
+		at := *info.SettledAt
+		out.SettledAt = &at
+	}
+	return &out
+}
+
+func prefixed(wallet string) string {
+	if len(wallet) == 40 {
+		return "0x" + wallet
+	}
+	return wallet
+}
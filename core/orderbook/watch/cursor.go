@@ -0,0 +1,85 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+// Cursor is the wallet state a Watcher compares the next poll against.
+type Cursor struct {
+	Wallet             string                          `json:"wallet"`
+	Seq                uint64                          `json:"seq"`                  // Seq of the last event
+	Positions          []types.UserPosition            `json:"positions"`            // as of the last poll
+	Markets            map[int]string                  `json:"markets"`              // bridge of each unsettled market with positions
+	Collateral         map[string]types.UserCollateral `json:"collateral"`           // by bridge
+	LastDistributionID int                             `json:"last_distribution_id"` // highest reward distribution reported
+}
+
+func (c *Cursor) clone() *Cursor {
+	out := *c
+	out.Positions = append([]types.UserPosition(nil), c.Positions...)
+	out.Markets = make(map[int]string, len(c.Markets))
+	for k, v := range c.Markets {
+		out.Markets[k] = v
+	}
+	out.Collateral = make(map[string]types.UserCollateral, len(c.Collateral))
+	for k, v := range c.Collateral {
+		out.Collateral[k] = v
+	}
+	return &out
+}
+
+// CursorStore persists a Watcher's cursor.
+type CursorStore interface {
+	// Load returns the saved cursor, or nil if none was saved.
+	Load(ctx context.Context) (*Cursor, error)
+	Save(ctx context.Context, c *Cursor) error
+}
+
+// FileCursorStore returns a CursorStore keeping the cursor as JSON in the
+// file at path. Saves replace the file atomically.
+func FileCursorStore(path string) CursorStore {
+	return fileStore(path)
+}
+
+type fileStore string
+
+func (f fileStore) Load(context.Context) (*Cursor, error) {
+	data, err := os.ReadFile(string(f))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, errors.Wrapf(err, "decode cursor %s", string(f))
+	}
+	return &c, nil
+}
+
+func (f fileStore) Save(_ context.Context, c *Cursor) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(string(f)), filepath.Base(string(f))+".*.tmp")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.WithStack(err)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.Rename(tmp.Name(), string(f)))
+}
@@ -0,0 +1,194 @@
+package watch
+
+import (
+	"sort"
+
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+type bookSide struct {
+	queryID int
+	outcome bool
+}
+
+type sideState struct {
+	holding int64
+	orders  map[int]int64 // signed price -> amount
+}
+
+func group(positions []types.UserPosition, skip map[int]*types.MarketInfo) map[bookSide]*sideState {
+	out := make(map[bookSide]*sideState)
+	for _, p := range positions {
+		if skip[p.QueryID] != nil {
+			continue
+		}
+		key := bookSide{p.QueryID, p.Outcome}
+		s := out[key]
+		if s == nil {
+			s = &sideState{orders: make(map[int]int64)}
+			out[key] = s
+		}
+		if p.Price == 0 {
+			s.holding += p.Amount
+		} else {
+			s.orders[p.Price] += p.Amount
+		}
+	}
+	return out
+}
+
+// diffPositions reports the order events that turn prev into cur, ignoring
+// the markets in settled.
+//
+// Positions do not say why an order shrank, so holdings decide it. A filled
+// bid adds its shares to the wallet's holdings and a filled ask does not,
+// while a cancelled ask returns its shares to the holdings and a cancelled
+// bid does not. The change in holdings of the outcome, net of shares moved
+// into new asks, is spent first on filling shrunk bids and then on
+// cancelling shrunk asks. When the wallet both trades and cancels on one
+// outcome between two polls the attribution can be wrong; the totals are not.
+func diffPositions(prev, cur []types.UserPosition, settled map[int]*types.MarketInfo) []Event {
+	before, after := group(prev, settled), group(cur, settled)
+	keys := make([]bookSide, 0, len(before)+len(after))
+	for k := range before {
+		keys = append(keys, k)
+	}
+	for k := range after {
+		if before[k] == nil {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].queryID != keys[j].queryID {
+			return keys[i].queryID < keys[j].queryID
+		}
+		return keys[i].outcome && !keys[j].outcome
+	})
+
+	empty := &sideState{orders: map[int]int64{}}
+	var events []Event
+	for _, k := range keys {
+		b, a := before[k], after[k]
+		if b == nil {
+			b = empty
+		}
+		if a == nil {
+			a = empty
+		}
+
+		prices := make([]int, 0, len(b.orders)+len(a.orders))
+		for p := range b.orders {
+			prices = append(prices, p)
+		}
+		for p := range a.orders {
+			if _, ok := b.orders[p]; !ok {
+				prices = append(prices, p)
+			}
+		}
+		sort.Ints(prices) // bids first
+
+		// Shares that came back to the holdings without a new ask taking them.
+		supply := a.holding - b.holding
+		for _, p := range prices {
+			if p > 0 && a.orders[p] > b.orders[p] {
+				supply += a.orders[p] - b.orders[p]
+			}
+		}
+		take := func(n int64) int64 {
+			if supply <= 0 {
+				return 0
+			}
+			t := min(n, supply)
+			supply -= t
+			return t
+		}
+
+		var placed []Event
+		for _, p := range prices {
+			was, now := b.orders[p], a.orders[p]
+			base := Event{QueryID: k.queryID, Outcome: k.outcome, Price: p}
+			if now > was {
+				ev := base
+				ev.Kind, ev.Amount, ev.Remaining = EventOrderPlaced, now-was, now
+				placed = append(placed, ev)
+				continue
+			}
+			if now == was {
+				continue
+			}
+			shrunk := was - now
+			var filled, cancelled int64
+			if p < 0 {
+				filled = take(shrunk)
+				cancelled = shrunk - filled
+			} else {
+				cancelled = take(shrunk)
+				filled = shrunk - cancelled
+			}
+			if filled > 0 {
+				ev := base
+				ev.Kind, ev.Amount, ev.Remaining = EventPartiallyFilled, filled, was-filled
+				if ev.Remaining == 0 {
+					ev.Kind = EventFilled
+				}
+				events = append(events, ev)
+			}
+			if cancelled > 0 {
+				ev := base
+				ev.Kind, ev.Amount, ev.Remaining = EventCancelled, cancelled, now
+				events = append(events, ev)
+			}
+		}
+		events = append(events, placed...)
+	}
+	return events
+}
+
+func marketIDs(positions []types.UserPosition) []int {
+	out := make([]int, 0, len(positions))
+	for _, p := range positions {
+		out = append(out, p.QueryID)
+	}
+	return out
+}
+
+func positionsOf(positions []types.UserPosition, queryID int) []types.UserPosition {
+	var out []types.UserPosition
+	for _, p := range positions {
+		if p.QueryID == queryID {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func dedupe(ids []int) []int {
+	sort.Ints(ids)
+	out := ids[:0]
+	for i, id := range ids {
+		if i == 0 || id != ids[i-1] {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func dedupeStrings(ss []string) []string {
+	sort.Strings(ss)
+	out := ss[:0]
+	for i, s := range ss {
+		if s != "" && (i == 0 || s != ss[i-1]) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func sortedKeys(m map[int]*types.MarketInfo) []int {
+	out := make([]int, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Ints(out)
+	return out
+}
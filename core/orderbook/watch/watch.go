@@ -0,0 +1,372 @@
+// Package watch turns a wallet's order book state into a stream of events.
+//
+// The order book has no push notifications, so a Watcher polls the wallet's
+// positions, its locked collateral, the settlement state of its markets and
+// its LP reward history, and reports what changed since the previous poll:
+//
+//	events, err := watch.WatchWallet(ctx, ob, wallet,
+//	    watch.WithCursorStore(watch.FileCursorStore("wallet.cursor.json")))
+//	if err != nil {
+//	    return err
+//	}
+//	for ev := range events {
+//	    fmt.Println(ev.Kind, ev.QueryID, ev.Outcome, ev.Price, ev.Amount)
+//	}
+//
+// With a CursorStore, a restarted watcher resumes from the last saved state
+// and reports everything that changed while it was down.
+package watch
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/trufnetwork/kwil-db/core/log"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+// EventKind identifies what happened.
+type EventKind string
+
+const (
+	// EventOrderPlaced is a new order, or more shares added to an order at
+	// the same price.
+	EventOrderPlaced EventKind = "order_placed"
+	// EventPartiallyFilled is a fill that left part of the order open.
+	EventPartiallyFilled EventKind = "partially_filled"
+	// EventFilled is a fill that closed the order.
+	EventFilled EventKind = "filled"
+	// EventCancelled is an order, or the unfilled rest of one, leaving the
+	// book without being filled.
+	EventCancelled EventKind = "cancelled"
+	// EventMarketSettled is the settlement of a market the wallet held
+	// positions in.
+	EventMarketSettled EventKind = "market_settled"
+	// EventRewardReceived is an LP reward distribution to the wallet.
+	EventRewardReceived EventKind = "reward_received"
+)
+
+// Event is one change to the watched wallet.
+type Event struct {
+	Seq    uint64 // increases by one per event, across restarts with a CursorStore
+	Kind   EventKind
+	Wallet string // 0x-prefixed, lower case
+	Time   int64  // Unix seconds of the poll that observed the change
+
+	QueryID   int
+	Outcome   bool  // order events
+	Price     int   // order events: negative for bids, positive for asks
+	Amount    int64 // shares placed, filled or cancelled
+	Remaining int64 // shares left on the order after the change
+
+	// WinningOutcome and Positions are set on EventMarketSettled; Positions
+	// are the wallet's last known positions in the market.
+	WinningOutcome *bool
+	Positions      []types.UserPosition
+
+	// Reward is set on EventRewardReceived.
+	Reward *types.RewardHistory
+
+	// Collateral is the wallet's locked collateral on the market's bridge
+	// when the change was observed, if known.
+	Collateral *types.UserCollateral
+}
+
+// Watcher polls one wallet. A Watcher is not safe for concurrent use.
+type Watcher struct {
+	ob       types.IOrderBook
+	wallet   string
+	store    CursorStore
+	interval time.Duration
+	bridges  []string
+	logger   log.Logger
+	now      func() time.Time
+
+	cursor *Cursor // nil until the first step
+}
+
+// Option configures a Watcher.
+type Option func(*Watcher)
+
+// WithCursorStore persists the cursor after every poll, and loads it on the
+// first one. Default: the cursor lives in memory only and the first poll
+// reports nothing.
+func WithCursorStore(s CursorStore) Option {
+	return func(w *Watcher) {
+		w.store = s
+	}
+}
+
+// WithInterval sets how often Run polls. Default: 5s.
+func WithInterval(d time.Duration) Option {
+	return func(w *Watcher) {
+		if d > 0 {
+			w.interval = d
+		}
+	}
+}
+
+// WithBridges adds bridges whose collateral is tracked even while the wallet
+// holds no position in one of their markets.
+func WithBridges(bridges ...string) Option {
+	return func(w *Watcher) {
+		w.bridges = append(w.bridges, bridges...)
+	}
+}
+
+// WithLogger attaches a logger. Default: discard.
+func WithLogger(logger log.Logger) Option {
+	return func(w *Watcher) {
+		w.logger = logger
+	}
+}
+
+// WithClock sets the clock used to timestamp events. Default: time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(w *Watcher) {
+		w.now = now
+	}
+}
+
+// New returns a watcher of wallet, a 0x-prefixed address, reading ob.
+func New(ob types.IOrderBook, wallet string, opts ...Option) (*Watcher, error) {
+	if ob == nil {
+		return nil, errors.New("order book is required")
+	}
+	wallet = strings.ToLower(wallet)
+	if !strings.HasPrefix(wallet, "0x") {
+		wallet = "0x" + wallet
+	}
+	check := types.GetParticipantRewardHistoryInput{WalletHex: wallet}
+	if err := check.Validate(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	w := &Watcher{
+		ob:       ob,
+		wallet:   wallet,
+		interval: 5 * time.Second,
+		logger:   log.DiscardLogger,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w, nil
+}
+
+// Wallet returns the watched wallet.
+func (w *Watcher) Wallet() string {
+	return w.wallet
+}
+
+// Cursor returns a copy of the state the next poll is compared against, or
+// nil before the first poll.
+func (w *Watcher) Cursor() *Cursor {
+	if w.cursor == nil {
+		return nil
+	}
+	return w.cursor.clone()
+}
+
+// Step polls the wallet once, saves the cursor and returns the events since
+// the previous poll. The first poll without a saved cursor only records the
+// current state.
+func (w *Watcher) Step(ctx context.Context) ([]Event, error) {
+	events, next, err := w.poll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.commit(ctx, next); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Run polls until ctx is done, calling emit with every event, and returns
+// ctx.Err(). The cursor is saved after a poll's events are emitted, so after
+// a crash the events of the last poll may be emitted again; Event.Seq
+// identifies them. Failed polls are logged and retried.
+func (w *Watcher) Run(ctx context.Context, emit func(Event)) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		events, next, err := w.poll(ctx)
+		if err == nil {
+			for _, ev := range events {
+				emit(ev)
+			}
+			if ctx.Err() != nil {
+				// The events may not have been delivered; poll them again
+				// next time.
+				return ctx.Err()
+			}
+			err = w.commit(ctx, next)
+		}
+		if err != nil && ctx.Err() == nil {
+			w.logger.Warn("watch: poll failed", "wallet", w.wallet, "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// WatchWallet starts a Watcher of wallet and returns its events. The channel
+// is closed when ctx is done. Events are delivered in order; a slow reader
+// delays the next poll.
+func WatchWallet(ctx context.Context, ob types.IOrderBook, wallet string, opts ...Option) (<-chan Event, error) {
+	w, err := New(ob, wallet, opts...)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan Event, 64)
+	go func() {
+		defer close(out)
+		_ = w.Run(ctx, func(ev Event) {
+			select {
+			case out <- ev:
+			case <-ctx.Done():
+			}
+		})
+	}()
+	return out, nil
+}
+
+func (w *Watcher) commit(ctx context.Context, next *Cursor) error {
+	if w.store != nil {
+		if err := w.store.Save(ctx, next); err != nil {
+			return errors.Wrap(err, "save cursor")
+		}
+	}
+	w.cursor = next
+	return nil
+}
+
+// poll reads the wallet's state and compares it with the cursor.
+func (w *Watcher) poll(ctx context.Context) ([]Event, *Cursor, error) {
+	prev := w.cursor
+	if prev == nil && w.store != nil {
+		saved, err := w.store.Load(ctx)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "load cursor")
+		}
+		if saved != nil && saved.Wallet != "" && saved.Wallet != w.wallet {
+			return nil, nil, errors.Errorf("cursor belongs to wallet %s, not %s", saved.Wallet, w.wallet)
+		}
+		prev = saved
+	}
+
+	positions, err := w.ob.GetPositionsByWallet(ctx, types.GetPositionsByWalletInput{WalletHex: w.wallet})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "get positions")
+	}
+	rewards, err := w.ob.GetParticipantRewardHistory(ctx, types.GetParticipantRewardHistoryInput{WalletHex: w.wallet})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "get reward history")
+	}
+
+	next := &Cursor{Wallet: w.wallet, Markets: make(map[int]string), Collateral: make(map[string]types.UserCollateral)}
+	if prev != nil {
+		next.Seq = prev.Seq
+		next.LastDistributionID = prev.LastDistributionID
+	}
+	next.Positions = positions
+
+	// Markets of the previous and current positions, with their bridge and
+	// settlement state.
+	ids := marketIDs(positions)
+	if prev != nil {
+		ids = append(ids, marketIDs(prev.Positions)...)
+	}
+	settled := make(map[int]*types.MarketInfo)
+	for _, id := range dedupe(ids) {
+		bridge, known := "", false
+		if prev != nil {
+			bridge, known = prev.Markets[id]
+		}
+		info, err := w.ob.GetMarketInfo(ctx, types.GetMarketInfoInput{QueryID: id})
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "get market %d", id)
+		}
+		if info.Settled {
+			settled[id] = info
+			continue
+		}
+		if !known {
+			bridge = info.Bridge
+		}
+		next.Markets[id] = bridge
+	}
+
+	bridges := append([]string(nil), w.bridges...)
+	for _, b := range next.Markets {
+		bridges = append(bridges, b)
+	}
+	for _, info := range settled {
+		bridges = append(bridges, info.Bridge)
+	}
+	for _, b := range dedupeStrings(bridges) {
+		c, err := w.ob.GetCollateralByWallet(ctx, types.GetCollateralByWalletInput{WalletHex: w.wallet, Bridge: b})
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "get collateral on %s", b)
+		}
+		if c != nil {
+			next.Collateral[b] = *c
+		}
+	}
+	for _, r := range rewards {
+		if r.DistributionID > next.LastDistributionID {
+			next.LastDistributionID = r.DistributionID
+		}
+	}
+	if prev == nil {
+		return nil, next, nil
+	}
+
+	now := w.now().Unix()
+	var events []Event
+	collateral := func(queryID int) *types.UserCollateral {
+		bridge, ok := next.Markets[queryID]
+		if info := settled[queryID]; info != nil {
+			bridge, ok = info.Bridge, true
+		}
+		if c, found := next.Collateral[bridge]; ok && found {
+			return &c
+		}
+		return nil
+	}
+	add := func(ev Event) {
+		next.Seq++
+		ev.Seq, ev.Wallet, ev.Time = next.Seq, w.wallet, now
+		if ev.Collateral == nil && ev.QueryID > 0 {
+			ev.Collateral = collateral(ev.QueryID)
+		}
+		events = append(events, ev)
+	}
+
+	for _, ev := range diffPositions(prev.Positions, positions, settled) {
+		add(ev)
+	}
+	for _, id := range sortedKeys(settled) {
+		held := positionsOf(prev.Positions, id)
+		if len(held) == 0 {
+			continue
+		}
+		add(Event{Kind: EventMarketSettled, QueryID: id, WinningOutcome: settled[id].WinningOutcome, Positions: held})
+	}
+	sort.Slice(rewards, func(i, j int) bool { return rewards[i].DistributionID < rewards[j].DistributionID })
+	for i := range rewards {
+		r := rewards[i]
+		if r.DistributionID > prev.LastDistributionID {
+			add(Event{Kind: EventRewardReceived, QueryID: r.QueryID, Reward: &r})
+		}
+	}
+	return events, next, nil
+}
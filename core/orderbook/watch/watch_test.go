@@ -0,0 +1,182 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trufnetwork/sdk-go/core/orderbook/sim"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+const (
+	start = int64(1_700_000_000)
+	alice = "0x1111111111111111111111111111111111111111"
+	bob   = "0x2222222222222222222222222222222222222222"
+	carol = "0x3333333333333333333333333333333333333333"
+	dave  = "0x4444444444444444444444444444444444444444"
+)
+
+// rewardBook adds a reward history to the simulator, which has none.
+type rewardBook struct {
+	*sim.Trader
+	rewards []types.RewardHistory
+}
+
+func (r *rewardBook) GetParticipantRewardHistory(context.Context, types.GetParticipantRewardHistoryInput) ([]types.RewardHistory, error) {
+	return append([]types.RewardHistory(nil), r.rewards...), nil
+}
+
+// kinds strips events down to what the scenario asserts.
+func kinds(events []Event) []Event {
+	out := make([]Event, 0, len(events))
+	for _, ev := range events {
+		out = append(out, Event{Seq: ev.Seq, Kind: ev.Kind, QueryID: ev.QueryID, Outcome: ev.Outcome, Price: ev.Price, Amount: ev.Amount, Remaining: ev.Remaining})
+	}
+	return out
+}
+
+func TestWatcher(t *testing.T) {
+	ctx := context.Background()
+	s := sim.New(sim.WithTime(start))
+	for _, w := range []string{alice, bob, carol, dave} {
+		require.NoError(t, s.Deposit(w, 100_000))
+	}
+	a, b, c, d := s.Trader(alice), s.Trader(bob), s.Trader(carol), s.Trader(dave)
+	must := func(_ any, err error) { require.NoError(t, err) }
+	must(a.CreateMarket(ctx, types.CreateMarketInput{
+		Bridge:          "hoodi_tt2",
+		QueryComponents: bytes.Repeat([]byte{1}, 128),
+		SettleTime:      start + 3600,
+		MaxSpread:       5,
+		MinOrderSize:    1,
+	}))
+
+	book := &rewardBook{Trader: b}
+	path := filepath.Join(t.TempDir(), "bob.cursor.json")
+	w, err := New(book, bob, WithCursorStore(FileCursorStore(path)))
+	require.NoError(t, err)
+	step := func(w *Watcher) []Event {
+		t.Helper()
+		events, err := w.Step(ctx)
+		require.NoError(t, err)
+		return events
+	}
+
+	// The first poll without a saved cursor is the baseline.
+	must(b.PlaceBuyOrder(ctx, types.PlaceBuyOrderInput{QueryID: 1, Outcome: true, Price: 40, Amount: 10}))
+	assert.Empty(t, step(w))
+
+	must(b.PlaceBuyOrder(ctx, types.PlaceBuyOrderInput{QueryID: 1, Outcome: true, Price: 45, Amount: 5}))
+	events := step(w)
+	assert.Equal(t, []Event{{Seq: 1, Kind: EventOrderPlaced, QueryID: 1, Outcome: true, Price: -45, Amount: 5, Remaining: 5}}, kinds(events))
+	assert.Equal(t, bob, events[0].Wallet)
+	require.NotNil(t, events[0].Collateral)
+
+	// Carol sells 7 YES into both of Bob's bids.
+	must(c.PlaceSplitLimitOrder(ctx, types.PlaceSplitLimitOrderInput{QueryID: 1, TruePrice: 60, Amount: 10}))
+	must(c.PlaceSellOrder(ctx, types.PlaceSellOrderInput{QueryID: 1, Outcome: true, Price: 40, Amount: 7}))
+	assert.Equal(t, []Event{
+		{Seq: 2, Kind: EventFilled, QueryID: 1, Outcome: true, Price: -45, Amount: 5},
+		{Seq: 3, Kind: EventPartiallyFilled, QueryID: 1, Outcome: true, Price: -40, Amount: 2, Remaining: 8},
+	}, kinds(step(w)))
+
+	// Bob cancels his bid and offers 4 of his 7 shares.
+	must(b.CancelOrder(ctx, types.CancelOrderInput{QueryID: 1, Outcome: true, Price: -40}))
+	must(b.PlaceSellOrder(ctx, types.PlaceSellOrderInput{QueryID: 1, Outcome: true, Price: 65, Amount: 4}))
+	assert.Equal(t, []Event{
+		{Seq: 4, Kind: EventCancelled, QueryID: 1, Outcome: true, Price: -40, Amount: 8},
+		{Seq: 5, Kind: EventOrderPlaced, QueryID: 1, Outcome: true, Price: 65, Amount: 4, Remaining: 4},
+	}, kinds(step(w)))
+
+	// A restarted watcher picks up what happened while it was down.
+	must(d.PlaceBuyOrder(ctx, types.PlaceBuyOrderInput{QueryID: 1, Outcome: true, Price: 65, Amount: 3}))
+	w, err = New(book, bob, WithCursorStore(FileCursorStore(path)))
+	require.NoError(t, err)
+	assert.Equal(t, []Event{
+		{Seq: 6, Kind: EventPartiallyFilled, QueryID: 1, Outcome: true, Price: 65, Amount: 3, Remaining: 1},
+	}, kinds(step(w)))
+
+	// Settlement and a reward distribution.
+	require.NoError(t, s.SetTime(start+3600))
+	require.NoError(t, s.Resolve(1, true))
+	must(a.SettleMarket(ctx, types.SettleMarketInput{QueryID: 1}))
+	book.rewards = []types.RewardHistory{{DistributionID: 3, QueryID: 1, RewardAmount: "25", TotalRewardPercent: "100.00"}}
+	events = step(w)
+	require.Len(t, events, 2)
+	assert.Equal(t, EventMarketSettled, events[0].Kind)
+	assert.Equal(t, uint64(7), events[0].Seq)
+	require.NotNil(t, events[0].WinningOutcome)
+	assert.True(t, *events[0].WinningOutcome)
+	assert.ElementsMatch(t, []types.UserPosition{
+		{QueryID: 1, Outcome: true, Price: 0, Amount: 3, PositionType: "holding"},
+		{QueryID: 1, Outcome: true, Price: 65, Amount: 1, PositionType: "sell_order"},
+	}, events[0].Positions)
+	assert.Equal(t, EventRewardReceived, events[1].Kind)
+	assert.Equal(t, 3, events[1].Reward.DistributionID)
+
+	assert.Empty(t, step(w))
+	assert.Empty(t, w.Cursor().Markets)
+	assert.Equal(t, 3, w.Cursor().LastDistributionID)
+
+	other, err := New(book, carol, WithCursorStore(FileCursorStore(path)))
+	require.NoError(t, err)
+	_, err = other.Step(ctx)
+	assert.ErrorContains(t, err, "cursor belongs to wallet")
+}
+
+func TestWatchWallet(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := sim.New(sim.WithTime(start))
+	require.NoError(t, s.Deposit(alice, 100_000))
+	a := s.Trader(alice)
+	_, err := a.CreateMarket(ctx, types.CreateMarketInput{
+		Bridge:          "hoodi_tt2",
+		QueryComponents: bytes.Repeat([]byte{2}, 128),
+		SettleTime:      start + 3600,
+		MaxSpread:       5,
+		MinOrderSize:    1,
+	})
+	require.NoError(t, err)
+
+	events, err := WatchWallet(ctx, &rewardBook{Trader: a}, alice[2:], WithInterval(time.Millisecond))
+	require.NoError(t, err)
+	time.Sleep(20 * time.Millisecond) // let the baseline poll run
+	_, err = a.PlaceBuyOrder(ctx, types.PlaceBuyOrderInput{QueryID: 1, Outcome: false, Price: 30, Amount: 2})
+	require.NoError(t, err)
+
+	select {
+	case ev := <-events:
+		assert.Equal(t, EventOrderPlaced, ev.Kind)
+		assert.Equal(t, -30, ev.Price)
+		assert.Equal(t, alice, ev.Wallet)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event")
+	}
+	cancel()
+	for range events {
+	}
+}
+
+func TestDiffPositions_AmbiguousHoldings(t *testing.T) {
+	// The ask shrank by 2 while the holdings grew by 2: a cancel, not a fill.
+	prev := []types.UserPosition{{QueryID: 4, Outcome: false, Price: 70, Amount: 2}, {QueryID: 4, Outcome: false, Amount: 1}}
+	cur := []types.UserPosition{{QueryID: 4, Outcome: false, Amount: 3}}
+	assert.Equal(t, []Event{{Kind: EventCancelled, QueryID: 4, Price: 70, Amount: 2}}, diffPositions(prev, cur, nil))
+
+	// Same shrink with no change in holdings: a fill.
+	cur = []types.UserPosition{{QueryID: 4, Outcome: false, Amount: 1}}
+	assert.Equal(t, []Event{{Kind: EventFilled, QueryID: 4, Price: 70, Amount: 2}}, diffPositions(prev, cur, nil))
+}
+
+func TestNew_Validation(t *testing.T) {
+	_, err := New(nil, alice)
+	assert.ErrorContains(t, err, "order book is required")
+	_, err = New(&rewardBook{}, "0x1234")
+	assert.Error(t, err)
+}
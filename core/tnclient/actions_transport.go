@@ -49,6 +49,9 @@ func (a *TransportAction) GetRecord(ctx context.Context, input clientType.GetRec
 	if err != nil {
 		return clientType.ActionResult{}, errors.WithStack(err)
 	}
+	if err := callResultError(prefix+"get_record", args, result); err != nil {
+		return clientType.ActionResult{}, err
+	}
 
 	// Decode raw SQL output using shared type
 	rawOutputs, err := tn_api.DecodeCallResult[clientType.GetRecordRawOutput](result.QueryResult)
@@ -95,6 +98,9 @@ func (a *TransportAction) CallProcedure(ctx context.Context, procedure string, a
 	if err != nil {
 		return nil, err
 	}
+	if err := callResultError(procedure, args, result); err != nil {
+		return nil, err
+	}
 	return result.QueryResult, nil
 }
 
@@ -146,6 +152,22 @@ func (a *TransportAction) DisableComposeStream(ctx context.Context, locator clie
 	return types.Hash{}, fmt.Errorf("DisableComposeStream not implemented for custom transports - use HTTP transport or implement if needed")
 }
 
+func (a *TransportAction) GetMetadata(ctx context.Context, locator clientType.StreamLocator, key clientType.MetadataKey) ([]clientType.MetadataEntry, error) {
+	return nil, fmt.Errorf("GetMetadata not implemented for custom transports - use HTTP transport or implement if needed")
+}
+
+func (a *TransportAction) ListMetadata(ctx context.Context, locator clientType.StreamLocator, extraKeys ...clientType.MetadataKey) ([]clientType.MetadataEntry, error) {
+	return nil, fmt.Errorf("ListMetadata not implemented for custom transports - use HTTP transport or implement if needed")
+}
+
+func (a *TransportAction) SetMetadata(ctx context.Context, input clientType.SetMetadataInput) (types.Hash, error) {
+	return types.Hash{}, fmt.Errorf("SetMetadata not implemented for custom transports - use HTTP transport or implement if needed")
+}
+
+func (a *TransportAction) DisableMetadata(ctx context.Context, input clientType.DisableMetadataInput) (types.Hash, error) {
+	return types.Hash{}, fmt.Errorf("DisableMetadata not implemented for custom transports - use HTTP transport or implement if needed")
+}
+
 func (a *TransportAction) GetStreamOwner(ctx context.Context, locator clientType.StreamLocator) ([]byte, error) {
 	return nil, fmt.Errorf("GetStreamOwner not implemented for custom transports - use HTTP transport or implement if needed")
 }
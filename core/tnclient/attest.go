@@ -0,0 +1,208 @@
+package tnclient
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/contractsapi"
+	"github.com/trufnetwork/sdk-go/core/types"
+	"github.com/trufnetwork/sdk-go/core/util"
+)
+
+type attestOptions struct {
+	waitInterval time.Duration
+	pollInitial  time.Duration
+	pollMax      time.Duration
+	signers      map[string]bool
+}
+
+// AttestOption configures Attest.
+type AttestOption func(*attestOptions)
+
+// WithAttestWaitInterval sets the polling interval used while waiting for the
+// request_attestation transaction. Default: 1s.
+func WithAttestWaitInterval(d time.Duration) AttestOption {
+	return func(o *attestOptions) {
+		if d > 0 {
+			o.waitInterval = d
+		}
+	}
+}
+
+// WithAttestBackoff sets how often Attest checks whether the attestation has
+// been signed: after initial, then doubling up to max. Default: 1s up to 10s.
+func WithAttestBackoff(initial, max time.Duration) AttestOption {
+	return func(o *attestOptions) {
+		if initial > 0 {
+			o.pollInitial = initial
+		}
+		if max >= o.pollInitial {
+			o.pollMax = max
+		}
+	}
+}
+
+// WithAttestSigners makes Attest fail unless the attestation was signed by
+// one of the given validator addresses. Without it, any recoverable signer is
+// accepted and returned in AttestResult.Signer for the caller to check.
+func WithAttestSigners(addresses ...string) AttestOption {
+	return func(o *attestOptions) {
+		o.signers = make(map[string]bool, len(addresses))
+		for _, a := range addresses {
+			o.signers[strings.ToLower(a)] = true
+		}
+	}
+}
+
+func newAttestOptions(opts []AttestOption) attestOptions {
+	o := attestOptions{waitInterval: time.Second, pollInitial: time.Second, pollMax: 10 * time.Second}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.pollMax < o.pollInitial {
+		o.pollMax = o.pollInitial
+	}
+	return o
+}
+
+// AttestResult is a signed and verified attestation.
+type AttestResult struct {
+	RequestTxID  string
+	SignedHeight int64
+	// Signed is the canonical payload followed by the validator signature,
+	// as returned by GetSignedAttestation.
+	Signed []byte
+	// Signer is the 0x address of the validator that signed the payload.
+	Signer  string
+	Payload *types.ParsedAttestationPayload
+	// Rows holds the result of numeric actions, Outcome the result of binary
+	// actions.
+	Rows    []types.DecodedRow
+	Outcome *bool
+}
+
+// attestBackend is the part of Client Attest uses.
+type attestBackend interface {
+	txWaiter
+	LoadAttestationActions() (types.IAttestationAction, error)
+	Address() util.EthereumAddress
+}
+
+var _ attestBackend = (*Client)(nil)
+
+// Attest requests an attestation of input and waits until a validator has
+// signed it. It submits request_attestation with input.MaxFee as the fee
+// cap, waits for the transaction, polls the client's attestations with
+// backoff until SignedHeight is set, then verifies the signature and checks
+// that the payload attests the requested stream and action.
+//
+// Bound the wait with ctx; on timeout the request stays on the node and can
+// still be fetched with GetSignedAttestation.
+func (c *Client) Attest(ctx context.Context, input types.RequestAttestationInput, opts ...AttestOption) (*AttestResult, error) {
+	return runAttest(ctx, c, input, newAttestOptions(opts))
+}
+
+func runAttest(ctx context.Context, backend attestBackend, input types.RequestAttestationInput, o attestOptions) (*AttestResult, error) {
+	attestations, err := backend.LoadAttestationActions()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	req, err := attestations.RequestAttestation(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := kwiltypes.NewHashFromString(req.RequestTxID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse request tx %s", req.RequestTxID)
+	}
+	if err := waitTxSuccess(ctx, backend, hash, o.waitInterval); err != nil {
+		return nil, errors.Wrap(err, "request attestation")
+	}
+
+	signedHeight, err := waitAttestationSigned(ctx, attestations, backend.Address(), req.RequestTxID, o)
+	if err != nil {
+		return nil, err
+	}
+	signed, err := attestations.GetSignedAttestation(ctx, types.GetSignedAttestationInput{RequestTxID: req.RequestTxID})
+	if err != nil {
+		return nil, errors.Wrapf(err, "get signed attestation %s", req.RequestTxID)
+	}
+
+	parsed, signer, err := contractsapi.VerifySignedAttestation(signed.Payload)
+	if err != nil {
+		return nil, errors.Wrapf(err, "verify attestation %s", req.RequestTxID)
+	}
+	if o.signers != nil && !o.signers[signer] {
+		return nil, errors.Errorf("attestation %s signed by untrusted validator %s", req.RequestTxID, signer)
+	}
+	actionID := types.GetActionID(input.ActionName)
+	if !strings.EqualFold(parsed.DataProvider, input.DataProvider) || parsed.StreamID != input.StreamID ||
+		(actionID != 0 && parsed.ActionID != actionID) {
+		return nil, errors.Errorf("attestation %s is for %s/%s action %d, requested %s/%s %s", req.RequestTxID,
+			parsed.DataProvider, parsed.StreamID, parsed.ActionID, input.DataProvider, input.StreamID, input.ActionName)
+	}
+
+	res := &AttestResult{
+		RequestTxID:  req.RequestTxID,
+		SignedHeight: signedHeight,
+		Signed:       signed.Payload,
+		Signer:       signer,
+		Payload:      parsed,
+	}
+	if types.IsBinaryActionID(parsed.ActionID) {
+		outcome, err := contractsapi.ParseBooleanResultFromParsed(parsed)
+		if err != nil {
+			return nil, errors.Wrapf(err, "attestation %s", req.RequestTxID)
+		}
+		res.Outcome = &outcome
+	} else {
+		res.Rows = parsed.Result
+	}
+	return res, nil
+}
+
+// attestationPageSize bounds the list_attestations page searched for a
+// request; the newest attestations come first.
+const attestationPageSize = 100
+
+// waitAttestationSigned polls requester's attestations until requestTxID is
+// signed and returns its SignedHeight.
+func waitAttestationSigned(ctx context.Context, attestations types.IAttestationAction, requester util.EthereumAddress, requestTxID string, o attestOptions) (int64, error) {
+	order := "created_height desc"
+	limit := attestationPageSize
+	want := normalizeTxID(requestTxID)
+	delay := o.pollInitial
+	for {
+		rows, err := attestations.ListAttestations(ctx, types.ListAttestationsInput{
+			Requester: requester.Bytes(),
+			Limit:     &limit,
+			OrderBy:   &order,
+		})
+		if err != nil {
+			return 0, errors.Wrap(err, "list attestations")
+		}
+		for _, row := range rows {
+			if normalizeTxID(row.RequestTxID) == want && row.SignedHeight != nil {
+				return *row.SignedHeight, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, errors.Wrapf(ctx.Err(), "attestation %s not signed", requestTxID)
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > o.pollMax {
+			delay = o.pollMax
+		}
+	}
+}
+
+func normalizeTxID(id string) string {
+	return strings.TrimPrefix(strings.ToLower(id), "0x")
+}
@@ -0,0 +1,134 @@
+package tnclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/types"
+	"github.com/trufnetwork/sdk-go/core/util"
+)
+
+const (
+	attestProvider = "0x0000000000000000000000000000000000000000"
+	attestStream   = "stbtcusd000000000000000000000000"
+	attestTxID     = "00000000000000000000000000000000000000000000000000000000000000aa"
+)
+
+// fakeAttestBackend signs the attestation after unsignedPolls calls to
+// ListAttestations. Other IAttestationAction methods panic through the nil
+// embedded interface.
+type fakeAttestBackend struct {
+	types.IAttestationAction
+
+	signed        []byte
+	unsignedPolls int
+	polls         int
+	requested     []types.RequestAttestationInput
+}
+
+func (f *fakeAttestBackend) LoadAttestationActions() (types.IAttestationAction, error) { return f, nil }
+func (f *fakeAttestBackend) Address() util.EthereumAddress {
+	return util.Unsafe_NewEthereumAddressFromString("0x2222222222222222222222222222222222222222")
+}
+
+func (f *fakeAttestBackend) WaitForTx(_ context.Context, hash kwiltypes.Hash, _ time.Duration) (*kwiltypes.TxQueryResponse, error) {
+	return &kwiltypes.TxQueryResponse{Hash: hash, Result: &kwiltypes.TxResult{Code: uint32(kwiltypes.CodeOk)}}, nil
+}
+
+func (f *fakeAttestBackend) RequestAttestation(_ context.Context, input types.RequestAttestationInput) (*types.RequestAttestationResult, error) {
+	f.requested = append(f.requested, input)
+	return &types.RequestAttestationResult{RequestTxID: attestTxID}, nil
+}
+
+func (f *fakeAttestBackend) ListAttestations(_ context.Context, input types.ListAttestationsInput) ([]types.AttestationMetadata, error) {
+	f.polls++
+	row := types.AttestationMetadata{RequestTxID: "0x" + attestTxID, Requester: input.Requester}
+	if f.polls > f.unsignedPolls {
+		height := int64(120)
+		row.SignedHeight = &height
+	}
+	return []types.AttestationMetadata{{RequestTxID: "0xother"}, row}, nil
+}
+
+func (f *fakeAttestBackend) GetSignedAttestation(_ context.Context, input types.GetSignedAttestationInput) (*types.SignedAttestationResult, error) {
+	return &types.SignedAttestationResult{Payload: f.signed}, nil
+}
+
+// signedAttestation builds and signs a payload of actionID over attestStream.
+func signedAttestation(t *testing.T, actionID uint16, result []byte) (signed []byte, signer string) {
+	t.Helper()
+	lengthPrefixed := func(b []byte) []byte {
+		return append(binary.BigEndian.AppendUint32(nil, uint32(len(b))), b...)
+	}
+	p := []byte{1, 0}
+	p = binary.BigEndian.AppendUint64(p, 100)
+	p = append(p, lengthPrefixed(make([]byte, 20))...)
+	p = append(p, lengthPrefixed([]byte(attestStream))...)
+	p = binary.BigEndian.AppendUint16(p, actionID)
+	p = append(p, lengthPrefixed(nil)...)
+	p = append(p, lengthPrefixed(result)...)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	hash := sha256.Sum256(p)
+	sig, err := crypto.Sign(hash[:], key)
+	require.NoError(t, err)
+	sig[64] += 27
+	return append(p, sig...), strings.ToLower(crypto.PubkeyToAddress(key.PublicKey).Hex())
+}
+
+func TestAttest(t *testing.T) {
+	word := make([]byte, 32)
+	word[31] = 1
+	signed, signer := signedAttestation(t, 6, word)
+	backend := &fakeAttestBackend{signed: signed, unsignedPolls: 2}
+	input := types.RequestAttestationInput{
+		DataProvider: attestProvider,
+		StreamID:     attestStream,
+		ActionName:   "price_above_threshold",
+		MaxFee:       "1000000000000000000",
+	}
+	o := newAttestOptions([]AttestOption{WithAttestBackoff(time.Millisecond, 2*time.Millisecond), WithAttestSigners(signer)})
+
+	res, err := runAttest(context.Background(), backend, input, o)
+	require.NoError(t, err)
+	assert.Equal(t, 3, backend.polls)
+	assert.Equal(t, "1000000000000000000", backend.requested[0].MaxFee)
+	assert.Equal(t, int64(120), res.SignedHeight)
+	assert.Equal(t, signer, res.Signer)
+	assert.Equal(t, signed, res.Signed)
+	require.NotNil(t, res.Outcome)
+	assert.True(t, *res.Outcome)
+	assert.Nil(t, res.Rows)
+
+	// Untrusted signers and mismatched streams are rejected.
+	o.signers = map[string]bool{"0x3333333333333333333333333333333333333333": true}
+	_, err = runAttest(context.Background(), backend, input, o)
+	assert.ErrorContains(t, err, "untrusted validator "+signer)
+
+	o.signers = nil
+	input.StreamID = "stethusd000000000000000000000000"
+	_, err = runAttest(context.Background(), backend, input, o)
+	assert.ErrorContains(t, err, "is for")
+}
+
+func TestAttest_Timeout(t *testing.T) {
+	backend := &fakeAttestBackend{unsignedPolls: 1 << 30}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	o := newAttestOptions([]AttestOption{WithAttestBackoff(time.Millisecond, 5*time.Millisecond)})
+	_, err := runAttest(ctx, backend, types.RequestAttestationInput{
+		DataProvider: attestProvider, StreamID: attestStream, ActionName: "get_record",
+	}, o)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "not signed")
+}
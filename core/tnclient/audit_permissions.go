@@ -0,0 +1,376 @@
+package tnclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/trufnetwork/sdk-go/core/types"
+	"github.com/trufnetwork/sdk-go/core/util"
+)
+
+const (
+	defaultAuditConcurrency = 8
+	defaultAuditPageSize    = 100
+)
+
+// PermissionAuditOption configures AuditPermissions.
+type PermissionAuditOption func(*permissionAuditConfig)
+
+type permissionAuditConfig struct {
+	concurrency int
+	pageSize    int
+}
+
+// WithAuditConcurrency bounds how many streams are inspected in parallel.
+// Each stream costs up to five view calls. Default 8.
+func WithAuditConcurrency(n int) PermissionAuditOption {
+	return func(c *permissionAuditConfig) {
+		if n > 0 {
+			c.concurrency = n
+		}
+	}
+}
+
+// WithAuditPageSize sets the page size used to enumerate streams with
+// ListStreams. Default 100.
+func WithAuditPageSize(n int) PermissionAuditOption {
+	return func(c *permissionAuditConfig) {
+		if n > 0 {
+			c.pageSize = n
+		}
+	}
+}
+
+// StreamPermissions is the permission state of a single stream. Visibilities
+// are "public" or "private"; an unset visibility is reported as public since
+// that is how the node treats it.
+type StreamPermissions struct {
+	DataProvider          string   `json:"data_provider"`
+	StreamId              string   `json:"stream_id"`
+	StreamType            string   `json:"stream_type"`
+	ReadVisibility        string   `json:"read_visibility"`
+	ComposeVisibility     string   `json:"compose_visibility"`
+	AllowedReadWallets    []string `json:"allowed_read_wallets,omitempty"`
+	AllowedComposeStreams []string `json:"allowed_compose_streams,omitempty"`
+	// Children holds "data_provider/stream_id" of the latest taxonomy of a composed stream.
+	Children []string `json:"children,omitempty"`
+}
+
+// PrivateChildExposure flags a publicly readable composed stream whose latest
+// taxonomy includes read-private children, which leaks their data in aggregate.
+type PrivateChildExposure struct {
+	StreamId        string   `json:"stream_id"`
+	PrivateChildren []string `json:"private_children"`
+}
+
+// PermissionAuditReport summarizes the access control of every stream owned by
+// a data provider.
+type PermissionAuditReport struct {
+	DataProvider string              `json:"data_provider"`
+	Streams      []StreamPermissions `json:"streams"`
+	// PrivateStreams lists stream ids with private read visibility.
+	PrivateStreams []string `json:"private_streams"`
+	// WalletAccess maps each allow-listed wallet to the private streams it can read.
+	WalletAccess map[string][]string `json:"wallet_access"`
+	// PublicComposingPrivate lists public composed streams with private children.
+	PublicComposingPrivate []PrivateChildExposure `json:"public_composing_private"`
+}
+
+// JSON renders the report as indented JSON.
+func (r *PermissionAuditReport) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// WriteTable renders one row per stream followed by the findings, aligned for terminals.
+func (r *PermissionAuditReport) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STREAM\tTYPE\tREAD\tCOMPOSE\tREADERS\tCOMPOSERS")
+	for _, s := range r.Streams {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%d\n",
+			s.StreamId, s.StreamType, s.ReadVisibility, s.ComposeVisibility,
+			len(s.AllowedReadWallets), len(s.AllowedComposeStreams))
+	}
+	if err := tw.Flush(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	fmt.Fprintf(w, "\nprivate streams: %d of %d\n", len(r.PrivateStreams), len(r.Streams))
+
+	if len(r.WalletAccess) > 0 {
+		fmt.Fprintln(w, "\nwallets with read access:")
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		wallets := make([]string, 0, len(r.WalletAccess))
+		for wallet := range r.WalletAccess {
+			wallets = append(wallets, wallet)
+		}
+		sort.Strings(wallets)
+		for _, wallet := range wallets {
+			fmt.Fprintf(tw, "%s\t%s\n", wallet, strings.Join(r.WalletAccess[wallet], ", "))
+		}
+		if err := tw.Flush(); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	if len(r.PublicComposingPrivate) > 0 {
+		fmt.Fprintln(w, "\npublic streams composing private children:")
+		for _, e := range r.PublicComposingPrivate {
+			fmt.Fprintf(w, "  %s <- %s\n", e.StreamId, strings.Join(e.PrivateChildren, ", "))
+		}
+	}
+	return nil
+}
+
+// permissionAuditBackend is the subset of Client the audit needs.
+type permissionAuditBackend interface {
+	ListStreams(ctx context.Context, input types.ListStreamsInput) ([]types.ListStreamsOutput, error)
+	LoadActions() (types.IAction, error)
+	LoadComposedActions() (types.IComposedAction, error)
+}
+
+var _ permissionAuditBackend = (*Client)(nil)
+
+// AuditPermissions enumerates every stream of dataProvider and gathers its read
+// and compose visibility, allow-lists and, for composed streams, the latest
+// taxonomy. Streams are inspected concurrently, bounded by WithAuditConcurrency;
+// the audit stops at the first stream that fails or when ctx is done.
+//
+// Example:
+//
+//	report, err := client.AuditPermissions(ctx, client.Address().Address())
+//	if err != nil { ... }
+//	_ = report.WriteTable(os.Stdout)
+func (c *Client) AuditPermissions(ctx context.Context, dataProvider string, opts ...PermissionAuditOption) (*PermissionAuditReport, error) {
+	return runPermissionAudit(ctx, c, dataProvider, opts...)
+}
+
+func runPermissionAudit(ctx context.Context, backend permissionAuditBackend, dataProvider string, opts ...PermissionAuditOption) (*PermissionAuditReport, error) {
+	cfg := permissionAuditConfig{concurrency: defaultAuditConcurrency, pageSize: defaultAuditPageSize}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	dp, err := util.NewEthereumAddressFromString(dataProvider)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid data provider")
+	}
+
+	var listed []types.ListStreamsOutput
+	for offset := 0; ; offset += cfg.pageSize {
+		page, err := backend.ListStreams(ctx, types.ListStreamsInput{
+			DataProvider: dp.Address(),
+			Limit:        cfg.pageSize,
+			Offset:       offset,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "list streams")
+		}
+		if len(page) == 0 {
+			break
+		}
+		listed = append(listed, page...)
+	}
+
+	actions, err := backend.LoadActions()
+	if err != nil {
+		return nil, errors.Wrap(err, "load actions")
+	}
+	var composed types.IComposedAction
+	for _, s := range listed {
+		if s.StreamType == string(types.StreamTypeComposed) {
+			if composed, err = backend.LoadComposedActions(); err != nil {
+				return nil, errors.Wrap(err, "load composed actions")
+			}
+			break
+		}
+	}
+
+	// the first failure cancels auditCtx so no further streams are dispatched
+	auditCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]StreamPermissions, len(listed))
+	var (
+		once     sync.Once
+		firstErr error
+	)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(cfg.concurrency, len(listed)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if auditCtx.Err() != nil {
+					continue
+				}
+				var err error
+				if results[i], err = inspectStreamPermissions(auditCtx, actions, composed, dp, listed[i]); err != nil {
+					once.Do(func() {
+						firstErr = errors.Wrapf(err, "audit %s", listed[i].StreamId)
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+dispatch:
+	for i := range listed {
+		select {
+		case jobs <- i:
+		case <-auditCtx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, errors.Wrap(err, "audit permissions")
+	}
+
+	report := &PermissionAuditReport{
+		DataProvider: dp.Address(),
+		Streams:      results,
+		WalletAccess: map[string][]string{},
+	}
+	sort.Slice(report.Streams, func(i, j int) bool { return report.Streams[i].StreamId < report.Streams[j].StreamId })
+
+	readPrivate := map[string]bool{}
+	for _, s := range report.Streams {
+		if s.ReadVisibility == visibilityName(util.PrivateVisibility) {
+			readPrivate[s.DataProvider+"/"+s.StreamId] = true
+			report.PrivateStreams = append(report.PrivateStreams, s.StreamId)
+			for _, wallet := range s.AllowedReadWallets {
+				report.WalletAccess[wallet] = append(report.WalletAccess[wallet], s.StreamId)
+			}
+		}
+	}
+
+	// children owned by other providers are outside the listing, look them up once
+	external := map[string]bool{}
+	for _, s := range report.Streams {
+		if s.ReadVisibility != visibilityName(util.PublicVisibility) {
+			continue
+		}
+		var private []string
+		for _, child := range s.Children {
+			isPrivate, known := readPrivate[child]
+			if !known && !strings.HasPrefix(child, dp.Address()+"/") {
+				if _, seen := external[child]; !seen {
+					external[child], err = childIsReadPrivate(ctx, actions, child)
+					if err != nil {
+						return nil, errors.Wrapf(err, "audit child %s", child)
+					}
+				}
+				isPrivate = external[child]
+			}
+			if isPrivate {
+				private = append(private, child)
+			}
+		}
+		if len(private) > 0 {
+			report.PublicComposingPrivate = append(report.PublicComposingPrivate, PrivateChildExposure{
+				StreamId:        s.StreamId,
+				PrivateChildren: private,
+			})
+		}
+	}
+
+	return report, nil
+}
+
+func inspectStreamPermissions(ctx context.Context, actions types.IAction, composed types.IComposedAction, dp util.EthereumAddress, listed types.ListStreamsOutput) (StreamPermissions, error) {
+	streamId, err := util.NewStreamId(listed.StreamId)
+	if err != nil {
+		return StreamPermissions{}, errors.WithStack(err)
+	}
+	locator := types.StreamLocator{StreamId: *streamId, DataProvider: dp}
+	perms := StreamPermissions{
+		DataProvider: dp.Address(),
+		StreamId:     listed.StreamId,
+		StreamType:   listed.StreamType,
+	}
+
+	readVisibility, err := actions.GetReadVisibility(ctx, locator)
+	if err != nil {
+		return StreamPermissions{}, errors.Wrap(err, "read visibility")
+	}
+	perms.ReadVisibility = visibilityName(visibilityOrPublic(readVisibility))
+
+	composeVisibility, err := actions.GetComposeVisibility(ctx, locator)
+	if err != nil {
+		return StreamPermissions{}, errors.Wrap(err, "compose visibility")
+	}
+	perms.ComposeVisibility = visibilityName(visibilityOrPublic(composeVisibility))
+
+	wallets, err := actions.GetAllowedReadWallets(ctx, locator)
+	if err != nil {
+		return StreamPermissions{}, errors.Wrap(err, "allowed read wallets")
+	}
+	perms.AllowedReadWallets = util.EthereumAddressesToStrings(wallets)
+
+	composers, err := actions.GetAllowedComposeStreams(ctx, locator)
+	if err != nil {
+		return StreamPermissions{}, errors.Wrap(err, "allowed compose streams")
+	}
+	for _, l := range composers {
+		perms.AllowedComposeStreams = append(perms.AllowedComposeStreams, l.StreamId.String())
+	}
+
+	if listed.StreamType == string(types.StreamTypeComposed) && composed != nil {
+		taxonomy, err := composed.DescribeTaxonomies(ctx, types.DescribeTaxonomiesParams{Stream: locator, LatestVersion: true})
+		if err != nil {
+			return StreamPermissions{}, errors.Wrap(err, "describe taxonomies")
+		}
+		for _, item := range taxonomy.TaxonomyItems {
+			perms.Children = append(perms.Children, item.ChildStream.DataProvider.Address()+"/"+item.ChildStream.StreamId.String())
+		}
+	}
+
+	return perms, nil
+}
+
+func childIsReadPrivate(ctx context.Context, actions types.IAction, child string) (bool, error) {
+	parts := strings.SplitN(child, "/", 2)
+	if len(parts) != 2 {
+		return false, errors.Errorf("malformed child locator %q", child)
+	}
+	dp, err := util.NewEthereumAddressFromString(parts[0])
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	streamId, err := util.NewStreamId(parts[1])
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	visibility, err := actions.GetReadVisibility(ctx, types.StreamLocator{StreamId: *streamId, DataProvider: dp})
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return visibilityOrPublic(visibility) == util.PrivateVisibility, nil
+}
+
+func visibilityOrPublic(v *util.VisibilityEnum) util.VisibilityEnum {
+	if v == nil {
+		return util.PublicVisibility
+	}
+	return *v
+}
+
+func visibilityName(v util.VisibilityEnum) string {
+	if v == util.PrivateVisibility {
+		return "private"
+	}
+	return "public"
+}
@@ -0,0 +1,180 @@
+package tnclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trufnetwork/sdk-go/core/types"
+	"github.com/trufnetwork/sdk-go/core/util"
+)
+
+type fakeAuditBackend struct {
+	types.IComposedAction
+
+	streams     []types.ListStreamsOutput
+	readPriv    map[string]bool
+	readers     map[string][]util.EthereumAddress
+	children    map[string][]types.StreamLocator
+	failRead    map[string]bool
+	listCalls   int
+	readCalls   int32
+	inflight    int32
+	maxInFlight int32
+}
+
+func (f *fakeAuditBackend) ListStreams(_ context.Context, input types.ListStreamsInput) ([]types.ListStreamsOutput, error) {
+	f.listCalls++
+	if input.Offset >= len(f.streams) {
+		return nil, nil
+	}
+	end := input.Offset + input.Limit
+	if end > len(f.streams) {
+		end = len(f.streams)
+	}
+	return f.streams[input.Offset:end], nil
+}
+
+func (f *fakeAuditBackend) LoadActions() (types.IAction, error)                 { return f, nil }
+func (f *fakeAuditBackend) LoadComposedActions() (types.IComposedAction, error) { return f, nil }
+
+func (f *fakeAuditBackend) GetReadVisibility(_ context.Context, locator types.StreamLocator) (*util.VisibilityEnum, error) {
+	atomic.AddInt32(&f.readCalls, 1)
+	n := atomic.AddInt32(&f.inflight, 1)
+	defer atomic.AddInt32(&f.inflight, -1)
+	for {
+		max := atomic.LoadInt32(&f.maxInFlight)
+		if n <= max || atomic.CompareAndSwapInt32(&f.maxInFlight, max, n) {
+			break
+		}
+	}
+	if f.failRead[locator.StreamId.String()] {
+		return nil, errors.New("read visibility unavailable")
+	}
+	if f.readPriv[locator.StreamId.String()] {
+		v := util.PrivateVisibility
+		return &v, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeAuditBackend) GetComposeVisibility(context.Context, types.StreamLocator) (*util.VisibilityEnum, error) {
+	v := util.PublicVisibility
+	return &v, nil
+}
+
+func (f *fakeAuditBackend) GetAllowedReadWallets(_ context.Context, locator types.StreamLocator) ([]util.EthereumAddress, error) {
+	return f.readers[locator.StreamId.String()], nil
+}
+
+func (f *fakeAuditBackend) GetAllowedComposeStreams(context.Context, types.StreamLocator) ([]types.StreamLocator, error) {
+	return nil, nil
+}
+
+func (f *fakeAuditBackend) DescribeTaxonomies(_ context.Context, params types.DescribeTaxonomiesParams) (types.Taxonomy, error) {
+	var items []types.TaxonomyItem
+	for _, c := range f.children[params.Stream.StreamId.String()] {
+		items = append(items, types.TaxonomyItem{ChildStream: c, Weight: 1})
+	}
+	return types.Taxonomy{ParentStream: params.Stream, TaxonomyItems: items}, nil
+}
+
+func TestAuditPermissions_Report(t *testing.T) {
+	owner, err := util.NewEthereumAddressFromString("0x000000000000000000000000000000000000a110")
+	require.NoError(t, err)
+	other, err := util.NewEthereumAddressFromString("0x000000000000000000000000000000000000b220")
+	require.NoError(t, err)
+	reader, err := util.NewEthereumAddressFromString("0x000000000000000000000000000000000000c330")
+	require.NoError(t, err)
+
+	secret := util.GenerateStreamId("secret")
+	open := util.GenerateStreamId("open")
+	index := util.GenerateStreamId("index")
+	foreign := util.GenerateStreamId("foreign")
+
+	f := &fakeAuditBackend{
+		streams: []types.ListStreamsOutput{
+			{DataProvider: owner.Address(), StreamId: secret.String(), StreamType: "primitive"},
+			{DataProvider: owner.Address(), StreamId: open.String(), StreamType: "primitive"},
+			{DataProvider: owner.Address(), StreamId: index.String(), StreamType: "composed"},
+		},
+		readPriv: map[string]bool{secret.String(): true, foreign.String(): true},
+		readers:  map[string][]util.EthereumAddress{secret.String(): {reader}},
+		children: map[string][]types.StreamLocator{
+			index.String(): {
+				{StreamId: secret, DataProvider: owner},
+				{StreamId: open, DataProvider: owner},
+				{StreamId: foreign, DataProvider: other},
+			},
+		},
+	}
+
+	report, err := runPermissionAudit(context.Background(), f, owner.Address(), WithAuditPageSize(2), WithAuditConcurrency(2))
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, f.listCalls, "two pages plus the empty terminator")
+	assert.LessOrEqual(t, atomic.LoadInt32(&f.maxInFlight), int32(2))
+	require.Len(t, report.Streams, 3)
+	assert.Equal(t, []string{secret.String()}, report.PrivateStreams)
+	assert.Equal(t, map[string][]string{reader.Address(): {secret.String()}}, report.WalletAccess)
+	require.Len(t, report.PublicComposingPrivate, 1)
+	assert.Equal(t, index.String(), report.PublicComposingPrivate[0].StreamId)
+	assert.ElementsMatch(t, []string{
+		owner.Address() + "/" + secret.String(),
+		other.Address() + "/" + foreign.String(),
+	}, report.PublicComposingPrivate[0].PrivateChildren)
+
+	raw, err := report.JSON()
+	require.NoError(t, err)
+	var decoded PermissionAuditReport
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	assert.Equal(t, report.PrivateStreams, decoded.PrivateStreams)
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteTable(&buf))
+	assert.Contains(t, buf.String(), "STREAM")
+	assert.Contains(t, buf.String(), "private streams: 1 of 3")
+	assert.Contains(t, buf.String(), index.String()+" <- ")
+}
+
+func TestAuditPermissions_StopsOnFirstError(t *testing.T) {
+	owner, err := util.NewEthereumAddressFromString("0x000000000000000000000000000000000000a110")
+	require.NoError(t, err)
+
+	f := &fakeAuditBackend{failRead: map[string]bool{}}
+	for i := 0; i < 50; i++ {
+		id := util.GenerateStreamId(fmt.Sprintf("stream-%d", i))
+		f.streams = append(f.streams, types.ListStreamsOutput{DataProvider: owner.Address(), StreamId: id.String(), StreamType: "primitive"})
+		if i == 0 {
+			f.failRead[id.String()] = true
+		}
+	}
+
+	_, err = runPermissionAudit(context.Background(), f, owner.Address(), WithAuditPageSize(100), WithAuditConcurrency(1))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "audit "+f.streams[0].StreamId)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&f.readCalls), "no stream is inspected after the first failure")
+}
+
+func TestAuditPermissions_StopsOnCancel(t *testing.T) {
+	owner, err := util.NewEthereumAddressFromString("0x000000000000000000000000000000000000a110")
+	require.NoError(t, err)
+
+	f := &fakeAuditBackend{}
+	for i := 0; i < 10; i++ {
+		id := util.GenerateStreamId(fmt.Sprintf("stream-%d", i))
+		f.streams = append(f.streams, types.ListStreamsOutput{DataProvider: owner.Address(), StreamId: id.String(), StreamType: "primitive"})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = runPermissionAudit(ctx, f, owner.Address(), WithAuditConcurrency(1))
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, atomic.LoadInt32(&f.readCalls))
+}
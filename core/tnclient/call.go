@@ -0,0 +1,122 @@
+package tnclient
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	kwilClientType "github.com/trufnetwork/kwil-db/core/client/types"
+	kwilType "github.com/trufnetwork/kwil-db/core/types"
+	tn_api "github.com/trufnetwork/sdk-go/core/contractsapi"
+	"github.com/trufnetwork/sdk-go/core/tnerrors"
+	"github.com/trufnetwork/sdk-go/core/util"
+)
+
+// CallOutput holds the rows decoded by Call and the NOTICE lines the action
+// emitted, in order and without their "N. " numbering.
+type CallOutput[Out any] struct {
+	Rows []Out
+	Logs []string
+}
+
+// Call calls the view action with in as arguments and decodes the returned
+// rows into Out. It goes through the client's Transport, so it works with
+// custom transports as well as HTTP.
+//
+// In is a struct (or pointer to one) serialized with util.StructAsArgs: fields
+// become positional arguments in declaration order, fields tagged
+// validate:"required" must be non-zero, other zero fields and nil pointers
+// are sent as NULL. Use struct{}{} for actions without arguments. Wrappers
+// generated by tngen differ: they send zero values as is and only nil
+// pointers as NULL.
+//
+// Out is decoded with contractsapi.DecodeCallResult: columns map to fields by
+// tn or json tag or by name, pointer fields are nil for NULL columns, and
+// numeric columns can be decoded into string, Decimal or big.Int fields. A
+// scalar Out decodes single-column results.
+//
+//	type priceInput struct {
+//	    QueryId int64 `validate:"required"`
+//	}
+//	type priceRow struct {
+//	    Price *kwiltypes.Decimal `json:"price"`
+//	}
+//	out, err := tnclient.Call[priceInput, priceRow](ctx, client, "get_last_price", priceInput{QueryId: 7})
+func Call[In, Out any](ctx context.Context, c *Client, action string, in In) (*CallOutput[Out], error) {
+	args, err := structArgs(tnerrors.OpCall, action, in)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.transport.Call(ctx, "", action, args)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := callResultError(action, args, result); err != nil {
+		return nil, err
+	}
+
+	rows, err := tn_api.DecodeCallResult[Out](result.QueryResult)
+	if err != nil {
+		return nil, errors.Wrapf(err, "decode %s result", action)
+	}
+	return &CallOutput[Out]{Rows: rows, Logs: noticeLines(result.Logs)}, nil
+}
+
+// Execute broadcasts the action with in as arguments, serialized as in Call,
+// and returns the transaction hash without waiting for it.
+func Execute[In any](ctx context.Context, c *Client, action string, in In, opts ...kwilClientType.TxOpt) (kwilType.Hash, error) {
+	return ExecuteBatch(ctx, c, action, []In{in}, opts...)
+}
+
+// ExecuteBatch runs the action once per element of ins, all in a single
+// transaction.
+func ExecuteBatch[In any](ctx context.Context, c *Client, action string, ins []In, opts ...kwilClientType.TxOpt) (kwilType.Hash, error) {
+	if len(ins) == 0 {
+		return kwilType.Hash{}, errors.Errorf("execute %s: no inputs", action)
+	}
+	tuples := make([][]any, len(ins))
+	for i, in := range ins {
+		args, err := structArgs(tnerrors.OpExecute, action, in)
+		if err != nil {
+			return kwilType.Hash{}, errors.Wrapf(err, "input %d", i)
+		}
+		tuples[i] = args
+	}
+
+	hash, err := c.transport.Execute(ctx, "", action, tuples, opts...)
+	if err != nil {
+		return kwilType.Hash{}, errors.WithStack(err)
+	}
+	return hash, nil
+}
+
+func structArgs(op, action string, in any) ([]any, error) {
+	args, err := util.StructAsArgs(in)
+	if err != nil {
+		return nil, tnerrors.Wrap(err, op, action, tnerrors.WithKind(tnerrors.ErrInvalidArgument))
+	}
+	if args == nil {
+		args = []any{}
+	}
+	return args, nil
+}
+
+// noticeLines splits CallResult.Logs into lines, dropping the "N. " prefix
+// kwil-db puts before each NOTICE.
+func noticeLines(logs string) []string {
+	var lines []string
+	for _, line := range strings.Split(logs, "\n") {
+		line = strings.TrimSpace(line)
+		if dot := strings.Index(line, ". "); dot > 0 {
+			if _, err := strconv.Atoi(line[:dot]); err == nil {
+				line = strings.TrimSpace(line[dot+2:])
+			}
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,124 @@
+package tnclient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	clientType "github.com/trufnetwork/kwil-db/core/client/types"
+	kwilTypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/tnerrors"
+)
+
+type marketInput struct {
+	QueryId  int64 `validate:"required"`
+	Outcome  *bool
+	MinPrice *kwilTypes.Decimal
+	Bucket   string
+}
+
+type marketRow struct {
+	Price   kwilTypes.Decimal  `json:"price"`
+	Amount  int64              `json:"amount"`
+	Settled *bool              `json:"settled"`
+	Payout  *kwilTypes.Decimal `json:"payout"`
+}
+
+func TestCall(t *testing.T) {
+	var gotAction string
+	var gotArgs []any
+	transport := &mockTransport{
+		callFunc: func(_ context.Context, _ string, action string, inputs []any) (*kwilTypes.CallResult, error) {
+			gotAction, gotArgs = action, inputs
+			return &kwilTypes.CallResult{
+				QueryResult: &kwilTypes.QueryResult{
+					ColumnNames: []string{"price", "amount", "settled", "payout"},
+					Values: [][]any{
+						{"0.55", int64(10), true, "1.000000000000000000"},
+						{"0.60", "20", nil, nil},
+					},
+				},
+				Logs: "1. orders scanned: 2\n2. cache miss\n",
+			}, nil
+		},
+	}
+	c := &Client{transport: transport}
+
+	no := false
+	minPrice := kwilTypes.MustParseDecimal("0.50")
+	out, err := Call[marketInput, marketRow](context.Background(), c, "get_orders", marketInput{QueryId: 7, Outcome: &no, MinPrice: minPrice})
+	require.NoError(t, err)
+
+	assert.Equal(t, "get_orders", gotAction)
+	require.Len(t, gotArgs, 4)
+	assert.Equal(t, int64(7), gotArgs[0])
+	assert.Equal(t, false, gotArgs[1], "pointer to a zero value is sent as the value")
+	assert.Equal(t, *minPrice, gotArgs[2])
+	assert.Nil(t, gotArgs[3], "zero optional field is sent as NULL")
+
+	require.Len(t, out.Rows, 2)
+	assert.Equal(t, "0.55", out.Rows[0].Price.String())
+	assert.Equal(t, int64(10), out.Rows[0].Amount)
+	require.NotNil(t, out.Rows[0].Settled)
+	assert.True(t, *out.Rows[0].Settled)
+	assert.Equal(t, "1.000000000000000000", out.Rows[0].Payout.String())
+	assert.Equal(t, int64(20), out.Rows[1].Amount)
+	assert.Nil(t, out.Rows[1].Settled)
+	assert.Nil(t, out.Rows[1].Payout)
+	assert.Equal(t, []string{"orders scanned: 2", "cache miss"}, out.Logs)
+}
+
+func TestCall_ScalarAndErrors(t *testing.T) {
+	transport := &mockTransport{
+		callFunc: func(_ context.Context, _ string, action string, _ []any) (*kwilTypes.CallResult, error) {
+			if action == "broken" {
+				msg := "market not found: query_id=7"
+				return &kwilTypes.CallResult{Error: &msg}, nil
+			}
+			return &kwilTypes.CallResult{QueryResult: &kwilTypes.QueryResult{
+				ColumnNames: []string{"total"},
+				Values:      [][]any{{"12.5"}},
+			}}, nil
+		},
+	}
+	c := &Client{transport: transport}
+
+	out, err := Call[struct{}, kwilTypes.Decimal](context.Background(), c, "get_total", struct{}{})
+	require.NoError(t, err)
+	require.Len(t, out.Rows, 1)
+	assert.Equal(t, "12.5", out.Rows[0].String())
+
+	_, err = Call[struct{}, kwilTypes.Decimal](context.Background(), c, "broken", struct{}{})
+	assert.ErrorIs(t, err, tnerrors.ErrNotFound)
+
+	_, err = Call[marketInput, marketRow](context.Background(), c, "get_orders", marketInput{})
+	assert.ErrorIs(t, err, tnerrors.ErrInvalidArgument)
+	assert.ErrorContains(t, err, "required field 'QueryId' is empty")
+
+	_, err = Call[int, marketRow](context.Background(), c, "get_orders", 7)
+	assert.ErrorIs(t, err, tnerrors.ErrInvalidArgument)
+}
+
+func TestExecuteBatch(t *testing.T) {
+	var gotInputs [][]any
+	transport := &mockTransport{
+		executeFunc: func(_ context.Context, _ string, _ string, inputs [][]any, opts ...clientType.TxOpt) (kwilTypes.Hash, error) {
+			gotInputs = inputs
+			assert.Len(t, opts, 1)
+			return kwilTypes.Hash{9}, nil
+		},
+	}
+	c := &Client{transport: transport}
+
+	hash, err := ExecuteBatch(context.Background(), c, "place_order", []marketInput{{QueryId: 1}, {QueryId: 2, Bucket: "yes"}}, clientType.WithNonce(5))
+	require.NoError(t, err)
+	assert.Equal(t, kwilTypes.Hash{9}, hash)
+	assert.Equal(t, [][]any{{int64(1), nil, nil, nil}, {int64(2), nil, nil, "yes"}}, gotInputs)
+
+	_, err = Execute(context.Background(), c, "place_order", marketInput{Bucket: "no"})
+	assert.ErrorContains(t, err, "input 0")
+
+	_, err = ExecuteBatch[marketInput](context.Background(), c, "place_order", nil)
+	assert.ErrorContains(t, err, "no inputs")
+}
@@ -219,6 +219,20 @@ func (c *Client) GetKwilClient() *gatewayclient.GatewayClient {
 	return nil
 }
 
+// BlockHeight returns the height of the latest block the node has committed.
+// It requires the HTTP transport.
+func (c *Client) BlockHeight(ctx context.Context) (int64, error) {
+	kwilClient := c.GetKwilClient()
+	if kwilClient == nil {
+		return 0, errors.New("block height requires the HTTP transport")
+	}
+	info, err := kwilClient.ChainInfo(ctx)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return int64(info.BlockHeight), nil
+}
+
 // DeployStreamOptions configures stream deployment behavior.
 // Zero-valued fields reproduce the historical defaults exactly, so
 // existing callers can continue to use DeployStream without change.
@@ -322,6 +336,20 @@ func (c *Client) LoadBulkInserter(opts ...tn_api.BulkInserterOption) (*tn_api.Bu
 	return tn_api.NewBulkInserter(primitive, kwilClient, c.transport.Signer(), opts...)
 }
 
+// LoadBulkPermissions wires up a BulkPermissions for granting and revoking
+// stream permissions in bulk. Requires HTTP transport, like LoadBulkInserter.
+func (c *Client) LoadBulkPermissions(opts ...tn_api.BulkPermissionOption) (*tn_api.BulkPermissions, error) {
+	kwilClient := c.GetKwilClient()
+	if kwilClient == nil {
+		return nil, errors.New("BulkPermissions requires HTTP transport (GetKwilClient returned nil)")
+	}
+	actions, err := c.LoadActions()
+	if err != nil {
+		return nil, errors.Wrap(err, "load actions")
+	}
+	return tn_api.NewBulkPermissions(actions, kwilClient, c.transport.Signer(), opts...)
+}
+
 func (c *Client) LoadRoleManagementActions() (clientType.IRoleManagement, error) {
 	return tn_api.LoadRoleManagementActions(tn_api.NewRoleManagementOptions{
 		Client: c.GetKwilClient(),
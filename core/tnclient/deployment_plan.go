@@ -0,0 +1,356 @@
+package tnclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/logging"
+	"github.com/trufnetwork/sdk-go/core/tnerrors"
+	"github.com/trufnetwork/sdk-go/core/types"
+	"github.com/trufnetwork/sdk-go/core/util"
+	"go.uber.org/zap"
+)
+
+// DeploymentPermissionKind selects which permission setter a DeploymentPermission maps to.
+type DeploymentPermissionKind string
+
+const (
+	// PermissionReadVisibility maps to IAction.SetReadVisibility.
+	PermissionReadVisibility DeploymentPermissionKind = "read_visibility"
+	// PermissionComposeVisibility maps to IAction.SetComposeVisibility.
+	PermissionComposeVisibility DeploymentPermissionKind = "compose_visibility"
+	// PermissionAllowReadWallet maps to IAction.AllowReadWallet.
+	PermissionAllowReadWallet DeploymentPermissionKind = "allow_read_wallet"
+	// PermissionAllowComposeStream maps to IAction.AllowComposeStream.
+	PermissionAllowComposeStream DeploymentPermissionKind = "allow_compose_stream"
+)
+
+// DeploymentPermission is a permission applied to one of the caller's own streams
+// once it exists. Visibility is used by the visibility kinds, Wallet by
+// PermissionAllowReadWallet.
+type DeploymentPermission struct {
+	Kind       DeploymentPermissionKind
+	StreamId   util.StreamId
+	Visibility util.VisibilityEnum
+	Wallet     util.EthereumAddress
+}
+
+// DeploymentPlan describes a set of streams to deploy together with the taxonomies
+// and permissions that complete them. Streams are created with a single
+// BatchDeployStreams transaction; taxonomies and permissions follow in that order.
+type DeploymentPlan struct {
+	Streams     []types.StreamDefinition
+	Taxonomies  []types.Taxonomy
+	Permissions []DeploymentPermission
+}
+
+// IsEmpty reports whether the plan has nothing left to do.
+func (p DeploymentPlan) IsEmpty() bool {
+	return len(p.Streams) == 0 && len(p.Taxonomies) == 0 && len(p.Permissions) == 0
+}
+
+// DeploymentFailureMode decides what ExecuteDeploymentPlan does when a step fails.
+type DeploymentFailureMode int
+
+const (
+	// DeploymentRollback destroys every stream created by the run, leaving the
+	// network as it was before the plan started.
+	DeploymentRollback DeploymentFailureMode = iota
+	// DeploymentResumable keeps whatever succeeded and returns the remaining
+	// work as DeploymentResult.Remaining, which can be passed back to
+	// ExecuteDeploymentPlan once the cause is fixed.
+	DeploymentResumable
+)
+
+// DeploymentOptions configures ExecuteDeploymentPlan. Zero values give rollback
+// on failure and a one second polling interval.
+type DeploymentOptions struct {
+	OnFailure    DeploymentFailureMode
+	WaitInterval time.Duration
+}
+
+// DeploymentStepKind identifies the transaction a step submitted.
+type DeploymentStepKind string
+
+const (
+	StepCreateStreams  DeploymentStepKind = "create_streams"
+	StepInsertTaxonomy DeploymentStepKind = "insert_taxonomy"
+	StepSetPermission  DeploymentStepKind = "set_permission"
+	StepDestroyStream  DeploymentStepKind = "destroy_stream"
+)
+
+// DeploymentStepStatus is the outcome of a single step.
+type DeploymentStepStatus string
+
+const (
+	StepPending   DeploymentStepStatus = "pending"
+	StepSucceeded DeploymentStepStatus = "succeeded"
+	StepFailed    DeploymentStepStatus = "failed"
+)
+
+// DeploymentStep records one transaction of a deployment run.
+type DeploymentStep struct {
+	Kind        DeploymentStepKind
+	Description string
+	TxHash      *kwiltypes.Hash
+	Status      DeploymentStepStatus
+	Err         error
+}
+
+// DeploymentResult is returned by ExecuteDeploymentPlan, including on failure.
+type DeploymentResult struct {
+	// Steps lists every step in submission order, rollback steps last.
+	Steps []DeploymentStep
+	// RolledBack is true when a failure triggered DeploymentRollback and all
+	// created streams were destroyed.
+	RolledBack bool
+	// Remaining holds the work that did not complete. It is empty on success
+	// and after a successful rollback.
+	Remaining DeploymentPlan
+}
+
+// deploymentBackend is the subset of Client used to run a plan.
+type deploymentBackend interface {
+	BatchDeployStreams(ctx context.Context, streamDefs []types.StreamDefinition) (kwiltypes.Hash, error)
+	DestroyStream(ctx context.Context, streamId util.StreamId) (kwiltypes.Hash, error)
+	WaitForTx(ctx context.Context, txHash kwiltypes.Hash, interval time.Duration) (*kwiltypes.TxQueryResponse, error)
+	LoadActions() (types.IAction, error)
+	LoadComposedActions() (types.IComposedAction, error)
+	OwnStreamLocator(streamId util.StreamId) types.StreamLocator
+}
+
+var _ deploymentBackend = (*Client)(nil)
+
+// txWaiter is the part of Client that waitTxSuccess needs.
+type txWaiter interface {
+	WaitForTx(ctx context.Context, txHash kwiltypes.Hash, interval time.Duration) (*kwiltypes.TxQueryResponse, error)
+}
+
+// ExecuteDeploymentPlan deploys streams, taxonomies and permissions as one unit.
+//
+// The plan runs in three stages: create streams, insert taxonomies, apply
+// permissions. Within a stage, transactions are broadcast back to back without
+// waiting, so they land in consecutive nonces. The stage is then awaited as a
+// whole before the next stage starts. If any step fails, later stages are not
+// started. Depending on opts.OnFailure, the run either destroys the created
+// streams or returns the unfinished work in DeploymentResult.Remaining.
+//
+// The returned result is never nil; err is non-nil when the plan did not complete.
+func (c *Client) ExecuteDeploymentPlan(ctx context.Context, plan DeploymentPlan, opts DeploymentOptions) (*DeploymentResult, error) {
+	return runDeploymentPlan(ctx, c, plan, opts)
+}
+
+func runDeploymentPlan(ctx context.Context, backend deploymentBackend, plan DeploymentPlan, opts DeploymentOptions) (*DeploymentResult, error) {
+	if opts.WaitInterval <= 0 {
+		opts.WaitInterval = time.Second
+	}
+	result := &DeploymentResult{}
+
+	// stage 1: create every stream in one transaction
+	var created []types.StreamDefinition
+	if len(plan.Streams) > 0 {
+		step := DeploymentStep{
+			Kind:        StepCreateStreams,
+			Description: fmt.Sprintf("create %d stream(s)", len(plan.Streams)),
+			Status:      StepPending,
+		}
+		hash, err := backend.BatchDeployStreams(ctx, plan.Streams)
+		if err == nil {
+			step.TxHash = &hash
+			err = waitTxSuccess(ctx, backend, hash, opts.WaitInterval)
+		}
+		if err != nil {
+			step.Status, step.Err = StepFailed, err
+			result.Steps = append(result.Steps, step)
+			result.Remaining = plan
+			return result, errors.Wrap(err, "create streams")
+		}
+		step.Status = StepSucceeded
+		result.Steps = append(result.Steps, step)
+		created = plan.Streams
+		logging.Logger.Info("Deployment plan created streams", zap.Int("count", len(created)), zap.String("txHash", hash.String()))
+	}
+
+	// stage 2: taxonomies
+	var failedTaxonomies []types.Taxonomy
+	if len(plan.Taxonomies) > 0 {
+		composed, err := backend.LoadComposedActions()
+		if err != nil {
+			remaining := plan
+			remaining.Streams = nil
+			return result, rollbackOrResume(ctx, backend, result, created, remaining, opts, errors.Wrap(err, "load composed actions"))
+		}
+		submits := make([]func() (kwiltypes.Hash, error), len(plan.Taxonomies))
+		descriptions := make([]string, len(plan.Taxonomies))
+		for i := range plan.Taxonomies {
+			taxonomy := plan.Taxonomies[i]
+			descriptions[i] = fmt.Sprintf("insert taxonomy for %s", taxonomy.ParentStream.StreamId.String())
+			submits[i] = func() (kwiltypes.Hash, error) { return composed.InsertTaxonomy(ctx, taxonomy) }
+		}
+		failed, stageErr := runDeploymentStage(ctx, backend, result, StepInsertTaxonomy, descriptions, submits, opts.WaitInterval)
+		for _, i := range failed {
+			failedTaxonomies = append(failedTaxonomies, plan.Taxonomies[i])
+		}
+		if stageErr != nil {
+			remaining := plan
+			remaining.Streams = nil
+			remaining.Taxonomies = failedTaxonomies
+			return result, rollbackOrResume(ctx, backend, result, created, remaining, opts, errors.Wrap(stageErr, "insert taxonomies"))
+		}
+	}
+
+	// stage 3: permissions
+	if len(plan.Permissions) > 0 {
+		actions, err := backend.LoadActions()
+		if err != nil {
+			remaining := DeploymentPlan{Permissions: plan.Permissions}
+			return result, rollbackOrResume(ctx, backend, result, created, remaining, opts, errors.Wrap(err, "load actions"))
+		}
+		submits := make([]func() (kwiltypes.Hash, error), len(plan.Permissions))
+		descriptions := make([]string, len(plan.Permissions))
+		for i := range plan.Permissions {
+			permission := plan.Permissions[i]
+			locator := backend.OwnStreamLocator(permission.StreamId)
+			descriptions[i] = fmt.Sprintf("set %s on %s", permission.Kind, permission.StreamId.String())
+			submits[i] = func() (kwiltypes.Hash, error) { return applyDeploymentPermission(ctx, actions, locator, permission) }
+		}
+		failed, stageErr := runDeploymentStage(ctx, backend, result, StepSetPermission, descriptions, submits, opts.WaitInterval)
+		if stageErr != nil {
+			remaining := DeploymentPlan{}
+			for _, i := range failed {
+				remaining.Permissions = append(remaining.Permissions, plan.Permissions[i])
+			}
+			return result, rollbackOrResume(ctx, backend, result, created, remaining, opts, errors.Wrap(stageErr, "apply permissions"))
+		}
+	}
+
+	return result, nil
+}
+
+// runDeploymentStage broadcasts every submit without waiting, then waits for all
+// broadcast transactions. It returns the indexes of the items that did not succeed,
+// which includes items never broadcast because an earlier broadcast failed.
+func runDeploymentStage(
+	ctx context.Context,
+	backend deploymentBackend,
+	result *DeploymentResult,
+	kind DeploymentStepKind,
+	descriptions []string,
+	submits []func() (kwiltypes.Hash, error),
+	interval time.Duration,
+) ([]int, error) {
+	first := len(result.Steps)
+	var firstErr error
+	broadcast := 0
+	for i, submit := range submits {
+		step := DeploymentStep{Kind: kind, Description: descriptions[i], Status: StepPending}
+		if firstErr == nil {
+			hash, err := submit()
+			if err != nil {
+				step.Status, step.Err = StepFailed, err
+				firstErr = errors.Wrap(err, descriptions[i])
+			} else {
+				step.TxHash = &hash
+				broadcast++
+			}
+		}
+		result.Steps = append(result.Steps, step)
+	}
+
+	var failed []int
+	for i := range submits {
+		step := &result.Steps[first+i]
+		if step.TxHash != nil {
+			if err := waitTxSuccess(ctx, backend, *step.TxHash, interval); err != nil {
+				step.Status, step.Err = StepFailed, err
+				if firstErr == nil {
+					firstErr = errors.Wrap(err, descriptions[i])
+				}
+			} else {
+				step.Status = StepSucceeded
+			}
+		}
+		if step.Status != StepSucceeded {
+			failed = append(failed, i)
+		}
+	}
+	return failed, firstErr
+}
+
+// rollbackOrResume applies the configured failure mode and returns cause, annotated
+// when the rollback itself did not complete.
+func rollbackOrResume(
+	ctx context.Context,
+	backend deploymentBackend,
+	result *DeploymentResult,
+	created []types.StreamDefinition,
+	remaining DeploymentPlan,
+	opts DeploymentOptions,
+	cause error,
+) error {
+	if opts.OnFailure == DeploymentResumable || len(created) == 0 {
+		result.Remaining = remaining
+		return cause
+	}
+
+	// destroy composed streams before primitives so no parent outlives its children
+	ordered := make([]types.StreamDefinition, 0, len(created))
+	for _, def := range created {
+		if def.StreamType == types.StreamTypeComposed {
+			ordered = append(ordered, def)
+		}
+	}
+	for _, def := range created {
+		if def.StreamType != types.StreamTypeComposed {
+			ordered = append(ordered, def)
+		}
+	}
+
+	submits := make([]func() (kwiltypes.Hash, error), len(ordered))
+	descriptions := make([]string, len(ordered))
+	for i := range ordered {
+		streamId := ordered[i].StreamId
+		descriptions[i] = fmt.Sprintf("destroy %s", streamId.String())
+		submits[i] = func() (kwiltypes.Hash, error) { return backend.DestroyStream(ctx, streamId) }
+	}
+	failed, err := runDeploymentStage(ctx, backend, result, StepDestroyStream, descriptions, submits, opts.WaitInterval)
+	if err != nil {
+		// whatever could not be destroyed is still on chain; hand it back as a plan
+		// for the caller to inspect rather than pretending the rollback worked
+		result.Remaining = remaining
+		for _, i := range failed {
+			result.Remaining.Streams = append(result.Remaining.Streams, ordered[i])
+		}
+		return errors.Wrapf(cause, "rollback incomplete (%v)", err)
+	}
+
+	result.RolledBack = true
+	logging.Logger.Info("Deployment plan rolled back", zap.Int("destroyed", len(ordered)))
+	return errors.Wrap(cause, "deployment rolled back")
+}
+
+func applyDeploymentPermission(ctx context.Context, actions types.IAction, locator types.StreamLocator, permission DeploymentPermission) (kwiltypes.Hash, error) {
+	switch permission.Kind {
+	case PermissionReadVisibility:
+		return actions.SetReadVisibility(ctx, types.VisibilityInput{Stream: locator, Visibility: permission.Visibility})
+	case PermissionComposeVisibility:
+		return actions.SetComposeVisibility(ctx, types.VisibilityInput{Stream: locator, Visibility: permission.Visibility})
+	case PermissionAllowReadWallet:
+		return actions.AllowReadWallet(ctx, types.ReadWalletInput{Stream: locator, Wallet: permission.Wallet})
+	case PermissionAllowComposeStream:
+		return actions.AllowComposeStream(ctx, locator)
+	default:
+		return kwiltypes.Hash{}, errors.Errorf("unknown permission kind %q", permission.Kind)
+	}
+}
+
+func waitTxSuccess(ctx context.Context, backend txWaiter, hash kwiltypes.Hash, interval time.Duration) error {
+	res, err := backend.WaitForTx(ctx, hash, interval)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return tnerrors.FromTxResult("", hash, res)
+}
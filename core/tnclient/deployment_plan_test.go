@@ -0,0 +1,173 @@
+package tnclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/types"
+	"github.com/trufnetwork/sdk-go/core/util"
+)
+
+// fakeDeploymentBackend records every broadcast in order. Hashes are numbered by
+// submission so failing transactions can be selected by position.
+type fakeDeploymentBackend struct {
+	owner      util.EthereumAddress
+	log        []string
+	next       byte
+	failTx     map[byte]bool // tx result code != ok
+	failSubmit map[string]bool
+	actions    *fakeDeploymentActions
+}
+
+func (f *fakeDeploymentBackend) hash(name string) (kwiltypes.Hash, error) {
+	if f.failSubmit[name] {
+		return kwiltypes.Hash{}, errors.New("broadcast rejected")
+	}
+	f.next++
+	f.log = append(f.log, name)
+	var h kwiltypes.Hash
+	h[0] = f.next
+	return h, nil
+}
+
+func (f *fakeDeploymentBackend) BatchDeployStreams(_ context.Context, defs []types.StreamDefinition) (kwiltypes.Hash, error) {
+	return f.hash("create_streams")
+}
+
+func (f *fakeDeploymentBackend) DestroyStream(_ context.Context, streamId util.StreamId) (kwiltypes.Hash, error) {
+	return f.hash("destroy:" + streamId.String())
+}
+
+func (f *fakeDeploymentBackend) WaitForTx(_ context.Context, txHash kwiltypes.Hash, _ time.Duration) (*kwiltypes.TxQueryResponse, error) {
+	code := uint32(kwiltypes.CodeOk)
+	if f.failTx[txHash[0]] {
+		code = uint32(kwiltypes.CodeUnknownError)
+	}
+	return &kwiltypes.TxQueryResponse{Result: &kwiltypes.TxResult{Code: code, Log: "boom"}}, nil
+}
+
+func (f *fakeDeploymentBackend) LoadActions() (types.IAction, error) {
+	return f.actions, nil
+}
+
+func (f *fakeDeploymentBackend) LoadComposedActions() (types.IComposedAction, error) {
+	return f.actions, nil
+}
+
+func (f *fakeDeploymentBackend) OwnStreamLocator(streamId util.StreamId) types.StreamLocator {
+	return types.StreamLocator{StreamId: streamId, DataProvider: f.owner}
+}
+
+type fakeDeploymentActions struct {
+	types.IComposedAction
+	backend *fakeDeploymentBackend
+}
+
+func (a *fakeDeploymentActions) InsertTaxonomy(_ context.Context, taxonomy types.Taxonomy) (kwiltypes.Hash, error) {
+	return a.backend.hash("taxonomy:" + taxonomy.ParentStream.StreamId.String())
+}
+
+func (a *fakeDeploymentActions) SetReadVisibility(_ context.Context, input types.VisibilityInput) (kwiltypes.Hash, error) {
+	return a.backend.hash("read_visibility:" + input.Stream.StreamId.String())
+}
+
+func newFakeDeploymentBackend(t *testing.T) *fakeDeploymentBackend {
+	owner, err := util.NewEthereumAddressFromString("0x000000000000000000000000000000000000a110")
+	require.NoError(t, err)
+	f := &fakeDeploymentBackend{owner: owner, failTx: map[byte]bool{}, failSubmit: map[string]bool{}}
+	f.actions = &fakeDeploymentActions{backend: f}
+	return f
+}
+
+func testDeploymentPlan(f *fakeDeploymentBackend) DeploymentPlan {
+	prim := util.GenerateStreamId("prim")
+	comp := util.GenerateStreamId("comp")
+	return DeploymentPlan{
+		Streams: []types.StreamDefinition{
+			{StreamId: prim, StreamType: types.StreamTypePrimitive},
+			{StreamId: comp, StreamType: types.StreamTypeComposed},
+		},
+		Taxonomies: []types.Taxonomy{{
+			ParentStream:  f.OwnStreamLocator(comp),
+			TaxonomyItems: []types.TaxonomyItem{{ChildStream: f.OwnStreamLocator(prim), Weight: 1}},
+		}},
+		Permissions: []DeploymentPermission{
+			{Kind: PermissionReadVisibility, StreamId: comp, Visibility: util.PrivateVisibility},
+		},
+	}
+}
+
+func TestDeploymentPlan_RunsStagesInOrder(t *testing.T) {
+	f := newFakeDeploymentBackend(t)
+	plan := testDeploymentPlan(f)
+
+	result, err := runDeploymentPlan(context.Background(), f, plan, DeploymentOptions{WaitInterval: time.Millisecond})
+	require.NoError(t, err)
+
+	comp := plan.Streams[1].StreamId.String()
+	assert.Equal(t, []string{"create_streams", "taxonomy:" + comp, "read_visibility:" + comp}, f.log)
+	require.Len(t, result.Steps, 3)
+	for _, step := range result.Steps {
+		assert.Equal(t, StepSucceeded, step.Status)
+		assert.NotNil(t, step.TxHash)
+	}
+	assert.True(t, result.Remaining.IsEmpty())
+	assert.False(t, result.RolledBack)
+}
+
+func TestDeploymentPlan_RollsBackComposedFirst(t *testing.T) {
+	f := newFakeDeploymentBackend(t)
+	plan := testDeploymentPlan(f)
+	f.failTx[2] = true // the taxonomy transaction
+
+	result, err := runDeploymentPlan(context.Background(), f, plan, DeploymentOptions{WaitInterval: time.Millisecond})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rolled back")
+	assert.True(t, result.RolledBack)
+	assert.True(t, result.Remaining.IsEmpty())
+
+	prim, comp := plan.Streams[0].StreamId.String(), plan.Streams[1].StreamId.String()
+	assert.Equal(t, []string{"create_streams", "taxonomy:" + comp, "destroy:" + comp, "destroy:" + prim}, f.log,
+		"permissions must not be attempted and composed streams are destroyed before primitives")
+	assert.Equal(t, StepFailed, result.Steps[1].Status)
+}
+
+func TestDeploymentPlan_ResumableReturnsRemainingWork(t *testing.T) {
+	f := newFakeDeploymentBackend(t)
+	plan := testDeploymentPlan(f)
+	f.failSubmit["taxonomy:"+plan.Streams[1].StreamId.String()] = true
+
+	result, err := runDeploymentPlan(context.Background(), f, plan, DeploymentOptions{
+		OnFailure:    DeploymentResumable,
+		WaitInterval: time.Millisecond,
+	})
+	require.Error(t, err)
+	assert.False(t, result.RolledBack)
+	assert.Empty(t, result.Remaining.Streams, "created streams are not redeployed on resume")
+	assert.Equal(t, plan.Taxonomies, result.Remaining.Taxonomies)
+	assert.Equal(t, plan.Permissions, result.Remaining.Permissions)
+
+	// resuming with the fault cleared finishes the remaining work only
+	delete(f.failSubmit, "taxonomy:"+plan.Streams[1].StreamId.String())
+	f.log = nil
+	result, err = runDeploymentPlan(context.Background(), f, result.Remaining, DeploymentOptions{WaitInterval: time.Millisecond})
+	require.NoError(t, err)
+	assert.Len(t, f.log, 2)
+	assert.True(t, result.Remaining.IsEmpty())
+}
+
+func TestDeploymentPlan_CreateFailureLeavesNothingToRollBack(t *testing.T) {
+	f := newFakeDeploymentBackend(t)
+	plan := testDeploymentPlan(f)
+	f.failTx[1] = true
+
+	result, err := runDeploymentPlan(context.Background(), f, plan, DeploymentOptions{WaitInterval: time.Millisecond})
+	require.Error(t, err)
+	assert.Equal(t, []string{"create_streams"}, f.log)
+	assert.Equal(t, plan, result.Remaining)
+}
@@ -19,11 +19,11 @@ func (c *Client) ListStreams(ctx context.Context, input types.ListStreamsInput)
 	args = append(args, input.BlockHeight)
 
 	result, err := c.transport.Call(ctx, "", "list_streams", args)
-	if err != nil || result.Error != nil {
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
-		return nil, errors.New(*result.Error)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := callResultError("list_streams", args, result); err != nil {
+		return nil, err
 	}
 
 	return contractsapi.DecodeCallResult[types.ListStreamsOutput](result.QueryResult)
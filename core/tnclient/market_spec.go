@@ -0,0 +1,63 @@
+package tnclient
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	kwilClientType "github.com/trufnetwork/kwil-db/core/client/types"
+	"github.com/trufnetwork/sdk-go/core/contractsapi"
+	"github.com/trufnetwork/sdk-go/core/types"
+	"github.com/trufnetwork/sdk-go/core/util"
+)
+
+// marketBackend is the part of Client CreateMarketFromSpec uses.
+type marketBackend interface {
+	txWaiter
+	LoadActions() (types.IAction, error)
+	LoadOrderBook() (types.IOrderBook, error)
+	Address() util.EthereumAddress
+}
+
+var _ marketBackend = (*Client)(nil)
+
+// CreateMarketFromSpec validates spec against the network as the client's
+// wallet, creates the market, waits for the transaction and returns the new
+// market. Nothing is submitted when validation fails; the returned error is
+// then a *contractsapi.MarketValidationError listing every problem found.
+func (c *Client) CreateMarketFromSpec(ctx context.Context, spec *contractsapi.MarketSpec, opts ...kwilClientType.TxOpt) (*types.MarketInfo, error) {
+	return runCreateMarketFromSpec(ctx, c, spec, time.Second, opts...)
+}
+
+func runCreateMarketFromSpec(ctx context.Context, backend marketBackend, spec *contractsapi.MarketSpec, interval time.Duration, opts ...kwilClientType.TxOpt) (*types.MarketInfo, error) {
+	if spec == nil {
+		return nil, errors.New("market spec is required")
+	}
+	actions, err := backend.LoadActions()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ob, err := backend.LoadOrderBook()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	input, err := spec.Validate(ctx, actions, ob, backend.Address())
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := ob.CreateMarket(ctx, input, opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "create market")
+	}
+	if err := waitTxSuccess(ctx, backend, hash, interval); err != nil {
+		return nil, errors.Wrap(err, "create market")
+	}
+
+	info, err := ob.GetMarketByHash(ctx, types.GetMarketByHashInput{QueryHash: contractsapi.QueryHash(input.QueryComponents)})
+	if err != nil {
+		return nil, errors.Wrap(err, "get created market")
+	}
+	return info, nil
+}
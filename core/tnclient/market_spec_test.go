@@ -0,0 +1,108 @@
+package tnclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kwilClientType "github.com/trufnetwork/kwil-db/core/client/types"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/contractsapi"
+	"github.com/trufnetwork/sdk-go/core/types"
+	"github.com/trufnetwork/sdk-go/core/util"
+)
+
+// fakeMarketBackend serves one public stream and an empty order book. Any
+// other IAction or IOrderBook method panics through the nil embedded
+// interfaces.
+type fakeMarketBackend struct {
+	types.IAction
+	types.IOrderBook
+
+	txCode  uint32
+	created []types.CreateMarketInput
+}
+
+func (f *fakeMarketBackend) LoadActions() (types.IAction, error)      { return f, nil }
+func (f *fakeMarketBackend) LoadOrderBook() (types.IOrderBook, error) { return f, nil }
+func (f *fakeMarketBackend) Address() util.EthereumAddress {
+	return util.Unsafe_NewEthereumAddressFromString("0x2222222222222222222222222222222222222222")
+}
+func (f *fakeMarketBackend) GetWalletBalance(context.Context, string, string) (string, error) {
+	return "1000", nil
+}
+
+func (f *fakeMarketBackend) WaitForTx(_ context.Context, hash kwiltypes.Hash, _ time.Duration) (*kwiltypes.TxQueryResponse, error) {
+	return &kwiltypes.TxQueryResponse{Hash: hash, Result: &kwiltypes.TxResult{Code: f.txCode, Log: "market rejected"}}, nil
+}
+
+func (f *fakeMarketBackend) BatchStreamExists(_ context.Context, locators []types.StreamLocator) ([]types.StreamExistsResult, error) {
+	out := make([]types.StreamExistsResult, len(locators))
+	for i, l := range locators {
+		out[i] = types.StreamExistsResult{StreamLocator: l, Exists: true}
+	}
+	return out, nil
+}
+
+func (f *fakeMarketBackend) GetReadVisibility(context.Context, types.StreamLocator) (*util.VisibilityEnum, error) {
+	v := util.PublicVisibility
+	return &v, nil
+}
+
+func (f *fakeMarketBackend) GetRecord(context.Context, types.GetRecordInput) (types.ActionResult, error) {
+	d, _, err := apd.NewFromString("100")
+	return types.ActionResult{Results: []types.StreamResult{{EventTime: 1, Value: *d}}}, err
+}
+
+func (f *fakeMarketBackend) MarketExists(_ context.Context, input types.MarketExistsInput) (bool, error) {
+	for _, c := range f.created {
+		if bytes.Equal(contractsapi.QueryHash(c.QueryComponents), input.QueryHash) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeMarketBackend) CreateMarket(_ context.Context, input types.CreateMarketInput, _ ...kwilClientType.TxOpt) (kwiltypes.Hash, error) {
+	f.created = append(f.created, input)
+	return kwiltypes.Hash{byte(len(f.created))}, nil
+}
+
+func (f *fakeMarketBackend) GetMarketByHash(_ context.Context, input types.GetMarketByHashInput) (*types.MarketInfo, error) {
+	for i, c := range f.created {
+		if bytes.Equal(contractsapi.QueryHash(c.QueryComponents), input.QueryHash) {
+			return &types.MarketInfo{ID: i + 1, Hash: input.QueryHash, Bridge: c.Bridge}, nil
+		}
+	}
+	return nil, errors.New("market not found")
+}
+
+func TestCreateMarketFromSpec(t *testing.T) {
+	ctx := context.Background()
+	streamID := util.GenerateStreamId("market_spec")
+	ts := time.Now().Add(time.Hour).Unix()
+	spec := contractsapi.NewPriceAboveThresholdSpec("0x1111111111111111111111111111111111111111", streamID.String(), ts, "110").
+		WithBridge("hoodi_tt2").WithSettleTime(ts + 60).WithMaxSpread(5).WithMinOrderSize(10)
+
+	backend := &fakeMarketBackend{}
+	info, err := runCreateMarketFromSpec(ctx, backend, spec, time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, 1, info.ID)
+	assert.Equal(t, "hoodi_tt2", info.Bridge)
+
+	// The same query is now taken: nothing is submitted.
+	_, err = runCreateMarketFromSpec(ctx, backend, spec, time.Millisecond)
+	var verr *contractsapi.MarketValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.True(t, verr.HasProblem(contractsapi.MarketProblemMarketExists))
+	assert.Len(t, backend.created, 1)
+
+	failing := &fakeMarketBackend{txCode: uint32(kwiltypes.CodeUnknownError)}
+	_, err = runCreateMarketFromSpec(ctx, failing, spec, time.Millisecond)
+	assert.ErrorContains(t, err, "market rejected")
+}
@@ -0,0 +1,35 @@
+package tnclient
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/trufnetwork/sdk-go/core/orderbook/batch"
+)
+
+// LoadOrderReplacer wires up a batch.Replacer for the client's wallet.
+// Requires HTTP transport, since the pipelined nonces need direct GetAccount
+// access on the gateway client.
+func (c *Client) LoadOrderReplacer(opts ...batch.Option) (*batch.Replacer, error) {
+	kwilClient := c.GetKwilClient()
+	if kwilClient == nil {
+		return nil, errors.New("ReplaceOrders requires HTTP transport (GetKwilClient returned nil)")
+	}
+	ob, err := c.LoadOrderBook()
+	if err != nil {
+		return nil, err
+	}
+	return batch.New(ob, kwilClient, c.transport.Signer(), opts...)
+}
+
+// ReplaceOrders makes desired the client's complete set of orders in market
+// queryID, cancelling, moving and placing orders with pipelined nonces. See
+// batch.Replacer.ReplaceOrders; callers replacing orders repeatedly should
+// keep the Replacer from LoadOrderReplacer instead.
+func (c *Client) ReplaceOrders(ctx context.Context, queryID int, desired []batch.OrderSpec, opts ...batch.Option) (*batch.Report, error) {
+	r, err := c.LoadOrderReplacer(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return r.ReplaceOrders(ctx, queryID, desired)
+}
@@ -0,0 +1,328 @@
+package tnclient
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/logging"
+	"github.com/trufnetwork/sdk-go/core/types"
+	"github.com/trufnetwork/sdk-go/core/util"
+	"go.uber.org/zap"
+)
+
+type roleOptions struct {
+	batchSize    int
+	pageSize     int
+	waitInterval time.Duration
+}
+
+// RoleOption configures SyncRoleMembers and SnapshotRoleMembers.
+type RoleOption func(*roleOptions)
+
+// WithRoleBatchSize sets how many wallets go into each grant_roles or
+// revoke_roles transaction. Default: 100.
+func WithRoleBatchSize(n int) RoleOption {
+	return func(o *roleOptions) {
+		if n > 0 {
+			o.batchSize = n
+		}
+	}
+}
+
+// WithRolePageSize sets the list_role_members page size. Default: 100.
+func WithRolePageSize(n int) RoleOption {
+	return func(o *roleOptions) {
+		if n > 0 {
+			o.pageSize = n
+		}
+	}
+}
+
+// WithRoleWaitInterval sets the polling interval used while waiting for
+// transactions. Default: 1s.
+func WithRoleWaitInterval(d time.Duration) RoleOption {
+	return func(o *roleOptions) {
+		if d > 0 {
+			o.waitInterval = d
+		}
+	}
+}
+
+func newRoleOptions(opts []RoleOption) roleOptions {
+	o := roleOptions{batchSize: 100, pageSize: 100, waitInterval: time.Second}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// RoleMembershipSnapshot is the full member list of a role at TakenAt.
+type RoleMembershipSnapshot struct {
+	Role    types.RoleRef
+	TakenAt time.Time
+	Members []types.RoleMember
+}
+
+type roleMemberExport struct {
+	Wallet    string `json:"wallet"`
+	GrantedAt int64  `json:"granted_at"`
+	GrantedBy string `json:"granted_by"`
+}
+
+// JSON renders the snapshot as indented JSON.
+func (s *RoleMembershipSnapshot) JSON() ([]byte, error) {
+	members := make([]roleMemberExport, len(s.Members))
+	for i, m := range s.Members {
+		members[i] = roleMemberExport{Wallet: m.Wallet.Address(), GrantedAt: m.GrantedAt, GrantedBy: m.GrantedBy}
+	}
+	return json.MarshalIndent(struct {
+		Owner    string             `json:"owner"`
+		RoleName string             `json:"role_name"`
+		TakenAt  time.Time          `json:"taken_at"`
+		Members  []roleMemberExport `json:"members"`
+	}{s.Role.Owner, s.Role.RoleName, s.TakenAt, members}, "", "  ")
+}
+
+// WriteCSV writes one wallet,granted_at,granted_by row per member after a header row.
+func (s *RoleMembershipSnapshot) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"wallet", "granted_at", "granted_by"}); err != nil {
+		return errors.WithStack(err)
+	}
+	for _, m := range s.Members {
+		if err := cw.Write([]string{m.Wallet.Address(), strconv.FormatInt(m.GrantedAt, 10), m.GrantedBy}); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	cw.Flush()
+	return errors.WithStack(cw.Error())
+}
+
+// RoleSyncResult reports what SyncRoleMembers changed. TxHashes holds the
+// grant transactions followed by the revoke transactions.
+type RoleSyncResult struct {
+	Role      types.RoleRef
+	Granted   []util.EthereumAddress
+	Revoked   []util.EthereumAddress
+	Unchanged []util.EthereumAddress
+	TxHashes  []kwiltypes.Hash
+}
+
+// MissingRoleError reports the roles a wallet lacks for an operation.
+type MissingRoleError struct {
+	Wallet    util.EthereumAddress
+	Operation string
+	Missing   []types.RoleRef
+}
+
+func (e *MissingRoleError) Error() string {
+	names := make([]string, len(e.Missing))
+	for i, r := range e.Missing {
+		names[i] = r.String()
+	}
+	return fmt.Sprintf("wallet %s lacks role %s required to %s", e.Wallet.Address(), strings.Join(names, ", "), e.Operation)
+}
+
+// roleBackend is the part of Client the role helpers use.
+type roleBackend interface {
+	txWaiter
+	LoadRoleManagementActions() (types.IRoleManagement, error)
+}
+
+var _ roleBackend = (*Client)(nil)
+
+// SnapshotRoleMembers pages through list_role_members and returns every
+// member of owner:role with GrantedAt and GrantedBy.
+func (c *Client) SnapshotRoleMembers(ctx context.Context, owner, role string, opts ...RoleOption) (*RoleMembershipSnapshot, error) {
+	roles, err := c.LoadRoleManagementActions()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return snapshotRoleMembers(ctx, roles, types.RoleRef{Owner: owner, RoleName: role}, newRoleOptions(opts))
+}
+
+func snapshotRoleMembers(ctx context.Context, roles types.IRoleManagement, ref types.RoleRef, o roleOptions) (*RoleMembershipSnapshot, error) {
+	snapshot := &RoleMembershipSnapshot{Role: ref, TakenAt: time.Now().UTC()}
+	seen := make(map[string]bool)
+	for offset := 0; ; offset += o.pageSize {
+		page, err := roles.ListRoleMembers(ctx, types.ListRoleMembersInput{
+			Owner:    ref.Owner,
+			RoleName: ref.RoleName,
+			Limit:    o.pageSize,
+			Offset:   offset,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "list members of %s at offset %d", ref, offset)
+		}
+		if len(page) == 0 {
+			break
+		}
+		for _, m := range page {
+			if addr := m.Wallet.Address(); !seen[addr] {
+				seen[addr] = true
+				snapshot.Members = append(snapshot.Members, m)
+			}
+		}
+	}
+	return snapshot, nil
+}
+
+// SyncRoleMembers makes the members of owner:role exactly desired. Missing
+// wallets are granted and extra wallets are revoked, in batches of
+// WithRoleBatchSize wallets per transaction. All transactions are broadcast
+// before any is awaited.
+//
+// On failure the returned result lists the wallets of the transactions that
+// did succeed.
+func (c *Client) SyncRoleMembers(ctx context.Context, owner, role string, desired []util.EthereumAddress, opts ...RoleOption) (*RoleSyncResult, error) {
+	return runRoleSync(ctx, c, types.RoleRef{Owner: owner, RoleName: role}, desired, newRoleOptions(opts))
+}
+
+func runRoleSync(ctx context.Context, backend roleBackend, ref types.RoleRef, desired []util.EthereumAddress, o roleOptions) (*RoleSyncResult, error) {
+	roles, err := backend.LoadRoleManagementActions()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	snapshot, err := snapshotRoleMembers(ctx, roles, ref, o)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string]bool, len(snapshot.Members))
+	for _, m := range snapshot.Members {
+		current[m.Wallet.Address()] = true
+	}
+
+	result := &RoleSyncResult{Role: ref}
+	var grant, revoke []util.EthereumAddress
+	wanted := make(map[string]bool, len(desired))
+	for _, w := range desired {
+		addr := w.Address()
+		if wanted[addr] {
+			continue
+		}
+		wanted[addr] = true
+		if current[addr] {
+			result.Unchanged = append(result.Unchanged, w)
+		} else {
+			grant = append(grant, w)
+		}
+	}
+	for _, m := range snapshot.Members {
+		if !wanted[m.Wallet.Address()] {
+			revoke = append(revoke, m.Wallet)
+		}
+	}
+	sort.Slice(revoke, func(i, j int) bool { return revoke[i].Address() < revoke[j].Address() })
+
+	type roleBatch struct {
+		revoke  bool
+		wallets []util.EthereumAddress
+		hash    kwiltypes.Hash
+	}
+	var batches []roleBatch
+	for _, chunk := range chunkWallets(grant, o.batchSize) {
+		batches = append(batches, roleBatch{wallets: chunk})
+	}
+	for _, chunk := range chunkWallets(revoke, o.batchSize) {
+		batches = append(batches, roleBatch{revoke: true, wallets: chunk})
+	}
+
+	for i := range batches {
+		b := &batches[i]
+		if b.revoke {
+			b.hash, err = roles.RevokeRole(ctx, types.RevokeRoleInput{Owner: ref.Owner, RoleName: ref.RoleName, Wallets: b.wallets})
+		} else {
+			b.hash, err = roles.GrantRole(ctx, types.GrantRoleInput{Owner: ref.Owner, RoleName: ref.RoleName, Wallets: b.wallets})
+		}
+		if err != nil {
+			batches = batches[:i]
+			err = errors.Wrapf(err, "broadcast %s batch %d", ref, i)
+			break
+		}
+	}
+
+	for _, b := range batches {
+		if waitErr := waitTxSuccess(ctx, backend, b.hash, o.waitInterval); waitErr != nil {
+			if err == nil {
+				err = errors.Wrapf(waitErr, "sync %s", ref)
+			}
+			continue
+		}
+		result.TxHashes = append(result.TxHashes, b.hash)
+		if b.revoke {
+			result.Revoked = append(result.Revoked, b.wallets...)
+		} else {
+			result.Granted = append(result.Granted, b.wallets...)
+		}
+	}
+
+	logging.Logger.Info("Synced role members",
+		zap.String("role", ref.String()),
+		zap.Int("granted", len(result.Granted)),
+		zap.Int("revoked", len(result.Revoked)))
+	return result, err
+}
+
+func chunkWallets(wallets []util.EthereumAddress, size int) [][]util.EthereumAddress {
+	var chunks [][]util.EthereumAddress
+	for start := 0; start < len(wallets); start += size {
+		end := start + size
+		if end > len(wallets) {
+			end = len(wallets)
+		}
+		chunks = append(chunks, wallets[start:end])
+	}
+	return chunks
+}
+
+// MissingRoles returns the roles in required that wallet is not a member of.
+func (c *Client) MissingRoles(ctx context.Context, wallet util.EthereumAddress, required ...types.RoleRef) ([]types.RoleRef, error) {
+	roles, err := c.LoadRoleManagementActions()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return missingRoles(ctx, roles, wallet, required)
+}
+
+func missingRoles(ctx context.Context, roles types.IRoleManagement, wallet util.EthereumAddress, required []types.RoleRef) ([]types.RoleRef, error) {
+	var missing []types.RoleRef
+	for _, ref := range required {
+		res, err := roles.AreMembersOf(ctx, types.AreMembersOfInput{
+			Owner:    ref.Owner,
+			RoleName: ref.RoleName,
+			Wallets:  []util.EthereumAddress{wallet},
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "check membership of %s", ref)
+		}
+		if len(res) == 0 || !res[0].IsMember {
+			missing = append(missing, ref)
+		}
+	}
+	return missing, nil
+}
+
+// CheckDeployPermission reports whether the client's wallet may deploy streams.
+// It returns a *MissingRoleError naming the role to request when it may not,
+// so callers can fail before broadcasting DeployStream.
+func (c *Client) CheckDeployPermission(ctx context.Context) error {
+	wallet := c.Address()
+	missing, err := c.MissingRoles(ctx, wallet, types.NetworkWriterRole)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return &MissingRoleError{Wallet: wallet, Operation: "deploy streams", Missing: missing}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package tnclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kwilClientType "github.com/trufnetwork/kwil-db/core/client/types"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/types"
+	"github.com/trufnetwork/sdk-go/core/util"
+)
+
+type fakeRoleBackend struct {
+	members   []types.RoleMember
+	listCalls int
+	grants    [][]util.EthereumAddress
+	revokes   [][]util.EthereumAddress
+}
+
+func (f *fakeRoleBackend) LoadRoleManagementActions() (types.IRoleManagement, error) { return f, nil }
+
+func (f *fakeRoleBackend) WaitForTx(_ context.Context, hash kwiltypes.Hash, _ time.Duration) (*kwiltypes.TxQueryResponse, error) {
+	return &kwiltypes.TxQueryResponse{Hash: hash, Result: &kwiltypes.TxResult{Code: uint32(kwiltypes.CodeOk)}}, nil
+}
+
+func (f *fakeRoleBackend) GrantRole(_ context.Context, input types.GrantRoleInput, _ ...kwilClientType.TxOpt) (kwiltypes.Hash, error) {
+	f.grants = append(f.grants, input.Wallets)
+	return kwiltypes.Hash{byte(len(f.grants))}, nil
+}
+
+func (f *fakeRoleBackend) RevokeRole(_ context.Context, input types.RevokeRoleInput, _ ...kwilClientType.TxOpt) (kwiltypes.Hash, error) {
+	f.revokes = append(f.revokes, input.Wallets)
+	return kwiltypes.Hash{0x80 | byte(len(f.revokes))}, nil
+}
+
+func (f *fakeRoleBackend) AreMembersOf(_ context.Context, input types.AreMembersOfInput) ([]types.RoleMembershipResult, error) {
+	if input.RoleName == "broken" {
+		return nil, errors.New("node unavailable")
+	}
+	var out []types.RoleMembershipResult
+	for _, w := range input.Wallets {
+		isMember := false
+		for _, m := range f.members {
+			if m.Wallet.Address() == w.Address() {
+				isMember = true
+			}
+		}
+		out = append(out, types.RoleMembershipResult{Wallet: w, IsMember: isMember})
+	}
+	return out, nil
+}
+
+func (f *fakeRoleBackend) ListRoleMembers(_ context.Context, input types.ListRoleMembersInput) ([]types.RoleMember, error) {
+	f.listCalls++
+	if input.Offset >= len(f.members) {
+		return nil, nil
+	}
+	end := input.Offset + input.Limit
+	if end > len(f.members) {
+		end = len(f.members)
+	}
+	return f.members[input.Offset:end], nil
+}
+
+func roleWallets(t *testing.T, n int) []util.EthereumAddress {
+	t.Helper()
+	out := make([]util.EthereumAddress, n)
+	for i := range out {
+		w, err := util.NewEthereumAddressFromBytes(append(make([]byte, 19), byte(i+1)))
+		require.NoError(t, err)
+		out[i] = w
+	}
+	return out
+}
+
+func TestSyncRoleMembers(t *testing.T) {
+	w := roleWallets(t, 6)
+	f := &fakeRoleBackend{members: []types.RoleMember{
+		{Wallet: w[0], GrantedAt: 10, GrantedBy: "0xmanager"},
+		{Wallet: w[1], GrantedAt: 11, GrantedBy: "0xmanager"},
+		{Wallet: w[2], GrantedAt: 12, GrantedBy: "0xmanager"},
+	}}
+
+	o := newRoleOptions([]RoleOption{WithRoleBatchSize(2), WithRolePageSize(2), WithRoleWaitInterval(time.Millisecond)})
+	result, err := runRoleSync(context.Background(), f, types.NetworkWriterRole, []util.EthereumAddress{w[1], w[3], w[4], w[5], w[3]}, o)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, f.listCalls)
+	assert.Equal(t, [][]util.EthereumAddress{{w[3], w[4]}, {w[5]}}, f.grants)
+	assert.Equal(t, [][]util.EthereumAddress{{w[0], w[2]}}, f.revokes)
+	assert.Equal(t, []util.EthereumAddress{w[1]}, result.Unchanged)
+	assert.Equal(t, []util.EthereumAddress{w[3], w[4], w[5]}, result.Granted)
+	assert.Equal(t, []util.EthereumAddress{w[0], w[2]}, result.Revoked)
+	assert.Len(t, result.TxHashes, 3)
+}
+
+func TestSnapshotRoleMembers_Export(t *testing.T) {
+	w := roleWallets(t, 3)
+	f := &fakeRoleBackend{members: []types.RoleMember{
+		{Wallet: w[0], GrantedAt: 100, GrantedBy: "0xmanager"},
+		{Wallet: w[1], GrantedAt: 200, GrantedBy: "0xmanager"},
+		{Wallet: w[2], GrantedAt: 300, GrantedBy: "0xother"},
+	}}
+
+	snapshot, err := snapshotRoleMembers(context.Background(), f, types.NetworkWriterRole, newRoleOptions([]RoleOption{WithRolePageSize(2)}))
+	require.NoError(t, err)
+	require.Len(t, snapshot.Members, 3)
+
+	raw, err := snapshot.JSON()
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), `"role_name": "network_writer"`)
+	assert.Contains(t, string(raw), `"wallet": "`+w[2].Address()+`"`)
+	assert.Contains(t, string(raw), `"granted_at": 300`)
+
+	var buf bytes.Buffer
+	require.NoError(t, snapshot.WriteCSV(&buf))
+	assert.Equal(t, "wallet,granted_at,granted_by\n"+
+		w[0].Address()+",100,0xmanager\n"+
+		w[1].Address()+",200,0xmanager\n"+
+		w[2].Address()+",300,0xother\n", buf.String())
+}
+
+func TestMissingRoles(t *testing.T) {
+	w := roleWallets(t, 2)
+	f := &fakeRoleBackend{members: []types.RoleMember{{Wallet: w[0]}}}
+
+	missing, err := missingRoles(context.Background(), f, w[0], []types.RoleRef{types.NetworkWriterRole})
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+
+	missing, err = missingRoles(context.Background(), f, w[1], []types.RoleRef{types.NetworkWriterRole})
+	require.NoError(t, err)
+	assert.Equal(t, []types.RoleRef{types.NetworkWriterRole}, missing)
+
+	merr := &MissingRoleError{Wallet: w[1], Operation: "deploy streams", Missing: missing}
+	assert.Contains(t, merr.Error(), "lacks role system:network_writer required to deploy streams")
+
+	_, err = missingRoles(context.Background(), f, w[1], []types.RoleRef{{Owner: "system", RoleName: "broken"}})
+	assert.ErrorContains(t, err, "node unavailable")
+}
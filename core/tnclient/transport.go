@@ -7,6 +7,7 @@ import (
 	clientType "github.com/trufnetwork/kwil-db/core/client/types"
 	"github.com/trufnetwork/kwil-db/core/crypto/auth"
 	"github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/tnerrors"
 )
 
 // Transport abstracts the communication layer for TRUF.NETWORK operations.
@@ -46,8 +47,9 @@ type Transport interface {
 	//   - inputs: Action input parameters as a slice of any
 	//
 	// Returns:
-	//   - CallResult containing the query results
-	//   - Error if the call fails or returns an application error
+	//   - CallResult containing the query results, or the application error
+	//     raised by the action in CallResult.Error
+	//   - Error if the call itself fails
 	Call(ctx context.Context, namespace string, action string, inputs []any) (*types.CallResult, error)
 
 	// Execute performs a write action and returns the transaction hash.
@@ -93,3 +95,21 @@ type Transport interface {
 	//   - Signer instance for transaction signing
 	Signer() auth.Signer
 }
+
+// callResultError returns the application error reported in result as a
+// *tnerrors.Error, or nil.
+func callResultError(action string, args []any, result *types.CallResult) error {
+	if result == nil || result.Error == nil {
+		return nil
+	}
+	return tnerrors.New(tnerrors.OpCall, action, *result.Error, tnerrors.WithArgs(args))
+}
+
+// withFirstTuple records the stream of the first argument tuple of an Execute
+// call, for the error returned when it fails.
+func withFirstTuple(inputs [][]any) tnerrors.Option {
+	if len(inputs) == 0 {
+		return func(*tnerrors.Error) {}
+	}
+	return tnerrors.WithArgs(inputs[0])
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/trufnetwork/kwil-db/core/rpc/client/gateway"
 	jsonrpc "github.com/trufnetwork/kwil-db/core/rpc/json"
 	"github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/tnerrors"
 
 	"github.com/smartcontractkit/cre-sdk-go/capabilities/networking/http"
 	"github.com/smartcontractkit/cre-sdk-go/cre"
@@ -272,7 +273,7 @@ func (t *CRETransport) doJSONRPC(ctx context.Context, method string, params any,
 
 	// Check HTTP status
 	if httpResp.StatusCode != 200 {
-		return fmt.Errorf("unexpected HTTP status code: %d", httpResp.StatusCode)
+		return httpStatusError(httpResp.StatusCode)
 	}
 
 	// Parse JSON-RPC response
@@ -283,20 +284,7 @@ func (t *CRETransport) doJSONRPC(ctx context.Context, method string, params any,
 
 	// Check for JSON-RPC errors
 	if rpcResp.Error != nil {
-		// For broadcast errors (-201), decode the BroadcastError details
-		if rpcResp.Error.Code == -201 && len(rpcResp.Error.Data) > 0 {
-			var broadcastErr struct {
-				Code    uint32 `json:"code"`
-				Hash    string `json:"hash"`
-				Message string `json:"message"`
-			}
-			if err := json.Unmarshal(rpcResp.Error.Data, &broadcastErr); err == nil {
-				return fmt.Errorf("JSON-RPC error: %s (code: %d) [Broadcast: code=%d, hash=%s, msg=%s]",
-					rpcResp.Error.Message, rpcResp.Error.Code,
-					broadcastErr.Code, broadcastErr.Hash, broadcastErr.Message)
-			}
-		}
-		return fmt.Errorf("JSON-RPC error: %s (code: %d)", rpcResp.Error.Message, rpcResp.Error.Code)
+		return jsonRPCError(rpcResp.Error)
 	}
 
 	// Verify JSON-RPC version
@@ -353,7 +341,7 @@ func (t *CRETransport) Call(ctx context.Context, namespace string, action string
 
 	var result types.CallResult
 	if err := t.callJSONRPC(ctx, "user.call", callMsg, &result); err != nil {
-		return nil, err
+		return nil, tnerrors.Wrap(err, tnerrors.OpCall, action, tnerrors.WithArgs(inputs))
 	}
 
 	return &result, nil
@@ -375,14 +363,14 @@ func (t *CRETransport) Execute(ctx context.Context, namespace string, action str
 		txHash, err := t.executeOnce(ctx, namespace, action, inputs, opts...)
 		if err != nil {
 			// Check if it's a nonce error
-			if strings.Contains(err.Error(), "invalid nonce") && attempt < maxRetries-1 {
+			if tnerrors.Classify(err) == tnerrors.ErrInvalidNonce && attempt < maxRetries-1 {
 				// Reset nonce tracking to refetch on next attempt
 				t.nonceMu.Lock()
 				t.nonceFetched = false
 				t.nonceMu.Unlock()
 				continue // Retry
 			}
-			return types.Hash{}, err
+			return types.Hash{}, tnerrors.Wrap(err, tnerrors.OpExecute, action, withFirstTuple(inputs))
 		}
 		return txHash, nil
 	}
@@ -574,7 +562,7 @@ func (t *CRETransport) executeOnce(ctx context.Context, namespace string, action
 
 	// Check HTTP status
 	if httpResp.StatusCode != 200 {
-		return types.Hash{}, fmt.Errorf("unexpected HTTP status code: %d", httpResp.StatusCode)
+		return types.Hash{}, httpStatusError(httpResp.StatusCode)
 	}
 
 	// Parse JSON-RPC response
@@ -585,20 +573,7 @@ func (t *CRETransport) executeOnce(ctx context.Context, namespace string, action
 
 	// Check for JSON-RPC errors
 	if rpcResp.Error != nil {
-		// For broadcast errors (-201), decode the BroadcastError details
-		if rpcResp.Error.Code == -201 && len(rpcResp.Error.Data) > 0 {
-			var broadcastErr struct {
-				Code    uint32 `json:"code"`
-				Hash    string `json:"hash"`
-				Message string `json:"message"`
-			}
-			if err := json.Unmarshal(rpcResp.Error.Data, &broadcastErr); err == nil {
-				return types.Hash{}, fmt.Errorf("JSON-RPC error: %s (code: %d) [Broadcast: code=%d, hash=%s, msg=%s]",
-					rpcResp.Error.Message, rpcResp.Error.Code,
-					broadcastErr.Code, broadcastErr.Hash, broadcastErr.Message)
-			}
-		}
-		return types.Hash{}, fmt.Errorf("JSON-RPC error: %s (code: %d)", rpcResp.Error.Message, rpcResp.Error.Code)
+		return types.Hash{}, jsonRPCError(rpcResp.Error)
 	}
 
 	// Unmarshal result
@@ -623,7 +598,7 @@ func (t *CRETransport) WaitTx(ctx context.Context, txHash types.Hash, interval t
 	for {
 		select {
 		case <-ctx.Done():
-			return nil, ctx.Err()
+			return nil, tnerrors.Wrap(ctx.Err(), tnerrors.OpWaitTx, "", tnerrors.WithTxHash(txHash))
 		case <-ticker.C:
 			// Query transaction status
 			params := map[string]any{
@@ -635,7 +610,7 @@ func (t *CRETransport) WaitTx(ctx context.Context, txHash types.Hash, interval t
 				// Distinguish between transient errors (retry-able) and permanent errors
 				if !isTransientTxError(err) {
 					// Permanent error - authentication failure, network issues, malformed request
-					return nil, fmt.Errorf("transaction query failed: %w", err)
+					return nil, tnerrors.Wrap(fmt.Errorf("transaction query failed: %w", err), tnerrors.OpWaitTx, "", tnerrors.WithTxHash(txHash))
 				}
 				// Transient error (tx not indexed yet) - continue polling
 				continue
@@ -649,6 +624,44 @@ func (t *CRETransport) WaitTx(ctx context.Context, txHash types.Hash, interval t
 	}
 }
 
+// jsonRPCError converts a JSON-RPC error object into a classified error. The
+// message keeps the "JSON-RPC error: <msg> (code: <n>)" layout parsed by
+// isTransientTxError.
+func jsonRPCError(rpcErr *jsonrpc.Error) error {
+	// For broadcast errors (-201), decode the BroadcastError details
+	if rpcErr.Code == jsonrpc.ErrorBroadcastRejected && len(rpcErr.Data) > 0 {
+		var broadcastErr struct {
+			Code    uint32 `json:"code"`
+			Hash    string `json:"hash"`
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(rpcErr.Data, &broadcastErr); err == nil {
+			opts := []tnerrors.Option{tnerrors.WithCode(int64(broadcastErr.Code))}
+			if hash, err := types.NewHashFromString(broadcastErr.Hash); err == nil {
+				opts = append(opts, tnerrors.WithTxHash(hash))
+			}
+			return tnerrors.Wrap(fmt.Errorf("JSON-RPC error: %s (code: %d) [Broadcast: code=%d, hash=%s, msg=%s]",
+				rpcErr.Message, rpcErr.Code,
+				broadcastErr.Code, broadcastErr.Hash, broadcastErr.Message), "", "", opts...)
+		}
+	}
+	return tnerrors.Wrap(fmt.Errorf("JSON-RPC error: %s (code: %d)", rpcErr.Message, rpcErr.Code),
+		"", "", tnerrors.WithCode(int64(rpcErr.Code)))
+}
+
+// httpStatusError reports a non-200 gateway response. The status code stays in
+// the message because callJSONRPC looks for "401" to trigger authentication.
+func httpStatusError(status uint32) error {
+	var kind error
+	switch status {
+	case 401:
+		kind = tnerrors.ErrUnauthenticated
+	case 429:
+		kind = tnerrors.ErrRateLimited
+	}
+	return tnerrors.Wrap(fmt.Errorf("unexpected HTTP status code: %d", status), "", "", tnerrors.WithKind(kind))
+}
+
 // isTransientTxError determines if an error from tx_query is transient (retry-able).
 //
 // Strategy:
@@ -896,7 +909,7 @@ func (t *CRETransport) doJSONRPCWithResponse(ctx context.Context, method string,
 
 	// Check HTTP status
 	if httpResp.StatusCode != 200 {
-		return nil, fmt.Errorf("unexpected HTTP status code: %d", httpResp.StatusCode)
+		return nil, httpStatusError(httpResp.StatusCode)
 	}
 
 	// Parse JSON-RPC response
@@ -907,7 +920,7 @@ func (t *CRETransport) doJSONRPCWithResponse(ctx context.Context, method string,
 
 	// Check for JSON-RPC errors
 	if rpcResp.Error != nil {
-		return nil, fmt.Errorf("JSON-RPC error: %s (code: %d)", rpcResp.Error.Message, rpcResp.Error.Code)
+		return nil, jsonRPCError(rpcResp.Error)
 	}
 
 	// Return the response headers
@@ -14,6 +14,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	jsonrpc "github.com/trufnetwork/kwil-db/core/rpc/json"
+	"github.com/trufnetwork/sdk-go/core/tnerrors"
 )
 
 // Note: These are basic structural tests for CRE transport.
@@ -188,6 +190,21 @@ func TestIsTransientTxError(t *testing.T) {
 	}
 }
 
+func TestJSONRPCError_Classified(t *testing.T) {
+	err := jsonRPCError(jsonrpc.NewError(jsonrpc.ErrorBroadcastRejected, "broadcast error",
+		[]byte(`{"code":4,"hash":"","message":"invalid nonce"}`)))
+	assert.ErrorIs(t, err, tnerrors.ErrInvalidNonce)
+	assert.Contains(t, err.Error(), "(code: -201)", "isTransientTxError still parses the code")
+
+	err = jsonRPCError(jsonrpc.NewError(jsonrpc.ErrorTxNotFound, "transaction not found", nil))
+	assert.ErrorIs(t, err, tnerrors.ErrTxNotFound)
+	assert.True(t, isTransientTxError(err))
+
+	err = httpStatusError(401)
+	assert.ErrorIs(t, err, tnerrors.ErrUnauthenticated)
+	assert.Contains(t, err.Error(), "401")
+}
+
 func TestCRETransport_ApplyHTTPCacheConfig(t *testing.T) {
 	t.Run("nil_config_is_noop", func(t *testing.T) {
 		tr, err := NewCRETransport(nil, "https://example.com", nil)
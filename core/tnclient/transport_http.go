@@ -10,6 +10,7 @@ import (
 	"github.com/trufnetwork/kwil-db/core/gatewayclient"
 	"github.com/trufnetwork/kwil-db/core/log"
 	"github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/tnerrors"
 )
 
 // HTTPTransport implements Transport using standard net/http via kwil-db's GatewayClient.
@@ -85,8 +86,15 @@ func NewHTTPTransport(ctx context.Context, provider string, signer auth.Signer,
 // The call is authenticated if a signer is configured. If authentication
 // fails with a 401 error, the transport will automatically re-authenticate
 // and retry the request.
+//
+// Transport errors are returned as *tnerrors.Error. An application error is
+// reported in CallResult.Error with a nil error.
 func (t *HTTPTransport) Call(ctx context.Context, namespace string, action string, inputs []any) (*types.CallResult, error) {
-	return t.gatewayClient.Call(ctx, namespace, action, inputs)
+	result, err := t.gatewayClient.Call(ctx, namespace, action, inputs)
+	if err != nil {
+		return nil, tnerrors.Wrap(err, tnerrors.OpCall, action, tnerrors.WithArgs(inputs))
+	}
+	return result, nil
 }
 
 // Execute performs a write action and returns the transaction hash.
@@ -95,7 +103,11 @@ func (t *HTTPTransport) Call(ctx context.Context, namespace string, action strin
 // The transaction will be signed using the configured signer. Options can
 // include custom nonce, fee, and other transaction parameters.
 func (t *HTTPTransport) Execute(ctx context.Context, namespace string, action string, inputs [][]any, opts ...clientType.TxOpt) (types.Hash, error) {
-	return t.gatewayClient.Execute(ctx, namespace, action, inputs, opts...)
+	hash, err := t.gatewayClient.Execute(ctx, namespace, action, inputs, opts...)
+	if err != nil {
+		return hash, tnerrors.Wrap(err, tnerrors.OpExecute, action, withFirstTuple(inputs))
+	}
+	return hash, nil
 }
 
 // WaitTx polls for transaction confirmation with the specified interval.
@@ -104,7 +116,11 @@ func (t *HTTPTransport) Execute(ctx context.Context, namespace string, action st
 // The method blocks until the transaction is confirmed, rejected, or the
 // context is cancelled. It polls the transaction status at the specified interval.
 func (t *HTTPTransport) WaitTx(ctx context.Context, txHash types.Hash, interval time.Duration) (*types.TxQueryResponse, error) {
-	return t.gatewayClient.WaitTx(ctx, txHash, interval)
+	res, err := t.gatewayClient.WaitTx(ctx, txHash, interval)
+	if err != nil {
+		return nil, tnerrors.Wrap(err, tnerrors.OpWaitTx, "", tnerrors.WithTxHash(txHash))
+	}
+	return res, nil
 }
 
 // ChainID returns the network chain identifier.
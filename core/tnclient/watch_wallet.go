@@ -0,0 +1,23 @@
+package tnclient
+
+import (
+	"context"
+
+	"github.com/trufnetwork/sdk-go/core/orderbook/watch"
+)
+
+// WatchWallet streams the order book events of wallet (the client's own
+// address when empty): orders placed, filled and cancelled, market
+// settlements and LP rewards. The channel is closed when ctx is done. See
+// package watch for how events are derived and for cursor persistence.
+func (c *Client) WatchWallet(ctx context.Context, wallet string, opts ...watch.Option) (<-chan watch.Event, error) {
+	ob, err := c.LoadOrderBook()
+	if err != nil {
+		return nil, err
+	}
+	if wallet == "" {
+		addr := c.Address()
+		wallet = addr.Address()
+	}
+	return watch.WatchWallet(ctx, ob, wallet, opts...)
+}
@@ -0,0 +1,121 @@
+package tnerrors
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	rpcclient "github.com/trufnetwork/kwil-db/core/rpc/client"
+	jsonrpc "github.com/trufnetwork/kwil-db/core/rpc/json"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+)
+
+// Classify returns the kind of err, or nil if it cannot be classified.
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) && e.Kind != nil {
+		return e.Kind
+	}
+	return classify(err, 0)
+}
+
+func classify(err error, code int64) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return nil
+	}
+
+	switch {
+	case errors.Is(err, kwiltypes.ErrInvalidNonce):
+		return ErrInvalidNonce
+	case errors.Is(err, kwiltypes.ErrMempoolFull):
+		return ErrMempoolFull
+	case errors.Is(err, kwiltypes.ErrInsufficientBalance):
+		return ErrInsufficientBalance
+	case errors.Is(err, kwiltypes.ErrTxNotFound):
+		return ErrTxNotFound
+	case errors.Is(err, rpcclient.ErrUnauthorized):
+		return ErrUnauthenticated
+	case errors.Is(err, rpcclient.ErrNotAllowed):
+		return ErrPermissionDenied
+	}
+
+	var rpcErr rpcclient.RPCError
+	if errors.As(err, &rpcErr) && code == 0 {
+		code = int64(rpcErr.Code)
+	}
+	var jsonErr *jsonrpc.Error
+	if errors.As(err, &jsonErr) && code == 0 {
+		code = int64(jsonErr.Code)
+	}
+
+	// messages are more specific than the generic codes, so they win
+	if kind := ClassifyMessage(err.Error()); kind != nil {
+		return kind
+	}
+	return classifyCode(code)
+}
+
+// classifyCode maps JSON-RPC and transaction result codes.
+func classifyCode(code int64) error {
+	switch code {
+	case int64(jsonrpc.ErrorTxNotFound):
+		return ErrTxNotFound
+	case int64(jsonrpc.ErrorInvalidParams), int64(jsonrpc.ErrorTxPayloadInvalid):
+		return ErrInvalidArgument
+	case int64(jsonrpc.ErrorKGWNotAuthorized):
+		return ErrUnauthenticated
+	case int64(jsonrpc.ErrorKGWNotAllowed):
+		return ErrPermissionDenied
+	case int64(jsonrpc.ErrorKGWTooManyRequests):
+		return ErrRateLimited
+	case int64(jsonrpc.ErrorKGWNotFound), int64(jsonrpc.ErrorEngineDatasetNotFound):
+		return ErrNotFound
+	case int64(kwiltypes.CodeInvalidNonce):
+		return ErrInvalidNonce
+	case int64(kwiltypes.CodeInsufficientBalance):
+		return ErrInsufficientBalance
+	case int64(kwiltypes.CodeMempoolFull):
+		return ErrMempoolFull
+	}
+	return nil
+}
+
+// messagePatterns is checked in order; the first match wins, so specific
+// patterns come before generic ones.
+var messagePatterns = []struct {
+	kind     error
+	patterns []string
+}{
+	{ErrNodeCatchingUp, []string{"node is catching up"}},
+	// only failures where the request demonstrably never reached the node
+	{ErrUnavailable, []string{"no available backend", "connection refused", "no such host"}},
+	{ErrInvalidNonce, []string{"invalid nonce"}},
+	{ErrMempoolFull, []string{"mempool is full"}},
+	{ErrInsufficientBalance, []string{"insufficient balance", "insufficient funds"}},
+	{ErrStreamExists, []string{"stream already exists"}},
+	{ErrStreamNotFound, []string{"stream not found", "stream does not exist", "stream doesn't exist"}},
+	{ErrTxNotFound, []string{"transaction not found", "unknown transaction"}},
+	{ErrUnauthenticated, []string{"unauthorized", "authentication failed"}},
+	{ErrPermissionDenied, []string{
+		"permission denied", "not allowed", "not authorized", "only the owner",
+		"not the owner", "caller is not", "wallet not allowed", "does not have permission",
+	}},
+	{ErrRateLimited, []string{"too many requests", "rate limit"}},
+	{ErrNotFound, []string{"not found", "does not exist"}},
+}
+
+// ClassifyMessage maps a node or gateway message to a kind, or nil.
+func ClassifyMessage(msg string) error {
+	lower := strings.ToLower(msg)
+	for _, p := range messagePatterns {
+		for _, pattern := range p.patterns {
+			if strings.Contains(lower, pattern) {
+				return p.kind
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+// Package tnerrors defines the error kinds returned by the SDK for node,
+// gateway and action failures.
+//
+// Every error that crosses a transport, stream action, LocalActions or
+// OrderBook boundary is an *Error whose Kind is one of the sentinels below (or nil when the failure
+// could not be classified). Callers test kinds with errors.Is and read the
+// structured fields with errors.As:
+//
+//	_, err := stream.GetRecord(ctx, input)
+//	if errors.Is(err, tnerrors.ErrStreamNotFound) { ... }
+//
+//	var tnErr *tnerrors.Error
+//	if errors.As(err, &tnErr) {
+//	    fmt.Println(tnErr.Action, tnErr.StreamId, tnErr.TxHash)
+//	}
+//
+// The original error stays in the chain, so checks against kwil-db sentinels
+// such as kwiltypes.ErrInvalidNonce keep working.
+package tnerrors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+)
+
+// Error kinds. Compare with errors.Is.
+var (
+	ErrStreamNotFound      = errors.New("stream not found")
+	ErrStreamExists        = errors.New("stream already exists")
+	ErrNotFound            = errors.New("not found")
+	ErrPermissionDenied    = errors.New("permission denied")
+	ErrUnauthenticated     = errors.New("unauthenticated")
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	ErrInvalidArgument     = errors.New("invalid argument")
+	ErrInvalidNonce        = errors.New("invalid nonce")
+	ErrMempoolFull         = errors.New("mempool full")
+	ErrNodeCatchingUp      = errors.New("node is catching up")
+	ErrUnavailable         = errors.New("node unavailable")
+	ErrRateLimited         = errors.New("rate limited")
+	ErrTxNotFound          = errors.New("transaction not found")
+	ErrTxFailed            = errors.New("transaction failed")
+)
+
+// Operation names used in Error.Op.
+const (
+	OpCall    = "call"
+	OpExecute = "execute"
+	OpWaitTx  = "wait_tx"
+	OpAdmin   = "admin"
+)
+
+// Error is a classified SDK error.
+type Error struct {
+	// Kind is one of the package sentinels, or nil if unclassified.
+	Kind error
+	// Op is the kind of request that failed (OpCall, OpExecute, ...).
+	Op string
+	// Action is the node action or local method name.
+	Action string
+	// DataProvider and StreamId identify the stream the request targeted, if known.
+	DataProvider string
+	StreamId     string
+	// TxHash is set for failures tied to a broadcast transaction.
+	TxHash *kwiltypes.Hash
+	// Code is the JSON-RPC error code or transaction result code, 0 if unknown.
+	Code int64
+	// Message is the raw message reported by the node, if any.
+	Message string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Op)
+	if e.Action != "" {
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(e.Action)
+	}
+	if e.StreamId != "" {
+		b.WriteString(" [")
+		if e.DataProvider != "" {
+			b.WriteString(e.DataProvider)
+			b.WriteByte('/')
+		}
+		b.WriteString(e.StreamId)
+		b.WriteByte(']')
+	}
+	if e.TxHash != nil {
+		b.WriteString(" tx ")
+		b.WriteString(e.TxHash.String())
+	}
+
+	var cause string
+	switch {
+	case e.Err != nil:
+		cause = e.Err.Error()
+	case e.Message != "":
+		cause = e.Message
+	case e.Kind != nil:
+		cause = e.Kind.Error()
+	}
+	if b.Len() == 0 {
+		return cause
+	}
+	return b.String() + ": " + cause
+}
+
+// Unwrap exposes both the kind and the underlying error to errors.Is/As.
+func (e *Error) Unwrap() []error {
+	var errs []error
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// Option sets a field of an Error built by Wrap or New.
+type Option func(*Error)
+
+// WithStream records the stream the request targeted.
+func WithStream(dataProvider, streamId string) Option {
+	return func(e *Error) {
+		e.DataProvider, e.StreamId = dataProvider, streamId
+	}
+}
+
+// WithArgs records the stream when args start with a data provider and a
+// stream id, which is the argument layout of stream actions.
+func WithArgs(args []any) Option {
+	return func(e *Error) {
+		if dp, sid, ok := StreamFromArgs(args); ok {
+			e.DataProvider, e.StreamId = dp, sid
+		}
+	}
+}
+
+// WithTxHash records the transaction the error is tied to.
+func WithTxHash(hash kwiltypes.Hash) Option {
+	return func(e *Error) {
+		e.TxHash = &hash
+	}
+}
+
+// WithKind sets the kind instead of classifying the error. A nil kind is
+// ignored.
+func WithKind(kind error) Option {
+	return func(e *Error) {
+		if kind != nil {
+			e.Kind = kind
+		}
+	}
+}
+
+// WithCode records a JSON-RPC or transaction result code.
+func WithCode(code int64) Option {
+	return func(e *Error) {
+		e.Code = code
+	}
+}
+
+// Wrap classifies err and attaches op, action and opts. It returns nil for a
+// nil err. If err already carries an *Error, its fields are kept and only the
+// missing ones are filled in.
+func Wrap(err error, op, action string, opts ...Option) error {
+	if err == nil {
+		return nil
+	}
+
+	var existing *Error
+	if errors.As(err, &existing) {
+		merged := *existing
+		fill := &Error{}
+		for _, opt := range opts {
+			opt(fill)
+		}
+		if err != error(existing) {
+			// keep the context added around the existing error
+			merged.Err = err
+		}
+		if merged.Kind == nil {
+			merged.Kind = fill.Kind
+		}
+		if merged.Op == "" {
+			merged.Op = op
+		}
+		if merged.Action == "" {
+			merged.Action = action
+		}
+		if merged.StreamId == "" {
+			merged.DataProvider, merged.StreamId = fill.DataProvider, fill.StreamId
+		}
+		if merged.TxHash == nil {
+			merged.TxHash = fill.TxHash
+		}
+		if merged.Code == 0 {
+			merged.Code = fill.Code
+		}
+		return &merged
+	}
+
+	e := &Error{Op: op, Action: action, Err: err}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.Kind == nil {
+		e.Kind = classify(err, e.Code)
+	}
+	return e
+}
+
+// New builds an Error from a message reported by the node, such as
+// CallResult.Error or a failed TxResult log.
+func New(op, action, message string, opts ...Option) error {
+	e := &Error{Op: op, Action: action, Message: message}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.Kind == nil {
+		e.Kind = ClassifyMessage(message)
+	}
+	if e.Kind == nil {
+		e.Kind = classifyCode(e.Code)
+	}
+	return e
+}
+
+// FromTxResult returns an ErrTxFailed-compatible error when res reports a
+// failed transaction, and nil otherwise. The error also matches the kind
+// derived from the result log (for example ErrPermissionDenied).
+func FromTxResult(action string, hash kwiltypes.Hash, res *kwiltypes.TxQueryResponse) error {
+	if res == nil || res.Result == nil || res.Result.Code == uint32(kwiltypes.CodeOk) {
+		return nil
+	}
+	e := &Error{
+		Kind:    ErrTxFailed,
+		Op:      OpExecute,
+		Action:  action,
+		TxHash:  &hash,
+		Code:    int64(res.Result.Code),
+		Message: res.Result.Log,
+	}
+	kind := classifyCode(e.Code)
+	if kind == nil {
+		kind = ClassifyMessage(res.Result.Log)
+	}
+	if kind != nil {
+		e.Err = fmt.Errorf("%w: %s", kind, res.Result.Log)
+	} else {
+		e.Err = fmt.Errorf("%w: %s", ErrTxFailed, res.Result.Log)
+	}
+	return e
+}
+
+// StreamFromArgs returns the data provider and stream id when args[0] is a
+// 0x-prefixed address and args[1] a stream id.
+func StreamFromArgs(args []any) (dataProvider, streamId string, ok bool) {
+	if len(args) < 2 {
+		return "", "", false
+	}
+	dp, ok1 := args[0].(string)
+	sid, ok2 := args[1].(string)
+	if !ok1 || !ok2 || len(dp) != 42 || !strings.HasPrefix(dp, "0x") || len(sid) != 32 || !strings.HasPrefix(sid, "st") {
+		return "", "", false
+	}
+	return dp, sid, true
+}
@@ -0,0 +1,101 @@
+package tnerrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rpcclient "github.com/trufnetwork/kwil-db/core/rpc/client"
+	jsonrpc "github.com/trufnetwork/kwil-db/core/rpc/json"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"nil", nil, nil},
+		{"kwil invalid nonce", fmt.Errorf("broadcast: %w", kwiltypes.ErrInvalidNonce), ErrInvalidNonce},
+		{"kwil mempool full", kwiltypes.ErrMempoolFull, ErrMempoolFull},
+		{"kwil insufficient balance", kwiltypes.ErrInsufficientBalance, ErrInsufficientBalance},
+		{"rpc unauthorized", fmt.Errorf("call: %w", rpcclient.ErrUnauthorized), ErrUnauthenticated},
+		{"rpc code", rpcclient.RPCError{Code: int32(jsonrpc.ErrorKGWTooManyRequests), Msg: "slow down"}, ErrRateLimited},
+		{"catching up", errors.New("JSON-RPC error: node is catching up (code: -32603)"), ErrNodeCatchingUp},
+		{"no backend", errors.New("500 no available backend"), ErrUnavailable},
+		{"stream missing", errors.New("ERROR: stream does not exist: st123"), ErrStreamNotFound},
+		{"stream exists", errors.New("stream already exists"), ErrStreamExists},
+		{"permission", errors.New("wallet not allowed to write to stream"), ErrPermissionDenied},
+		{"generic not found", errors.New("market not found for given hash"), ErrNotFound},
+		{"context", context.Canceled, nil},
+		{"unknown", errors.New("boom"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Classify(tt.err))
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	assert.NoError(t, Wrap(nil, OpCall, "get_record"))
+
+	args := []any{"0x0000000000000000000000000000000000000001", "st00000000000000000000000000abcd", int64(1)}
+	err := Wrap(fmt.Errorf("execute: %w", kwiltypes.ErrInvalidNonce), OpExecute, "insert_records", WithArgs(args))
+
+	assert.ErrorIs(t, err, ErrInvalidNonce)
+	assert.ErrorIs(t, err, kwiltypes.ErrInvalidNonce, "original cause stays in the chain")
+
+	var tnErr *Error
+	require.True(t, errors.As(err, &tnErr))
+	assert.Equal(t, "insert_records", tnErr.Action)
+	assert.Equal(t, "0x0000000000000000000000000000000000000001", tnErr.DataProvider)
+	assert.Equal(t, "st00000000000000000000000000abcd", tnErr.StreamId)
+	assert.Equal(t, "execute insert_records [0x0000000000000000000000000000000000000001/st00000000000000000000000000abcd]: execute: invalid nonce", err.Error())
+
+	// re-wrapping keeps the existing fields and fills missing ones
+	hash := kwiltypes.Hash{1}
+	inner := Wrap(errors.New("JSON-RPC error: stream not found (code: -32603)"), "", "", WithCode(-32603))
+	outer := Wrap(fmt.Errorf("retry after auth failed: %w", inner), OpCall, "get_record", WithTxHash(hash))
+	require.True(t, errors.As(outer, &tnErr))
+	assert.Equal(t, ErrStreamNotFound, tnErr.Kind)
+	assert.Equal(t, int64(-32603), tnErr.Code)
+	assert.Equal(t, "get_record", tnErr.Action)
+	assert.Equal(t, &hash, tnErr.TxHash)
+	assert.Contains(t, outer.Error(), "retry after auth failed")
+}
+
+func TestNewAndFromTxResult(t *testing.T) {
+	err := New(OpCall, "get_market_info", "market not found: query_id=7")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, "call get_market_info: market not found: query_id=7", err.Error())
+
+	hash := kwiltypes.Hash{2}
+	assert.NoError(t, FromTxResult("insert_records", hash, &kwiltypes.TxQueryResponse{Result: &kwiltypes.TxResult{Code: uint32(kwiltypes.CodeOk)}}))
+
+	err = FromTxResult("insert_records", hash, &kwiltypes.TxQueryResponse{Result: &kwiltypes.TxResult{
+		Code: uint32(kwiltypes.CodeUnknownError),
+		Log:  "caller is not the stream owner",
+	}})
+	assert.ErrorIs(t, err, ErrTxFailed)
+	assert.ErrorIs(t, err, ErrPermissionDenied)
+	var tnErr *Error
+	require.True(t, errors.As(err, &tnErr))
+	assert.Equal(t, &hash, tnErr.TxHash)
+	assert.Equal(t, "caller is not the stream owner", tnErr.Message)
+}
+
+func TestStreamFromArgs(t *testing.T) {
+	_, _, ok := StreamFromArgs([]any{"0x0000000000000000000000000000000000000001"})
+	assert.False(t, ok)
+	_, _, ok = StreamFromArgs([]any{"system", "network_writer", []string{}})
+	assert.False(t, ok)
+	dp, sid, ok := StreamFromArgs([]any{"0x0000000000000000000000000000000000000001", "st00000000000000000000000000abcd"})
+	assert.True(t, ok)
+	assert.Equal(t, "0x0000000000000000000000000000000000000001", dp)
+	assert.Equal(t, "st00000000000000000000000000abcd", sid)
+}
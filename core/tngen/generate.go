@@ -0,0 +1,289 @@
+package tngen
+
+import (
+	"bytes"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// Generate renders the Go source for spec. source names the spec file in the
+// generated header and may be empty.
+//
+// The file declares a Procedures type wrapping types.IAction, a WithPrefix
+// option, and per action:
+//
+//   - <Action>Input, one field per parameter in declaration order (omitted
+//     when the action takes no parameters). Nullable scalar parameters are
+//     pointers, and nil pointers and slices are sent as NULL; other values,
+//     zero or not, are sent as is. This differs from util.StructAsArgs, used
+//     by tnclient.Call and tnclient.Execute, which sends zero values of
+//     non-pointer fields as NULL (or rejects them when tagged
+//     validate:"required"), so the same struct can produce different
+//     arguments through the two;
+//   - <Action>Row, one field per returned column tagged json:"<column>"
+//     (view actions only);
+//   - a Procedures method that calls the action and returns []<Action>Row for
+//     views, or the transaction hash otherwise.
+//
+// Only one spec should be generated per Go package, since the Procedures,
+// Option and WithPrefix names are fixed.
+func Generate(spec *Spec, source string) ([]byte, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	data := fileData{Package: spec.Package, Source: source, Prefix: spec.Prefix}
+	data.StdImports = []string{`"context"`}
+	imports := map[string]bool{
+		`"github.com/trufnetwork/sdk-go/core/types"`: true,
+	}
+	for _, a := range spec.Actions {
+		ad := actionData{
+			Name:   a.Name,
+			Method: a.methodName(),
+			Doc:    docLines(a.Doc),
+			View:   a.View,
+			Zero:   "kwiltypes.Hash{}",
+		}
+		if a.View {
+			ad.Zero = "nil"
+		}
+		for i, p := range a.Params {
+			field, err := paramType(p.Type)
+			if err != nil {
+				return nil, err
+			}
+			pd := paramData{
+				Field:    goName(p.Name),
+				Type:     field.goType,
+				Nullable: p.Nullable,
+				Doc:      docLines(p.Doc),
+				Index:    i,
+				Name:     strings.TrimPrefix(p.Name, "$"),
+			}
+			pd.Value = "input." + pd.Field
+			if p.Nullable && !strings.HasPrefix(pd.Type, "[]") {
+				pd.Type = "*" + pd.Type
+				pd.Value = "*" + pd.Value
+			}
+			pd.Convert = field.convert(pd.Value)
+			if pd.Convert != "" {
+				imports[`kwiltypes "github.com/trufnetwork/kwil-db/core/types"`] = true
+				imports[`"github.com/pkg/errors"`] = true
+				ad.HasConvert = true
+			}
+			ad.HasNullable = ad.HasNullable || p.Nullable
+			ad.Params = append(ad.Params, pd)
+		}
+		for _, c := range a.Returns {
+			goType, err := columnType(c.Type, c.Nullable)
+			if err != nil {
+				return nil, err
+			}
+			ad.Columns = append(ad.Columns, columnData{Field: goName(c.Name), Type: goType, Name: c.Name})
+		}
+		if a.View {
+			imports[`"github.com/pkg/errors"`] = true
+			imports[`"github.com/trufnetwork/sdk-go/core/contractsapi"`] = true
+		} else {
+			imports[`kwiltypes "github.com/trufnetwork/kwil-db/core/types"`] = true
+		}
+		data.Actions = append(data.Actions, ad)
+	}
+	for imp := range imports {
+		data.Imports = append(data.Imports, imp)
+	}
+	sort.Slice(data.Imports, func(i, j int) bool {
+		return importPath(data.Imports[i]) < importPath(data.Imports[j])
+	})
+
+	var buf bytes.Buffer
+	if err := fileTemplate.Execute(&buf, data); err != nil {
+		return nil, errors.Wrap(err, "render template")
+	}
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, errors.Wrap(err, "format generated code")
+	}
+	return out, nil
+}
+
+func importPath(spec string) string {
+	return spec[strings.IndexByte(spec, '"'):]
+}
+
+func docLines(doc string) []string {
+	doc = strings.TrimSpace(doc)
+	if doc == "" {
+		return nil
+	}
+	return strings.Split(doc, "\n")
+}
+
+type fileData struct {
+	Package string
+	Source  string
+	Prefix  string
+	// StdImports and Imports are rendered as separate groups.
+	StdImports []string
+	Imports    []string
+	Actions    []actionData
+}
+
+type actionData struct {
+	Name   string
+	Method string
+	Doc    []string
+	View   bool
+	// Zero is the zero value of the method's first result.
+	Zero string
+	// HasNullable is set when a parameter may be sent as NULL.
+	HasNullable bool
+	// HasConvert is set when a parameter is parsed before the call.
+	HasConvert bool
+	Params     []paramData
+	Columns    []columnData
+}
+
+type paramData struct {
+	Field    string
+	Type     string
+	Nullable bool
+	Doc      []string
+	Index    int
+	Name     string
+	// Value is the expression of the field's value, dereferenced for
+	// pointers, and Convert its kwil-db parse expression, if any.
+	Value   string
+	Convert string
+}
+
+// argData is the data of the "arg" template, which sets one argument.
+type argData struct {
+	Action actionData
+	Param  paramData
+}
+
+type columnData struct {
+	Field string
+	Type  string
+	Name  string
+}
+
+var fileTemplate = template.Must(template.New("file").Funcs(template.FuncMap{
+	"quote": strconv.Quote,
+	"argData": func(a actionData, p paramData) argData {
+		return argData{Action: a, Param: p}
+	},
+}).Parse(`// Code generated by tngen{{if .Source}} from {{.Source}}{{end}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .StdImports}}
+	{{.}}
+{{- end}}
+{{range .Imports}}
+	{{.}}
+{{- end}}
+)
+
+// Procedures calls the actions described in this file through an Action API
+// loaded with Client.LoadActions.
+type Procedures struct {
+	actions types.IAction
+	prefix  string
+}
+
+// Option configures Procedures.
+type Option func(*Procedures)
+
+// WithPrefix sets the prefix prepended to every action name, for actions
+// deployed under a namespace such as "truflation_".{{if .Prefix}} Default: {{quote .Prefix}}.{{end}}
+func WithPrefix(prefix string) Option {
+	return func(p *Procedures) {
+		p.prefix = prefix
+	}
+}
+
+// NewProcedures wraps actions.
+func NewProcedures(actions types.IAction, opts ...Option) *Procedures {
+	p := &Procedures{actions: actions, prefix: {{quote .Prefix}}}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+{{range .Actions}}{{$action := .}}
+{{- if .Params}}
+// {{.Method}}Input holds the arguments of {{.Name}} in declaration order.
+{{- if .HasNullable}}
+// Nil fields are sent as NULL.{{end}}
+// Zero values are sent as is, unlike util.StructAsArgs, which sends them as NULL.
+type {{.Method}}Input struct {
+{{- range .Params}}
+{{- range .Doc}}
+	// {{.}}
+{{- end}}
+	{{.Field}} {{.Type}}
+{{- end}}
+}
+{{end}}
+{{- if .View}}
+// {{.Method}}Row is a row returned by {{.Name}}.
+type {{.Method}}Row struct {
+{{- range .Columns}}
+	{{.Field}} {{.Type}} ` + "`" + `json:"{{.Name}}"` + "`" + `
+{{- end}}
+}
+{{end}}
+{{- if .Doc}}
+{{- range .Doc}}
+// {{.}}
+{{- end}}
+{{- else}}
+// {{.Method}} {{if .View}}calls{{else}}executes{{end}} {{.Name}}.
+{{- end}}
+func (p *Procedures) {{.Method}}(ctx context.Context{{if .Params}}, input {{.Method}}Input{{end}}) ({{if .View}}[]{{.Method}}Row{{else}}kwiltypes.Hash{{end}}, error) {
+{{- if .Params}}
+	args := make([]any, {{len .Params}})
+{{- if .HasConvert}}
+	var err error
+{{- end}}
+{{- range .Params}}
+{{- if .Nullable}}
+	if input.{{.Field}} != nil {
+		{{- template "arg" (argData $action .)}}
+	}
+{{- else}}
+	{{- template "arg" (argData $action .)}}
+{{- end}}
+{{- end}}
+{{- else}}
+	args := []any{}
+{{- end}}
+{{- if .View}}
+	result, err := p.actions.CallProcedure(ctx, p.prefix+{{quote .Name}}, args)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return contractsapi.DecodeCallResult[{{.Method}}Row](result)
+{{- else}}
+	return p.actions.ExecuteProcedure(ctx, p.prefix+{{quote .Name}}, [][]any{args})
+{{- end}}
+}
+{{end}}
+{{- define "arg"}}
+{{- if .Param.Convert}}
+	if args[{{.Param.Index}}], err = {{.Param.Convert}}; err != nil {
+		return {{.Action.Zero}}, errors.Wrapf(err, "{{.Action.Name}}: parse {{.Param.Name}}")
+	}
+{{- else}}
+	args[{{.Param.Index}}] = {{.Param.Value}}
+{{- end}}
+{{- end}}`))
@@ -0,0 +1,136 @@
+package tngen
+
+import (
+	"bytes"
+	"flag"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden file")
+
+// TestGenerate_Golden compares the output for testdata/procedures.json with
+// internal/divergence, whose tests call the generated methods.
+func TestGenerate_Golden(t *testing.T) {
+	raw, err := os.ReadFile(filepath.Join("testdata", "procedures.json"))
+	require.NoError(t, err)
+	spec, err := ParseSpec(bytes.NewReader(raw))
+	require.NoError(t, err)
+
+	src, err := Generate(spec, "procedures.json")
+	require.NoError(t, err)
+
+	_, err = parser.ParseFile(token.NewFileSet(), "procedures_gen.go", src, parser.AllErrors)
+	require.NoError(t, err)
+
+	golden := filepath.Join("internal", "divergence", "procedures_gen.go")
+	if *update {
+		require.NoError(t, os.WriteFile(golden, src, 0o644))
+	}
+	want, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(src))
+}
+
+func TestGenerate_Prefix(t *testing.T) {
+	spec := &Spec{
+		Package: "truflation",
+		Prefix:  "truflation_",
+		Actions: []Action{{
+			Name:    "get_index",
+			View:    true,
+			Returns: []Column{{Name: "value", Type: "numeric(36,18)"}},
+		}},
+	}
+	src, err := Generate(spec, "")
+	require.NoError(t, err)
+
+	out := string(src)
+	assert.True(t, strings.HasPrefix(out, "// Code generated by tngen. DO NOT EDIT."))
+	assert.Contains(t, out, `p := &Procedures{actions: actions, prefix: "truflation_"}`)
+	assert.Contains(t, out, `p.actions.CallProcedure(ctx, p.prefix+"get_index", args)`)
+	assert.Contains(t, out, "func (p *Procedures) GetIndex(ctx context.Context) ([]GetIndexRow, error)")
+	assert.NotContains(t, out, "kwiltypes", "unused imports are left out")
+}
+
+func TestSpecValidate(t *testing.T) {
+	view := func(a Action) *Spec {
+		a.View = true
+		if a.Returns == nil {
+			a.Returns = []Column{{Name: "value", Type: "text"}}
+		}
+		return &Spec{Package: "p", Actions: []Action{a}}
+	}
+	tests := []struct {
+		name string
+		spec *Spec
+		err  string
+	}{
+		{"bad package", &Spec{Package: "my-pkg", Actions: []Action{{Name: "x"}}}, "invalid package name"},
+		{"no actions", &Spec{Package: "p"}, "no actions"},
+		{"view without returns", &Spec{Package: "p", Actions: []Action{{Name: "x", View: true}}}, "must declare returns"},
+		{"execute with returns", &Spec{Package: "p", Actions: []Action{{Name: "x", Returns: []Column{{Name: "a", Type: "text"}}}}}, "only view actions"},
+		{"unknown type", view(Action{Name: "x", Params: []Param{{Name: "a", Type: "jsonb"}}}), `unsupported type "jsonb"`},
+		{"numeric array param", view(Action{Name: "x", Params: []Param{{Name: "a", Type: "numeric(36,18)[]"}}}), "array parameters of type numeric"},
+		{"bad precision", view(Action{Name: "x", Params: []Param{{Name: "a", Type: "numeric(2,5)"}}}), "scale exceeds precision"},
+		{"duplicate field", view(Action{Name: "x", Params: []Param{{Name: "stream_id", Type: "text"}, {Name: "$stream_id", Type: "text"}}}), "duplicate field StreamId"},
+		{"duplicate method", &Spec{Package: "p", Actions: []Action{{Name: "get_x"}, {Name: "x", GoName: "GetX"}}}, "GetX already used by get_x"},
+		{"unexported go name", &Spec{Package: "p", Actions: []Action{{Name: "x", GoName: "doX"}}}, "invalid Go name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, tt.spec.Validate(), tt.err)
+		})
+	}
+}
+
+func TestParseSpec_UnknownField(t *testing.T) {
+	_, err := ParseSpec(strings.NewReader(`{"package": "p", "actions": [{"name": "x", "returns_table": []}]}`))
+	assert.ErrorContains(t, err, "unknown field")
+}
+
+func TestTypeMapping(t *testing.T) {
+	params := map[string]string{
+		"TEXT":           "string",
+		"int":            "int64",
+		"int4[]":         "[]int64",
+		"boolean":        "bool",
+		"numeric(36,18)": "string",
+		"uuid":           "string",
+		"bytea":          "[]byte",
+		"text[]":         "[]string",
+	}
+	for sqlType, want := range params {
+		field, err := paramType(sqlType)
+		require.NoError(t, err, sqlType)
+		assert.Equal(t, want, field.goType, sqlType)
+	}
+
+	columns := []struct {
+		sqlType  string
+		nullable bool
+		want     string
+	}{
+		{"int8", false, "int64"},
+		{"int8", true, "*int64"},
+		{"numeric(36,18)", true, "*string"},
+		{"bytea", true, "[]byte"},
+		{"text[]", true, "[]string"},
+	}
+	for _, c := range columns {
+		got, err := columnType(c.sqlType, c.nullable)
+		require.NoError(t, err)
+		assert.Equal(t, c.want, got, c.sqlType)
+	}
+
+	field, err := paramType("decimal")
+	require.NoError(t, err)
+	assert.Equal(t, "kwiltypes.ParseDecimal(s)", field.convert("s"))
+}
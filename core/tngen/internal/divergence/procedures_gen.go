@@ -0,0 +1,142 @@
+// Code generated by tngen from procedures.json. DO NOT EDIT.
+
+package divergence
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/contractsapi"
+	"github.com/trufnetwork/sdk-go/core/types"
+)
+
+// Procedures calls the actions described in this file through an Action API
+// loaded with Client.LoadActions.
+type Procedures struct {
+	actions types.IAction
+	prefix  string
+}
+
+// Option configures Procedures.
+type Option func(*Procedures)
+
+// WithPrefix sets the prefix prepended to every action name, for actions
+// deployed under a namespace such as "truflation_".
+func WithPrefix(prefix string) Option {
+	return func(p *Procedures) {
+		p.prefix = prefix
+	}
+}
+
+// NewProcedures wraps actions.
+func NewProcedures(actions types.IAction, opts ...Option) *Procedures {
+	p := &Procedures{actions: actions, prefix: ""}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// GetDivergenceIndexChangeInput holds the arguments of get_divergence_index_change in declaration order.
+// Nil fields are sent as NULL.
+// Zero values are sent as is, unlike util.StructAsArgs, which sends them as NULL.
+type GetDivergenceIndexChangeInput struct {
+	// From is the first event time, inclusive.
+	From         int64
+	To           int64
+	FrozenAt     *int64
+	BaseTime     *int64
+	TimeInterval int64
+}
+
+// GetDivergenceIndexChangeRow is a row returned by get_divergence_index_change.
+type GetDivergenceIndexChangeRow struct {
+	EventTime int64   `json:"event_time"`
+	Value     string  `json:"value"`
+	Note      *string `json:"note"`
+}
+
+// GetDivergenceIndexChange returns the index change between from and to.
+func (p *Procedures) GetDivergenceIndexChange(ctx context.Context, input GetDivergenceIndexChangeInput) ([]GetDivergenceIndexChangeRow, error) {
+	args := make([]any, 5)
+	args[0] = input.From
+	args[1] = input.To
+	if input.FrozenAt != nil {
+		args[2] = *input.FrozenAt
+	}
+	if input.BaseTime != nil {
+		args[3] = *input.BaseTime
+	}
+	args[4] = input.TimeInterval
+	result, err := p.actions.CallProcedure(ctx, p.prefix+"get_divergence_index_change", args)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return contractsapi.DecodeCallResult[GetDivergenceIndexChangeRow](result)
+}
+
+// ListCategoriesRow is a row returned by list_categories.
+type ListCategoriesRow struct {
+	Category string   `json:"category"`
+	Tags     []string `json:"tags"`
+}
+
+// ListCategories calls list_categories.
+func (p *Procedures) ListCategories(ctx context.Context) ([]ListCategoriesRow, error) {
+	args := []any{}
+	result, err := p.actions.CallProcedure(ctx, p.prefix+"list_categories", args)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return contractsapi.DecodeCallResult[ListCategoriesRow](result)
+}
+
+// RecordAdjustmentInput holds the arguments of record_adjustment in declaration order.
+// Nil fields are sent as NULL.
+// Zero values are sent as is, unlike util.StructAsArgs, which sends them as NULL.
+type RecordAdjustmentInput struct {
+	StreamId string
+	Amount   string
+	Ref      *string
+	Payload  []byte
+}
+
+// RecordAdjustment executes record_adjustment.
+func (p *Procedures) RecordAdjustment(ctx context.Context, input RecordAdjustmentInput) (kwiltypes.Hash, error) {
+	args := make([]any, 4)
+	var err error
+	args[0] = input.StreamId
+	if args[1], err = kwiltypes.ParseDecimalExplicit(input.Amount, 36, 18); err != nil {
+		return kwiltypes.Hash{}, errors.Wrapf(err, "record_adjustment: parse amount")
+	}
+	if input.Ref != nil {
+		if args[2], err = kwiltypes.ParseUUID(*input.Ref); err != nil {
+			return kwiltypes.Hash{}, errors.Wrapf(err, "record_adjustment: parse ref")
+		}
+	}
+	if input.Payload != nil {
+		args[3] = input.Payload
+	}
+	return p.actions.ExecuteProcedure(ctx, p.prefix+"record_adjustment", [][]any{args})
+}
+
+// SetAlertInput holds the arguments of set_alert in declaration order.
+// Nil fields are sent as NULL.
+// Zero values are sent as is, unlike util.StructAsArgs, which sends them as NULL.
+type SetAlertInput struct {
+	Enabled   bool
+	Threshold int64
+	Label     *string
+}
+
+// SetAlert executes set_alert.
+func (p *Procedures) SetAlert(ctx context.Context, input SetAlertInput) (kwiltypes.Hash, error) {
+	args := make([]any, 3)
+	args[0] = input.Enabled
+	args[1] = input.Threshold
+	if input.Label != nil {
+		args[2] = *input.Label
+	}
+	return p.actions.ExecuteProcedure(ctx, p.prefix+"set_alert", [][]any{args})
+}
@@ -0,0 +1,86 @@
+package divergence
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+	"github.com/trufnetwork/sdk-go/core/types"
+	"github.com/trufnetwork/sdk-go/core/util"
+)
+
+// fakeActions records the arguments of the last call. Other IAction methods
+// panic through the nil embedded interface.
+type fakeActions struct {
+	types.IAction
+	procedure string
+	args      []any
+}
+
+func (f *fakeActions) ExecuteProcedure(_ context.Context, procedure string, args [][]any) (kwiltypes.Hash, error) {
+	f.procedure, f.args = procedure, args[0]
+	return kwiltypes.Hash{1}, nil
+}
+
+func (f *fakeActions) CallProcedure(_ context.Context, procedure string, args []any) (*kwiltypes.QueryResult, error) {
+	f.procedure, f.args = procedure, args
+	return &kwiltypes.QueryResult{ColumnNames: []string{"event_time", "value", "note"}}, nil
+}
+
+func TestZeroValuesAreSent(t *testing.T) {
+	ctx := context.Background()
+	actions := &fakeActions{}
+	p := NewProcedures(actions, WithPrefix("test_"))
+
+	_, err := p.SetAlert(ctx, SetAlertInput{Enabled: false, Threshold: 0})
+	require.NoError(t, err)
+	assert.Equal(t, "test_set_alert", actions.procedure)
+	assert.Equal(t, []any{false, int64(0), nil}, actions.args)
+
+	label := ""
+	_, err = p.SetAlert(ctx, SetAlertInput{Label: &label})
+	require.NoError(t, err)
+	assert.Equal(t, []any{false, int64(0), ""}, actions.args)
+
+	frozenAt := int64(0)
+	_, err = p.GetDivergenceIndexChange(ctx, GetDivergenceIndexChangeInput{To: 10, FrozenAt: &frozenAt})
+	require.NoError(t, err)
+	assert.Equal(t, []any{int64(0), int64(10), int64(0), nil, int64(0)}, actions.args)
+}
+
+// TestArgsDivergeFromStructAsArgs pins the documented difference between the
+// generated wrappers and util.StructAsArgs, which tnclient.Call and Execute
+// use: zero values are sent as is here and as NULL there.
+func TestArgsDivergeFromStructAsArgs(t *testing.T) {
+	input := SetAlertInput{Enabled: false, Threshold: 0}
+
+	structArgs, err := util.StructAsArgs(input)
+	require.NoError(t, err)
+	assert.Equal(t, []any{nil, nil, nil}, structArgs)
+
+	actions := &fakeActions{}
+	_, err = NewProcedures(actions).SetAlert(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, []any{false, int64(0), nil}, actions.args)
+}
+
+func TestConvertedParams(t *testing.T) {
+	ctx := context.Background()
+	actions := &fakeActions{}
+	p := NewProcedures(actions)
+
+	_, err := p.RecordAdjustment(ctx, RecordAdjustmentInput{StreamId: "st1", Amount: "1.5"})
+	require.NoError(t, err)
+	require.Len(t, actions.args, 4)
+	amount, ok := actions.args[1].(*kwiltypes.Decimal)
+	require.True(t, ok, "amount is parsed, got %T", actions.args[1])
+	assert.Equal(t, "1.500000000000000000", amount.String())
+	assert.Nil(t, actions.args[2])
+	assert.Nil(t, actions.args[3])
+
+	bad := "not-a-uuid"
+	_, err = p.RecordAdjustment(ctx, RecordAdjustmentInput{Amount: "1", Ref: &bad})
+	assert.ErrorContains(t, err, "record_adjustment: parse ref")
+}
@@ -0,0 +1,186 @@
+// Package tngen generates typed Go wrappers for custom actions from a JSON
+// description of their signatures.
+//
+// A spec lists the actions of one namespace:
+//
+//	{
+//	  "package": "divergence",
+//	  "actions": [{
+//	    "name": "get_divergence_index_change",
+//	    "view": true,
+//	    "params": [
+//	      {"name": "from", "type": "int8"},
+//	      {"name": "to", "type": "int8"},
+//	      {"name": "frozen_at", "type": "int8", "nullable": true},
+//	      {"name": "base_time", "type": "int8", "nullable": true},
+//	      {"name": "time_interval", "type": "int8"}
+//	    ],
+//	    "returns": [
+//	      {"name": "event_time", "type": "int8"},
+//	      {"name": "value", "type": "numeric(36,18)"}
+//	    ]
+//	  }]
+//	}
+//
+// For every action the generated file holds an input struct whose fields are
+// passed as the action arguments, a row struct decoded with
+// contractsapi.DecodeCallResult, and a method on Procedures that calls the
+// action through types.IAction. See Generate for the exact output.
+package tngen
+
+import (
+	"encoding/json"
+	"go/token"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Spec describes the actions to generate wrappers for.
+type Spec struct {
+	// Package is the Go package name of the generated file.
+	Package string `json:"package"`
+	// Prefix is the default action name prefix of the generated Procedures,
+	// for actions deployed under a namespace such as "truflation_".
+	Prefix  string   `json:"prefix,omitempty"`
+	Actions []Action `json:"actions"`
+}
+
+// Action is the signature of a single action.
+type Action struct {
+	// Name is the action name without prefix, e.g. "get_divergence_index_change".
+	Name string `json:"name"`
+	// GoName overrides the Go method name derived from Name.
+	GoName string `json:"go_name,omitempty"`
+	Doc    string `json:"doc,omitempty"`
+	// View actions are read with CallProcedure and must declare Returns.
+	// Other actions are executed as transactions and return a tx hash.
+	View    bool     `json:"view"`
+	Params  []Param  `json:"params,omitempty"`
+	Returns []Column `json:"returns,omitempty"`
+}
+
+// Param is a positional action parameter.
+type Param struct {
+	Name string `json:"name"`
+	// Type is the action parameter type, e.g. "text", "int8", "numeric(36,18)".
+	Type string `json:"type"`
+	// Nullable parameters are pointer fields, or slices for bytea and arrays,
+	// and are sent as NULL when nil. Other parameters are sent as is, so zero
+	// values such as false, 0 and "" reach the action, unlike with
+	// util.StructAsArgs.
+	Nullable bool   `json:"nullable,omitempty"`
+	Doc      string `json:"doc,omitempty"`
+}
+
+// Column is a column of the table returned by a view action.
+type Column struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Nullable scalar columns are decoded into pointer fields.
+	Nullable bool `json:"nullable,omitempty"`
+}
+
+// ParseSpec reads a JSON spec. Unknown fields are rejected; the spec itself is
+// checked by Validate, which Generate calls.
+func ParseSpec(r io.Reader) (*Spec, error) {
+	var spec Spec
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&spec); err != nil {
+		return nil, errors.Wrap(err, "decode spec")
+	}
+	return &spec, nil
+}
+
+// Validate checks names and types and that Go identifiers do not collide.
+func (s *Spec) Validate() error {
+	if !token.IsIdentifier(s.Package) {
+		return errors.Errorf("invalid package name %q", s.Package)
+	}
+	if len(s.Actions) == 0 {
+		return errors.New("spec declares no actions")
+	}
+
+	declared := make(map[string]string)
+	for _, a := range s.Actions {
+		if a.Name == "" {
+			return errors.New("action without name")
+		}
+		method := a.methodName()
+		if !token.IsIdentifier(method) || !token.IsExported(method) {
+			return errors.Errorf("action %s: invalid Go name %q", a.Name, method)
+		}
+		for _, name := range []string{method, method + "Input", method + "Row"} {
+			if other, ok := declared[name]; ok {
+				return errors.Errorf("action %s: Go name %s already used by %s", a.Name, name, other)
+			}
+			declared[name] = a.Name
+		}
+
+		if a.View && len(a.Returns) == 0 {
+			return errors.Errorf("action %s: view actions must declare returns", a.Name)
+		}
+		if !a.View && len(a.Returns) > 0 {
+			return errors.Errorf("action %s: only view actions can declare returns", a.Name)
+		}
+
+		fields := make(map[string]bool)
+		for _, p := range a.Params {
+			if _, err := paramType(p.Type); err != nil {
+				return errors.Wrapf(err, "action %s param %s", a.Name, p.Name)
+			}
+			if err := checkField(fields, p.Name); err != nil {
+				return errors.Wrapf(err, "action %s param", a.Name)
+			}
+		}
+		fields = make(map[string]bool)
+		for _, c := range a.Returns {
+			if _, err := columnType(c.Type, c.Nullable); err != nil {
+				return errors.Wrapf(err, "action %s column %s", a.Name, c.Name)
+			}
+			if err := checkField(fields, c.Name); err != nil {
+				return errors.Wrapf(err, "action %s column", a.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func checkField(seen map[string]bool, name string) error {
+	field := goName(name)
+	if !token.IsIdentifier(field) {
+		return errors.Errorf("%q has no valid Go field name", name)
+	}
+	if seen[field] {
+		return errors.Errorf("%q maps to duplicate field %s", name, field)
+	}
+	seen[field] = true
+	return nil
+}
+
+func (a Action) methodName() string {
+	if a.GoName != "" {
+		return a.GoName
+	}
+	return goName(a.Name)
+}
+
+// goName turns a snake_case action, parameter or column name into an
+// exported Go identifier: "$frozen_at" becomes "FrozenAt".
+func goName(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(strings.TrimPrefix(name, "$"), "_") {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]))
+		b.WriteString(part[1:])
+	}
+	out := b.String()
+	if out != "" && out[0] >= '0' && out[0] <= '9' {
+		out = "X" + out
+	}
+	return out
+}
@@ -0,0 +1,149 @@
+package tngen
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// sqlType is a parsed action parameter or column type.
+type sqlType struct {
+	base      string // text, int8, bool, numeric, uuid or bytea
+	array     bool
+	precision uint16
+	scale     uint16
+}
+
+var typeAliases = map[string]string{
+	"text":    "text",
+	"int":     "int8",
+	"int2":    "int8",
+	"int4":    "int8",
+	"int8":    "int8",
+	"integer": "int8",
+	"bigint":  "int8",
+	"bool":    "bool",
+	"boolean": "bool",
+	"numeric": "numeric",
+	"decimal": "numeric",
+	"uuid":    "uuid",
+	"bytea":   "bytea",
+	"blob":    "bytea",
+}
+
+func parseSQLType(raw string) (sqlType, error) {
+	s := strings.ToLower(strings.TrimSpace(raw))
+	var t sqlType
+	if strings.HasSuffix(s, "[]") {
+		t.array = true
+		s = strings.TrimSpace(strings.TrimSuffix(s, "[]"))
+	}
+	if open := strings.IndexByte(s, '('); open >= 0 {
+		if !strings.HasSuffix(s, ")") {
+			return t, errors.Errorf("malformed type %q", raw)
+		}
+		parts := strings.Split(s[open+1:len(s)-1], ",")
+		if len(parts) != 2 {
+			return t, errors.Errorf("type %q: expected precision and scale", raw)
+		}
+		precision, err := strconv.ParseUint(strings.TrimSpace(parts[0]), 10, 16)
+		if err != nil {
+			return t, errors.Wrapf(err, "type %q: precision", raw)
+		}
+		scale, err := strconv.ParseUint(strings.TrimSpace(parts[1]), 10, 16)
+		if err != nil {
+			return t, errors.Wrapf(err, "type %q: scale", raw)
+		}
+		if scale > precision {
+			return t, errors.Errorf("type %q: scale exceeds precision", raw)
+		}
+		t.precision, t.scale = uint16(precision), uint16(scale)
+		s = strings.TrimSpace(s[:open])
+	}
+	base, ok := typeAliases[s]
+	if !ok {
+		return t, errors.Errorf("unsupported type %q", raw)
+	}
+	if t.precision > 0 && base != "numeric" {
+		return t, errors.Errorf("type %q: only numeric takes precision and scale", raw)
+	}
+	t.base = base
+	return t, nil
+}
+
+// paramField is how a parameter is declared in the input struct and, for
+// numeric and uuid, how its text value is converted before the call.
+type paramField struct {
+	goType string
+	sql    sqlType
+}
+
+// convert returns the kwil-db parse expression for the parameter, or "" when
+// the field value is sent as is.
+func (f paramField) convert(v string) string {
+	switch {
+	case f.sql.base == "numeric" && f.sql.precision > 0:
+		return "kwiltypes.ParseDecimalExplicit(" + v + ", " + strconv.Itoa(int(f.sql.precision)) + ", " + strconv.Itoa(int(f.sql.scale)) + ")"
+	case f.sql.base == "numeric":
+		return "kwiltypes.ParseDecimal(" + v + ")"
+	case f.sql.base == "uuid":
+		return "kwiltypes.ParseUUID(" + v + ")"
+	}
+	return ""
+}
+
+// paramType maps a parameter type to its input field type, before nullable
+// scalars are made pointers. Numeric and uuid values are written as text and
+// parsed by the generated method.
+func paramType(raw string) (paramField, error) {
+	t, err := parseSQLType(raw)
+	if err != nil {
+		return paramField{}, err
+	}
+	var goType string
+	switch t.base {
+	case "text", "numeric", "uuid":
+		goType = "string"
+	case "int8":
+		goType = "int64"
+	case "bool":
+		goType = "bool"
+	case "bytea":
+		goType = "[]byte"
+	}
+	if t.array {
+		if t.base == "numeric" || t.base == "uuid" {
+			return paramField{}, errors.Errorf("array parameters of type %s are not supported", t.base)
+		}
+		goType = "[]" + goType
+	}
+	return paramField{goType: goType, sql: t}, nil
+}
+
+// columnType maps a result column type to a field type DecodeCallResult can
+// scan into. Numeric and uuid columns are kept as their text form.
+func columnType(raw string, nullable bool) (string, error) {
+	t, err := parseSQLType(raw)
+	if err != nil {
+		return "", err
+	}
+	var goType string
+	switch t.base {
+	case "text", "numeric", "uuid":
+		goType = "string"
+	case "int8":
+		goType = "int64"
+	case "bool":
+		goType = "bool"
+	case "bytea":
+		goType = "[]byte"
+	}
+	switch {
+	case t.array:
+		return "[]" + goType, nil
+	case nullable && t.base != "bytea":
+		return "*" + goType, nil
+	}
+	return goType, nil
+}
@@ -1,15 +1,66 @@
 package types
 
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
+)
+
 // ActionInfo contains metadata about an attestation action
 type ActionInfo struct {
-	ID          uint16 // Unique action ID (1-9 currently defined)
+	ID          uint16 // Unique action ID (1-9 built in)
 	Name        string // Action name as used in query_components
 	IsBinary    bool   // True for binary (TRUE/FALSE) actions, false for numeric actions
 	Description string // Human-readable description
+
+	// Args is the action's argument schema, in call order. Nil skips argument
+	// validation.
+	Args []ActionArg
+	// DecodeResult decodes the result field of the action's attestation
+	// payloads. Nil uses abi.encode(bool) for binary actions and
+	// abi.encode(uint256[], int256[]) otherwise.
+	DecodeResult ResultDecoder
+}
+
+// ActionArg describes one argument of an attestable action.
+type ActionArg struct {
+	Name string
+	// Type is the node's type name: text, int8, bool or numeric(p,s). Other
+	// types are not checked.
+	Type string
+	// Optional arguments may be nil, and may be left off the end of the call.
+	Optional bool
 }
 
-// ActionRegistry maps action names to their metadata
-// These correspond to the actions defined in the node's migrations:
+// ResultDecoder decodes the ABI-encoded result of an attestation payload.
+type ResultDecoder func(result []byte) ([]DecodedRow, error)
+
+var (
+	recordArgs = []ActionArg{
+		{Name: "data_provider", Type: "text"},
+		{Name: "stream_id", Type: "text"},
+		{Name: "from", Type: "int8", Optional: true},
+		{Name: "to", Type: "int8", Optional: true},
+		{Name: "frozen_at", Type: "int8", Optional: true},
+		{Name: "use_cache", Type: "bool", Optional: true},
+	}
+	thresholdArgs = []ActionArg{
+		{Name: "data_provider", Type: "text"},
+		{Name: "stream_id", Type: "text"},
+		{Name: "timestamp", Type: "int8"},
+		{Name: "threshold", Type: "numeric(36,18)"},
+		{Name: "frozen_at", Type: "int8", Optional: true},
+	}
+)
+
+// ActionRegistry maps action names to their metadata. Add actions with
+// RegisterAction rather than writing to the map, which is not safe for
+// concurrent use.
+// The built-in actions correspond to the actions defined in the node's migrations:
 // - 000-create-tn.sql (IDs 1-5: numeric actions)
 // - 040-binary-attestation-actions.sql (IDs 6-9: binary actions)
 var ActionRegistry = map[string]ActionInfo{
@@ -19,30 +70,56 @@ var ActionRegistry = map[string]ActionInfo{
 		Name:        "get_record",
 		IsBinary:    false,
 		Description: "Get record value at a specific timestamp",
+		Args:        recordArgs,
 	},
 	"get_index": {
 		ID:          2,
 		Name:        "get_index",
 		IsBinary:    false,
 		Description: "Get index value at a specific timestamp",
+		Args:        recordArgs,
 	},
 	"get_change_over_time": {
 		ID:          3,
 		Name:        "get_change_over_time",
 		IsBinary:    false,
 		Description: "Get change in value over a time period",
+		Args: []ActionArg{
+			{Name: "data_provider", Type: "text"},
+			{Name: "stream_id", Type: "text"},
+			{Name: "from", Type: "int8", Optional: true},
+			{Name: "to", Type: "int8", Optional: true},
+			{Name: "frozen_at", Type: "int8", Optional: true},
+			{Name: "base_time", Type: "int8", Optional: true},
+			{Name: "time_interval", Type: "int8"},
+			{Name: "use_cache", Type: "bool", Optional: true},
+		},
 	},
 	"get_last_record": {
 		ID:          4,
 		Name:        "get_last_record",
 		IsBinary:    false,
 		Description: "Get the most recent record value",
+		Args: []ActionArg{
+			{Name: "data_provider", Type: "text"},
+			{Name: "stream_id", Type: "text"},
+			{Name: "before", Type: "int8", Optional: true},
+			{Name: "frozen_at", Type: "int8", Optional: true},
+			{Name: "use_cache", Type: "bool", Optional: true},
+		},
 	},
 	"get_first_record": {
 		ID:          5,
 		Name:        "get_first_record",
 		IsBinary:    false,
 		Description: "Get the earliest record value",
+		Args: []ActionArg{
+			{Name: "data_provider", Type: "text"},
+			{Name: "stream_id", Type: "text"},
+			{Name: "after", Type: "int8", Optional: true},
+			{Name: "frozen_at", Type: "int8", Optional: true},
+			{Name: "use_cache", Type: "bool", Optional: true},
+		},
 	},
 
 	// Binary actions (IDs 6-9) - return bool (TRUE/FALSE)
@@ -51,38 +128,114 @@ var ActionRegistry = map[string]ActionInfo{
 		Name:        "price_above_threshold",
 		IsBinary:    true,
 		Description: "TRUE if value > threshold (e.g., 'Will BTC exceed $100k?')",
+		Args:        thresholdArgs,
 	},
 	"price_below_threshold": {
 		ID:          7,
 		Name:        "price_below_threshold",
 		IsBinary:    true,
 		Description: "TRUE if value < threshold (e.g., 'Will unemployment drop below 4%?')",
+		Args:        thresholdArgs,
 	},
 	"value_in_range": {
 		ID:          8,
 		Name:        "value_in_range",
 		IsBinary:    true,
 		Description: "TRUE if min <= value <= max (e.g., 'Will BTC stay between $90k-$110k?')",
+		Args: []ActionArg{
+			{Name: "data_provider", Type: "text"},
+			{Name: "stream_id", Type: "text"},
+			{Name: "timestamp", Type: "int8"},
+			{Name: "min_value", Type: "numeric(36,18)"},
+			{Name: "max_value", Type: "numeric(36,18)"},
+			{Name: "frozen_at", Type: "int8", Optional: true},
+		},
 	},
 	"value_equals": {
 		ID:          9,
 		Name:        "value_equals",
 		IsBinary:    true,
 		Description: "TRUE if value = target ± tolerance (e.g., 'Will Fed rate be exactly 5.25%?')",
+		Args: []ActionArg{
+			{Name: "data_provider", Type: "text"},
+			{Name: "stream_id", Type: "text"},
+			{Name: "timestamp", Type: "int8"},
+			{Name: "target_value", Type: "numeric(36,18)"},
+			{Name: "tolerance", Type: "numeric(36,18)"},
+			{Name: "frozen_at", Type: "int8", Optional: true},
+		},
 	},
 }
 
 // ActionByID maps action IDs to their metadata
 var ActionByID = map[uint16]ActionInfo{}
 
+// registryMu guards ActionRegistry and ActionByID.
+var registryMu sync.RWMutex
+
 func init() {
 	for _, info := range ActionRegistry {
 		ActionByID[info.ID] = info
 	}
 }
 
+// RegisterAction adds an attestable action to the registry, e.g. one deployed
+// on the node after this SDK was released. Registering an action again with
+// the same ID and name replaces its metadata; reusing an ID or name for a
+// different action is an error.
+func RegisterAction(info ActionInfo) error {
+	if info.ID == 0 {
+		return fmt.Errorf("action %q: ID is required", info.Name)
+	}
+	if info.Name == "" {
+		return fmt.Errorf("action %d: name is required", info.ID)
+	}
+	for i, arg := range info.Args {
+		if arg.Name == "" {
+			return fmt.Errorf("action %s: arg %d has no name", info.Name, i)
+		}
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if existing, ok := ActionByID[info.ID]; ok && existing.Name != info.Name {
+		return fmt.Errorf("action ID %d is already registered as %s", info.ID, existing.Name)
+	}
+	if existing, ok := ActionRegistry[info.Name]; ok && existing.ID != info.ID {
+		return fmt.Errorf("action %s is already registered with ID %d", info.Name, existing.ID)
+	}
+	ActionRegistry[info.Name] = info
+	ActionByID[info.ID] = info
+	return nil
+}
+
+// UnregisterAction removes the action with the given ID, if registered.
+// Tests use it to undo RegisterAction; built-in actions can be removed too.
+func UnregisterAction(id uint16) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if info, ok := ActionByID[id]; ok {
+		delete(ActionRegistry, info.Name)
+		delete(ActionByID, id)
+	}
+}
+
+// Actions returns the registered actions ordered by ID.
+func Actions() []ActionInfo {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	out := make([]ActionInfo, 0, len(ActionByID))
+	for _, info := range ActionByID {
+		out = append(out, info)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
 // GetActionInfo returns the ActionInfo for a given action name, or nil if not found
 func GetActionInfo(name string) *ActionInfo {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
 	if info, ok := ActionRegistry[name]; ok {
 		return &info
 	}
@@ -91,23 +244,25 @@ func GetActionInfo(name string) *ActionInfo {
 
 // GetActionInfoByID returns the ActionInfo for a given action ID, or nil if not found
 func GetActionInfoByID(id uint16) *ActionInfo {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
 	if info, ok := ActionByID[id]; ok {
 		return &info
 	}
 	return nil
 }
 
-// IsBinaryAction returns true if the action name corresponds to a binary action (IDs 6-9)
+// IsBinaryAction returns true if the action name corresponds to a binary action
 func IsBinaryAction(name string) bool {
-	if info, ok := ActionRegistry[name]; ok {
+	if info := GetActionInfo(name); info != nil {
 		return info.IsBinary
 	}
 	return false
 }
 
-// IsBinaryActionID returns true if the action ID corresponds to a binary action (6-9)
+// IsBinaryActionID returns true if the action ID corresponds to a binary action
 func IsBinaryActionID(id uint16) bool {
-	if info, ok := ActionByID[id]; ok {
+	if info := GetActionInfoByID(id); info != nil {
 		return info.IsBinary
 	}
 	return false
@@ -115,7 +270,7 @@ func IsBinaryActionID(id uint16) bool {
 
 // GetActionID returns the action ID for a given action name, or 0 if not found
 func GetActionID(name string) uint16 {
-	if info, ok := ActionRegistry[name]; ok {
+	if info := GetActionInfo(name); info != nil {
 		return info.ID
 	}
 	return 0
@@ -123,7 +278,7 @@ func GetActionID(name string) uint16 {
 
 // GetActionName returns the action name for a given action ID, or empty string if not found
 func GetActionName(id uint16) string {
-	if info, ok := ActionByID[id]; ok {
+	if info := GetActionInfoByID(id); info != nil {
 		return info.Name
 	}
 	return ""
@@ -131,12 +286,86 @@ func GetActionName(id uint16) string {
 
 // ValidateActionName returns an error if the action name is not recognized
 func ValidateActionName(name string) error {
-	if _, ok := ActionRegistry[name]; !ok {
+	if GetActionInfo(name) == nil {
+		return &UnknownActionError{Name: name}
+	}
+	return nil
+}
+
+// ValidateActionArgs checks args against the argument schema of a registered
+// action: their count, that required arguments are set, and the Go types of
+// text, int8, bool and numeric arguments. Actions without a schema accept any
+// args.
+func ValidateActionArgs(name string, args []any) error {
+	info := GetActionInfo(name)
+	if info == nil {
 		return &UnknownActionError{Name: name}
 	}
+	if info.Args == nil {
+		return nil
+	}
+	if len(args) > len(info.Args) {
+		return fmt.Errorf("%s takes at most %d args, got %d", name, len(info.Args), len(args))
+	}
+	for i, spec := range info.Args {
+		var arg any
+		if i < len(args) {
+			arg = args[i]
+		}
+		if isNilArg(arg) {
+			if !spec.Optional {
+				return fmt.Errorf("%s: %s is required", name, spec.Name)
+			}
+			continue
+		}
+		if !argHasType(arg, spec.Type) {
+			return fmt.Errorf("%s: %s must be %s, got %T", name, spec.Name, spec.Type, arg)
+		}
+	}
 	return nil
 }
 
+func isNilArg(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
+
+// argHasType reports whether v can be encoded as the node type typ. Unknown
+// types always match.
+func argHasType(v any, typ string) bool {
+	typ = strings.ToLower(typ)
+	switch {
+	case typ == "text":
+		switch v.(type) {
+		case string, *string:
+			return true
+		}
+		return false
+	case typ == "int8" || typ == "int":
+		switch v.(type) {
+		case int, int8, int16, int32, int64, uint8, uint16, uint32, *int, *int64:
+			return true
+		}
+		return false
+	case typ == "bool":
+		switch v.(type) {
+		case bool, *bool:
+			return true
+		}
+		return false
+	case strings.HasPrefix(typ, "numeric"):
+		switch v.(type) {
+		case kwiltypes.Decimal, *kwiltypes.Decimal:
+			return true
+		}
+		return false
+	}
+	return true
+}
+
 // UnknownActionError is returned when an unrecognized action name is used
 type UnknownActionError struct {
 	Name string
@@ -0,0 +1,52 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterAction(t *testing.T) {
+	t.Cleanup(func() { UnregisterAction(3001) })
+	require.Error(t, RegisterAction(ActionInfo{Name: "test_no_id"}))
+	require.Error(t, RegisterAction(ActionInfo{ID: 3001}))
+	require.Error(t, RegisterAction(ActionInfo{ID: 3001, Name: "test_bad_args", Args: []ActionArg{{Type: "text"}}}))
+	// IDs and names of other actions cannot be reused.
+	require.Error(t, RegisterAction(ActionInfo{ID: 6, Name: "test_above"}))
+	require.Error(t, RegisterAction(ActionInfo{ID: 3001, Name: "price_above_threshold"}))
+
+	require.NoError(t, RegisterAction(ActionInfo{ID: 3001, Name: "test_above", IsBinary: true}))
+	require.True(t, IsBinaryAction("test_above"))
+	require.Equal(t, "test_above", GetActionName(3001))
+	require.NoError(t, ValidateActionName("test_above"))
+
+	// Registering again replaces the metadata.
+	require.NoError(t, RegisterAction(ActionInfo{ID: 3001, Name: "test_above", Description: "updated"}))
+	require.False(t, IsBinaryActionID(3001))
+	require.Equal(t, "updated", GetActionInfo("test_above").Description)
+
+	actions := Actions()
+	require.Equal(t, uint16(1), actions[0].ID)
+	require.Equal(t, uint16(3001), actions[len(actions)-1].ID)
+
+	UnregisterAction(3001)
+	require.Nil(t, GetActionInfo("test_above"))
+	require.Nil(t, GetActionInfoByID(3001))
+}
+
+func TestValidateActionArgs(t *testing.T) {
+	provider := "0x1111111111111111111111111111111111111111"
+	stream := "stbtcusd000000000000000000000000"
+	from := int64(1)
+
+	require.NoError(t, ValidateActionArgs("get_record", []any{provider, stream, from, nil, nil, false}))
+	require.NoError(t, ValidateActionArgs("get_record", []any{&provider, &stream, &from}))
+	require.ErrorContains(t, ValidateActionArgs("get_record", []any{provider}), "stream_id is required")
+	require.ErrorContains(t, ValidateActionArgs("get_record", []any{provider, stream, "1"}), "from must be int8")
+	require.ErrorContains(t, ValidateActionArgs("get_record", make([]any, 7)), "at most 6 args")
+	require.ErrorContains(t, ValidateActionArgs("get_change_over_time", []any{provider, stream, nil, nil, nil, nil}), "time_interval is required")
+	require.ErrorContains(t, ValidateActionArgs("value_in_range", []any{provider, stream, from, 1.5, 2.5}), "min_value must be numeric(36,18)")
+
+	var unknown *UnknownActionError
+	require.ErrorAs(t, ValidateActionArgs("unknown_action", nil), &unknown)
+}
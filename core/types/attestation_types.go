@@ -42,12 +42,12 @@ type ListAttestationsInput struct {
 
 // AttestationMetadata represents a single attestation in a list
 type AttestationMetadata struct {
-	RequestTxID     string
-	AttestationHash []byte
-	Requester       []byte
-	CreatedHeight   int64
-	SignedHeight    *int64 // Nil if not yet signed
-	EncryptSig      bool
+	RequestTxID     string `tn:"request_tx_id"`
+	AttestationHash []byte `tn:"attestation_hash,base64"`
+	Requester       []byte `tn:"requester,base64"`
+	CreatedHeight   int64  `tn:"created_height"`
+	SignedHeight    *int64 `tn:"signed_height"` // Nil if not yet signed
+	EncryptSig      bool   `tn:"encrypt_sig"`
 }
 
 // IAttestationAction provides methods for requesting and retrieving attestations
@@ -97,6 +97,13 @@ func (r *RequestAttestationInput) Validate() error {
 	if r.ActionName == "" {
 		return fmt.Errorf("action_name cannot be empty")
 	}
+	// Args of registered actions must match their schema; the node checks
+	// actions the SDK does not know about.
+	if GetActionInfo(r.ActionName) != nil {
+		if err := ValidateActionArgs(r.ActionName, r.Args); err != nil {
+			return err
+		}
+	}
 	if r.EncryptSig {
 		return fmt.Errorf("encryption not implemented in MVP")
 	}
@@ -32,63 +32,63 @@ type MAAExecuteInput struct {
 
 // MAARule is a rule's terms (maa_get_rule).
 type MAARule struct {
-	RuleID         string // 32-byte content-hash identifier
-	RestrictedAddr string // the agent / rule creator
-	RulesHash      string // commitment over fee + allow-list
-	FeeMode        string // "bps" | "flat"
-	FeeBps         int64
-	FeeFlat        string // base-unit decimal string
-	CreatedAt      int64
+	RuleID         string `tn:"rule_id"`         // 32-byte content-hash identifier
+	RestrictedAddr string `tn:"restricted_addr"` // the agent / rule creator
+	RulesHash      string `tn:"rules_hash"`      // commitment over fee + allow-list
+	FeeMode        string `tn:"fee_mode"`        // "bps" | "flat"
+	FeeBps         int64  `tn:"fee_bps"`
+	FeeFlat        string `tn:"fee_flat"` // base-unit decimal string
+	CreatedAt      int64  `tn:"created_at"`
 }
 
 // MAAAllowedAction is one allow-list entry (maa_get_allowed_actions).
 type MAAAllowedAction struct {
-	Namespace string
-	Action    string
-	BodyHash  string // "" when unpinned
+	Namespace string `tn:"namespace"`
+	Action    string `tn:"action"`
+	BodyHash  string `tn:"body_hash"` // "" when unpinned
 }
 
 // MAAInstance is an agent wallet and its two component keys (maa_get_instance) — the primary
 // explorer/wallet lookup: MAA address -> {rule, restricted, unrestricted}.
 type MAAInstance struct {
-	MAAAddress       string // 20-byte ETH address that holds funds
-	RuleID           string
-	RestrictedAddr   string // the agent
-	UnrestrictedAddr string // the owner / funder
-	CreatedAt        int64
+	MAAAddress       string `tn:"maa_address"` // 20-byte ETH address that holds funds
+	RuleID           string `tn:"rule_id"`
+	RestrictedAddr   string `tn:"restricted_addr"`   // the agent
+	UnrestrictedAddr string `tn:"unrestricted_addr"` // the owner / funder
+	CreatedAt        int64  `tn:"created_at"`
 }
 
 // MAARuleRef references a rule created by an agent (maa_list_by_restricted).
 type MAARuleRef struct {
-	RuleID    string
-	CreatedAt int64
+	RuleID    string `tn:"rule_id"`
+	CreatedAt int64  `tn:"created_at"`
 }
 
 // MAAOwnedWallet references a wallet an owner funded (maa_list_by_unrestricted).
 type MAAOwnedWallet struct {
-	MAAAddress string
-	RuleID     string
-	CreatedAt  int64
+	MAAAddress string `tn:"maa_address"`
+	RuleID     string `tn:"rule_id"`
+	CreatedAt  int64  `tn:"created_at"`
 }
 
 // MAARuleWallet references a wallet under a rule (maa_list_instances_by_rule).
 type MAARuleWallet struct {
-	MAAAddress       string
-	UnrestrictedAddr string
-	CreatedAt        int64
+	MAAAddress       string `tn:"maa_address"`
+	UnrestrictedAddr string `tn:"unrestricted_addr"`
+	CreatedAt        int64  `tn:"created_at"`
 }
 
 // MAAEvent is one append-only audit row (maa_get_events).
 type MAAEvent struct {
-	ID             int64
-	MAAAddress     string // "" for rule-level events (e.g. CREATE_RULE)
-	EventType      string // CREATE_RULE | JOIN | ... (FUND/EXEC/WITHDRAW added by later issues)
-	ActorRole      string // restricted | unrestricted
-	ActorAddr      string
-	InnerNamespace string // "" until exec events
-	InnerAction    string
-	Amount         string // "" unless populated by fee/withdraw events
-	TxHash         string
-	BlockHeight    int64
-	BlockTimestamp int64
+	ID             int64  `tn:"id"`
+	MAAAddress     string `tn:"maa_address"` // "" for rule-level events (e.g. CREATE_RULE)
+	EventType      string `tn:"event_type"`  // CREATE_RULE | JOIN | ... (FUND/EXEC/WITHDRAW added by later issues)
+	ActorRole      string `tn:"actor_role"`  // restricted | unrestricted
+	ActorAddr      string `tn:"actor_addr"`
+	InnerNamespace string `tn:"inner_namespace"` // "" until exec events
+	InnerAction    string `tn:"inner_action"`
+	Amount         string `tn:"amount"` // "" unless populated by fee/withdraw events
+	TxHash         string `tn:"tx_hash"`
+	BlockHeight    int64  `tn:"block_height"`
+	BlockTimestamp int64  `tn:"block_timestamp"`
 }
@@ -6,8 +6,8 @@ import (
 	"fmt"
 	"time"
 
-	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
 	kwilClientType "github.com/trufnetwork/kwil-db/core/client/types"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
 )
 
 // ═══════════════════════════════════════════════════════════════
@@ -191,6 +191,18 @@ var ValidBridges = map[string]bool{
 	"ethereum_bridge": true,
 }
 
+// BridgeDecimals maps bridge namespaces to the decimals of their token.
+// Collateral amounts returned for a bridge (vault balances, locked
+// collateral, rewards) are in that token's smallest unit.
+var BridgeDecimals = map[string]int{
+	"eth_usdc":        6,
+	"eth_truf":        18,
+	"hoodi_tt":        18,
+	"hoodi_tt2":       18,
+	"sepolia_bridge":  18,
+	"ethereum_bridge": 18,
+}
+
 // Validate checks if CreateMarketInput is valid
 func (c *CreateMarketInput) Validate() error {
 	// Validate bridge
@@ -649,41 +661,41 @@ func (g *GetCollateralByWalletInput) Validate() error {
 
 // MarketInfo contains detailed information about a market
 type MarketInfo struct {
-	ID              int     // Query ID (returned by get_market_by_hash)
-	Hash            []byte  // 32-byte query hash (BYTEA)
-	QueryComponents []byte  // ABI-encoded query components (BYTEA) - only from get_market_info
-	Bridge          string  // Bridge namespace (TEXT) - only from get_market_info
-	SettleTime      int64   // Unix timestamp (INT8)
-	Settled         bool    // Settlement status (BOOL)
-	WinningOutcome  *bool   // TRUE=YES won, FALSE=NO won, nil=not settled (BOOL nullable)
-	SettledAt       *int64  // Settlement timestamp, nil if not settled (INT8 nullable)
-	MaxSpread       int     // LP reward spread (1-50 cents) (INT)
-	MinOrderSize    int64   // LP reward minimum (INT8)
-	CreatedAt       int64   // Creation block height (INT8)
-	Creator         []byte  // Creator's Ethereum address (BYTEA)
+	ID              int    `tn:"id"`                      // Query ID (returned by get_market_by_hash)
+	Hash            []byte `tn:"hash,base64"`             // 32-byte query hash (BYTEA)
+	QueryComponents []byte `tn:"query_components,base64"` // ABI-encoded query components (BYTEA) - only from get_market_info
+	Bridge          string `tn:"bridge"`                  // Bridge namespace (TEXT) - only from get_market_info
+	SettleTime      int64  `tn:"settle_time"`             // Unix timestamp (INT8)
+	Settled         bool   `tn:"settled"`                 // Settlement status (BOOL)
+	WinningOutcome  *bool  `tn:"winning_outcome"`         // TRUE=YES won, FALSE=NO won, nil=not settled (BOOL nullable)
+	SettledAt       *int64 `tn:"settled_at"`              // Settlement timestamp, nil if not settled (INT8 nullable)
+	MaxSpread       int    `tn:"max_spread"`              // LP reward spread (1-50 cents) (INT)
+	MinOrderSize    int64  `tn:"min_order_size"`          // LP reward minimum (INT8)
+	CreatedAt       int64  `tn:"created_at"`              // Creation block height (INT8)
+	Creator         []byte `tn:"creator,base64"`          // Creator's Ethereum address (BYTEA)
 }
 
 // MarketSummary contains summary information about a market
 type MarketSummary struct {
-	ID             int     // Query ID
-	Hash           []byte  // 32-byte query hash
-	SettleTime     int64   // Unix timestamp
-	Settled        bool    // Settlement status
-	WinningOutcome *bool   // Winner if settled
-	MaxSpread      int     // LP reward spread
-	MinOrderSize   int64   // LP reward minimum
-	CreatedAt      int64   // Creation block
+	ID             int    `tn:"id"`              // Query ID
+	Hash           []byte `tn:"hash,base64"`     // 32-byte query hash
+	SettleTime     int64  `tn:"settle_time"`     // Unix timestamp
+	Settled        bool   `tn:"settled"`         // Settlement status
+	WinningOutcome *bool  `tn:"winning_outcome"` // Winner if settled
+	MaxSpread      int    `tn:"max_spread"`      // LP reward spread
+	MinOrderSize   int64  `tn:"min_order_size"`  // LP reward minimum
+	CreatedAt      int64  `tn:"created_at"`      // Creation block
 }
 
 // MarketValidation contains market collateral validation results
 type MarketValidation struct {
-	ValidTokenBinaries bool   // TRUE if total_true = total_false
-	ValidCollateral    bool   // TRUE if vault balance matches expected
-	TotalTrue          int64  // Total TRUE shares (holdings + sell orders)
-	TotalFalse         int64  // Total FALSE shares (holdings + sell orders)
-	VaultBalance       string // Current vault balance (NUMERIC(78,0) as string)
-	ExpectedCollateral string // Expected collateral (NUMERIC(78,0) as string)
-	OpenBuysValue      int64  // Sum of buy order collateral in cents
+	ValidTokenBinaries bool   `tn:"valid_token_binaries"` // TRUE if total_true = total_false
+	ValidCollateral    bool   `tn:"valid_collateral"`     // TRUE if vault balance matches expected
+	TotalTrue          int64  `tn:"total_true"`           // Total TRUE shares (holdings + sell orders)
+	TotalFalse         int64  `tn:"total_false"`          // Total FALSE shares (holdings + sell orders)
+	VaultBalance       string `tn:"vault_balance"`        // Current vault balance (NUMERIC(78,0) as string)
+	ExpectedCollateral string `tn:"expected_collateral"`  // Expected collateral (NUMERIC(78,0) as string)
+	OpenBuysValue      int64  `tn:"open_buys_value"`      // Sum of buy order collateral in cents
 }
 
 // ═══════════════════════════════════════════════════════════════
@@ -692,41 +704,41 @@ type MarketValidation struct {
 
 // OrderBookEntry represents a single order in the order book
 type OrderBookEntry struct {
-	ParticipantID int    // Participant ID (INT)
-	Price         int    // Order price (negative=buy, positive=sell) (INT)
-	Amount        int64  // Share amount (INT8)
-	LastUpdated   int64  // Unix timestamp for FIFO ordering (INT8)
-	WalletAddress []byte // Participant's Ethereum address (BYTEA/TEXT)
+	ParticipantID int    `tn:"participant_id"`        // Participant ID (INT)
+	Price         int    `tn:"price"`                 // Order price (negative=buy, positive=sell) (INT)
+	Amount        int64  `tn:"amount"`                // Share amount (INT8)
+	LastUpdated   int64  `tn:"last_updated"`          // Unix timestamp for FIFO ordering (INT8)
+	WalletAddress []byte `tn:"wallet_address,base64"` // Participant's Ethereum address (BYTEA/TEXT)
 }
 
 // UserPosition represents a user's position in a market
 type UserPosition struct {
-	QueryID      int    // Market ID (INT)
-	Outcome      bool   // TRUE=YES, FALSE=NO (BOOL)
-	Price        int    // 0=holding, <0=buy, >0=sell (INT)
-	Amount       int64  // Share amount (INT8)
-	PositionType string // 'holding', 'buy_order', 'sell_order' (TEXT)
+	QueryID      int    `tn:"query_id"`      // Market ID (INT)
+	Outcome      bool   `tn:"outcome"`       // TRUE=YES, FALSE=NO (BOOL)
+	Price        int    `tn:"price"`         // 0=holding, <0=buy, >0=sell (INT)
+	Amount       int64  `tn:"amount"`        // Share amount (INT8)
+	PositionType string `tn:"position_type"` // 'holding', 'buy_order', 'sell_order' (TEXT)
 }
 
 // DepthLevel represents aggregated volume at a price level
 type DepthLevel struct {
-	Price      int   // Price level (INT)
-	BuyVolume  int64 // Total buy volume at this price (INT8)
-	SellVolume int64 // Total sell volume at this price (INT8)
+	Price      int   `tn:"price"`       // Price level (INT)
+	BuyVolume  int64 `tn:"buy_volume"`  // Total buy volume at this price (INT8)
+	SellVolume int64 `tn:"sell_volume"` // Total sell volume at this price (INT8)
 }
 
 // BestPrices contains the current bid/ask spread
 type BestPrices struct {
-	BestBid *int // Highest buy price, nil if no bids (INT nullable)
-	BestAsk *int // Lowest sell price, nil if no asks (INT nullable)
-	Spread  *int // BestAsk - BestBid, nil if either side empty (INT nullable)
+	BestBid *int `tn:"best_bid"` // Highest buy price, nil if no bids (INT nullable)
+	BestAsk *int `tn:"best_ask"` // Lowest sell price, nil if no asks (INT nullable)
+	Spread  *int `tn:"spread"`   // BestAsk - BestBid, nil if either side empty (INT nullable)
 }
 
 // UserCollateral contains user's total locked collateral
 type UserCollateral struct {
-	TotalLocked     string // NUMERIC(78,0) as string - total locked collateral in wei
-	BuyOrdersLocked string // NUMERIC(78,0) as string - collateral locked in buy orders
-	SharesValue     string // NUMERIC(78,0) as string - value of shares at $1.00 per share
+	TotalLocked     string `tn:"total_locked"`      // NUMERIC(78,0) as string - total locked collateral in wei
+	BuyOrdersLocked string `tn:"buy_orders_locked"` // NUMERIC(78,0) as string - collateral locked in buy orders
+	SharesValue     string `tn:"shares_value"`      // NUMERIC(78,0) as string - value of shares at $1.00 per share
 }
 
 // ═══════════════════════════════════════════════════════════════
@@ -735,25 +747,25 @@ type UserCollateral struct {
 
 // DistributionSummary contains fee distribution summary for a market
 type DistributionSummary struct {
-	DistributionID       int    // Unique distribution ID (INT)
-	TotalFeesDistributed string // NUMERIC(78,0) as string - total fees in wei
-	TotalLPCount         int64  // Number of LPs who received rewards (INT8)
-	BlockCount           int64  // Number of blocks sampled (INT8)
-	DistributedAt        int64  // Distribution timestamp (INT8)
+	DistributionID       int    `tn:"distribution_id"`        // Unique distribution ID (INT)
+	TotalFeesDistributed string `tn:"total_fees_distributed"` // NUMERIC(78,0) as string - total fees in wei
+	TotalLPCount         int64  `tn:"total_lp_count"`         // Number of LPs who received rewards (INT8)
+	BlockCount           int64  `tn:"block_count"`            // Number of blocks sampled (INT8)
+	DistributedAt        int64  `tn:"distributed_at"`         // Distribution timestamp (INT8)
 }
 
 // LPRewardDetail contains per-LP reward details
 type LPRewardDetail struct {
-	WalletAddress      []byte // LP's Ethereum address (BYTEA)
-	RewardAmount       string // NUMERIC(78,0) as string - reward in wei
-	TotalRewardPercent string // NUMERIC(10,2) as string - sum of percentages across blocks
+	WalletAddress      []byte `tn:"wallet_address,base64"` // LP's Ethereum address (BYTEA)
+	RewardAmount       string `tn:"reward_amount"`         // NUMERIC(78,0) as string - reward in wei
+	TotalRewardPercent string `tn:"total_reward_percent"`  // NUMERIC(10,2) as string - sum of percentages across blocks
 }
 
 // RewardHistory contains reward history for a participant
 type RewardHistory struct {
-	DistributionID     int    // Distribution ID (INT)
-	QueryID            int    // Market ID (INT)
-	RewardAmount       string // NUMERIC(78,0) as string
-	TotalRewardPercent string // NUMERIC(10,2) as string
-	DistributedAt      int64  // Timestamp (INT8)
+	DistributionID     int    `tn:"distribution_id"`      // Distribution ID (INT)
+	QueryID            int    `tn:"query_id"`             // Market ID (INT)
+	RewardAmount       string `tn:"reward_amount"`        // NUMERIC(78,0) as string
+	TotalRewardPercent string `tn:"total_reward_percent"` // NUMERIC(10,2) as string
+	DistributedAt      int64  `tn:"distributed_at"`       // Timestamp (INT8)
 }
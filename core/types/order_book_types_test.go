@@ -103,6 +103,14 @@ func TestCreateMarketInput_Validate_AllValidBridges(t *testing.T) {
 	}
 }
 
+func TestBridgeDecimals_CoversValidBridges(t *testing.T) {
+	for bridge := range ValidBridges {
+		_, ok := BridgeDecimals[bridge]
+		require.True(t, ok, "no decimals for bridge %s", bridge)
+	}
+	require.Equal(t, 6, BridgeDecimals["eth_usdc"])
+}
+
 func TestCreateMarketInput_Validate_InvalidQueryComponents(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -8,6 +8,19 @@ import (
 	"github.com/trufnetwork/sdk-go/core/util"
 )
 
+// RoleRef identifies a role by its owner namespace and name, as in "system:network_writer".
+type RoleRef struct {
+	Owner    string
+	RoleName string
+}
+
+func (r RoleRef) String() string {
+	return r.Owner + ":" + r.RoleName
+}
+
+// NetworkWriterRole is the system role required to deploy streams.
+var NetworkWriterRole = RoleRef{Owner: "system", RoleName: "network_writer"}
+
 // GrantRoleInput represents the input for granting a role to multiple wallets.
 type GrantRoleInput struct {
 	Owner    string
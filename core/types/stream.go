@@ -127,6 +127,17 @@ type IAction interface {
 	// implicit default).
 	GetAllowZeros(ctx context.Context, locator StreamLocator) (bool, error)
 
+	// GetMetadata returns the active rows for a metadata key, newest first
+	GetMetadata(ctx context.Context, locator StreamLocator, key MetadataKey) ([]MetadataEntry, error)
+	// ListMetadata returns the active rows of every known key plus the given
+	// user-defined keys. The node cannot enumerate keys, so custom keys must be named.
+	ListMetadata(ctx context.Context, locator StreamLocator, extraKeys ...MetadataKey) ([]MetadataEntry, error)
+	// SetMetadata inserts a metadata row for a user-defined key. Protocol keys
+	// (KnownMetadataKeys) are rejected; use their dedicated setters.
+	SetMetadata(ctx context.Context, input SetMetadataInput) (types.Hash, error)
+	// DisableMetadata disables a metadata row of a user-defined key by its row id
+	DisableMetadata(ctx context.Context, input DisableMetadataInput) (types.Hash, error)
+
 	// GetStreamOwner gets the owner of the stream
 	GetStreamOwner(ctx context.Context, locator StreamLocator) ([]byte, error)
 
@@ -0,0 +1,173 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// KnownMetadataKeys lists the metadata keys defined by the protocol. The node
+// offers no way to enumerate the keys present on a stream, so ListMetadata
+// queries these plus any user-defined keys the caller names.
+var KnownMetadataKeys = []MetadataKey{
+	ReadonlyKey,
+	StreamOwner,
+	TypeKey,
+	ComposeVisibilityKey,
+	ReadVisibilityKey,
+	AllowReadWalletKey,
+	AllowComposeStreamKey,
+	DefaultBaseTimeKey,
+	AllowZerosKey,
+}
+
+// IsKnown reports whether the key is one of KnownMetadataKeys.
+func (s MetadataKey) IsKnown() bool {
+	for _, k := range KnownMetadataKeys {
+		if k == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Type infers the metadata type from the Go type the value was built with.
+// Strings map to MetadataTypeString; use an explicit type to store a ref.
+func (v MetadataValue) Type() (MetadataType, error) {
+	switch v.value.(type) {
+	case int:
+		return MetadataTypeInt, nil
+	case bool:
+		return MetadataTypeBool, nil
+	case float64:
+		return MetadataTypeFloat, nil
+	case string:
+		return MetadataTypeString, nil
+	default:
+		return "", errors.Errorf("unsupported metadata value type %T", v.value)
+	}
+}
+
+// SetMetadataInput is the input for IAction.SetMetadata.
+type SetMetadataInput struct {
+	Stream StreamLocator
+	Key    MetadataKey
+	Value  MetadataValue
+	// Type is optional. When empty, the type of Value is used.
+	Type MetadataType
+}
+
+// ResolveType returns the metadata type the value will be stored as.
+func (s SetMetadataInput) ResolveType() (MetadataType, error) {
+	if s.Type != "" {
+		return s.Type, nil
+	}
+	return s.Value.Type()
+}
+
+// DisableMetadataInput is the input for IAction.DisableMetadata. The row must
+// belong to Key; this is checked before the transaction is sent.
+type DisableMetadataInput struct {
+	Stream StreamLocator
+	Key    MetadataKey
+	RowId  string
+}
+
+// MetadataEntry is a single active metadata row. Exactly one of the value
+// fields is set, matching Type.
+type MetadataEntry struct {
+	RowId     string
+	Key       MetadataKey
+	Type      MetadataType
+	ValueI    *int64
+	ValueF    *string // NUMERIC(36,18) as a decimal string to avoid precision loss
+	ValueB    *bool
+	ValueS    *string
+	ValueRef  *string
+	CreatedAt int64
+}
+
+// String renders the value regardless of its type.
+func (e MetadataEntry) String() string {
+	switch {
+	case e.ValueI != nil:
+		return strconv.FormatInt(*e.ValueI, 10)
+	case e.ValueF != nil:
+		return *e.ValueF
+	case e.ValueB != nil:
+		return strconv.FormatBool(*e.ValueB)
+	case e.ValueS != nil:
+		return *e.ValueS
+	case e.ValueRef != nil:
+		return *e.ValueRef
+	default:
+		return ""
+	}
+}
+
+// MetadataScalar is the set of Go types a metadata value can be read as.
+type MetadataScalar interface {
+	int64 | bool | float64 | string
+}
+
+// MetadataEntryValue converts the entry to T. Strings accept both string and
+// ref entries; float64 parses the decimal string.
+func MetadataEntryValue[T MetadataScalar](entry MetadataEntry) (T, error) {
+	var zero T
+	var out any
+	switch any(zero).(type) {
+	case int64:
+		if entry.ValueI == nil {
+			return zero, errors.Errorf("metadata %s is %s, not int", entry.Key, entry.Type)
+		}
+		out = *entry.ValueI
+	case bool:
+		if entry.ValueB == nil {
+			return zero, errors.Errorf("metadata %s is %s, not bool", entry.Key, entry.Type)
+		}
+		out = *entry.ValueB
+	case float64:
+		if entry.ValueF == nil {
+			return zero, errors.Errorf("metadata %s is %s, not float", entry.Key, entry.Type)
+		}
+		f, err := strconv.ParseFloat(*entry.ValueF, 64)
+		if err != nil {
+			return zero, errors.WithStack(err)
+		}
+		out = f
+	case string:
+		switch {
+		case entry.ValueS != nil:
+			out = *entry.ValueS
+		case entry.ValueRef != nil:
+			out = *entry.ValueRef
+		default:
+			return zero, errors.Errorf("metadata %s is %s, not string", entry.Key, entry.Type)
+		}
+	}
+	return out.(T), nil
+}
+
+// GetMetadataValue reads the latest active value of key as T. The boolean is
+// false when the stream has no active row for the key.
+//
+// Example:
+//
+//	unit, ok, err := types.GetMetadataValue[string](ctx, actions, locator, "unit")
+func GetMetadataValue[T MetadataScalar](ctx context.Context, actions IAction, locator StreamLocator, key MetadataKey) (T, bool, error) {
+	var zero T
+	entries, err := actions.GetMetadata(ctx, locator, key)
+	if err != nil {
+		return zero, false, err
+	}
+	if len(entries) == 0 {
+		return zero, false, nil
+	}
+	v, err := MetadataEntryValue[T](entries[0])
+	if err != nil {
+		return zero, false, fmt.Errorf("%s/%s: %w", locator.StreamId.String(), key, err)
+	}
+	return v, true, nil
+}
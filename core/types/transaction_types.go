@@ -7,14 +7,14 @@ import (
 
 // TransactionEvent represents a single transaction from the ledger
 type TransactionEvent struct {
-	TxID             string            // 0x-prefixed transaction hash
-	BlockHeight      int64             // Block height when transaction was included
-	Method           string            // Method name (e.g., "deployStream", "insertRecords")
-	Caller           string            // Ethereum address of caller (lowercase, 0x-prefixed)
-	FeeAmount        string            // Fee amount as string (NUMERIC(78,0) - big number)
-	FeeRecipient     *string           // Primary fee recipient (nullable)
-	Metadata         *string           // Optional metadata JSON (nullable)
-	FeeDistributions []FeeDistribution // Parsed fee distributions
+	TxID             string            `tn:"tx_id"`         // 0x-prefixed transaction hash
+	BlockHeight      int64             `tn:"block_height"`  // Block height when transaction was included
+	Method           string            `tn:"method"`        // Method name (e.g., "deployStream", "insertRecords")
+	Caller           string            `tn:"caller"`        // Ethereum address of caller (lowercase, 0x-prefixed)
+	FeeAmount        string            `tn:"fee_amount"`    // Fee amount as string (NUMERIC(78,0) - big number)
+	FeeRecipient     *string           `tn:"fee_recipient"` // Primary fee recipient (nullable)
+	Metadata         *string           `tn:"metadata"`      // Optional metadata JSON (nullable)
+	FeeDistributions []FeeDistribution `tn:"-"`             // Parsed fee distributions
 }
 
 // FeeDistribution represents a single fee payment to a recipient
@@ -49,16 +49,16 @@ const (
 // Note: list_transaction_fees returns one row per fee distribution,
 // so multiple rows may have the same TxID with different distribution details
 type TransactionFeeEntry struct {
-	TxID                  string  // Transaction hash
-	BlockHeight           int64   // Block height
-	Method                string  // Method name
-	Caller                string  // Transaction caller
-	TotalFee              string  // Total fee amount
-	FeeRecipient          *string // Primary fee recipient (nullable)
-	Metadata              *string // Optional metadata JSON (nullable)
-	DistributionSequence  int     // Sequence number of this distribution
-	DistributionRecipient *string // Recipient of this specific distribution (nullable - NULL when no distributions)
-	DistributionAmount    *string // Amount for this specific distribution (nullable - NULL when no distributions)
+	TxID                  string  `tn:"tx_id"`                  // Transaction hash
+	BlockHeight           int64   `tn:"block_height"`           // Block height
+	Method                string  `tn:"method"`                 // Method name
+	Caller                string  `tn:"caller"`                 // Transaction caller
+	TotalFee              string  `tn:"total_fee"`              // Total fee amount
+	FeeRecipient          *string `tn:"fee_recipient"`          // Primary fee recipient (nullable)
+	Metadata              *string `tn:"metadata"`               // Optional metadata JSON (nullable)
+	DistributionSequence  int     `tn:"distribution_sequence"`  // Sequence number of this distribution
+	DistributionRecipient *string `tn:"distribution_recipient"` // Recipient of this specific distribution (nullable - NULL when no distributions)
+	DistributionAmount    *string `tn:"distribution_amount"`    // Amount for this specific distribution (nullable - NULL when no distributions)
 }
 
 // ITransactionAction defines transaction ledger query methods
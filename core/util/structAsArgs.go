@@ -1,8 +1,10 @@
 package util
 
 import (
-	"github.com/pkg/errors"
 	"reflect"
+
+	"github.com/pkg/errors"
+	kwiltypes "github.com/trufnetwork/kwil-db/core/types"
 )
 
 // addArgOrNull adds a new argument to the list of arguments
@@ -19,8 +21,18 @@ func addArgOrNull(oldArgs []any, newArg any, nullIfZero bool) []any {
 // StructAsArgs converts a struct to a list of arguments from struct fields, in the same order
 // as they are defined in the struct.
 // it also checks if the fields was required by tag
+//
+// Pointer fields are sent as NULL when nil and as the pointed-to value
+// otherwise, so a pointer to a zero value is not turned into NULL.
+// kwil-db decimals (types.Decimal or *types.Decimal) are accepted as is.
 func StructAsArgs(s interface{}) ([]any, error) {
 	v := reflect.ValueOf(s)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, errors.Errorf("expected a struct, got %T", s)
+	}
 	t := v.Type()
 
 	var args []any
@@ -36,7 +48,16 @@ func StructAsArgs(s interface{}) ([]any, error) {
 
 		// check if the field is an accepted type
 		if !isAcceptedType(value) {
-			return nil, errors.Errorf("unsupported field type '%s' for field '%s'", reflect.TypeOf(value).Name(), field.Name)
+			return nil, errors.Errorf("unsupported field type '%s' for field '%s'", field.Type.String(), field.Name)
+		}
+
+		if field.Type.Kind() == reflect.Ptr {
+			if v.Field(i).IsNil() {
+				args = append(args, nil)
+			} else {
+				args = append(args, v.Field(i).Elem().Interface())
+			}
+			continue
 		}
 
 		// if it's not required, then we can add it as NULL if it's zero-like
@@ -46,6 +67,8 @@ func StructAsArgs(s interface{}) ([]any, error) {
 	return args, nil
 }
 
+var decimalType = reflect.TypeOf(kwiltypes.Decimal{})
+
 // isAcceptedType checks if the given value is of an accepted type
 func isAcceptedType(v interface{}) bool {
 	t := reflect.TypeOf(v)
@@ -58,6 +81,10 @@ func isAcceptedType(v interface{}) bool {
 	case reflect.Slice, reflect.Array:
 		// Check if the slice/array element type is an accepted type
 		return isAcceptedType(reflect.Zero(t.Elem()).Interface())
+	case reflect.Ptr:
+		return isAcceptedType(reflect.Zero(t.Elem()).Interface())
+	case reflect.Struct:
+		return t == decimalType
 	default:
 		return false
 	}
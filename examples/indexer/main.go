@@ -12,17 +12,18 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"math/big"
-	"net/http"
 	"os"
+
+	"github.com/trufnetwork/sdk-go/core/orderbook/indexer"
 )
 
 // Indexer URLs
-// Production: https://indexer.infra.truf.network
-// Testnet:    http://ec2-52-15-66-172.us-east-2.compute.amazonaws.com:8080
-var indexerURL = "http://ec2-52-15-66-172.us-east-2.compute.amazonaws.com:8080"
+// Production: indexer.MainnetURL
+// Testnet:    indexer.TestnetURL
+var indexerURL = indexer.TestnetURL
 
 // Example wallet addresses (from testnet order book examples)
 const (
@@ -30,105 +31,23 @@ const (
 	lp1Wallet   = "c11Ff6d3cC60823EcDCAB1089F1A4336053851EF"
 )
 
-// API response types
-
-type APIResponse[T any] struct {
-	OK   bool `json:"ok"`
-	Data T    `json:"data"`
-}
-
-type Market struct {
-	QueryID        int32  `json:"query_id"`
-	QueryHash      string `json:"query_hash"`
-	SettleTime     int64  `json:"settle_time"`
-	Settled        bool   `json:"settled"`
-	WinningOutcome *bool  `json:"winning_outcome"`
-	SettledAt      *int64 `json:"settled_at"`
-	CreatedAt      int64  `json:"created_at"`
-	Creator        string `json:"creator"`
-	MaxSpread      int32  `json:"max_spread"`
-	MinOrderSize   string `json:"min_order_size"`
-	Bridge         string `json:"bridge"`
-}
-
-type Snapshot struct {
-	BlockHeight   int64  `json:"block_height"`
-	Timestamp     int64  `json:"timestamp"`
-	YesBidPrice   *int32 `json:"yes_bid_price"`
-	YesAskPrice   *int32 `json:"yes_ask_price"`
-	NoBidPrice    *int32 `json:"no_bid_price"`
-	NoAskPrice    *int32 `json:"no_ask_price"`
-	YesVolume     *int64 `json:"yes_volume"`
-	NoVolume      *int64 `json:"no_volume"`
-	MidpointPrice *int32 `json:"midpoint_price"`
-	Spread        *int32 `json:"spread"`
-}
-
-type Settlement struct {
-	QueryID            int32  `json:"query_id"`
-	WinningShares      int64  `json:"winning_shares"`
-	LosingShares       int64  `json:"losing_shares"`
-	Payout             string `json:"payout"`
-	RefundedCollateral string `json:"refunded_collateral"`
-	Timestamp          int64  `json:"timestamp"`
-}
-
-type SettlementsData struct {
-	WalletAddress string       `json:"wallet_address"`
-	Settlements   []Settlement `json:"settlements"`
-	TotalWon      int64        `json:"total_won"`
-	TotalLost     int64        `json:"total_lost"`
-}
-
-type Reward struct {
-	QueryID            int32   `json:"query_id"`
-	TotalRewardPercent float64 `json:"total_reward_percent"`
-	RewardAmount       string  `json:"reward_amount"`
-	BlocksSampled      int32   `json:"blocks_sampled"`
-	DistributedAt      int64   `json:"distributed_at"`
-}
-
-type RewardsData struct {
-	WalletAddress string   `json:"wallet_address"`
-	Rewards       []Reward `json:"rewards"`
-	TotalRewards  string   `json:"total_rewards"`
-}
-
-func weiToUSDC(weiStr string) string {
-	wei := new(big.Float)
-	wei.SetString(weiStr)
-	divisor := new(big.Float).SetFloat64(1e18)
-	result := new(big.Float).Quo(wei, divisor)
+func weiToUSDC(wei *big.Int) string {
+	result := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e18))
 	return result.Text('f', 4)
 }
 
-func fetchJSON(url string, target any) error {
-	resp, err := http.Get(url)
-	if err != nil {
-		return fmt.Errorf("HTTP request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("HTTP %d from %s", resp.StatusCode, url)
-	}
-
-	return json.NewDecoder(resp.Body).Decode(target)
-}
-
-func queryMarkets() ([]Market, error) {
+func queryMarkets(ctx context.Context, c *indexer.Client) ([]indexer.Market, error) {
 	fmt.Println("============================================================")
 	fmt.Println("Endpoint 1: List Historical Markets")
 	fmt.Println("============================================================")
 
-	var result APIResponse[[]Market]
-	err := fetchJSON(fmt.Sprintf("%s/v0/prediction-market/markets?limit=5", indexerURL), &result)
+	markets, err := c.ListMarkets(ctx, indexer.MarketFilter{ListOptions: indexer.ListOptions{Limit: 5}})
 	if err != nil {
 		return nil, err
 	}
 
-	fmt.Printf("\nFound %d markets:\n", len(result.Data))
-	for _, m := range result.Data {
+	fmt.Printf("\nFound %d markets:\n", len(markets))
+	for _, m := range markets {
 		status := "ACTIVE"
 		if m.Settled {
 			status = "SETTLED"
@@ -142,31 +61,30 @@ func queryMarkets() ([]Market, error) {
 			}
 		}
 		fmt.Printf("  Market #%d: %s%s\n", m.QueryID, status, outcome)
-		fmt.Printf("    Hash: %s...\n", m.QueryHash[:16])
+		fmt.Printf("    Hash: %x...\n", m.Hash[:min(8, len(m.Hash))])
 		fmt.Printf("    Bridge: %s, Max Spread: %dc\n", m.Bridge, m.MaxSpread)
 	}
 
-	return result.Data, nil
+	return markets, nil
 }
 
-func querySnapshots(queryID int32) error {
+func querySnapshots(ctx context.Context, c *indexer.Client, queryID int) error {
 	fmt.Println("\n============================================================")
 	fmt.Printf("Endpoint 2: Order Book Snapshots (Market #%d)\n", queryID)
 	fmt.Println("============================================================")
 
-	var result APIResponse[[]Snapshot]
-	err := fetchJSON(fmt.Sprintf("%s/v0/prediction-market/markets/%d/snapshots?limit=5", indexerURL, queryID), &result)
+	snapshots, err := c.Snapshots(ctx, queryID, indexer.ListOptions{Limit: 5})
 	if err != nil {
 		return err
 	}
 
-	if len(result.Data) == 0 {
+	if len(snapshots) == 0 {
 		fmt.Println("  No snapshots found for this market.")
 		return nil
 	}
 
-	fmt.Printf("\nFound %d snapshots:\n", len(result.Data))
-	for _, s := range result.Data {
+	fmt.Printf("\nFound %d snapshots:\n", len(snapshots))
+	for _, s := range snapshots {
 		mid := "N/A"
 		spread := "N/A"
 		if s.MidpointPrice != nil {
@@ -181,18 +99,16 @@ func querySnapshots(queryID int32) error {
 	return nil
 }
 
-func querySettlements(wallet string) error {
+func querySettlements(ctx context.Context, c *indexer.Client, wallet string) error {
 	fmt.Println("\n============================================================")
 	fmt.Printf("Endpoint 3: Participant Settlements (%s...)\n", wallet[:10])
 	fmt.Println("============================================================")
 
-	var result APIResponse[SettlementsData]
-	err := fetchJSON(fmt.Sprintf("%s/v0/prediction-market/participants/%s/settlements?limit=10", indexerURL, wallet), &result)
+	data, err := c.Settlements(ctx, wallet, indexer.ListOptions{Limit: 10})
 	if err != nil {
 		return err
 	}
 
-	data := result.Data
 	fmt.Printf("\n  Wallet: %s\n", data.WalletAddress)
 	fmt.Printf("  Total Won: %d, Total Lost: %d\n", data.TotalWon, data.TotalLost)
 
@@ -205,18 +121,16 @@ func querySettlements(wallet string) error {
 	return nil
 }
 
-func queryRewards(wallet string) error {
+func queryRewards(ctx context.Context, c *indexer.Client, wallet string) error {
 	fmt.Println("\n============================================================")
 	fmt.Printf("Endpoint 4: LP Rewards (%s...)\n", wallet[:10])
 	fmt.Println("============================================================")
 
-	var result APIResponse[RewardsData]
-	err := fetchJSON(fmt.Sprintf("%s/v0/prediction-market/participants/%s/rewards?limit=10", indexerURL, wallet), &result)
+	data, err := c.Rewards(ctx, wallet, indexer.ListOptions{Limit: 10})
 	if err != nil {
 		return err
 	}
 
-	data := result.Data
 	fmt.Printf("\n  Wallet: %s\n", data.WalletAddress)
 	fmt.Printf("  Total Rewards: %s USDC\n", weiToUSDC(data.TotalRewards))
 
@@ -234,8 +148,15 @@ func main() {
 	fmt.Println("Indexer URL:", indexerURL)
 	fmt.Println()
 
+	ctx := context.Background()
+	c, err := indexer.New(indexerURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating indexer client: %v\n", err)
+		os.Exit(1)
+	}
+
 	// 1. List markets
-	markets, err := queryMarkets()
+	markets, err := queryMarkets(ctx, c)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error querying markets: %v\n", err)
 		os.Exit(1)
@@ -243,18 +164,18 @@ func main() {
 
 	// 2. Snapshots for the most recent market
 	if len(markets) > 0 {
-		if err := querySnapshots(markets[0].QueryID); err != nil {
+		if err := querySnapshots(ctx, c, markets[0].QueryID); err != nil {
 			fmt.Fprintf(os.Stderr, "Error querying snapshots: %v\n", err)
 		}
 	}
 
 	// 3. Settlement results for buyer
-	if err := querySettlements(buyerWallet); err != nil {
+	if err := querySettlements(ctx, c, buyerWallet); err != nil {
 		fmt.Fprintf(os.Stderr, "Error querying settlements: %v\n", err)
 	}
 
 	// 4. LP rewards for LP1
-	if err := queryRewards(lp1Wallet); err != nil {
+	if err := queryRewards(ctx, c, lp1Wallet); err != nil {
 		fmt.Fprintf(os.Stderr, "Error querying rewards: %v\n", err)
 	}
 